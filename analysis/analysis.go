@@ -0,0 +1,31 @@
+// Package analysis holds the market indicators strikes are sized and stopped
+// with, computed from exchange data but independent of any one venue.
+package analysis
+
+import "math"
+
+// Candle is one OHLC bar
+type Candle struct {
+	Time  int64
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// AverageTrueRange computes a simple-average ATR over the last period bars;
+// ok is false when there are too few bars for the period
+func AverageTrueRange(candles []Candle, period int) (float64, bool) {
+	if period <= 0 || len(candles) < period+1 {
+		return 0, false
+	}
+	sum := 0.0
+	for i := len(candles) - period; i < len(candles); i++ {
+		prevClose := candles[i-1].Close
+		tr := candles[i].High - candles[i].Low
+		tr = math.Max(tr, math.Abs(candles[i].High-prevClose))
+		tr = math.Max(tr, math.Abs(candles[i].Low-prevClose))
+		sum += tr
+	}
+	return sum / float64(period), true
+}
@@ -0,0 +1,25 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAverageTrueRangeUsesGapsFromPreviousClose(t *testing.T) {
+	candles := []Candle{
+		{Close: 100},
+		{High: 103, Low: 99, Close: 102},
+		// Gap up: the range from the previous close beats the bar's own
+		{High: 108, Low: 106, Close: 107},
+	}
+	atr, ok := AverageTrueRange(candles, 2)
+	if !ok || math.Abs(atr-5) > 1e-9 {
+		t.Fatalf("ATR = %v (ok %v), want 5", atr, ok)
+	}
+	if _, ok := AverageTrueRange(candles, 3); ok {
+		t.Fatal("want not ok with too few bars for the period")
+	}
+	if _, ok := AverageTrueRange(candles, 0); ok {
+		t.Fatal("want not ok for a zero period")
+	}
+}
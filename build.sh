@@ -34,7 +34,7 @@ fi
 
 # Build Go trading engine
 echo "🔨 Building Go trading engine..."
-go build -o macro_strike_bot trading_engine.go
+go build -o macro_strike_bot ./cmd/msb
 
 if [ $? -eq 0 ]; then
     echo "✅ Go trading engine built successfully"
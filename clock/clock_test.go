@@ -0,0 +1,56 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeFiresAfterOnAdvance(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	ch := c.After(30 * time.Second)
+	c.Advance(29 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("After fired early")
+	default:
+	}
+	c.Sleep(time.Second)
+	select {
+	case at := <-ch:
+		if !at.Equal(start.Add(30 * time.Second)) {
+			t.Errorf("After fired at %v, want %v", at, start.Add(30*time.Second))
+		}
+	default:
+		t.Fatal("After did not fire once its time passed")
+	}
+}
+
+func TestFakeTickerTicksAndStops(t *testing.T) {
+	c := NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	tick := c.NewTicker(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		c.Advance(10 * time.Second)
+		select {
+		case <-tick.C():
+		default:
+			t.Fatalf("tick %d missing", i+1)
+		}
+	}
+	// Unread ticks are dropped, as with time.Ticker
+	c.Advance(time.Minute)
+	<-tick.C()
+	select {
+	case <-tick.C():
+		t.Fatal("ticker queued more than one tick")
+	default:
+	}
+
+	tick.Stop()
+	c.Advance(time.Minute)
+	select {
+	case <-tick.C():
+		t.Fatal("stopped ticker ticked")
+	default:
+	}
+}
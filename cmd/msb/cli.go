@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/engine"
+	"macro-strike-bot/report"
+)
+
+// settingFlag writes a checked flag value into a Config under its setting
+type settingFlag struct {
+	s   config.Setting
+	cfg config.Config
+}
+
+func (f settingFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return f.cfg[f.s.Env]
+}
+
+func (f settingFlag) IsBoolFlag() bool { return f.s.Kind == config.KindBool }
+
+func (f settingFlag) Set(v string) error {
+	switch f.s.Kind {
+	case config.KindBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", v)
+		}
+		v = "0"
+		if b {
+			v = "1"
+		}
+	case config.KindInt:
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+			return fmt.Errorf("%q is not a non-negative integer", v)
+		}
+	case config.KindFloat:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+	case config.KindDuration:
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 5m", v)
+		}
+	}
+	f.cfg[f.s.Env] = v
+	return nil
+}
+
+// command is one CLI subcommand. Preset settings are forced for the mode and
+// get no flag, so a run can't be both simulated and live.
+type command struct {
+	Name    string
+	Args    string
+	Summary string
+	Preset  config.Config
+	Run     func(ctx context.Context, cfg config.Config, fs *flag.FlagSet) int
+}
+
+var commands []command
+
+// sweepEpoch is where every sweep point's fake clock starts, so run IDs and
+// timestamps match across reruns
+var sweepEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func init() {
+	commands = []command{
+		{Name: "run", Summary: "run a live trading campaign",
+			Preset: config.Config{"LIVE_TRADING": "1", "SIM_MODE": "0"}, Run: runCampaignCommand},
+		{Name: "sim", Summary: "run a simulated campaign without the analyzer or real orders",
+			Preset: config.Config{"LIVE_TRADING": "0", "SIM_MODE": "1"}, Run: runCampaignCommand},
+		{Name: "backtest", Summary: "replay a campaign against recorded Kraken traffic (-kraken-replay-file)",
+			Preset: config.Config{"LIVE_TRADING": "1", "SIM_MODE": "0", "KRAKEN_RECORD_FILE": ""}, Run: runBacktestCommand},
+		{Name: "sweep", Args: "-param NAME=v1,v2,...", Summary: "run one simulated campaign per value of a setting and compare them",
+			Preset: config.Config{"LIVE_TRADING": "0", "SIM_MODE": "1"}, Run: runSweepCommand},
+		{Name: "report", Args: "<campaign_report.json>", Summary: "summarize a saved campaign report", Run: runReportCommand},
+		{Name: "reconcile", Summary: "settle orders left in ORDER_WAL by a previous process, then exit",
+			Preset: config.Config{"LIVE_TRADING": "1", "SIM_MODE": "0"}, Run: runReconcileCommand},
+	}
+}
+
+// toolCommands are the standalone tools, which take their own arguments
+var toolCommands = map[string]func(context.Context, []string) int{
+	"verify-audit":      offline(runVerifyAudit),
+	"compare-reports":   offline(runCompareReports),
+	"import-strike-log": offline(runImportStrikeLog),
+	"import-trades":     runImportTrades,
+}
+
+// offline adapts a tool that only works on local files
+func offline(run func([]string) int) func(context.Context, []string) int {
+	return func(_ context.Context, args []string) int { return run(args) }
+}
+
+// runCLI dispatches os.Args[1:] under ctx, which main cancels on SIGINT or
+// SIGTERM. With no arguments the campaign is configured from the
+// environment alone, as before subcommands existed.
+func runCLI(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		return runCampaignCommand(ctx, config.EnvConfig(), nil)
+	}
+	name := args[0]
+	if tool, ok := toolCommands[name]; ok {
+		return tool(ctx, args[1:])
+	}
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			return runCLI(ctx, []string{args[1], "-help"})
+		}
+		writeCLIUsage(os.Stdout)
+		return 0
+	}
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			cfg, fs, code := parseCommand(cmd, args[1:], config.EnvConfig(), os.Stdout, os.Stderr)
+			if cfg == nil {
+				return code
+			}
+			return cmd.Run(ctx, cfg, fs)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	writeCLIUsage(os.Stderr)
+	return 2
+}
+
+func writeCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: macro-strike-bot <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.Name, cmd.Summary)
+	}
+	tools := make([]string, 0, len(toolCommands))
+	for name := range toolCommands {
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+	for _, name := range tools {
+		fmt.Fprintf(tw, "  %s\t(see %s -h)\n", name, name)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun 'macro-strike-bot <command> -help' for its flags. Every flag overrides the")
+	fmt.Fprintln(w, "environment variable of the same name, e.g. -order-usd-size for ORDER_USD_SIZE.")
+}
+
+// parseCommand layers the environment, the -config file, flags and the
+// command's presets, in that order. A nil Config means the command should
+// exit with the returned code.
+func parseCommand(cmd command, args []string, env config.Config, stdout, stderr io.Writer) (config.Config, *flag.FlagSet, int) {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	overrides := make(config.Config)
+	configPath := fs.String("config", "", "read KEY=value settings from this file; flags override it")
+	for _, s := range config.Settings {
+		if _, fixed := cmd.Preset[s.Env]; fixed {
+			continue
+		}
+		fs.Var(settingFlag{s, overrides}, config.FlagName(s.Env), s.Usage)
+	}
+	if cmd.Name == "sweep" {
+		fs.String("param", "", "NAME=v1,v2,... setting to sweep, by flag or env name")
+	}
+	usage := func(w io.Writer) {
+		fmt.Fprintf(w, "usage: macro-strike-bot %s [flags] %s\n\n%s.\n\n", cmd.Name, cmd.Args, cmd.Summary)
+		writeCommandFlags(w, fs, cmd)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			usage(stdout)
+			return nil, nil, 0
+		}
+		msg := err.Error()
+		if undefined, ok := strings.CutPrefix(msg, "flag provided but not defined: -"); ok {
+			msg += config.SuggestSetting(strings.TrimPrefix(undefined, "-"))
+		}
+		fmt.Fprintf(stderr, "%s: %s\nRun 'macro-strike-bot %s -help' for the flags it accepts.\n", cmd.Name, msg, cmd.Name)
+		return nil, nil, 2
+	}
+	if cmd.Args == "" && fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "%s: unexpected argument %q\n", cmd.Name, fs.Arg(0))
+		return nil, nil, 2
+	}
+	cfg := make(config.Config, len(env))
+	for k, v := range env {
+		cfg[k] = v
+	}
+	if *configPath != "" {
+		if err := config.LoadConfigFile(*configPath, cfg); err != nil {
+			fmt.Fprintf(stderr, "%s: -config: %v\n", cmd.Name, err)
+			return nil, nil, 2
+		}
+	}
+	for k, v := range overrides {
+		cfg[k] = v
+	}
+	for k, v := range cmd.Preset {
+		cfg[k] = v
+	}
+	return cfg, fs, 0
+}
+
+// writeCommandFlags prints the command's flags grouped like the settings table
+func writeCommandFlags(w io.Writer, fs *flag.FlagSet, cmd command) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Flags:")
+	fmt.Fprintf(tw, "  -config FILE\tread KEY=value settings from this file; flags override it\n")
+	if f := fs.Lookup("param"); f != nil {
+		fmt.Fprintf(tw, "  -param NAME=v1,v2,...\t%s\n", f.Usage)
+	}
+	group := ""
+	for _, s := range config.Settings {
+		if fs.Lookup(config.FlagName(s.Env)) == nil {
+			continue
+		}
+		if s.Group != group {
+			group = s.Group
+			fmt.Fprintf(tw, "\n%s:\n", group)
+		}
+		arg := [...]string{config.KindString: " VALUE", config.KindBool: "", config.KindInt: " N", config.KindFloat: " X", config.KindDuration: " DURATION"}[s.Kind]
+		fmt.Fprintf(tw, "  -%s%s\t%s [%s]\n", config.FlagName(s.Env), arg, s.Usage, s.Env)
+	}
+	tw.Flush()
+}
+
+// runCampaignCommand runs a campaign until it ends or is signalled
+func runCampaignCommand(ctx context.Context, cfg config.Config, _ *flag.FlagSet) int {
+	te, err := engine.NewValidated(cfg, clock.Real{})
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+	if err := te.Preflight(ctx); err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+	defer te.RedactLogs()()
+	if addr := cfg.Get("STATUS_ADDR"); addr != "" {
+		if err := te.StartStatusServer(addr); err != nil {
+			log.Printf("⚠️ Status server not started: %v", err)
+		}
+	}
+	defer te.Close()
+	if cfg.Get("TUI") == "1" {
+		if isTerminal(os.Stdout) {
+			dash := engine.StartDashboard(te, os.Stdout)
+			defer dash.Stop()
+		} else {
+			log.Printf("stdout is not a terminal; -tui falls back to plain logs")
+		}
+	}
+	te.RunCampaigns(ctx)
+	return 0
+}
+
+// runBacktestCommand is a campaign served entirely from a Kraken recording
+func runBacktestCommand(ctx context.Context, cfg config.Config, fs *flag.FlagSet) int {
+	if cfg.Get("KRAKEN_REPLAY_FILE") == "" {
+		fmt.Fprintln(os.Stderr, "backtest: -kraken-replay-file is required (record one with -kraken-record-file)")
+		return 2
+	}
+	return runCampaignCommand(ctx, cfg, fs)
+}
+
+// runSweepCommand runs one simulated campaign per value on a fake clock, so
+// each finishes in moments, and prints their results side by side. Engine
+// logging is silenced while the campaigns run.
+func runSweepCommand(ctx context.Context, cfg config.Config, fs *flag.FlagSet) int {
+	name, rawValues, ok := strings.Cut(fs.Lookup("param").Value.String(), "=")
+	if !ok || name == "" || rawValues == "" {
+		fmt.Fprintln(os.Stderr, "sweep: -param NAME=v1,v2,... is required")
+		return 2
+	}
+	env := strings.ToUpper(strings.ReplaceAll(strings.TrimLeft(name, "-"), "-", "_"))
+	if !config.IsKnownSetting(env) {
+		fmt.Fprintf(os.Stderr, "sweep: unknown setting %s%s\n", name, config.SuggestSetting(name))
+		return 2
+	}
+	if env == "LIVE_TRADING" || env == "SIM_MODE" {
+		fmt.Fprintf(os.Stderr, "sweep: %s is fixed for sweeps\n", env)
+		return 2
+	}
+	var s config.Setting
+	for _, candidate := range config.Settings {
+		if candidate.Env == env {
+			s = candidate
+		}
+	}
+	values := strings.Split(rawValues, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+		if err := (settingFlag{s, make(config.Config)}).Set(values[i]); err != nil {
+			fmt.Fprintf(os.Stderr, "sweep: %s: %v\n", env, err)
+			return 2
+		}
+	}
+
+	// Each point draws its own streams, derived from one base seed so the
+	// whole sweep can be rerun; without RAND_SEED the base comes from the
+	// fixed sweep epoch, so a bare sweep reruns too
+	base := sweepEpoch.UnixNano()
+	if v := cfg.Get("RAND_SEED"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			fmt.Fprintf(os.Stderr, "sweep: RAND_SEED: %q is not a non-negative integer\n", v)
+			return 2
+		}
+		base = n
+	}
+	seeds := make([]int64, len(values))
+
+	results := make([]*report.CampaignResult, len(values))
+	logOut := log.Writer()
+	log.SetOutput(io.Discard)
+	for i, v := range values {
+		run := make(config.Config, len(cfg))
+		for k, val := range cfg {
+			run[k] = val
+		}
+		seeds[i] = engine.PointSeed(base, i)
+		run["RAND_SEED"] = strconv.FormatInt(seeds[i], 10)
+		run[env] = v
+		// One report email per sweep point would be noise
+		delete(run, "SMTP_HOST")
+		te, err := engine.NewValidated(run, clock.NewFake(sweepEpoch))
+		if err != nil {
+			log.SetOutput(logOut)
+			fmt.Fprintf(os.Stderr, "sweep: %s=%s: %v\n", env, v, err)
+			return 1
+		}
+		results[i] = te.ExecuteCampaign(ctx)
+		te.Close()
+	}
+	log.SetOutput(logOut)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\ttrades\twins\tlosses\treturn %%\tmax dd %%\tsharpe\tstop\tseed\t\n", env)
+	for i, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t%s\t%d\t\n", values[i], r.TradesCompleted, r.Wins, r.Losses,
+			r.ReturnPct, r.MaxDrawdownPct, r.Sharpe, r.StopReason, seeds[i])
+	}
+	tw.Flush()
+	fmt.Printf("RAND_SEED=%d\n", base)
+	return 0
+}
+
+// runReportCommand prints the headline numbers and per-symbol table of a
+// report written by REPORT_JSON
+func runReportCommand(_ context.Context, _ config.Config, fs *flag.FlagSet) int {
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: macro-strike-bot report <campaign_report.json>")
+		return 2
+	}
+	rep, err := report.LoadCampaignReport(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := rep.WriteSummary(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runReconcileCommand settles the order WAL without starting a campaign
+func runReconcileCommand(ctx context.Context, cfg config.Config, _ *flag.FlagSet) int {
+	if cfg.Get("ORDER_WAL") == "" {
+		fmt.Fprintln(os.Stderr, "reconcile: -order-wal is required")
+		return 2
+	}
+	te, err := engine.NewValidated(cfg, clock.Real{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer te.Close()
+	pending := te.ReconcileOrderWAL(ctx)
+	fmt.Printf("✅ %d unresolved strike(s) in %s handled; the log shows how each was settled\n", pending, cfg.Get("ORDER_WAL"))
+	return 0
+}
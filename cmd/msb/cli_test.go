@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/engine"
+)
+
+func commandNamed(t *testing.T, name string) command {
+	t.Helper()
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	t.Fatalf("no %s command", name)
+	return command{}
+}
+
+func TestParseCommandLayersEnvFileFlagsAndPresets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "msb.env")
+	file := "# sizing\nORDER_USD_SIZE=40\nexport CAMPAIGN_DAYS=\"2\"\nLIVE_TRADING=1\n"
+	if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := config.Config{"ORDER_USD_SIZE": "10", "CAMPAIGN_DAYS": "9", "MAX_DRAWDOWN_PCT": "7"}
+	var stdout, stderr bytes.Buffer
+	cfg, _, code := parseCommand(commandNamed(t, "sim"), []string{"-config", path, "-campaign-days", "3", "-sim-price-check"}, env, &stdout, &stderr)
+	if cfg == nil {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	want := map[string]string{
+		"MAX_DRAWDOWN_PCT": "7",  // environment
+		"ORDER_USD_SIZE":   "40", // config file over environment
+		"CAMPAIGN_DAYS":    "3",  // flag over config file
+		"SIM_PRICE_CHECK":  "1",
+		"LIVE_TRADING":     "0", // sim's preset over config file
+		"SIM_MODE":         "1",
+	}
+	for k, v := range want {
+		if cfg.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, cfg.Get(k), v)
+		}
+	}
+	if env.Get("ORDER_USD_SIZE") != "10" {
+		t.Error("parseCommand modified the environment snapshot")
+	}
+
+	te := engine.NewTradingEngineFromConfig(cfg)
+	if te.OrderUSDSize != 40 || te.CampaignDays != 3 || te.LiveTrading || !te.SimPriceCheck {
+		t.Errorf("engine got size %.0f days %d live %v price check %v", te.OrderUSDSize, te.CampaignDays, te.LiveTrading, te.SimPriceCheck)
+	}
+}
+
+func TestParseCommandFailsFastWithHints(t *testing.T) {
+	bad := filepath.Join(t.TempDir(), "bad.env")
+	if err := os.WriteFile(bad, []byte("ORDER_USD_SIZ=5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		cmd  string
+		args []string
+		want string
+	}{
+		{"sim", []string{"-order-usd-siz", "5"}, "did you mean -order-usd-size?"},
+		{"sim", []string{"-campaign-days", "five"}, "not a non-negative integer"},
+		{"sim", []string{"-shutdown-grace", "10"}, "not a duration"},
+		{"sim", []string{"-live-trading"}, "not defined"},
+		{"sim", []string{"extra"}, "unexpected argument"},
+		{"sim", []string{"-config", bad}, "did you mean ORDER_USD_SIZE?"},
+	} {
+		var stdout, stderr bytes.Buffer
+		cfg, _, code := parseCommand(commandNamed(t, tc.cmd), tc.args, config.Config{}, &stdout, &stderr)
+		if cfg != nil || code != 2 || !strings.Contains(stderr.String(), tc.want) {
+			t.Errorf("%s %v: exit %d, stderr %q; want exit 2 mentioning %q", tc.cmd, tc.args, code, stderr.String(), tc.want)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if cfg, _, code := parseCommand(commandNamed(t, "run"), []string{"-help"}, config.Config{}, &stdout, &stderr); cfg != nil || code != 0 {
+		t.Errorf("-help: exit %d", code)
+	}
+	if help := stdout.String(); !strings.Contains(help, "-order-usd-size X") || !strings.Contains(help, "[ORDER_USD_SIZE]") || strings.Contains(help, "-live-trading") {
+		t.Errorf("run -help should document settings but not the preset -live-trading:\n%s", help)
+	}
+}
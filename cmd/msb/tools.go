@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/engine"
+	"macro-strike-bot/report"
+)
+
+// runVerifyAudit implements the verify-audit subcommand
+func runVerifyAudit(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: verify-audit <strike-log> [newer-log ...]  (oldest first)")
+		return 2
+	}
+	n, err := engine.VerifyAuditLog(paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v (%d records verified before the break)\n", err, n)
+		return 1
+	}
+	fmt.Printf("✅ %d records verified\n", n)
+	return 0
+}
+
+// runCompareReports implements the compare-reports subcommand
+func runCompareReports(args []string) int {
+	fs := flag.NewFlagSet("compare-reports", flag.ContinueOnError)
+	minPct := fs.Float64("pct", 5, "flag deltas whose relative change exceeds this percentage")
+	minAbs := fs.Float64("abs", 0, "flag deltas whose absolute change exceeds this value")
+	asJSON := fs.Bool("json", false, "emit the comparison as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: compare-reports [-pct N] [-abs N] [-json] <report-a.json> <report-b.json>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	a, err := report.LoadReportDocument(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	b, err := report.LoadReportDocument(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cmp := report.CompareReports(a, b, report.CompareThresholds{MinAbs: *minAbs, MinPct: *minPct})
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(cmp)
+	} else {
+		err = cmp.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runImportStrikeLog is the import-strike-log subcommand: it loads strike logs
+// into the journal configured in the environment
+func runImportStrikeLog(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: import-strike-log <strike-log> [more-logs ...]")
+		return 2
+	}
+	te, err := engine.NewValidated(config.EnvConfig(), clock.Real{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer te.Close()
+	imported, err := te.ImportStrikeLogs(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ %d strikes imported\n", imported)
+	return 0
+}
+
+// runImportTrades is the import-trades subcommand. Kraken credentials, the
+// journal and the performance store come from the usual environment.
+func runImportTrades(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("import-trades", flag.ContinueOnError)
+	start := fs.String("start", "", "first day to import (YYYY-MM-DD or RFC3339); empty imports all history")
+	end := fs.String("end", "", "import up to this time (YYYY-MM-DD or RFC3339); default now")
+	offset := fs.Int("offset", -1, "TradesHistory offset to start from; default resumes the checkpoint")
+	checkpoint := fs.String("checkpoint", "trades_import.checkpoint.json", "paging progress file; empty disables resuming")
+	delay := fs.Duration("page-delay", 2*time.Second, "pause between TradesHistory pages")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	opts := engine.ImportOptions{Offset: *offset, CheckpointPath: *checkpoint, PageDelay: *delay}
+	var err error
+	if opts.Start, err = parseImportTime(*start); err != nil {
+		fmt.Fprintf(os.Stderr, "-start: %v\n", err)
+		return 2
+	}
+	if opts.End, err = parseImportTime(*end); err != nil {
+		fmt.Fprintf(os.Stderr, "-end: %v\n", err)
+		return 2
+	}
+
+	te, err := engine.NewValidated(config.EnvConfig(), clock.Real{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer te.Close()
+	sum, err := te.ImportTradeHistory(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ %d fills → %d round trips: %d imported, %d already imported\n",
+		sum.Fills, sum.RoundTrips, sum.Imported, sum.AlreadyImported)
+	if sum.Unmapped > 0 || sum.OpenPositions > 0 || sum.UnpairedSells > 0 {
+		fmt.Printf("   skipped: %d fills on untraded pairs, %d open position(s), %d sells with nothing open\n",
+			sum.Unmapped, sum.OpenPositions, sum.UnpairedSells)
+	}
+	return 0
+}
+
+// parseImportTime accepts a date or an RFC3339 timestamp; empty is the zero time
+func parseImportTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
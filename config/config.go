@@ -0,0 +1,112 @@
+// Package config holds the settings the engine is built from: the Config map,
+// the documented settings table, and redaction of the credentials among them.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds engine settings keyed by their environment variable names.
+// EnvConfig reads them from the environment; the CLI layers a --config file
+// and flags on top before handing the result to the engine.
+type Config map[string]string
+
+// EnvConfig snapshots the process environment
+func EnvConfig() Config {
+	cfg := make(Config)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			cfg[k] = v
+		}
+	}
+	return cfg
+}
+
+// Get returns the named setting, "" when unset
+func (c Config) Get(name string) string {
+	return c[name]
+}
+
+// Lookup returns the named setting and whether it is set at all
+func (c Config) Lookup(name string) (string, bool) {
+	v, ok := c[name]
+	return v, ok
+}
+
+// First returns the first non-empty setting among names
+func (c Config) First(names ...string) string {
+	for _, n := range names {
+		if v := c[n]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Float reads a non-negative float setting, keeping def when unset; an
+// invalid value also keeps def and is recorded in errs
+func (c Config) Float(name string, def float64, errs *[]error) float64 {
+	if v := c[name]; v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+			*errs = append(*errs, fmt.Errorf("%s: %q is not a non-negative number", name, v))
+			return def
+		}
+		return f
+	}
+	return def
+}
+
+// LoadConfigFile reads KEY=value lines into cfg, overriding what is there.
+// Blank lines and # comments are skipped, surrounding quotes are dropped, and
+// a key that is not a known setting is an error so typos don't go unnoticed.
+func LoadConfigFile(path string, cfg Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return fmt.Errorf("%s:%d: want KEY=value, got %q", path, n, line)
+		}
+		if !IsKnownSetting(k) {
+			return fmt.Errorf("%s:%d: unknown setting %s%s", path, n, k, SuggestSetting(k))
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		cfg[k] = v
+	}
+	return sc.Err()
+}
+
+// ParseKeyValueList parses "KEY=value,KEY2=value2" lists used by map-valued env settings
+func ParseKeyValueList(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return out, fmt.Errorf("malformed entry %q (want KEY=value)", entry)
+		}
+		out[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return out, nil
+}
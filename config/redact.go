@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces every secret the Redactor finds
+const Redacted = "[REDACTED]"
+
+// minSecretLen keeps trivially short values from masking ordinary text
+const minSecretLen = 6
+
+// secretSettings hold credentials, or URLs and DSNs with credentials in them
+var secretSettings = map[string]bool{
+	"KRAKEN_API_KEY":             true,
+	"KRAKEN_API_SECRET":          true,
+	"COINBASE_API_KEY":           true,
+	"COINBASE_API_SECRET":        true,
+	"JOURNAL_POSTGRES_DSN":       true,
+	"ARTIFACT_S3_ACCESS_KEY":     true,
+	"ARTIFACT_S3_SECRET_KEY":     true,
+	"AWS_ACCESS_KEY_ID":          true,
+	"AWS_SECRET_ACCESS_KEY":      true,
+	"ALERT_WEBHOOK_URL":          true,
+	"SMTP_PASSWORD":              true,
+	"CONTROL_TOKEN":              true,
+	"OTEL_EXPORTER_OTLP_HEADERS": true,
+}
+
+var (
+	// secretHeaderPattern matches credential headers however they are printed:
+	// "API-Sign: x", "API-Sign=x", `"API-Key":"x"`, "Authorization: Bearer x"
+	secretHeaderPattern = regexp.MustCompile(`(?i)((?:API-Sign|API-Key|Authorization)["']?\s*[:=]\s*["']?(?:Bearer\s+|Basic\s+)?)[^\s"',}\]]+`)
+	// base64Pattern finds long base64 runs; only those that are clearly base64
+	// rather than hex or words (they carry +, / or padding) are masked
+	base64Pattern = regexp.MustCompile(`[A-Za-z0-9+/]{40,}={0,2}`)
+	// dsnPasswordPattern finds password=... in key/value DSNs
+	dsnPasswordPattern = regexp.MustCompile(`password=([^\s]+)`)
+)
+
+// Redactor masks configured secret values and common credential patterns in
+// text bound for logs, reports, alerts and the status server. A nil
+// *Redactor still masks the patterns.
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor collects the secret values set in cfg
+func NewRedactor(cfg Config) *Redactor {
+	seen := make(map[string]bool)
+	var secrets []string
+	add := func(v string) {
+		if len(v) >= minSecretLen && !seen[v] {
+			seen[v] = true
+			secrets = append(secrets, v)
+		}
+	}
+	for name := range secretSettings {
+		v := cfg.Get(name)
+		if v == "" {
+			continue
+		}
+		add(v)
+		switch name {
+		case "JOURNAL_POSTGRES_DSN", "ALERT_WEBHOOK_URL":
+			// The password alone also shows up, e.g. in driver errors
+			if u, err := url.Parse(v); err == nil && u.User != nil {
+				if p, ok := u.User.Password(); ok {
+					add(p)
+				}
+			}
+			for _, m := range dsnPasswordPattern.FindAllStringSubmatch(v, -1) {
+				add(m[1])
+			}
+		case "OTEL_EXPORTER_OTLP_HEADERS":
+			for _, pair := range strings.Split(v, ",") {
+				if _, val, ok := strings.Cut(pair, "="); ok {
+					add(strings.TrimSpace(val))
+				}
+			}
+		}
+	}
+	// Longest first, so a secret containing another is masked whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return &Redactor{secrets: secrets}
+}
+
+// Redact returns s with every secret masked
+func (r *Redactor) Redact(s string) string {
+	if r != nil {
+		for _, secret := range r.secrets {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	s = secretHeaderPattern.ReplaceAllString(s, "${1}"+Redacted)
+	return base64Pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.ContainsAny(m, "+/=") {
+			return Redacted
+		}
+		return m
+	})
+}
+
+// Writer returns w with everything written to it redacted. Each Write is
+// redacted on its own, which suits writers fed whole lines or documents such
+// as the standard logger.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactingWriter{w: w, r: r}
+}
+
+type redactingWriter struct {
+	w io.Writer
+	r *Redactor
+}
+
+func (rw redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	// Callers account for what they wrote, not the redacted length
+	return len(p), nil
+}
+
+// Handler redacts every response body h writes
+func (r *Redactor) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(&redactingResponse{ResponseWriter: w, r: r}, req)
+	})
+}
+
+// redactingResponse redacts each body write; streamed responses such as
+// /events keep flushing through it
+type redactingResponse struct {
+	http.ResponseWriter
+	r *Redactor
+}
+
+func (rr *redactingResponse) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rr.ResponseWriter, rr.r.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (rr *redactingResponse) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rr *redactingResponse) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// String lists the settings with every credential elided
+func (c Config) String() string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + c.display(k)
+	}
+	return "Config{" + strings.Join(parts, " ") + "}"
+}
+
+// MarshalJSON encodes the settings with every credential elided
+func (c Config) MarshalJSON() ([]byte, error) {
+	out := make(map[string]string, len(c))
+	for k := range c {
+		out[k] = c.display(k)
+	}
+	return json.Marshal(out)
+}
+
+// display is a setting's value as safe to print
+func (c Config) display(name string) string {
+	if secretSettings[name] && c[name] != "" {
+		return Redacted
+	}
+	return c[name]
+}
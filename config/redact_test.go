@@ -0,0 +1,23 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactLeavesOrdinaryTextAlone(t *testing.T) {
+	r := NewRedactor(Config{"KRAKEN_API_KEY": "abc"})
+	// A hex digest is long but not base64, and short secrets are not masked
+	for _, s := range []string{
+		"txid OQCLML-BW3P3-BUCMWZ filled at 2500.00 abc",
+		"sha256 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	} {
+		if got := r.Redact(s); got != s {
+			t.Errorf("Redact(%q) = %q", s, got)
+		}
+	}
+	dsn := NewRedactor(Config{"JOURNAL_POSTGRES_DSN": "postgres://bot:s3cr3tpass@db:5432/strikes"})
+	if got := dsn.Redact("connect failed: password s3cr3tpass rejected"); strings.Contains(got, "s3cr3tpass") {
+		t.Errorf("DSN password not masked: %q", got)
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind decides how a setting's flag value is checked before the engine
+// ever sees it
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindDuration
+)
+
+// Setting documents one engine setting. Its flag is the environment
+// variable name lowercased with dashes: ORDER_USD_SIZE is -order-usd-size.
+type Setting struct {
+	Env   string
+	Kind  Kind
+	Group string
+	Usage string
+}
+
+// Settings lists everything the engine reads; --help prints it and config
+// files may only set names listed here
+var Settings = func() []Setting {
+	s := []Setting{
+		{"LIVE_TRADING", KindBool, "Mode", "place real orders on the exchange"},
+		{"SIM_MODE", KindBool, "Mode", "simulate strikes instead of running the Julia analyzer"},
+		{"EXCHANGE", KindString, "Mode", "venue: kraken (default) or coinbase"},
+		{"ACCOUNT_QUOTE_CURRENCY", KindString, "Exchange", "currency the account is funded in, e.g. EUR (default USD); kraken only"},
+		{"JULIA_MISSING", KindString, "Mode", "when julia is not on PATH: abort (default) or sim"},
+		{"KRAKEN_API_KEY", KindString, "Exchange", "Kraken API key"},
+		{"KRAKEN_API_SECRET", KindString, "Exchange", "Kraken API secret"},
+		{"KRAKEN_API_URL", KindString, "Exchange", "Kraken API base URL"},
+		{"KRAKEN_PAIR_OVERRIDES", KindString, "Exchange", "SYMBOL=PAIR,... Kraken pair overrides"},
+		{"KRAKEN_RECORD_FILE", KindString, "Exchange", "record Kraken API traffic to this file"},
+		{"KRAKEN_REPLAY_FILE", KindString, "Exchange", "serve Kraken API responses from this recording"},
+		{"COINBASE_API_KEY", KindString, "Exchange", "Coinbase API key name"},
+		{"COINBASE_API_SECRET", KindString, "Exchange", "Coinbase EC private key (PEM)"},
+		{"COINBASE_API_URL", KindString, "Exchange", "Coinbase API base URL"},
+		{"COINBASE_PRODUCT_OVERRIDES", KindString, "Exchange", "SYMBOL=PRODUCT,... Coinbase product overrides"},
+		{"CAMPAIGN_DAYS", KindInt, "Campaign", "campaign length in days (default 5)"},
+		{"INFINITE", KindBool, "Campaign", "ignore the trade limit"},
+		{"PAUSE_EXTENDS_WINDOW", KindBool, "Campaign", "time spent paused does not count against the campaign window"},
+		{"STATE_FILE", KindString, "Campaign", "save engine state here for RESUME"},
+		{"POST_CAMPAIGN", KindString, "Campaign", "once a campaign ends: flatten and exit (default), hold for monitoring, or loop into a new campaign"},
+		{"STATE_SNAPSHOT_EVERY", KindInt, "Campaign", "save state every N trades (default 10)"},
+		{"PROGRESS_LOG_EVERY", KindInt, "Campaign", "log progress and pace every N trades (default 100, 0 disables)"},
+		{"RESUME", KindBool, "Campaign", "resume the run saved in STATE_FILE"},
+		{"SHUTDOWN_GRACE", KindDuration, "Campaign", "time to finish in-flight strikes on SIGINT/SIGTERM"},
+		{"ORDER_USD_SIZE", KindFloat, "Orders", "fixed live order size in USD (default 25)"},
+		{"ORDER_RISK_PCT", KindFloat, "Orders", "percent of capital risked per order (default 1)"},
+		{"AUTO_BUMP_MIN", KindBool, "Orders", "raise orders under the pair minimum to it instead of skipping them"},
+		{"LIVE_ENTRY_ORDER", KindString, "Orders", "live entry order type: market (default) or limit"},
+		{"LIMIT_MAX_CHASES", KindInt, "Orders", "times an unfilled limit entry is repriced (default 3)"},
+		{"LIMIT_CHASE_WAIT_MS", KindFloat, "Orders", "wait before repricing a limit entry (default 3000)"},
+		{"MIN_FILL_RATIO", KindFloat, "Orders", "abort and flatten live entries filling under this fraction of the order (default 0)"},
+		{"FILL_POLL_INTERVAL_MS", KindInt, "Orders", "longest wait between fill polls, which back off from 200ms (default 2000)"},
+		{"FILL_TIMEOUT_MS", KindInt, "Orders", "give up waiting for a fill after this long (default 30000)"},
+		{"SIM_MIN_HOLD_MS", KindInt, "Orders", "minimum simulated hold time"},
+		{"TP_LADDER", KindString, "Orders", "scale out at take-profit levels, as pct:portion,... e.g. 0.5:0.5,1:0.5"},
+		{"RAND_SEED", KindInt, "Orders", "seed for simulated strikes; logged at startup so a run can be replayed"},
+		{"SIM_HIT_MODEL", KindString, "Orders", "simulated hit rate: identity, power:K or curve:C=P,..."},
+		{"FUNDING_RATE_PCT_PER_HOUR", KindFloat, "Orders", "simulated funding per hour held, as a percent of levered notional (default 0)"},
+		{"SYMBOL_FUNDING_RATES", KindString, "Orders", "SYMBOL=pct,... per-symbol overrides of FUNDING_RATE_PCT_PER_HOUR"},
+		{"SIM_PRICE_CHECK", KindBool, "Orders", "check simulated strikes against live tickers"},
+		{"MAX_DRAWDOWN_PCT", KindFloat, "Risk", "stop the campaign at this drawdown (default 10)"},
+		{"MAX_DAILY_LOSS_PCT", KindFloat, "Risk", "pause for the day at this loss; 0 disables"},
+		{"DAILY_LOSS_ENDS_CAMPAIGN", KindBool, "Risk", "end the campaign instead of pausing at the daily loss limit"},
+		{"MAX_NOTIONAL_USD", KindFloat, "Risk", "cap levered notional open across strikes; 0 disables"},
+		{"MAX_POSITIONS_PER_SYMBOL", KindInt, "Risk", "strikes allowed open at once on one symbol (default 1); 0 disables"},
+		{"MAX_OPEN_POSITIONS", KindInt, "Risk", "live positions allowed open at once (default 1); above 1 exits run without blocking new strikes"},
+		{"MIN_TRADING_CAPITAL", KindFloat, "Risk", "stop below this capital in USD (default 10)"},
+		{"MIN_RISK_REWARD", KindFloat, "Risk", "skip strikes below this reward:risk; 0 disables"},
+		{"TARGET_COST_HAIRCUT", KindBool, "Risk", "net formulaic targets of round-trip fees and TARGET_SLIPPAGE_BPS"},
+		{"TARGET_SLIPPAGE_BPS", KindFloat, "Risk", "round-trip slippage the target haircut assumes (default 10)"},
+		{"MIN_VOLATILITY", KindFloat, "Risk", "skip analyses below this volatility; 0 disables"},
+		{"MAX_VOLATILITY", KindFloat, "Risk", "skip analyses above this volatility; 0 disables"},
+		{"SYMBOL_LOSS_COOLDOWN_MS", KindInt, "Risk", "sit a symbol out this long after a miss"},
+		{"MAX_SUGGESTED_STOP_PCT", KindFloat, "Risk", "cap analyzer-suggested stops (default 10)"},
+		{"MAX_SUGGESTED_TARGET_PCT", KindFloat, "Risk", "cap analyzer-suggested targets (default 20)"},
+		{"NONFINITE_ANALYSIS", KindString, "Risk", "analysis with NaN or Inf fields: skip the setup (default) or stop the campaign"},
+		{"PRICE_DEVIATION_TOLERANCE_PCT", KindFloat, "Risk", "reject analyses this far from the ticker (default 5)"},
+		{"ATR_STOP_MULTIPLE", KindFloat, "Risk", "place stops this many ATRs away; 0 disables"},
+		{"ATR_PERIOD", KindInt, "Risk", "ATR period in candles (default 14)"},
+		{"ATR_INTERVAL_MIN", KindInt, "Risk", "ATR candle interval in minutes (default 5)"},
+		{"STABLECOIN_SYMBOLS", KindString, "Risk", "comma-separated stablecoin pairs (default USDC/USDT,DAI/USDC)"},
+		{"STABLECOIN_TARGET_BPS", KindFloat, "Risk", "stablecoin target in basis points (default 5)"},
+		{"STABLECOIN_STOP_BPS", KindFloat, "Risk", "stablecoin stop in basis points (default 10)"},
+		{"CONFIDENCE_THRESHOLD", KindFloat, "Selection", "confidence required to strike (default 0.80)"},
+		{"SYMBOL_CONFIDENCE_THRESHOLDS", KindString, "Selection", "SYMBOL=threshold,... per-symbol overrides"},
+		{"STRIKE_TYPE_WEIGHTS", KindString, "Selection", "TYPE=weight,... strike type sampling weights"},
+		{"DIRECTION_BY_TYPE", KindString, "Selection", "TYPE=long|short|both,... allowed directions; both trades long setups until shorts can execute, short is rejected"},
+		{"LIQUIDITY_WEIGHT", KindFloat, "Selection", "liquidity's effect on size (default 0.5)"},
+		{"LIQUIDITY_FACTOR_MIN", KindFloat, "Selection", "liquidity factor floor (default 0.25)"},
+		{"LIQUIDITY_FACTOR_MAX", KindFloat, "Selection", "liquidity factor ceiling (default 1)"},
+		{"MOMENTUM_WEIGHT", KindFloat, "Selection", "momentum's effect on size (default 0.5)"},
+		{"MOMENTUM_FACTOR_MIN", KindFloat, "Selection", "momentum factor floor (default 0.5)"},
+		{"MOMENTUM_FACTOR_MAX", KindFloat, "Selection", "momentum factor ceiling (default 1.5)"},
+		{"PRECISION_WEIGHT", KindFloat, "Selection", "precision's effect on confidence, 0-1 (default 1)"},
+		{"WIN_RATE_EMA_ALPHA", KindFloat, "Selection", "smoothing of the recent win rate (default 0.1)"},
+		{"PERF_STORE_FILE", KindString, "Performance", "persist per-symbol performance here"},
+		{"PERF_STORE_RESET", KindBool, "Performance", "start PERF_STORE_FILE afresh"},
+		{"PERF_HALF_LIFE", KindDuration, "Performance", "performance decay half-life (default 168h)"},
+		{"PERF_MIN_TRADES", KindFloat, "Performance", "trades before performance adjusts sizing (default 20)"},
+		{"PERF_WIN_RATE_FLOOR", KindFloat, "Performance", "haircut symbols below this win rate (default 0.5)"},
+		{"PERF_HAIRCUT", KindFloat, "Performance", "size multiplier for underperformers (default 0.5)"},
+		{"PERF_EXCLUDE_WIN_RATE", KindFloat, "Performance", "skip symbols below this win rate (default 0.3)"},
+		{"STRIKE_LOG", KindString, "Output", "append strikes to this JSONL file"},
+		{"STRIKE_LOG_AUDIT", KindBool, "Output", "hash-chain the strike log"},
+		{"CSV_EXPORT_PATH", KindString, "Output", "export strikes as CSV here"},
+		{"CSV_EXPORT_MODE", KindString, "Output", "stream (default) or end"},
+		{"PARQUET_EXPORT_PATH", KindString, "Output", "export strikes as Parquet here"},
+		{"PARQUET_EQUITY_PATH", KindString, "Output", "export the equity curve as Parquet here"},
+		{"REALIZED_GAINS_CSV", KindString, "Output", "write realized gains here"},
+		{"REPORT_JSON", KindString, "Output", "write the campaign report as JSON here"},
+		{"REPORT_HTML", KindString, "Output", "write the campaign report as HTML here"},
+		{"STRIKES_JSON", KindString, "Output", "write every strike as JSON here"},
+		{"JOURNAL_DB", KindString, "Output", "SQLite trade journal path"},
+		{"JOURNAL_POSTGRES_DSN", KindString, "Output", "Postgres trade journal DSN"},
+		{"JOURNAL_BUFFER_MAX", KindInt, "Output", "journal records buffered while Postgres is down (default 10000)"},
+		{"INSTANCE_ID", KindString, "Output", "journal instance id (default host-pid)"},
+		{"ORDER_WAL", KindString, "Output", "order write-ahead log path"},
+		{"ARTIFACT_S3_BUCKET", KindString, "Artifacts", "upload artifacts to this S3 bucket"},
+		{"ARTIFACT_S3_ENDPOINT", KindString, "Artifacts", "S3 endpoint (default AWS)"},
+		{"ARTIFACT_S3_REGION", KindString, "Artifacts", "S3 region (default us-east-1)"},
+		{"ARTIFACT_S3_PREFIX", KindString, "Artifacts", "S3 key prefix"},
+		{"ARTIFACT_S3_ACCESS_KEY", KindString, "Artifacts", "S3 access key (default AWS_ACCESS_KEY_ID)"},
+		{"ARTIFACT_S3_SECRET_KEY", KindString, "Artifacts", "S3 secret key (default AWS_SECRET_ACCESS_KEY)"},
+		{"AWS_ACCESS_KEY_ID", KindString, "Artifacts", "fallback S3 access key"},
+		{"AWS_SECRET_ACCESS_KEY", KindString, "Artifacts", "fallback S3 secret key"},
+		{"ALERT_WEBHOOK_URL", KindString, "Alerts", "send alerts to this webhook"},
+		{"ALERT_WEBHOOK_FORMAT", KindString, "Alerts", "json (default), slack or telegram"},
+		{"ALERT_TELEGRAM_CHAT_ID", KindString, "Alerts", "Telegram chat for the telegram format"},
+		{"ALERT_MIN_INTERVAL", KindDuration, "Alerts", "minimum time between alerts of one kind"},
+		{"ALERT_LOSS_USD", KindFloat, "Alerts", "alert on a single loss at least this large"},
+		{"ALERT_DRAWDOWN_LEVELS", KindString, "Alerts", "comma-separated drawdown percentages to alert at"},
+		{"SMTP_HOST", KindString, "Alerts", "email the campaign report through this SMTP server"},
+		{"SMTP_PORT", KindInt, "Alerts", "SMTP port (default 587, or 465 with SMTP_TLS=tls)"},
+		{"SMTP_TLS", KindString, "Alerts", "starttls (default), tls or none"},
+		{"SMTP_USERNAME", KindString, "Alerts", "SMTP login"},
+		{"SMTP_PASSWORD", KindString, "Alerts", "SMTP password; never echoed in logs or reports"},
+		{"SMTP_FROM", KindString, "Alerts", "sender address (default SMTP_USERNAME)"},
+		{"SMTP_TO", KindString, "Alerts", "comma-separated recipients"},
+		{"STATUS_ADDR", KindString, "Operations", "serve status, metrics and probes on this address"},
+		{"EVENT_HISTORY_SIZE", KindInt, "Operations", "recent strike results and stops kept for GET /events?format=json (default 200, 0 disables)"},
+		{"CONTROL_TOKEN", KindString, "Operations", "bearer token for /pause, /resume and /kill (/kill is disabled without it)"},
+		{"KILL_TIMEOUT", KindDuration, "Operations", "how long /kill waits for positions to go flat (default 60s)"},
+		{"TUI", KindBool, "Operations", "show a live dashboard instead of log lines when stdout is a terminal"},
+		{"LOG_LEVEL", KindString, "Operations", "debug for verbose logging"},
+		{"SKIP_LOG", KindBool, "Operations", "1 logs each skipped setup with its reason and a per-reason tally at campaign end"},
+		{"OTEL_EXPORTER_OTLP_ENDPOINT", KindString, "Operations", "export strike traces to this OTLP/HTTP collector (/v1/traces is appended); unset disables tracing"},
+		{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", KindString, "Operations", "full OTLP/HTTP traces URL, overriding OTEL_EXPORTER_OTLP_ENDPOINT"},
+		{"OTEL_EXPORTER_OTLP_HEADERS", KindString, "Operations", "key=value,... headers sent with trace exports"},
+		{"OTEL_SERVICE_NAME", KindString, "Operations", "service name on exported traces (default macro-strike-bot)"},
+		{"HTTP_TIMEOUT_MS", KindInt, "Operations", "HTTP request timeout"},
+		{"HTTP_IDLE_CONN_TIMEOUT_MS", KindInt, "Operations", "HTTP idle connection timeout"},
+		{"HTTP_KEEPALIVE_MS", KindInt, "Operations", "TCP keep-alive period"},
+		{"HTTP_MAX_IDLE_CONNS", KindInt, "Operations", "idle HTTP connections kept"},
+		{"HTTP_MAX_IDLE_CONNS_PER_HOST", KindInt, "Operations", "idle HTTP connections kept per host"},
+		{"RETRY_BUDGET_PER_HOUR", KindInt, "Operations", "exchange API retries allowed per rolling hour before trading pauses; 0 disables"},
+		{"HTTP_WARMUP", KindBool, "Operations", "open exchange connections before the first strike (default true)"},
+		{"WATCHDOG_STALL", KindDuration, "Operations", "report a stall when the loop and order polling make no progress this long; unset disables"},
+		{"WATCHDOG_ABORT", KindBool, "Operations", "abort the in-flight strike when the watchdog reports a stall"},
+		{"HEALTH_STALE_AFTER", KindDuration, "Operations", "/healthz fails when the loop is quiet this long (default 5m)"},
+		{"READY_CACHE_TTL", KindDuration, "Operations", "reuse /readyz results this long (default 30s)"},
+	}
+	for _, prefix := range []string{"STRIKE_LOG", "CSV_EXPORT", "KRAKEN_RECORD"} {
+		s = append(s,
+			Setting{prefix + "_ROTATE_MAX_MB", KindFloat, "Rotation", "rotate " + prefix + " at this size"},
+			Setting{prefix + "_ROTATE_MAX_AGE", KindDuration, "Rotation", "rotate " + prefix + " at this age"},
+			Setting{prefix + "_ROTATE_KEEP", KindInt, "Rotation", "rotated " + prefix + " files kept"},
+			Setting{prefix + "_ROTATE_COMPRESS", KindBool, "Rotation", "gzip rotated " + prefix + " files"},
+		)
+	}
+	return s
+}()
+
+// FlagName is the command-line spelling of a setting
+func FlagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// IsKnownSetting reports whether name is listed in Settings
+func IsKnownSetting(name string) bool {
+	for _, s := range Settings {
+		if s.Env == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SuggestSetting returns " (did you mean X?)" for the closest known setting,
+// or "" when nothing is close
+func SuggestSetting(name string) string {
+	best, bestDist := "", 4
+	for _, s := range Settings {
+		if d := editDistance(strings.ToUpper(name), s.Env); d < bestDist {
+			best, bestDist = s.Env, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	if strings.ToUpper(name) != name || strings.Contains(name, "-") {
+		best = "-" + FlagName(best)
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Every setting the module reads must be documented, or -help lies and
+// config files reject it
+func TestSettingsTableCoversModuleSettings(t *testing.T) {
+	read := regexp.MustCompile(`(?:cfg|settings|te\.config)\.(?:Get|Lookup|Float|First)\(([^)]*)\)`)
+	name := regexp.MustCompile(`"([A-Z][A-Z0-9_]+)"`)
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != ".." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "target" || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, call := range read.FindAllStringSubmatch(string(src), -1) {
+			for _, n := range name.FindAllStringSubmatch(call[1], -1) {
+				if !IsKnownSetting(n[1]) {
+					t.Errorf("%s reads %s, which is missing from Settings", path, n[1])
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
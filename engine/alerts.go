@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+// Alert kinds, also the kind label on macro_alerts_total
+const (
+	AlertEmergencyStop    = "emergency_stop"
+	AlertLargeLoss        = "large_loss"
+	AlertDrawdown         = "drawdown"
+	AlertExitFailed       = "exit_failed"
+	AlertCampaignComplete = "campaign_complete"
+	AlertStall            = "stall"
+	AlertRetryBudget      = "retry_budget"
+)
+
+// Webhook payload formats
+const (
+	AlertFormatJSON     = "json"
+	AlertFormatSlack    = "slack"
+	AlertFormatTelegram = "telegram"
+)
+
+// Alert delivery tuning
+const (
+	alertAttempts           = 3
+	alertQueueSize          = 64
+	alertDrainTimeout       = 10 * time.Second
+	defaultAlertMinInterval = 5 * time.Minute
+)
+
+// alertRetryDelay is the base backoff between delivery attempts
+var alertRetryDelay = time.Second
+
+// Alert is one notification; the json format posts it as is
+type Alert struct {
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+	RunID   string    `json:"run_id"`
+	Time    time.Time `json:"time"`
+}
+
+// AlertNotifier POSTs alerts to a webhook from a background worker, so a slow
+// endpoint never holds up the trading loop. Each kind is rate-limited to one
+// alert per MinInterval; alerts over the limit, or arriving while the queue
+// is full, are dropped and counted.
+type AlertNotifier struct {
+	URL         string
+	Format      string
+	ChatID      string
+	MinInterval time.Duration
+
+	client  *http.Client
+	clock   clock.Clock
+	metrics *EngineMetrics
+	queue   chan Alert
+	done    chan struct{}
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	closed   bool
+}
+
+// NewAlertNotifierFromConfig returns nil when ALERT_WEBHOOK_URL is unset, which
+// leaves alerting disabled. ALERT_WEBHOOK_FORMAT picks json, slack or
+// telegram (with ALERT_TELEGRAM_CHAT_ID); ALERT_MIN_INTERVAL rate-limits
+// each kind.
+func NewAlertNotifierFromConfig(cfg config.Config, client *http.Client, clock clock.Clock, metrics *EngineMetrics) (*AlertNotifier, error) {
+	webhookURL := cfg.Get("ALERT_WEBHOOK_URL")
+	if webhookURL == "" {
+		return nil, nil
+	}
+	format := strings.ToLower(cfg.Get("ALERT_WEBHOOK_FORMAT"))
+	if format == "" {
+		format = AlertFormatJSON
+	}
+	switch format {
+	case AlertFormatJSON, AlertFormatSlack:
+	case AlertFormatTelegram:
+		if cfg.Get("ALERT_TELEGRAM_CHAT_ID") == "" {
+			return nil, fmt.Errorf("ALERT_WEBHOOK_FORMAT=telegram needs ALERT_TELEGRAM_CHAT_ID")
+		}
+	default:
+		return nil, fmt.Errorf("ALERT_WEBHOOK_FORMAT: %q is not json, slack or telegram", format)
+	}
+	interval := defaultAlertMinInterval
+	if v := cfg.Get("ALERT_MIN_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("ALERT_MIN_INTERVAL: %q is not a non-negative duration", v)
+		}
+		interval = d
+	}
+	return NewAlertNotifier(webhookURL, format, cfg.Get("ALERT_TELEGRAM_CHAT_ID"), interval, client, clock, metrics), nil
+}
+
+// NewAlertNotifier starts the delivery worker; Close stops it
+func NewAlertNotifier(webhookURL, format, chatID string, minInterval time.Duration, client *http.Client, clock clock.Clock, metrics *EngineMetrics) *AlertNotifier {
+	n := &AlertNotifier{
+		URL:         webhookURL,
+		Format:      format,
+		ChatID:      chatID,
+		MinInterval: minInterval,
+		client:      client,
+		clock:       clock,
+		metrics:     metrics,
+		queue:       make(chan Alert, alertQueueSize),
+		done:        make(chan struct{}),
+		lastSent:    make(map[string]time.Time),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues an alert without blocking
+func (n *AlertNotifier) Notify(a Alert) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	if last, ok := n.lastSent[a.Kind]; ok && n.clock.Since(last) < n.MinInterval {
+		n.metrics.AlertOutcome(a.Kind, "rate_limited")
+		return
+	}
+	select {
+	case n.queue <- a:
+		n.lastSent[a.Kind] = n.clock.Now()
+	default:
+		n.metrics.AlertOutcome(a.Kind, "dropped")
+		log.Printf("⚠️ Alert queue full; dropped %s alert", a.Kind)
+	}
+}
+
+// Close stops accepting alerts and waits up to timeout for queued ones
+func (n *AlertNotifier) Close(timeout time.Duration) bool {
+	if n == nil {
+		return true
+	}
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	select {
+	case <-n.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (n *AlertNotifier) run() {
+	defer close(n.done)
+	for a := range n.queue {
+		var err error
+		for attempt := 1; attempt <= alertAttempts; attempt++ {
+			if err = n.post(a); err == nil {
+				break
+			}
+			if attempt < alertAttempts {
+				time.Sleep(alertRetryDelay * time.Duration(attempt))
+			}
+		}
+		if err != nil {
+			n.metrics.AlertOutcome(a.Kind, "failed")
+			log.Printf("⚠️ %s alert not delivered after %d attempts: %v", a.Kind, alertAttempts, err)
+			continue
+		}
+		n.metrics.AlertOutcome(a.Kind, "sent")
+	}
+}
+
+// post delivers one alert in the configured format
+func (n *AlertNotifier) post(a Alert) error {
+	body, err := json.Marshal(n.payload(a))
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL can carry a token (Slack, Telegram); keep it out of logs
+		return fmt.Errorf("webhook request failed: %v", redactURLError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// payload renders an alert for the webhook's format
+func (n *AlertNotifier) payload(a Alert) interface{} {
+	text := fmt.Sprintf("🚨 [%s] %s (run %s)", a.Kind, a.Message, a.RunID)
+	switch n.Format {
+	case AlertFormatSlack:
+		return map[string]string{"text": text}
+	case AlertFormatTelegram:
+		return map[string]string{"chat_id": n.ChatID, "text": text}
+	default:
+		return a
+	}
+}
+
+// redactURLError strips the request URL from a client error
+func redactURLError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return ue.Err
+	}
+	return err
+}
+
+// alert sends a notification when alerting is configured
+func (te *TradingEngine) alert(kind, format string, args ...interface{}) {
+	te.alerts.Notify(Alert{Kind: kind, Message: te.redactor.Redact(fmt.Sprintf(format, args...)), RunID: te.RunID, Time: te.Clock.Now().UTC()})
+}
+
+// checkStrikeAlerts raises the per-strike alerts: a loss above AlertLossUSD,
+// and drawdown crossing one of AlertDrawdownLevels. A level re-arms once the
+// drawdown recovers back under it.
+func (te *TradingEngine) checkStrikeAlerts(strike *MacroStrike) {
+	if strike.PnL != nil && te.AlertLossUSD > 0 && -*strike.PnL >= te.AlertLossUSD {
+		te.alert(AlertLargeLoss, "strike %d on %s lost $%.2f", strike.ID, strike.Symbol, -*strike.PnL)
+	}
+	if len(te.AlertDrawdownLevels) == 0 {
+		return
+	}
+	capital := atomic.LoadInt64(&te.Capital)
+	peak := atomic.LoadInt64(&te.PeakCapital)
+	var drawdown float64
+	if peak > 0 && capital < peak {
+		drawdown = float64(peak-capital) / float64(peak) * 100.0
+	}
+	crossed := int64(0)
+	for _, level := range te.AlertDrawdownLevels {
+		if drawdown >= level {
+			crossed++
+		}
+	}
+	// Managed positions settle concurrently; the swap hands each crossing to
+	// exactly one of them
+	if prev := atomic.SwapInt64(&te.alertDrawdownCrossed, crossed); crossed > prev {
+		te.alert(AlertDrawdown, "drawdown %.2f%% crossed %.2f%% (capital %v, peak %v)",
+			drawdown, te.AlertDrawdownLevels[crossed-1], Money(capital), Money(peak))
+	}
+}
+
+// parseAlertDrawdownLevels reads ALERT_DRAWDOWN_LEVELS, percents like "5,10,15"
+func parseAlertDrawdownLevels(raw string) ([]float64, error) {
+	var levels []float64
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v <= 0 || v >= 100 {
+			return nil, fmt.Errorf("drawdown level %q is not a percent in (0,100)", f)
+		}
+		levels = append(levels, v)
+	}
+	sort.Float64s(levels)
+	return levels, nil
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAlertsRetryRateLimitAndCount(t *testing.T) {
+	defer func(d time.Duration) { alertRetryDelay = d }(alertRetryDelay)
+	alertRetryDelay = 0
+
+	var mu sync.Mutex
+	var bodies []map[string]string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			http.Error(w, "try again", http.StatusBadGateway)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		json.Unmarshal(raw, &body)
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	t.Setenv("ALERT_WEBHOOK_URL", srv.URL)
+	t.Setenv("ALERT_WEBHOOK_FORMAT", "slack")
+	t.Setenv("ALERT_LOSS_USD", "50")
+	t.Setenv("ALERT_DRAWDOWN_LEVELS", "10,5")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if te.AlertDrawdownLevels[0] != 5 {
+		t.Errorf("levels = %v, want sorted", te.AlertDrawdownLevels)
+	}
+
+	loss := func(id uint64, pnl float64) *MacroStrike {
+		s := certainStrike(id, false)
+		s.PnL = &pnl
+		return s
+	}
+	te.checkStrikeAlerts(loss(1, -75))
+	// A second large loss inside ALERT_MIN_INTERVAL is rate-limited
+	te.checkStrikeAlerts(loss(2, -80))
+	te.checkStrikeAlerts(loss(3, -10))
+	atomic.StoreInt64(&te.ConsecutiveMisses, te.MaxConsecutiveMisses)
+	te.CheckEmergencyStops()
+	if !te.alerts.Close(5 * time.Second) {
+		t.Fatal("alerts did not drain")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 || !strings.Contains(bodies[0]["text"], "[large_loss] strike 1 on WETH/USDC lost $75.00") ||
+		!strings.Contains(bodies[1]["text"], "[emergency_stop] Too many consecutive misses") {
+		t.Errorf("webhook bodies = %v, want the first large loss then the emergency stop", bodies)
+	}
+	var buf bytes.Buffer
+	te.WriteMetrics(&buf)
+	for _, want := range []string{
+		`macro_alerts_total{kind="large_loss",outcome="sent"} 1`,
+		`macro_alerts_total{kind="large_loss",outcome="rate_limited"} 1`,
+		`macro_alerts_total{kind="emergency_stop",outcome="sent"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestDrawdownAlertsFireOncePerLevel(t *testing.T) {
+	var mu sync.Mutex
+	var sent []Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		mu.Lock()
+		sent = append(sent, a)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	te := NewTradingEngine()
+	te.alerts = NewAlertNotifier(srv.URL, AlertFormatJSON, "", 0, srv.Client(), te.Clock, nil)
+	te.AlertDrawdownLevels = []float64{5, 10}
+
+	te.Capital, te.PeakCapital = 9400_00, 10000_00
+	te.checkStrikeAlerts(certainStrike(1, false))
+	te.Capital = 9300_00
+	te.checkStrikeAlerts(certainStrike(2, false))
+	if crossed := atomic.LoadInt64(&te.alertDrawdownCrossed); crossed != 1 {
+		t.Errorf("crossed = %d after 7%% drawdown, want 1", crossed)
+	}
+	te.Capital = 8900_00
+	te.checkStrikeAlerts(certainStrike(3, false))
+	// Recovering under 5% re-arms the first level
+	te.Capital = 9900_00
+	te.checkStrikeAlerts(certainStrike(4, false))
+	te.Capital = 9400_00
+	te.checkStrikeAlerts(certainStrike(5, false))
+	te.alerts.Close(5 * time.Second)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 3 || sent[0].Kind != AlertDrawdown || !strings.Contains(sent[1].Message, "crossed 10.00%") {
+		t.Errorf("alerts = %+v, want drawdown alerts at 5%%, 10%%, then 5%% again", sent)
+	}
+}
+
+// Managed positions settle on their own goroutines; run with -race
+func TestDrawdownAlertOnceAcrossConcurrentManagedExits(t *testing.T) {
+	var mu sync.Mutex
+	var sent []Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		mu.Lock()
+		sent = append(sent, a)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	te := NewTradingEngine()
+	te.alerts = NewAlertNotifier(srv.URL, AlertFormatJSON, "", 0, srv.Client(), te.Clock, nil)
+	te.AlertDrawdownLevels = []float64{5}
+	te.Capital, te.PeakCapital = 9300_00, 10000_00
+
+	const positions = 8
+	pm := NewPositionManager(positions)
+	start := make(chan struct{})
+	for i := 0; i < positions; i++ {
+		strike := certainStrike(uint64(i+1), false)
+		pm.Go(strike, func() (float64, error) {
+			<-start
+			te.strikeCompleted(strike, atomic.LoadInt64(&te.Capital))
+			return 0, nil
+		})
+	}
+	close(start)
+	for i := 0; i < positions; i++ {
+		pm.Next()
+	}
+	te.alerts.Close(5 * time.Second)
+	mu.Lock()
+	defer mu.Unlock()
+	drawdowns := 0
+	for _, a := range sent {
+		if a.Kind == AlertDrawdown {
+			drawdowns++
+		}
+	}
+	if drawdowns != 1 {
+		t.Errorf("%d drawdown alerts from %d concurrent exits, want 1", drawdowns, positions)
+	}
+}
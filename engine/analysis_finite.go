@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// What a strike does when its analysis carries NaN or Inf (NONFINITE_ANALYSIS)
+const (
+	NonFiniteSkip = "skip"
+	NonFiniteStop = "stop"
+)
+
+// ErrAnalysisNonFinite means the analyzer reported NaN or Inf in a field the
+// strike would be priced or sized from
+var ErrAnalysisNonFinite = errors.New("non-finite market analysis")
+
+// nonFiniteToken matches a bare NaN or Inf value, which Julia writes and
+// encoding/json refuses to parse
+var nonFiniteToken = regexp.MustCompile(`"(\w+)"\s*:\s*([+-]?(?:NaN|nan|Infinity|Inf|inf))\b`)
+
+// parseMarketAnalysis decodes analyzer output, naming the first field that
+// holds NaN or Inf instead of letting it reach levels and sizing
+func parseMarketAnalysis(output []byte) (*MarketAnalysis, error) {
+	if m := nonFiniteToken.FindSubmatch(output); m != nil {
+		return nil, fmt.Errorf("%w: %s is %s", ErrAnalysisNonFinite, m[1], m[2])
+	}
+	var analysis MarketAnalysis
+	if err := json.Unmarshal(output, &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse market analysis: %v", err)
+	}
+	if err := analysis.checkFinite(); err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// checkFinite rejects analysis with a NaN or Inf numeric field
+func (a *MarketAnalysis) checkFinite() error {
+	fields := []struct {
+		name string
+		v    *float64
+	}{
+		{"price", &a.Price},
+		{"confidence", &a.Confidence},
+		{"expected_return", &a.ExpectedReturn},
+		{"volatility", &a.Volatility},
+		{"momentum", &a.Momentum},
+		{"liquidity", &a.Liquidity},
+		{"precision_score", &a.PrecisionScore},
+		{"suggested_stop", a.SuggestedStop},
+		{"suggested_target", a.SuggestedTarget},
+	}
+	for _, f := range fields {
+		if f.v != nil && (math.IsNaN(*f.v) || math.IsInf(*f.v, 0)) {
+			return fmt.Errorf("%w: %s is %v", ErrAnalysisNonFinite, f.name, *f.v)
+		}
+	}
+	return nil
+}
+
+// nonFiniteAnalysis turns a non-finite analysis into a skip, or passes it on
+// to stop the campaign under NONFINITE_ANALYSIS=stop
+func (te *TradingEngine) nonFiniteAnalysis(symbol string, err error) error {
+	if te.NonFiniteAnalysis == NonFiniteStop {
+		return fmt.Errorf("%s: %w", symbol, err)
+	}
+	return newSkip(SkipNonFinite, "%s %v", symbol, err)
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+func TestParseMarketAnalysisRejectsNonFinite(t *testing.T) {
+	for _, tc := range []struct {
+		output string
+		field  string
+	}{
+		{`{"symbol":"ETHUSD","price":2500.0,"confidence":NaN,"expected_return":0.02}`, "confidence"},
+		{`{"symbol":"ETHUSD","price":Infinity,"confidence":0.9}`, "price"},
+		{`{"symbol":"ETHUSD","price":2500.0,"expected_return": -Inf}`, "expected_return"},
+		{`{"symbol":"ETHUSD","price":2500.0,"suggested_stop":nan}`, "suggested_stop"},
+	} {
+		_, err := parseMarketAnalysis([]byte(tc.output))
+		if !errors.Is(err, ErrAnalysisNonFinite) {
+			t.Errorf("%s: err = %v, want ErrAnalysisNonFinite", tc.output, err)
+			continue
+		}
+		if want := tc.field + " is"; !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err %q does not name %s", tc.output, err, tc.field)
+		}
+	}
+	a, err := parseMarketAnalysis([]byte(`{"symbol":"ETHUSD","price":2500.0,"confidence":0.9,"recommendation":"EXECUTE NaN check"}`))
+	if err != nil || a.Price != 2500 {
+		t.Errorf("finite analysis: %+v, %v", a, err)
+	}
+}
+
+// fakeAnalyzer puts a julia on PATH that prints output for every analysis
+func fakeAnalyzer(t *testing.T, output string) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, analyzerBinary), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestNonFiniteAnalysisSkipsOrStops(t *testing.T) {
+	fakeAnalyzer(t, `{"symbol":"ETHUSD","strike_type":"momentum","price":2500.0,"confidence":NaN,"expected_return":0.02,"volatility":0.02,"recommendation":"EXECUTE"}`)
+
+	te := NewTradingEngineFromConfig(config.Config{})
+	for i := 0; i < 3; i++ {
+		strike, err := te.GenerateStrike(context.Background())
+		if strike != nil {
+			t.Fatalf("strike %+v built from NaN confidence", strike)
+		}
+		var skip *skipError
+		if !errors.As(err, &skip) {
+			t.Fatalf("err = %v, want a skip", err)
+		}
+		if skip.Reason == SkipStablecoinType {
+			continue
+		}
+		if skip.Reason != SkipNonFinite || !strings.Contains(skip.Detail, "confidence is NaN") {
+			t.Errorf("skip %s (%s), want %s naming confidence", skip.Reason, skip.Detail, SkipNonFinite)
+		}
+	}
+
+	te = NewTradingEngineFromConfig(config.Config{"NONFINITE_ANALYSIS": NonFiniteStop})
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopNonFiniteAnalysis || result.TradesCompleted != 0 {
+		t.Errorf("stop %q after %d trades, want %q before any", result.StopReason, result.TradesCompleted, report.StopNonFiniteAnalysis)
+	}
+
+	if err := NewTradingEngineFromConfig(config.Config{"NONFINITE_ANALYSIS": "clamp"}).ValidateConfig(); err == nil {
+		t.Error("NONFINITE_ANALYSIS=clamp accepted")
+	}
+}
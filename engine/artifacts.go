@@ -0,0 +1,267 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// Artifact upload tuning
+const (
+	artifactUploadAttempts = 3
+	artifactDrainTimeout   = 60 * time.Second
+)
+
+// artifactRetryDelay is the base backoff between upload attempts
+var artifactRetryDelay = 2 * time.Second
+
+// S3Uploader PUTs campaign artifacts to an S3-compatible bucket using
+// path-style URLs and SigV4, so MinIO/R2/etc. work as well as AWS
+type S3Uploader struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	client    *http.Client
+	wg        sync.WaitGroup
+	// now dates request signatures; the engine points it at its Clock
+	now func() time.Time
+}
+
+// NewS3UploaderFromConfig returns nil when ARTIFACT_S3_BUCKET is unset, which
+// leaves artifact upload disabled
+func NewS3UploaderFromConfig(cfg config.Config) (*S3Uploader, error) {
+	bucket := cfg.Get("ARTIFACT_S3_BUCKET")
+	if bucket == "" {
+		return nil, nil
+	}
+	u := &S3Uploader{
+		Endpoint:  strings.TrimRight(cfg.Get("ARTIFACT_S3_ENDPOINT"), "/"),
+		Region:    cfg.Get("ARTIFACT_S3_REGION"),
+		Bucket:    bucket,
+		Prefix:    strings.Trim(cfg.Get("ARTIFACT_S3_PREFIX"), "/"),
+		AccessKey: cfg.First("ARTIFACT_S3_ACCESS_KEY", "AWS_ACCESS_KEY_ID"),
+		SecretKey: cfg.First("ARTIFACT_S3_SECRET_KEY", "AWS_SECRET_ACCESS_KEY"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+		now:       time.Now,
+	}
+	if u.Region == "" {
+		u.Region = "us-east-1"
+	}
+	if u.Endpoint == "" {
+		u.Endpoint = "https://s3." + u.Region + ".amazonaws.com"
+	}
+	if u.AccessKey == "" || u.SecretKey == "" {
+		return nil, fmt.Errorf("ARTIFACT_S3_BUCKET set without access key/secret")
+	}
+	return u, nil
+}
+
+// artifact is one object to upload
+type artifact struct {
+	Name string
+	Body []byte
+}
+
+// UploadAsync uploads artifacts under prefix/runID/stamp/ in the background
+func (u *S3Uploader) UploadAsync(runID string, stamp time.Time, artifacts []artifact) {
+	if u == nil || len(artifacts) == 0 {
+		return
+	}
+	base := path.Join(u.Prefix, runID, stamp.UTC().Format("20060102T150405Z"))
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		for _, a := range artifacts {
+			key := path.Join(base, a.Name)
+			var err error
+			for attempt := 1; attempt <= artifactUploadAttempts; attempt++ {
+				if err = u.put(key, a.Body, u.now()); err == nil {
+					break
+				}
+				if attempt < artifactUploadAttempts {
+					time.Sleep(artifactRetryDelay * time.Duration(attempt))
+				}
+			}
+			if err != nil {
+				log.Printf("⚠️ Artifact upload of %s failed after %d attempts: %v", key, artifactUploadAttempts, err)
+				continue
+			}
+			log.Printf("☁️ Uploaded s3://%s/%s (%d bytes)", u.Bucket, key, len(a.Body))
+		}
+	}()
+}
+
+// Wait blocks until queued uploads finish or timeout elapses
+func (u *S3Uploader) Wait(timeout time.Duration) bool {
+	if u == nil {
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		u.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// put uploads one object
+func (u *S3Uploader) put(key string, body []byte, now time.Time) error {
+	req, err := http.NewRequest(http.MethodPut, u.Endpoint+s3EscapePath("/"+u.Bucket+"/"+key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	signS3Request(req, body, u.AccessKey, u.SecretKey, u.Region, now)
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// signS3Request adds SigV4 headers for the s3 service. Host and every header
+// already on the request are signed.
+func signS3Request(req *http.Request, body []byte, accessKey, secretKey, region string, now time.Time) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	crSum := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crSum[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+// s3EscapePath percent-encodes a path the way SigV4 expects for S3: every
+// byte except unreserved characters and '/'
+func s3EscapePath(p string) string {
+	var sb strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// equityCurveCSV renders the campaign equity curve
+func (te *TradingEngine) equityCurveCSV() []byte {
+	curve := te.campaignStats.Snapshot().EquityCurve
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"trade", "time", "capital"})
+	for _, p := range curve {
+		w.Write([]string{strconv.FormatInt(p.Trade, 10), p.Time.UTC().Format(time.RFC3339Nano), formatCSVFloat(p.Capital)})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// uploadArtifacts queues the campaign's reports and logs for upload. when
+// names the trigger; the journal and Parquet strike export are only included
+// once they have been closed.
+func (te *TradingEngine) uploadArtifacts(when string, sinksClosed bool) {
+	if te.artifacts == nil {
+		return
+	}
+	files := []struct{ name, path string }{
+		{"campaign_report.json", te.ReportJSONPath},
+		{"campaign_report.html", te.ReportHTMLPath},
+		{"strikes.jsonl", te.StrikeLogPath},
+		{"strikes.json", te.StrikesJSONPath},
+		{"strikes.csv", te.CSVExportPath},
+		{"realized_gains.csv", te.RealizedGainsPath},
+		{"equity_curve.parquet", te.ParquetEquityPath},
+	}
+	if sinksClosed {
+		// The Parquet strike export has no footer until it is closed
+		files = append(files,
+			struct{ name, path string }{"journal.db", te.JournalPath},
+			struct{ name, path string }{"strikes.parquet", te.ParquetExportPath})
+	}
+	arts := []artifact{{Name: "equity_curve.csv", Body: te.equityCurveCSV()}}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			log.Printf("⚠️ Artifact %s not uploaded: %v", f.name, err)
+			continue
+		}
+		arts = append(arts, artifact{Name: f.name, Body: data})
+	}
+	log.Printf("Uploading %d artifact(s) (%s)", len(arts), when)
+	te.artifacts.UploadAsync(te.RunID, te.Clock.Now(), arts)
+}
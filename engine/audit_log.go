@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// auditGenesisHash is the prev_hash of the first record in a chain
+var auditGenesisHash = strings.Repeat("0", 64)
+
+// Audit chain fields added to each strike log record in audit mode
+const (
+	auditPrevHashField = "prev_hash"
+	auditHashField     = "hash"
+)
+
+// Canonical JSON used for audit hashing. The rules are fixed so a log written
+// by one build verifies under any other:
+//   - objects: keys sorted by raw UTF-8 byte order, no insignificant whitespace
+//   - numbers: the literal text as it appears in the record, never re-formatted
+//   - strings: `"` and `\` escaped, \b \f \n \r \t as short escapes, other
+//     control characters as \u00xx (lowercase hex), everything else raw UTF-8
+//   - arrays, true, false and null as usual
+//
+// Records are always decoded with UseNumber before canonicalization, so float
+// formatting only matters when a record is first written.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if x {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		buf.WriteString(string(x))
+	case string:
+		writeCanonicalString(buf, x)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonical JSON: unsupported type %T", v)
+	}
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// decodeAuditRecord parses a JSON object keeping numbers as literal text
+func decodeAuditRecord(data []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("record is not valid UTF-8")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("record is not a JSON object")
+	}
+	return m, nil
+}
+
+// auditLinkHash is SHA-256(prevHash ‖ canonical record), hex encoded. The
+// record must already carry prev_hash and must not carry hash.
+func auditLinkHash(prevHash string, record map[string]interface{}) (string, error) {
+	canon, err := canonicalJSON(record)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(canon)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// chainAuditRecord links a marshaled record to prevHash and returns the
+// canonical line to append along with its hash
+func chainAuditRecord(prevHash string, record []byte) ([]byte, string, error) {
+	m, err := decodeAuditRecord(record)
+	if err != nil {
+		return nil, "", err
+	}
+	delete(m, auditHashField)
+	m[auditPrevHashField] = prevHash
+	hash, err := auditLinkHash(prevHash, m)
+	if err != nil {
+		return nil, "", err
+	}
+	m[auditHashField] = hash
+	line, err := canonicalJSON(m)
+	return line, hash, err
+}
+
+// AuditBreak describes the first record whose chain link does not verify
+type AuditBreak struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+func (b *AuditBreak) Error() string {
+	return fmt.Sprintf("audit chain broken at %s:%d: %s", b.File, b.Line, b.Reason)
+}
+
+// VerifyAuditLog walks audit-mode strike logs in order (oldest rotated file
+// first, the live file last; .gz files are read transparently) and returns
+// the number of verified records. The first broken link is returned as an
+// *AuditBreak.
+func VerifyAuditLog(paths ...string) (int, error) {
+	prev := auditGenesisHash
+	verified := 0
+	for _, path := range paths {
+		n, last, err := verifyAuditFile(path, prev)
+		verified += n
+		if err != nil {
+			return verified, err
+		}
+		prev = last
+	}
+	return verified, nil
+}
+
+// verifyAuditFile checks one file, continuing the chain from prev
+func verifyAuditFile(path, prev string) (int, string, error) {
+	r, closeFn, err := openMaybeGzip(path)
+	if err != nil {
+		return 0, prev, err
+	}
+	defer closeFn()
+	verified := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		broken := func(format string, args ...interface{}) (int, string, error) {
+			return verified, prev, &AuditBreak{File: path, Line: lineNo, Reason: fmt.Sprintf(format, args...)}
+		}
+		m, err := decodeAuditRecord(scanner.Bytes())
+		if err != nil {
+			return broken("unparseable record: %v", err)
+		}
+		hash, _ := m[auditHashField].(string)
+		if hash == "" {
+			return broken("record has no %s", auditHashField)
+		}
+		if got, _ := m[auditPrevHashField].(string); got != prev {
+			return broken("prev_hash %.12s… does not match the previous record's hash %.12s…", got, prev)
+		}
+		delete(m, auditHashField)
+		want, err := auditLinkHash(prev, m)
+		if err != nil {
+			return broken("%v", err)
+		}
+		if hash != want {
+			return broken("hash %.12s… does not match record contents (expected %.12s…)", hash, want)
+		}
+		prev = hash
+		verified++
+	}
+	if err := scanner.Err(); err != nil {
+		return verified, prev, err
+	}
+	return verified, prev, nil
+}
+
+// lastAuditHash returns the hash a new record appended to path should chain
+// from: the last record of path, or of its newest rotation when path is empty
+func lastAuditHash(path string) (string, error) {
+	for _, candidate := range []string{path, path + ".1", path + ".1.gz"} {
+		r, closeFn, err := openMaybeGzip(candidate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		last := ""
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+				last = scanner.Text()
+			}
+		}
+		err = scanner.Err()
+		closeFn()
+		if err != nil {
+			return "", err
+		}
+		if last == "" {
+			continue
+		}
+		m, err := decodeAuditRecord([]byte(last))
+		if err != nil {
+			return "", fmt.Errorf("%s: last record: %v", candidate, err)
+		}
+		hash, _ := m[auditHashField].(string)
+		if hash == "" {
+			return "", fmt.Errorf("%s: last record is not hash-chained", candidate)
+		}
+		return hash, nil
+	}
+	return auditGenesisHash, nil
+}
+
+// openMaybeGzip opens path, decompressing when it ends in .gz
+func openMaybeGzip(path string) (io.Reader, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, func() { f.Close() }, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return zr, func() { zr.Close(); f.Close() }, nil
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestCanonicalJSONIsPinned(t *testing.T) {
+	rec, err := decodeAuditRecord([]byte(`{
+		"z": 1, "a": {"y": [true, null, 1e-7], "b": 0.1},
+		"s": "tab\tquote\"back\\slash\u0001<&> é ✅", "n": -0.000000,
+		"big": 12345678901234567890
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := canonicalJSON(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":{"b":0.1,"y":[true,null,1e-7]},"big":12345678901234567890,"n":-0.000000,` +
+		`"s":"tab\tquote\"back\\slash\u0001<&> é ✅","z":1}`
+	if string(got) != want {
+		t.Errorf("canonical =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAuditChainGoldenHash(t *testing.T) {
+	line, hash, err := chainAuditRecord(auditGenesisHash, []byte(`{"type":"strike","time":1736164800000,"run_id":"r1","capital_after":1012.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLine := `{"capital_after":1012.5,"hash":"` + hash + `","prev_hash":"` + auditGenesisHash + `","run_id":"r1","time":1736164800000,"type":"strike"}`
+	if string(line) != wantLine {
+		t.Errorf("line =\n%s\nwant\n%s", line, wantLine)
+	}
+	// Pinned so a change to the canonical form or hash input is caught
+	const want = "7bc8076253b9aef0d806e038d0b1da869754cd4fbe91393f10daef71ae9731f8"
+	if hash != want {
+		t.Errorf("hash = %s, want %s", hash, want)
+	}
+}
+
+// writeAuditStrikes logs n sim strikes through an audit-mode strike logger
+func writeAuditStrikes(t *testing.T, path string, rotation RotationPolicy, n int) {
+	t.Helper()
+	sl, err := NewJSONLStrikeLogger(path, "run-1", rotation, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		pnl, exit := 1.25*float64(i), 3000.1
+		sl.LogStrike(&MacroStrike{ID: uint64(i + 1), Symbol: "WETH/USDC", PnL: &pnl, ExitPrice: &exit, Status: Hit}, 1000+pnl)
+		if i%3 == 0 {
+			sl.LogSkip(newSkip(SkipLowConfidence, "conf=0.5"), 1000)
+		}
+	}
+	if err := sl.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditLogVerifiesAndReportsFirstBrokenLink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	writeAuditStrikes(t, path, RotationPolicy{}, 5)
+	// A restart keeps chaining from the last record on disk
+	writeAuditStrikes(t, path, RotationPolicy{}, 2)
+
+	n, err := VerifyAuditLog(path)
+	if err != nil {
+		t.Fatalf("VerifyAuditLog: %v", err)
+	}
+	if n != 10 {
+		t.Fatalf("verified %d records, want 10", n)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	// Editing a value breaks that record's own hash
+	edited := append([]string(nil), lines...)
+	edited[3] = strings.Replace(edited[3], `"capital_after":1002.5`, `"capital_after":9002.5`, 1)
+	if edited[3] == lines[3] {
+		t.Fatalf("test setup: line 4 has no capital_after 1002.5: %s", lines[3])
+	}
+	assertAuditBreak(t, path, edited, 4, "does not match record contents")
+
+	// Deleting a record breaks the next record's prev_hash
+	deleted := append(append([]string(nil), lines[:2]...), lines[3:]...)
+	assertAuditBreak(t, path, deleted, 3, "prev_hash")
+}
+
+func assertAuditBreak(t *testing.T, path string, lines []string, wantLine int, wantReason string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := VerifyAuditLog(path)
+	var br *AuditBreak
+	if !errors.As(err, &br) {
+		t.Fatalf("err = %v, want an *AuditBreak", err)
+	}
+	if br.Line != wantLine || !strings.Contains(br.Reason, wantReason) || n != wantLine-1 {
+		t.Errorf("break at line %d after %d records (%s), want line %d containing %q", br.Line, n, br.Reason, wantLine, wantReason)
+	}
+}
+
+func TestAuditChainContinuesAcrossRotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	rotation := RotationPolicy{MaxBytes: 2048, Keep: 50, Compress: true}
+	writeAuditStrikes(t, path, rotation, 12)
+
+	var files []string
+	for i := 50; i >= 1; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			if name := path + "." + strconv.Itoa(i) + ext; fileExists(name) {
+				files = append(files, name)
+			}
+		}
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected rotations, got %v", files)
+	}
+	files = append(files, path)
+	if _, err := VerifyAuditLog(files...); err != nil {
+		t.Fatalf("VerifyAuditLog across rotations: %v", err)
+	}
+	// Verifying a later segment on its own fails at its first link
+	if _, err := VerifyAuditLog(files[1:]...); err == nil {
+		t.Error("a segment verified without its predecessor")
+	}
+}
+
+func TestAuditModeNeverPrunesRotatedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	// keep=1 would drop the genesis segment; audit mode must ignore it
+	writeAuditStrikes(t, path, RotationPolicy{MaxBytes: 1024, Keep: 1}, 12)
+
+	var files []string
+	for i := 1; fileExists(path + "." + strconv.Itoa(i)); i++ {
+		files = append([]string{path + "." + strconv.Itoa(i)}, files...)
+	}
+	if len(files) < 3 {
+		t.Fatalf("expected several rotations, got %v", files)
+	}
+	files = append(files, path)
+	// 12 strikes plus a skip on every third
+	if n, err := VerifyAuditLog(files...); err != nil || n != 16 {
+		t.Fatalf("verified %d records, err %v; want all 16 from genesis", n, err)
+	}
+}
+
+func TestAuditChainResumesFromRotatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	writeAuditStrikes(t, path, RotationPolicy{}, 3)
+	// Rotated just before shutdown: the restart finds an empty live file
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeAuditStrikes(t, path, RotationPolicy{}, 2)
+	if n, err := VerifyAuditLog(path+".1", path); err != nil || n != 7 {
+		t.Fatalf("verified %d records, err %v; want 7, nil", n, err)
+	}
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// krakenMaxBatchOrders is the most orders AddOrderBatch accepts at once; it
+// also needs at least two, all on one pair
+const krakenMaxBatchOrders = 15
+
+// OrderReq is one market order for placeBatchOrders, sized in USD like
+// placeMarketOrder
+type OrderReq struct {
+	Pair    string
+	Side    string
+	USDSize float64
+	Price   float64
+}
+
+// placeBatchOrders places market orders with as few round trips as Kraken
+// allows: orders sharing a pair go through AddOrderBatch, a pair with a
+// single order through AddOrder. Txids come back in request order, "" for an
+// order that was not placed, alongside an error naming every failure.
+//
+// A batch Kraken rejects outright is placed one order at a time instead. A
+// batch that fails in transport is not: it may have reached the book, and
+// placing it again could double the position.
+func (te *TradingEngine) placeBatchOrders(ctx context.Context, orders []OrderReq) ([]string, error) {
+	txids := make([]string, len(orders))
+	var errs []string
+	fail := func(i int, err error) {
+		errs = append(errs, fmt.Sprintf("order %d (%s %s): %v", i, orders[i].Side, orders[i].Pair, err))
+	}
+
+	placeOne := func(i int) {
+		tx, err := te.placeMarketOrder(ctx, orders[i].Pair, orders[i].Side, orders[i].USDSize, orders[i].Price)
+		if err != nil {
+			fail(i, err)
+		}
+		txids[i] = tx
+	}
+
+	// Group by pair, keeping first-seen order
+	var pairs []string
+	byPair := make(map[string][]int)
+	for i, o := range orders {
+		if _, ok := byPair[o.Pair]; !ok {
+			pairs = append(pairs, o.Pair)
+		}
+		byPair[o.Pair] = append(byPair[o.Pair], i)
+	}
+	for _, pair := range pairs {
+		// Orders that can't be sized never join a batch
+		var idx []int
+		volumes := make(map[int]string)
+		for _, i := range byPair[pair] {
+			volume, err := te.marketOrderVolume(ctx, pair, orders[i].USDSize, orders[i].Price)
+			if err != nil {
+				fail(i, err)
+				continue
+			}
+			idx = append(idx, i)
+			volumes[i] = volume
+		}
+		for len(idx) > 0 {
+			chunk := idx
+			if len(chunk) > krakenMaxBatchOrders {
+				chunk = chunk[:krakenMaxBatchOrders]
+			}
+			idx = idx[len(chunk):]
+			if len(chunk) == 1 {
+				placeOne(chunk[0])
+				continue
+			}
+			sides := make([]string, len(chunk))
+			vols := make([]string, len(chunk))
+			for k, i := range chunk {
+				sides[k], vols[k] = orders[i].Side, volumes[i]
+			}
+			batch, err := te.addOrderBatch(ctx, pair, sides, vols)
+			switch {
+			case err == nil:
+				for k, i := range chunk {
+					txids[i] = batch[k].txid
+					if batch[k].err != nil {
+						fail(i, batch[k].err)
+					}
+				}
+			case isKrakenAPIError(err):
+				log.Printf("⚠️ AddOrderBatch for %s rejected (%v); placing %d orders one at a time", pair, err, len(chunk))
+				for _, i := range chunk {
+					placeOne(i)
+				}
+			default:
+				for _, i := range chunk {
+					fail(i, fmt.Errorf("batch outcome unknown, not retried: %v", err))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return txids, errors.New(strings.Join(errs, "; "))
+	}
+	return txids, nil
+}
+
+// batchResult is one order's outcome within an AddOrderBatch response
+type batchResult struct {
+	txid string
+	err  error
+}
+
+// addOrderBatch sends one AddOrderBatch of market orders on pair
+func (te *TradingEngine) addOrderBatch(ctx context.Context, pair string, sides, volumes []string) ([]batchResult, error) {
+	vals := url.Values{}
+	vals.Set("pair", pair)
+	for k := range sides {
+		prefix := fmt.Sprintf("orders[%d]", k)
+		vals.Set(prefix+"[type]", sides[k])
+		vals.Set(prefix+"[ordertype]", "market")
+		vals.Set(prefix+"[volume]", volumes[k])
+	}
+	// No retry: a repeated batch could place every order twice
+	res, err := te.krakenPrivate(ctx, "/0/private/AddOrderBatch", vals)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := res["result"].(map[string]interface{})
+	placed, _ := result["orders"].([]interface{})
+	if len(placed) != len(sides) {
+		return nil, fmt.Errorf("AddOrderBatch returned %d results for %d orders", len(placed), len(sides))
+	}
+	out := make([]batchResult, len(sides))
+	for k, raw := range placed {
+		entry, _ := raw.(map[string]interface{})
+		if tx, ok := entry["txid"].(string); ok && tx != "" {
+			out[k].txid = tx
+			continue
+		}
+		out[k].err = fmt.Errorf("kraken error: [%v]", entry["error"])
+	}
+	return out, nil
+}
+
+// isKrakenAPIError reports whether err is an error envelope from Kraken
+// itself, meaning the request was received and refused
+func isKrakenAPIError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "kraken error: ")
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBatchOrdersGroupByPairAndKeepRequestOrder(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrderBatch", `{"orders":[{"txid":"ETH1"},{"txid":"ETH2"}]}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["BTC1"]}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 4, PairDecimals: 2}
+	te.pairInfoCache["XBTUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 1}
+
+	txids, err := te.placeBatchOrders(context.Background(), []OrderReq{
+		{Pair: "ETHUSD", Side: "buy", USDSize: 300, Price: 3000},
+		{Pair: "XBTUSD", Side: "buy", USDSize: 500, Price: 50000},
+		{Pair: "ETHUSD", Side: "buy", USDSize: 600, Price: 3000},
+	})
+	if err != nil {
+		t.Fatalf("placeBatchOrders: %v", err)
+	}
+	if strings.Join(txids, ",") != "ETH1,BTC1,ETH2" {
+		t.Errorf("txids = %v, want ETH1,BTC1,ETH2", txids)
+	}
+	var req map[string]string
+	for _, p := range te.takeOrderPayloads("ETH2") {
+		req = p.Request
+	}
+	if req["orders[0][volume]"] != "0.1000" || req["orders[1][volume]"] != "0.2000" || req["pair"] != "ETHUSD" {
+		t.Errorf("batch request = %v", req)
+	}
+}
+
+func TestRejectedBatchFallsBackToSequentialOrders(t *testing.T) {
+	te := replayEngine(t,
+		krakenExchangeRecord{Path: "/0/private/AddOrderBatch", Response: json.RawMessage(`{"error":["EGeneral:Invalid arguments"]}`)},
+		krakenReply("/0/private/AddOrder", `{"txid":["A"]}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["B"]}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 4, PairDecimals: 2}
+	orders := []OrderReq{
+		{Pair: "ETHUSD", Side: "buy", USDSize: 300, Price: 3000},
+		{Pair: "ETHUSD", Side: "buy", USDSize: 300, Price: 3000},
+	}
+	txids, err := te.placeBatchOrders(context.Background(), orders)
+	if err != nil || strings.Join(txids, ",") != "A,B" {
+		t.Errorf("txids %v err %v, want A,B placed one at a time", txids, err)
+	}
+
+	// A transport failure may have placed the batch; it is reported, not repeated
+	te = replayEngine(t, krakenExchangeRecord{Path: "/0/private/AddOrderBatch", Error: "connection reset"})
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 4, PairDecimals: 2}
+	txids, err = te.placeBatchOrders(context.Background(), orders)
+	if err == nil || !strings.Contains(err.Error(), "not retried") || txids[0] != "" || txids[1] != "" {
+		t.Errorf("txids %v err %v, want both unplaced and unretried", txids, err)
+	}
+}
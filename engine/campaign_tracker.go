@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"math"
+)
+
+// campaignTracker accumulates per-trade returns and the equity drawdown
+// profile while a campaign runs
+type campaignTracker struct {
+	trades int64
+	mean   float64
+	m2     float64
+	peak   float64
+	maxDD  float64
+}
+
+// newCampaignTracker starts tracking from the given capital (dollars)
+func newCampaignTracker(startCapital float64) *campaignTracker {
+	return &campaignTracker{peak: startCapital}
+}
+
+// trackerState is the persisted form of a campaignTracker's return statistics
+type trackerState struct {
+	Trades int64   `json:"trades"`
+	Mean   float64 `json:"mean"`
+	M2     float64 `json:"m2"`
+}
+
+// state returns the tracker's return statistics for persisting
+func (ct *campaignTracker) state() trackerState {
+	return trackerState{Trades: ct.trades, Mean: ct.mean, M2: ct.m2}
+}
+
+// resumeFrom carries the peak and worst drawdown over from before a restart
+func (ct *campaignTracker) resumeFrom(peak, maxDD float64, returns trackerState) {
+	ct.trades, ct.mean, ct.m2 = returns.Trades, returns.Mean, returns.M2
+	if peak > ct.peak {
+		ct.peak = peak
+	}
+	if maxDD > ct.maxDD {
+		ct.maxDD = maxDD
+	}
+}
+
+// observe records one completed trade's PnL and the capital after it
+func (ct *campaignTracker) observe(pnl, capitalAfter float64) {
+	capitalBefore := capitalAfter - pnl
+	if capitalBefore > 0 {
+		// Welford's online mean/variance of per-trade returns
+		r := pnl / capitalBefore
+		ct.trades++
+		delta := r - ct.mean
+		ct.mean += delta / float64(ct.trades)
+		ct.m2 += delta * (r - ct.mean)
+	}
+	if capitalAfter > ct.peak {
+		ct.peak = capitalAfter
+	}
+	if ct.peak > 0 {
+		if dd := (ct.peak - capitalAfter) / ct.peak; dd > ct.maxDD {
+			ct.maxDD = dd
+		}
+	}
+}
+
+// sharpe returns the per-trade (non-annualised) Sharpe ratio
+func (ct *campaignTracker) sharpe() float64 {
+	if ct.trades < 2 {
+		return 0
+	}
+	std := math.Sqrt(ct.m2 / float64(ct.trades-1))
+	if std == 0 {
+		return 0
+	}
+	return ct.mean / std
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestSinksStampRecordsFromEngineClock(t *testing.T) {
+	dir := t.TempDir()
+	te := NewTradingEngineFromConfig(config.Config{
+		"SIM_MODE":           "1",
+		"ORDER_WAL":          filepath.Join(dir, "orders.wal"),
+		"KRAKEN_RECORD_FILE": filepath.Join(dir, "kraken.jsonl"),
+		"SMTP_HOST":          "mail.invalid",
+		"SMTP_TO":            "me@example.com",
+		"SMTP_FROM":          "bot@example.com",
+	})
+	start := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	te.Clock = clock.NewFake(start)
+	want := start.UnixMilli()
+
+	te.orderWAL.Intent(1, "XETHZUSD", "buy", 25)
+	te.orderWAL.Close()
+	var walRec orderWALRecord
+	data, _ := os.ReadFile(filepath.Join(dir, "orders.wal"))
+	if err := json.Unmarshal(bytes.TrimSpace(data), &walRec); err != nil || walRec.Time != want {
+		t.Errorf("WAL record %s stamped %d, want %d", data, walRec.Time, want)
+	}
+
+	te.krakenRecorder.record("/0/public/Ticker", url.Values{"pair": {"XETHZUSD"}}, []byte(`{"error":[]}`), nil)
+	te.krakenRecorder.Close()
+	var krakenRec krakenExchangeRecord
+	data, _ = os.ReadFile(filepath.Join(dir, "kraken.jsonl"))
+	if err := json.Unmarshal(bytes.TrimSpace(data), &krakenRec); err != nil || krakenRec.Time != want {
+		t.Errorf("kraken record %s stamped %d, want %d", data, krakenRec.Time, want)
+	}
+
+	te.captureOrderPayload("/0/private/AddOrder", url.Values{}, []byte(`{"error":[],"result":{"txid":["OABC-1"]}}`))
+	if got := te.takeOrderPayloads("OABC-1"); len(got) != 1 || got[0].Time != want {
+		t.Errorf("order payloads %+v, want one stamped %d", got, want)
+	}
+
+	if msg := string(te.email.compose(emailMessage{Subject: "s"})); !strings.Contains(msg, "Date: "+start.Format(time.RFC1123Z)) {
+		t.Errorf("email not dated from the engine clock:\n%s", msg)
+	}
+
+	// Sync waits on the logger itself, so a clock that never advances can't stall it
+	te.publish(EventStrikeGenerated, certainStrike(1, true), nil)
+	if !te.eventLog.Sync(time.Second) {
+		t.Error("event log Sync timed out under a FakeClock")
+	}
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"log"
+	"time"
+)
+
+// recordSymbolLoss starts symbol's cooldown after a miss
+func (te *TradingEngine) recordSymbolLoss(symbol string, at time.Time) {
+	if te.SymbolLossCooldownMs <= 0 {
+		return
+	}
+	te.symbolLossMu.Lock()
+	te.symbolLastLoss[symbol] = at
+	te.symbolLossMu.Unlock()
+	log.Printf("⏳ %s cooling down for %v after a miss", symbol, time.Duration(te.SymbolLossCooldownMs)*time.Millisecond)
+}
+
+// checkSymbolCooldown skips a strike on a symbol whose last miss is more
+// recent than SymbolLossCooldownMs
+func (te *TradingEngine) checkSymbolCooldown(strike *MacroStrike) error {
+	if te.SymbolLossCooldownMs <= 0 {
+		return nil
+	}
+	te.symbolLossMu.Lock()
+	last, ok := te.symbolLastLoss[strike.Symbol]
+	te.symbolLossMu.Unlock()
+	if !ok {
+		return nil
+	}
+	cooldown := time.Duration(te.SymbolLossCooldownMs) * time.Millisecond
+	if left := cooldown - te.Clock.Since(last); left > 0 {
+		return newSkip(SkipSymbolCooldown, "%s cooling down after a miss, %v left", strike.Symbol, left.Round(time.Millisecond))
+	}
+	return nil
+}
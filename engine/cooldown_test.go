@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestSymbolSitsOutCooldownAfterMiss(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("SYMBOL_LOSS_COOLDOWN_MS", "60000")
+	te := NewTradingEngine()
+	clock := clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Clock = clock
+	other := certainStrike(3, true)
+	other.Symbol = "WBTC/USDC"
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, false)},
+		{Strike: certainStrike(2, true)},
+		{Strike: other},
+	}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.TradesCompleted != 2 || te.SkipCounts()[SkipSymbolCooldown] != 1 {
+		t.Errorf("trades %d, cooldown skips %d; want the WETH re-entry skipped and WBTC traded",
+			result.TradesCompleted, te.SkipCounts()[SkipSymbolCooldown])
+	}
+
+	clock.Advance(time.Minute)
+	if err := te.checkSymbolCooldown(certainStrike(4, true)); err != nil {
+		t.Errorf("still cooling down a minute later: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// csvColumns is the stable column order of the strike CSV export
+var csvColumns = []string{
+	"id", "timestamp", "symbol", "strike_type", "side", "entry", "exit", "stop", "target",
+	"size", "leverage", "confidence", "fees", "funding", "pnl", "status", "exit_reason", "duration_ms",
+}
+
+// CSVExporter writes one row per completed strike. In streaming mode rows
+// are appended as strikes complete; otherwise they are buffered and written
+// once on Close. The header is only written to an empty file, so resumed
+// runs keep appending to the same export, and every rotated file starts
+// with its own header.
+type CSVExporter struct {
+	mu       sync.Mutex
+	path     string
+	stream   bool
+	rotation RotationPolicy
+	file     *RotatingFile
+	err      error
+	rows     [][]byte
+}
+
+// NewCSVExporter prepares a CSV export at path
+func NewCSVExporter(path string, stream bool, rotation RotationPolicy) (*CSVExporter, error) {
+	e := &CSVExporter{path: path, stream: stream, rotation: rotation}
+	if stream {
+		if err := e.open(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *CSVExporter) open() error {
+	f, err := OpenRotatingFile(e.path, 0644, e.rotation, encodeCSVRecord(csvColumns))
+	if err != nil {
+		return err
+	}
+	e.file = f
+	return nil
+}
+
+// encodeCSVRecord renders one CSV line so it can be written in a single call
+func encodeCSVRecord(record []string) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(record)
+	w.Flush()
+	return buf.Bytes()
+}
+
+// Record adds a completed strike to the export; the first write error is
+// reported by Close
+func (e *CSVExporter) Record(strike *MacroStrike) {
+	row := encodeCSVRecord(strikeCSVRow(strike))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.stream {
+		e.rows = append(e.rows, row)
+		return
+	}
+	if _, err := e.file.Write(row); err != nil && e.err == nil {
+		e.err = err
+	}
+}
+
+// Close writes any buffered rows and closes the file
+func (e *CSVExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.stream {
+		if err := e.open(); err != nil {
+			return err
+		}
+		for _, row := range e.rows {
+			if _, err := e.file.Write(row); err != nil && e.err == nil {
+				e.err = err
+			}
+		}
+		e.rows = nil
+	}
+	err := e.file.Close()
+	if e.err != nil {
+		return e.err
+	}
+	return err
+}
+
+// strikeCSVRow renders a strike in csvColumns order with deterministic formatting
+func strikeCSVRow(s *MacroStrike) []string {
+	var exit, pnl string
+	if s.ExitPrice != nil {
+		exit = formatCSVFloat(*s.ExitPrice)
+	}
+	if s.PnL != nil {
+		pnl = formatCSVFloat(*s.PnL)
+	}
+	return []string{
+		strconv.FormatUint(s.ID, 10),
+		time.Unix(s.Timestamp, 0).UTC().Format(time.RFC3339),
+		s.Symbol,
+		s.StrikeType.String(),
+		strikeSide(s),
+		formatCSVFloat(s.EntryPrice),
+		exit,
+		formatCSVFloat(s.StopLoss),
+		formatCSVFloat(s.TargetPrice),
+		formatCSVFloat(s.StrikeForce),
+		strconv.FormatUint(uint64(s.Leverage), 10),
+		formatCSVFloat(s.Confidence),
+		formatCSVFloat(s.Fees),
+		formatCSVFloat(s.Funding),
+		pnl,
+		s.Status.String(),
+		s.ExitReason,
+		strconv.FormatInt(s.DurationMs, 10),
+	}
+}
+
+// formatCSVFloat prints the shortest exact decimal representation
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
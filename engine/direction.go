@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"macro-strike-bot/config"
+)
+
+// Direction is the side a strike takes: long buys first, short sells first
+type Direction int
+
+const (
+	Long Direction = iota
+	Short
+)
+
+// String returns the lowercase name of a direction
+func (d Direction) String() string {
+	switch d {
+	case Long:
+		return "long"
+	case Short:
+		return "short"
+	default:
+		return "unknown"
+	}
+}
+
+// directionByName parses a direction as String renders it
+func directionByName(name string) (Direction, bool) {
+	switch name {
+	case "long":
+		return Long, true
+	case "short":
+		return Short, true
+	}
+	return Long, false
+}
+
+// entrySide is the order side that opens a strike: buy for a long, sell to
+// open a short
+func (d Direction) entrySide() string {
+	if d == Short {
+		return "sell"
+	}
+	return "buy"
+}
+
+// exitSide is the order side that closes a strike: sell a long, buy to cover
+// a short
+func (d Direction) exitSide() string {
+	if d == Short {
+		return "buy"
+	}
+	return "sell"
+}
+
+// levelDirection is the direction the analysed levels imply: a target below
+// the entry is a short setup
+func levelDirection(entry, target float64) Direction {
+	if target < entry {
+		return Short
+	}
+	return Long
+}
+
+// DirectionPolicy restricts the directions a strike type may trade. The zero
+// value, DirectionBoth, keeps whatever direction the analysis chose.
+type DirectionPolicy int
+
+const (
+	DirectionBoth DirectionPolicy = iota
+	LongOnly
+	ShortOnly
+)
+
+// String returns the policy as DIRECTION_BY_TYPE spells it
+func (p DirectionPolicy) String() string {
+	switch p {
+	case LongOnly:
+		return "long"
+	case ShortOnly:
+		return "short"
+	default:
+		return "both"
+	}
+}
+
+// allows reports whether the policy permits trading in direction d
+func (p DirectionPolicy) allows(d Direction) bool {
+	switch p {
+	case LongOnly:
+		return d == Long
+	case ShortOnly:
+		return d == Short
+	default:
+		return true
+	}
+}
+
+// parseDirectionByType parses "MacroFunding=short,MacroMomentum=long"; types
+// not listed trade both directions
+func parseDirectionByType(raw string) (map[StrikeType]DirectionPolicy, error) {
+	entries, err := config.ParseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[StrikeType]DirectionPolicy, len(entries))
+	for name, rawPolicy := range entries {
+		t, ok := strikeTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown strike type %q", name)
+		}
+		switch strings.ToLower(rawPolicy) {
+		case "long":
+			policies[t] = LongOnly
+		case "short":
+			policies[t] = ShortOnly
+		case "both":
+			policies[t] = DirectionBoth
+		default:
+			return nil, fmt.Errorf("invalid direction %q for %s (want long, short or both)", rawPolicy, name)
+		}
+	}
+	return policies, nil
+}
+
+// checkDirection holds a strike to its type's DirectionByType policy. The
+// generator picks the direction from the analysed levels; a policy that forbids it
+// skips the setup rather than flipping the strike, since the levels were built
+// for the analysed side.
+func (te *TradingEngine) checkDirection(strike *MacroStrike) error {
+	policy := te.DirectionByType[strike.StrikeType]
+	if !policy.allows(strike.Direction) {
+		return newSkip(SkipDirection, "%s %s setup is %s but the type trades %s only",
+			strike.Symbol, strike.StrikeType, strike.Direction, policy)
+	}
+	return nil
+}
+
+// checkShortsDisabled rejects short-only policies: the exchanges are only
+// traded spot, so a short has no way to open yet. A "both" type is accepted,
+// like an unlisted one; executeStrike clamps it to its long setups until
+// shorts can run.
+func checkShortsDisabled(policies map[StrikeType]DirectionPolicy) []string {
+	var problems []string
+	for t, p := range policies {
+		if p == ShortOnly {
+			problems = append(problems, fmt.Sprintf("DIRECTION_BY_TYPE %s=%s: short strikes cannot execute yet; use long or both", t, p))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDirectionByTypeGatesGeneratedStrikes(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("DIRECTION_BY_TYPE", "MacroFunding=short,MacroMomentum=long,MacroFlash=both")
+	// ValidateConfig refuses short-only policies until shorts execute; the
+	// generator gate still honors them
+	te := NewTradingEngine()
+
+	strikeOf := func(typ StrikeType, dir Direction) *MacroStrike {
+		s := certainStrike(1, true)
+		s.StrikeType, s.Direction = typ, dir
+		return s
+	}
+	for _, tc := range []struct {
+		typ     StrikeType
+		dir     Direction
+		skipped bool
+	}{
+		{MacroFunding, Long, true},
+		{MacroFunding, Short, false},
+		{MacroMomentum, Long, false},
+		{MacroMomentum, Short, true},
+		// "both" and unlisted types keep the analysis-driven direction
+		{MacroFlash, Long, false},
+		{MacroFlash, Short, false},
+		{MacroArbitrage, Short, false},
+	} {
+		te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: strikeOf(tc.typ, tc.dir)}}}
+		strike, err := te.GenerateStrike(context.Background())
+		var se *skipError
+		if tc.skipped {
+			if !errors.As(err, &se) || se.Reason != SkipDirection {
+				t.Errorf("%s %s: err = %v, want a direction skip", tc.typ, tc.dir, err)
+			}
+			continue
+		}
+		if err != nil || strike.Direction != tc.dir {
+			t.Errorf("%s %s: strike %+v err %v, want it generated unchanged", tc.typ, tc.dir, strike, err)
+		}
+	}
+}
+
+func TestParseDirectionByTypeRejectsBadEntries(t *testing.T) {
+	for _, raw := range []string{"MacroFunding=sideways", "MacroNope=long", "MacroFunding"} {
+		if _, err := parseDirectionByType(raw); err == nil {
+			t.Errorf("parseDirectionByType(%q) accepted", raw)
+		}
+	}
+}
+
+func TestShortPoliciesRejectedUntilShortsExecute(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("DIRECTION_BY_TYPE", "MacroFunding=short")
+	if err := NewTradingEngine().ValidateConfig(); err == nil || !strings.Contains(err.Error(), "short strikes cannot execute") {
+		t.Errorf("short-only policy: ValidateConfig = %v, want a short-strike rejection", err)
+	}
+	t.Setenv("DIRECTION_BY_TYPE", "MacroFunding=long,MacroMomentum=long,MacroFlash=both")
+	if err := NewTradingEngine().ValidateConfig(); err != nil {
+		t.Errorf("long and both policies: ValidateConfig = %v", err)
+	}
+}
+
+func TestBothPolicyClampsToLongAtExecution(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("DIRECTION_BY_TYPE", "MacroFlash=both")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig = %v", err)
+	}
+	for _, dir := range []Direction{Long, Short} {
+		s := certainStrike(1, true)
+		s.StrikeType, s.Direction = MacroFlash, dir
+		te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: s}}}
+		strike, err := te.GenerateStrike(context.Background())
+		if err != nil {
+			t.Fatalf("%s: GenerateStrike = %v, want the analysis direction kept", dir, err)
+		}
+		_, err = te.ExecuteStrike(context.Background(), strike)
+		var se *skipError
+		switch dir {
+		case Long:
+			if err != nil || strike.PnL == nil {
+				t.Errorf("long: ExecuteStrike = %v, want it traded", err)
+			}
+		case Short:
+			if !errors.As(err, &se) || se.Reason != SkipDirection {
+				t.Errorf("short: ExecuteStrike = %v, want a direction skip", err)
+			}
+		}
+	}
+}
+
+func TestExecuteStrikeSkipsShorts(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	capital := te.Capital
+	strike := certainStrike(1, true)
+	strike.Direction = Short
+	_, err := te.ExecuteStrike(context.Background(), strike)
+	var se *skipError
+	if !errors.As(err, &se) || se.Reason != SkipDirection {
+		t.Fatalf("ExecuteStrike(short) err = %v, want a direction skip", err)
+	}
+	if te.Capital != capital || strike.PnL != nil {
+		t.Errorf("short strike touched capital %d -> %d or booked pnl %v", capital, te.Capital, strike.PnL)
+	}
+}
+
+func TestGeneratedStrikesCarryTheirDirection(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	strike, err := te.generateStrike(context.Background())
+	if err != nil {
+		t.Fatalf("generateStrike: %v", err)
+	}
+	if strike.Direction != Long || strikeSide(strike) != "long" {
+		t.Errorf("sim strike direction %s side %q, want long", strike.Direction, strikeSide(strike))
+	}
+	if got := levelDirection(100, 97); got != Short {
+		t.Errorf("levelDirection(100, 97) = %s, want short", got)
+	}
+	strike.Direction = Short
+	if strikeSide(strike) != "short" || Short.entrySide() != "sell" || Short.exitSide() != "buy" {
+		t.Errorf("short strike side %q opens %s closes %s, want short/sell/buy", strikeSide(strike), Short.entrySide(), Short.exitSide())
+	}
+	if Long.entrySide() != "buy" || Long.exitSide() != "sell" {
+		t.Errorf("long opens %s closes %s, want buy/sell", Long.entrySide(), Long.exitSide())
+	}
+}
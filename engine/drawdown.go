@@ -0,0 +1,31 @@
+package engine
+
+// DrawdownState is the drawdown history measured against PeakCapital. It is
+// persisted with the engine state so a resumed campaign keeps its baseline.
+type DrawdownState struct {
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"` // deepest drawdown seen, percent of the peak at the time
+	TroughCapital  int64   `json:"trough_capital"`   // capital in cents at that deepest point
+	Streak         int64   `json:"streak"`           // consecutive trades closed below the peak
+}
+
+// updateDrawdown folds the capital after a trade into the drawdown history
+func (te *TradingEngine) updateDrawdown(capital, peak int64) {
+	te.drawdownMu.Lock()
+	defer te.drawdownMu.Unlock()
+	if capital >= peak || peak <= 0 {
+		te.drawdown.Streak = 0
+		return
+	}
+	te.drawdown.Streak++
+	if dd := float64(peak-capital) / float64(peak) * 100.0; dd > te.drawdown.MaxDrawdownPct {
+		te.drawdown.MaxDrawdownPct = dd
+		te.drawdown.TroughCapital = capital
+	}
+}
+
+// Drawdown returns a copy of the drawdown history
+func (te *TradingEngine) Drawdown() DrawdownState {
+	te.drawdownMu.Lock()
+	defer te.drawdownMu.Unlock()
+	return te.drawdown
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"macro-strike-bot/report"
+)
+
+func TestDrawdownBaselineSurvivesResume(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("STATE_FILE", filepath.Join(t.TempDir(), "state.json"))
+
+	// Peak at $120k, then a slide to $102k: 15% under the peak
+	te := NewTradingEngine()
+	te.Capital, te.PeakCapital = 10_000_000, 10_000_000
+	te.applyPnL(2_000_000)
+	te.applyPnL(-1_000_000)
+	te.applyPnL(-800_000)
+	dd := te.Drawdown()
+	if dd.Streak != 2 || dd.TroughCapital != 10_200_000 || math.Abs(dd.MaxDrawdownPct-15) > 1e-9 {
+		t.Fatalf("drawdown = %+v, want streak 2, trough $102k, 15%%", dd)
+	}
+	if err := te.SaveState(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("RESUME", "1")
+	resumed := NewTradingEngine()
+	if err := resumed.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt64(&resumed.PeakCapital); got != 12_000_000 {
+		t.Fatalf("peak after resume = %d, want the pre-restart 12000000", got)
+	}
+	if resumed.Drawdown() != dd {
+		t.Errorf("drawdown after resume = %+v, want %+v", resumed.Drawdown(), dd)
+	}
+	// 15% under the restored peak exceeds the 10% default limit straight away
+	if !resumed.CheckEmergencyStops() {
+		t.Fatal("CheckEmergencyStops should fire immediately after resuming in a deep drawdown")
+	}
+
+	result := resumed.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopEmergency {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopEmergency)
+	}
+	if result.TradesCompleted != 0 {
+		t.Errorf("traded %d times after resuming past the drawdown limit", result.TradesCompleted)
+	}
+	if math.Abs(result.MaxDrawdownPct-15) > 1e-9 {
+		t.Errorf("result max drawdown = %.4f%%, want the pre-restart 15%%", result.MaxDrawdownPct)
+	}
+}
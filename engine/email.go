@@ -0,0 +1,276 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+// SMTP transport security
+const (
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+	SMTPTLSNone     = "none"
+)
+
+// Email delivery tuning: one send may take emailSendTimeout, but Close only
+// waits emailDrainTimeout for it so a dead mail server never holds up exit
+const (
+	emailSendTimeout  = 20 * time.Second
+	emailDrainTimeout = 5 * time.Second
+	emailQueueSize    = 4
+)
+
+// emailAttachment is a file attached to an email
+type emailAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// emailMessage is one queued email
+type emailMessage struct {
+	Subject     string
+	Body        string
+	Attachments []emailAttachment
+}
+
+// EmailNotifier mails campaign summaries over SMTP from a background worker.
+// Sending is best effort: failures are logged and never retried.
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	TLS      string
+	Username string
+	password string
+	From     string
+	To       []string
+
+	queue  chan emailMessage
+	done   chan struct{}
+	mu     sync.Mutex
+	closed bool
+
+	// now dates messages; the engine points it at its Clock
+	now func() time.Time
+}
+
+// NewEmailNotifierFromConfig returns nil when SMTP_HOST is unset, which
+// leaves email disabled. SMTP_TO takes a comma-separated recipient list;
+// SMTP_TLS is starttls (the default), tls or none.
+func NewEmailNotifierFromConfig(cfg config.Config) (*EmailNotifier, error) {
+	host := cfg.Get("SMTP_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	mode := strings.ToLower(cfg.Get("SMTP_TLS"))
+	if mode == "" {
+		mode = SMTPTLSStartTLS
+	}
+	port := 587
+	switch mode {
+	case SMTPTLSStartTLS, SMTPTLSNone:
+	case SMTPTLSImplicit:
+		port = 465
+	default:
+		return nil, fmt.Errorf("SMTP_TLS: %q is not starttls, tls or none", mode)
+	}
+	if v := cfg.Get("SMTP_PORT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 65535 {
+			return nil, fmt.Errorf("SMTP_PORT: %q is not a port", v)
+		}
+		port = n
+	}
+	var to []string
+	for _, addr := range strings.Split(cfg.Get("SMTP_TO"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("SMTP_HOST is set but SMTP_TO lists no recipients")
+	}
+	from := cfg.First("SMTP_FROM", "SMTP_USERNAME")
+	if from == "" {
+		return nil, fmt.Errorf("SMTP_HOST is set but neither SMTP_FROM nor SMTP_USERNAME is")
+	}
+	n := &EmailNotifier{
+		Host:     host,
+		Port:     port,
+		TLS:      mode,
+		Username: cfg.Get("SMTP_USERNAME"),
+		password: cfg.Get("SMTP_PASSWORD"),
+		From:     from,
+		To:       to,
+		queue:    make(chan emailMessage, emailQueueSize),
+		done:     make(chan struct{}),
+		now:      time.Now,
+	}
+	go n.run()
+	return n, nil
+}
+
+// Send queues an email without blocking; it is dropped when the queue is full
+func (n *EmailNotifier) Send(m emailMessage) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.queue <- m:
+	default:
+		log.Printf("⚠️ Email %q dropped: queue full", m.Subject)
+	}
+}
+
+// Close stops accepting email and waits up to timeout for queued ones
+func (n *EmailNotifier) Close(timeout time.Duration) bool {
+	if n == nil {
+		return true
+	}
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	select {
+	case <-n.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (n *EmailNotifier) run() {
+	defer close(n.done)
+	for m := range n.queue {
+		if err := n.send(m); err != nil {
+			log.Printf("⚠️ Email %q not sent: %v", m.Subject, err)
+			continue
+		}
+		log.Printf("📧 Email %q sent to %s", m.Subject, strings.Join(n.To, ", "))
+	}
+}
+
+// send delivers one message, bounded by emailSendTimeout end to end
+func (n *EmailNotifier) send(m emailMessage) error {
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+	dialer := &net.Dialer{Timeout: emailSendTimeout}
+	var conn net.Conn
+	var err error
+	if n.TLS == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: n.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(emailSendTimeout))
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if n.TLS == SMTPTLSStartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return fmt.Errorf("starttls: %v", err)
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.password, n.Host)); err != nil {
+			return fmt.Errorf("auth: %v", err)
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.compose(m)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// compose renders m as a multipart/mixed message: a text body, then each
+// attachment base64-encoded
+func (n *EmailNotifier) compose(m emailMessage) []byte {
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n",
+		n.From, strings.Join(n.To, ", "), m.Subject, n.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	part, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	part.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n")))
+	for _, a := range m.Attachments {
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", a.Name)},
+		})
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			fmt.Fprintf(part, "%s\r\n", enc[:76])
+			enc = enc[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", enc)
+	}
+	mw.Close()
+	return b.Bytes()
+}
+
+// emailCampaignReport mails the headline result with the JSON and HTML
+// reports attached. A kill switch or emergency stop ends the campaign too,
+// so it is covered here with the stop reason in the subject.
+func (te *TradingEngine) emailCampaignReport(result *report.CampaignResult) {
+	if te.email == nil {
+		return
+	}
+	doc := te.BuildReport(result)
+	var body bytes.Buffer
+	doc.WriteSummary(&body)
+	msg := emailMessage{
+		Subject: fmt.Sprintf("[macro-strike-bot] %s: %s %+.2f%%", result.RunID, result.StopReason, result.ReturnPct),
+		Body:    body.String(),
+	}
+	if data, err := json.MarshalIndent(doc, "", "  "); err == nil {
+		msg.Attachments = append(msg.Attachments, emailAttachment{Name: "campaign_report.json", ContentType: "application/json", Data: []byte(doc.Redactor.Redact(string(data)))})
+	}
+	var html bytes.Buffer
+	if err := doc.RenderHTML(&html); err == nil {
+		msg.Attachments = append(msg.Attachments, emailAttachment{Name: "campaign_report.html", ContentType: "text/html; charset=utf-8", Data: html.Bytes()})
+	}
+	te.email.Send(msg)
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+// fakeSMTP accepts one plain-text SMTP session and hands back its DATA
+func fakeSMTP(t *testing.T) (host string, port int, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 fake")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				out <- b.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func TestCampaignEndEmailsReportWithoutPassword(t *testing.T) {
+	host, port, data := fakeSMTP(t)
+	te := NewTradingEngineFromConfig(config.Config{
+		"SIM_MODE":      "1",
+		"SMTP_HOST":     host,
+		"SMTP_PORT":     strconv.Itoa(port),
+		"SMTP_TLS":      "none",
+		"SMTP_FROM":     "bot@example.com",
+		"SMTP_TO":       "me@example.com, ops@example.com",
+		"SMTP_PASSWORD": "hunter2",
+	})
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: certainStrike(1, true)}}}
+	result := te.ExecuteCampaign(context.Background())
+
+	var msg string
+	select {
+	case msg = <-data:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email sent at campaign end")
+	}
+	for _, want := range []string{
+		"Subject: [macro-strike-bot] " + result.RunID + ": " + report.StopGeneratorExhausted,
+		"To: me@example.com, ops@example.com",
+		`filename="campaign_report.json"`,
+		`filename="campaign_report.html"`,
+		"Trades 1: 1 wins",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("email missing %q", want)
+		}
+	}
+	if strings.Contains(msg, "hunter2") || strings.Contains(msg, "aHVudGVyMg") {
+		t.Error("email leaks the SMTP password")
+	}
+	if snap, _ := json.Marshal(te.configSnapshot()); strings.Contains(string(snap), "hunter2") {
+		t.Error("config snapshot leaks the SMTP password")
+	}
+	if !te.email.Close(time.Second) {
+		t.Error("email worker did not drain")
+	}
+}
+
+func TestEmailConfigValidation(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  config.Config
+		want string
+	}{
+		{config.Config{"SMTP_HOST": "mail", "SMTP_FROM": "a@b"}, "no recipients"},
+		{config.Config{"SMTP_HOST": "mail", "SMTP_TO": "a@b"}, "SMTP_FROM"},
+		{config.Config{"SMTP_HOST": "mail", "SMTP_TO": "a@b", "SMTP_FROM": "a@b", "SMTP_TLS": "ssl"}, "SMTP_TLS"},
+		{config.Config{"SMTP_HOST": "mail", "SMTP_TO": "a@b", "SMTP_FROM": "a@b", "SMTP_PORT": "99999"}, "SMTP_PORT"},
+	} {
+		if _, err := NewEmailNotifierFromConfig(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: err = %v, want one mentioning %s", tc.cfg, err, tc.want)
+		}
+	}
+	n, err := NewEmailNotifierFromConfig(config.Config{"SMTP_HOST": "mail", "SMTP_USERNAME": "bot@b", "SMTP_TO": "a@b", "SMTP_TLS": "tls"})
+	if err != nil || n.Port != 465 || n.From != "bot@b" {
+		t.Errorf("implicit TLS notifier = %+v, %v; want port 465 sending as SMTP_USERNAME", n, err)
+	}
+	n.Close(time.Second)
+}
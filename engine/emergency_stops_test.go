@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"macro-strike-bot/config"
+)
+
+func TestCheckEmergencyStopsThresholds(t *testing.T) {
+	cases := []struct {
+		name           string
+		capital, peak  int64
+		maxDrawdownPct float64
+		misses         int64
+		want           string // reason prefix; "" means no stop
+	}{
+		// Hard 15% stop: strictly under 85% of peak
+		{"hard at exactly 85%", 85_000, 100_000, 0, 0, ""},
+		{"hard a cent under 85%", 84_999, 100_000, 0, 0, "Capital dropped 15%"},
+		// peak·85/100 truncates, so a drawdown a hair over 15% still passes
+		{"hard threshold truncates", 85_000, 100_001, 0, 0, ""},
+		{"hard below truncated threshold", 84_999, 100_001, 0, 0, "Capital dropped 15%"},
+		{"no peak yet", 0, 0, 0, 0, ""},
+
+		// Configured drawdown, tighter than 15%
+		{"configured at exactly 10%", 90_000, 100_000, 10, 0, ""},
+		{"configured a cent past 10%", 89_999, 100_000, 10, 0, "Configured drawdown hit: 10.00%"},
+		{"configured at exactly 5%", 95_000, 100_000, 5, 0, ""},
+		{"configured a cent past 5%", 94_999, 100_000, 5, 0, "Configured drawdown hit: 5.00%"},
+
+		// Both set: the hard stop is checked first and caps a looser limit
+		{"looser configured limit not reached", 85_000, 100_000, 20, 0, ""},
+		{"hard stop preempts looser limit", 84_999, 100_000, 20, 0, "Capital dropped 15%"},
+		{"hard stop wins when both trip", 80_000, 100_000, 10, 0, "Capital dropped 15%"},
+
+		// Consecutive misses, against MaxConsecutiveMisses = 20
+		{"one miss short", 100_000, 100_000, 0, MaxConsecutiveMisses - 1, ""},
+		{"at the miss limit", 100_000, 100_000, 0, MaxConsecutiveMisses, "Too many consecutive misses: 20"},
+		{"drawdown checked before misses", 89_000, 100_000, 10, MaxConsecutiveMisses, "Configured drawdown hit"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+			te.Capital, te.PeakCapital = tc.capital, tc.peak
+			te.MaxDrawdownPct = tc.maxDrawdownPct
+			te.ConsecutiveMisses = tc.misses
+			sub, cancel := te.events.Subscribe("test", 4)
+			defer cancel()
+
+			if got := te.CheckEmergencyStops(); got != (tc.want != "") {
+				t.Fatalf("CheckEmergencyStops() = %v, want %v", got, tc.want != "")
+			}
+			if tc.want == "" {
+				return
+			}
+			select {
+			case ev := <-sub.ch:
+				if reason, _ := ev.Data["reason"].(string); ev.Type != EventEmergencyStop || !strings.HasPrefix(reason, tc.want) {
+					t.Errorf("stop event %s %q, want reason %q", ev.Type, reason, tc.want)
+				}
+			default:
+				t.Error("no emergency stop event published")
+			}
+		})
+	}
+}
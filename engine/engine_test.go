@@ -0,0 +1,521 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/report"
+)
+
+func TestLosingStreakBlowsUpAndStopsCampaign(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Capital = 100000 // $1,000
+	te.PeakCapital = te.Capital
+
+	// A leveraged losing streak larger than the account
+	for i := 0; i < 5; i++ {
+		te.applyPnL(-30000)
+	}
+	if got := atomic.LoadInt64(&te.Capital); got != 0 {
+		t.Fatalf("capital = %d, want floored at 0", got)
+	}
+	if !te.BlownUp() {
+		t.Fatal("engine should be marked blown up")
+	}
+	if got := atomic.LoadInt64(&te.TotalPnL); got != -100000 {
+		t.Errorf("total PnL = %d, want -100000 (only existing capital lost)", got)
+	}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopBankrupt {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopBankrupt)
+	}
+	if result.TradesCompleted != 0 {
+		t.Errorf("trades completed = %d, want 0 after bankruptcy", result.TradesCompleted)
+	}
+}
+
+func TestCampaignWindowUsesInjectedClock(t *testing.T) {
+	// The window only applies outside SIM_MODE; the script keeps julia out of it
+	t.Setenv("SIM_MODE", "")
+	te := NewTradingEngine()
+	clock := clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Clock = clock
+	te.CampaignDays = 5
+	skips := &scriptedStrikeGenerator{}
+	for i := 0; i < 100; i++ {
+		skips.steps = append(skips.steps, scriptedStrike{Err: newSkip(SkipLowConfidence, "scripted skip")})
+	}
+	te.Generator = skips
+
+	// Already past the window: the campaign must stop before generating anything
+	te.CampaignStart = clock.Now().Add(-5*24*time.Hour - time.Second)
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopCampaignWindow {
+		t.Fatalf("stop reason = %q, want %q", result.StopReason, report.StopCampaignWindow)
+	}
+
+	// 10ms left: skipped setups sleep on the fake clock until the window closes
+	te.CampaignStart = clock.Now().Add(-5*24*time.Hour + 10*time.Millisecond)
+	before := clock.Now()
+	result = te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopCampaignWindow {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopCampaignWindow)
+	}
+	if advanced := clock.Since(before); advanced < 10*time.Millisecond {
+		t.Errorf("fake clock advanced %v, want at least 10ms", advanced)
+	}
+	if result.TradesCompleted != 0 {
+		t.Errorf("trades completed = %d, want 0", result.TradesCompleted)
+	}
+}
+
+func TestStrikeAndStateTimesUseInjectedClock(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	clock := clock.NewFake(time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC))
+	te.Clock = clock
+
+	var strike *MacroStrike
+	for i := 0; strike == nil && i < 100; i++ {
+		strike, _ = te.GenerateStrike(context.Background())
+	}
+	if strike == nil {
+		t.Fatal("no sim strike generated")
+	}
+	if strike.Timestamp != clock.Now().Unix() {
+		t.Errorf("strike timestamp = %d, want fake clock %d", strike.Timestamp, clock.Now().Unix())
+	}
+	if st := te.captureState(); !st.SavedAt.Equal(clock.Now()) {
+		t.Errorf("state saved at %v, want fake clock %v", st.SavedAt, clock.Now())
+	}
+}
+
+func TestSimMinHoldScalesByStrikeType(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.SimMinHoldMs = 1000
+
+	for _, tc := range []struct {
+		strikeType StrikeType
+		wantMs     int64
+	}{
+		{MacroFlash, 250},
+		{MacroMomentum, 1000},
+		{MacroFunding, 3000},
+	} {
+		strike := &MacroStrike{ID: 1, Symbol: "WETH/USDC", StrikeType: tc.strikeType, EntryPrice: 3000, Confidence: 0.9}
+		if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+			t.Fatalf("ExecuteStrike: %v", err)
+		}
+		if strike.DurationMs != tc.wantMs {
+			t.Errorf("%s held %dms, want %dms", tc.strikeType, strike.DurationMs, tc.wantMs)
+		}
+	}
+}
+
+func TestStablecoinStrikesUseBasisPointLevels(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+
+	// Strike 6: USDC/USDT with a MacroArbitrage strike
+	te.NextStrikeID = 5
+	strike, err := te.GenerateStrike(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateStrike: %v", err)
+	}
+	if strike.Symbol != "USDC/USDT" || strike.StrikeType != MacroArbitrage {
+		t.Fatalf("got %s %s, want USDC/USDT MacroArbitrage", strike.Symbol, strike.StrikeType)
+	}
+	if got := (strike.TargetPrice - strike.EntryPrice) / strike.EntryPrice; got > 0.002 {
+		t.Errorf("stablecoin target is %.4f%% away, want basis points", got*100)
+	}
+	if got := (strike.EntryPrice - strike.StopLoss) / strike.EntryPrice; got > 0.002 {
+		t.Errorf("stablecoin stop is %.4f%% away, want basis points", got*100)
+	}
+
+	// Strike 38: USDC/USDT with a MacroVolatility strike is skipped
+	te.NextStrikeID = 37
+	if _, err := te.GenerateStrike(context.Background()); err == nil {
+		t.Fatal("volatility strike on a stablecoin pair should be skipped")
+	} else if se, ok := err.(*skipError); !ok || se.Reason != SkipStablecoinType {
+		t.Fatalf("err = %v, want a %s skip", err, SkipStablecoinType)
+	}
+}
+
+func TestKrakenPairOverridesTakePrecedence(t *testing.T) {
+	t.Setenv("KRAKEN_PAIR_OVERRIDES", "WBTC/USDC=XXBTZUSD, LINK/USDC = LINKUSDT")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	for symbol, want := range map[string]string{"WBTC/USDC": "XXBTZUSD", "LINK/USDC": "LINKUSDT", "WETH/USDC": "ETHUSD"} {
+		if got := te.krakenPair(symbol); got != want {
+			t.Errorf("krakenPair(%s) = %s, want %s", symbol, got, want)
+		}
+	}
+
+	t.Setenv("KRAKEN_PAIR_OVERRIDES", "WBTC/USD=XBTUSD")
+	if err := NewTradingEngine().ValidateConfig(); err == nil {
+		t.Error("override for an unknown symbol should fail validation")
+	}
+}
+
+func TestFillPollingConfig(t *testing.T) {
+	te := NewTradingEngine()
+	if te.FillPollIntervalMs != 2000 || te.FillTimeoutMs != 30000 {
+		t.Errorf("defaults = %d/%d ms, want 2000/30000", te.FillPollIntervalMs, te.FillTimeoutMs)
+	}
+
+	t.Setenv("FILL_POLL_INTERVAL_MS", "250")
+	t.Setenv("FILL_TIMEOUT_MS", "5000")
+	te = NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if te.FillPollIntervalMs != 250 || te.FillTimeoutMs != 5000 {
+		t.Errorf("got %d/%d ms, want 250/5000", te.FillPollIntervalMs, te.FillTimeoutMs)
+	}
+
+	t.Setenv("FILL_TIMEOUT_MS", "100")
+	if err := NewTradingEngine().ValidateConfig(); err == nil {
+		t.Error("poll interval longer than the timeout should fail validation")
+	}
+}
+
+func TestCapitalFloorStopsCampaign(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("MIN_TRADING_CAPITAL", "50")
+	te := NewTradingEngine()
+	if te.MinTradingCapital != 5000 {
+		t.Fatalf("MinTradingCapital = %d cents, want 5000", te.MinTradingCapital)
+	}
+	te.Capital = 4999 // $49.99: depleted but not blown up
+	te.PeakCapital = te.Capital
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopCapitalFloor {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopCapitalFloor)
+	}
+	if result.TradesCompleted != 0 || te.BlownUp() {
+		t.Errorf("trades = %d, blown up = %v; want no trades and not blown up", result.TradesCompleted, te.BlownUp())
+	}
+}
+
+func TestInfiniteCampaignIgnoresTradeCount(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("INFINITE", "1")
+	te := NewTradingEngine()
+	if !te.InfiniteTrades {
+		t.Fatal("INFINITE=1 did not enable InfiniteTrades")
+	}
+	// Already at the bounded campaign's limit: only a stop condition ends it
+	te.TradesCompleted = TotalTrades
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, true)},
+	}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopGeneratorExhausted {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopGeneratorExhausted)
+	}
+	if result.TradesCompleted != TotalTrades+2 {
+		t.Errorf("trades completed = %d, want %d", result.TradesCompleted, TotalTrades+2)
+	}
+}
+
+func TestMinRiskRewardSkipsWideStops(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("MIN_RISK_REWARD", "1.5")
+	te := NewTradingEngine()
+	levels := []struct{ target, stop float64 }{
+		{103, 98},  // 3:2 passes
+		{102, 98},  // 1:1 skipped
+		{105, 101}, // stop above entry: invalid, skipped
+	}
+	gen := &scriptedStrikeGenerator{}
+	for i, l := range levels {
+		gen.steps = append(gen.steps, scriptedStrike{Strike: &MacroStrike{
+			ID: uint64(i + 1), Symbol: "WETH/USDC", EntryPrice: 100, TargetPrice: l.target, StopLoss: l.stop, Confidence: 0.9,
+		}})
+	}
+	te.Generator = gen
+
+	strike, err := te.GenerateStrike(context.Background())
+	if err != nil {
+		t.Fatalf("1.5 R:R strike skipped: %v", err)
+	}
+	if strike.RiskReward != 1.5 {
+		t.Errorf("RiskReward = %v, want 1.5", strike.RiskReward)
+	}
+	for range levels[1:] {
+		if _, err := te.GenerateStrike(context.Background()); err == nil {
+			t.Error("strike below MinRiskReward was not skipped")
+		} else if se, ok := err.(*skipError); !ok || se.Reason != SkipRiskReward {
+			t.Errorf("err = %v, want a %s skip", err, SkipRiskReward)
+		}
+	}
+}
+
+func TestVolatilityBandSkipsDeadAndChaoticMarkets(t *testing.T) {
+	t.Setenv("MIN_VOLATILITY", "0.01")
+	t.Setenv("MAX_VOLATILITY", "0.08")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	for _, tc := range []struct {
+		vol  float64
+		skip bool
+	}{
+		{0.005, true},
+		{0.01, false},
+		{0.05, false},
+		{0.08, false},
+		{0.12, true},
+	} {
+		err := te.checkVolatility("WETH/USDC", tc.vol)
+		if !tc.skip {
+			if err != nil {
+				t.Errorf("volatility %.3f skipped: %v", tc.vol, err)
+			}
+			continue
+		}
+		se, ok := err.(*skipError)
+		if !ok || se.Reason != SkipVolatility {
+			t.Errorf("volatility %.3f: err = %v, want a %s skip", tc.vol, err, SkipVolatility)
+		} else if !strings.Contains(se.Detail, fmt.Sprintf("%.4f", tc.vol)) {
+			t.Errorf("skip detail %q does not report the observed volatility", se.Detail)
+		}
+	}
+
+	t.Setenv("MIN_VOLATILITY", "0.1")
+	if err := NewTradingEngine().ValidateConfig(); err == nil {
+		t.Error("a band with min above max should be rejected")
+	}
+}
+
+// replayEngine returns a live engine whose Kraken traffic is served, per
+// endpoint path in order, from the given records
+func replayEngine(t *testing.T, records ...krakenExchangeRecord) *TradingEngine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "replay.jsonl")
+	var buf bytes.Buffer
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := newKrakenReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	te := NewTradingEngine()
+	te.LiveTrading = true
+	te.ReplayMode = true
+	te.krakenReplayer = rp
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	return te
+}
+
+// krakenReply is a replayed successful response
+func krakenReply(path, result string) krakenExchangeRecord {
+	return krakenExchangeRecord{Path: path, Response: json.RawMessage(`{"error":[],"result":` + result + `}`)}
+}
+
+func TestFlattenCancelsRestingExitBeforeSelling(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/QueryOrders", `{"EXIT1":{"status":"open","vol_exec":"0.4"}}`),
+		krakenReply("/0/private/CancelOrder", `{"count":1}`),
+		krakenReply("/0/private/QueryOrders", `{"EXIT1":{"status":"canceled","vol_exec":"0.45"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["FLAT1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"FLAT1":{"status":"closed","price":"3000"}}`),
+	)
+	pos := te.trackPosition(7, "ETHUSD", 1.0, "ENTRY1")
+	pos.ExitTx = "EXIT1"
+
+	te.flattenOpenPositions(context.Background(), "test")
+
+	if len(te.openPositions) != 0 {
+		t.Fatalf("position still tracked after flatten")
+	}
+	var sold string
+	for _, p := range te.takeOrderPayloads("FLAT1") {
+		if p.Path == "/0/private/AddOrder" {
+			sold = p.Request["volume"]
+		}
+	}
+	if sold != "0.55000000" {
+		t.Errorf("flatten sold %q, want only the 0.55 the cancelled exit left behind", sold)
+	}
+}
+
+func TestFlattenKeepsPositionWhenExitStatusUnknown(t *testing.T) {
+	down := krakenExchangeRecord{Path: "/0/private/QueryOrders", Error: "connection reset"}
+	te := replayEngine(t, down, down, down)
+	pos := te.trackPosition(7, "ETHUSD", 1.0, "ENTRY1")
+	pos.ExitTx = "EXIT1"
+
+	te.flattenOpenPositions(context.Background(), "test")
+
+	if _, ok := te.openPositions[7]; !ok {
+		t.Fatal("position released even though its exit could not be queried")
+	}
+	if got := te.takeOrderPayloads("EXIT1"); len(got) != 0 {
+		t.Errorf("unexpected order traffic: %+v", got)
+	}
+}
+
+func TestSimStrikesHonorPerSymbolConfidenceThreshold(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("SYMBOL_CONFIDENCE_THRESHOLDS", "WETH/USDC=0.9,USDC/USDT=0.96")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+
+	// Strike 8 is WETH/USDC: sim confidence spans 0.80-0.95, so across seeds
+	// some clear 0.9 and some don't
+	passed, skipped := 0, 0
+	for i := 0; i < 200; i++ {
+		te.NextStrikeID = 7
+		te.RandSeed = int64(i)
+		strike, err := te.GenerateStrike(context.Background())
+		if err != nil {
+			if se, ok := err.(*skipError); !ok || se.Reason != SkipLowConfidence {
+				t.Fatalf("err = %v, want a %s skip", err, SkipLowConfidence)
+			}
+			skipped++
+			continue
+		}
+		passed++
+		if strike.Symbol != "WETH/USDC" || strike.Confidence < 0.9 {
+			t.Fatalf("%s strike with confidence %.3f passed a 0.9 gate", strike.Symbol, strike.Confidence)
+		}
+	}
+	if passed == 0 || skipped == 0 {
+		t.Errorf("passed %d, skipped %d; want both", passed, skipped)
+	}
+
+	// Strike 6 is USDC/USDT: no sim confidence reaches 0.96
+	for i := 0; i < 50; i++ {
+		te.NextStrikeID = 5
+		te.RandSeed = int64(i)
+		if strike, err := te.GenerateStrike(context.Background()); err == nil {
+			t.Fatalf("USDC/USDT strike with confidence %.3f passed a 0.96 gate", strike.Confidence)
+		}
+	}
+}
+
+func TestDailyLossLimitPausesUntilNextDay(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	start := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+	// A $5,000 loss already booked today: 5% of the day's opening capital
+	losingDay := func() *TradingEngine {
+		te := NewTradingEngine()
+		te.Clock = clock.NewFake(start)
+		te.MaxDailyLossPct = 2
+		te.applyPnL(-500000)
+		te.pnlRollups.Record(start, -5000, false)
+		te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+			{Strike: certainStrike(1, true)},
+			{Strike: certainStrike(2, true)},
+			{Strike: certainStrike(3, true)},
+		}}
+		return te
+	}
+
+	te := losingDay()
+	if loss := te.dailyLossPct(start, atomic.LoadInt64(&te.Capital)); math.Abs(loss-5) > 1e-9 {
+		t.Fatalf("daily loss = %.4f%%, want 5%%", loss)
+	}
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopGeneratorExhausted || result.TradesCompleted != 3 {
+		t.Fatalf("stop %q after %d trades, want all 3 traded", result.StopReason, result.TradesCompleted)
+	}
+	// The first strike trips the limit; the rest wait for the next UTC day
+	for i, want := range []int{6, 7, 7} {
+		if day := time.Unix(*te.Strikes[i].HitTime, 0).UTC().Day(); day != want {
+			t.Errorf("strike %d closed on day %d, want %d", te.Strikes[i].ID, day, want)
+		}
+	}
+
+	te = losingDay()
+	te.DailyLossEndsCampaign = true
+	if result := te.ExecuteCampaign(context.Background()); result.StopReason != report.StopEmergency || result.TradesCompleted != 1 {
+		t.Errorf("stop %q after %d trades, want an emergency stop after the first trade", result.StopReason, result.TradesCompleted)
+	}
+}
+
+func TestLiveFeesPreferExchangeReportedFee(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.01","price":"2500","fee":"0.065"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		// No fee reported on the exit: that leg is modeled
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.01","price":"2510"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.Stop()
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	want := 0.065 + 2510*0.01*RoundTripFeePct/2
+	if math.Abs(strike.Fees-want) > 1e-9 {
+		t.Errorf("strike fees = %.6f, want %.6f", strike.Fees, want)
+	}
+	if got := te.Stats().TotalFeesPaid; got != math.Round(want*100)/100 {
+		t.Errorf("total fees paid = %.2f, want %.2f", got, want)
+	}
+}
+
+func TestModeFlagsFixedAtConstruction(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("PATH", t.TempDir()) // no julia: an analyzer-driven strike would fail
+	te := NewTradingEngine()
+
+	// Neither the environment nor the engine's config is consulted again
+	t.Setenv("SIM_MODE", "0")
+	te.config["SIM_MODE"] = "0"
+	if !te.SimMode {
+		t.Fatal("SimMode changed after construction")
+	}
+	if err := te.checkAnalyzerInstalled(); err != nil {
+		t.Fatalf("analyzer check after the env changed: %v", err)
+	}
+	generated := 0
+	for i := 0; i < 5; i++ {
+		_, err := te.GenerateStrike(context.Background())
+		if errors.Is(err, ErrAnalyzerMissing) {
+			t.Fatalf("strike %d went to the analyzer: %v", i+1, err)
+		}
+		if err == nil {
+			generated++
+		}
+	}
+	if generated == 0 {
+		t.Error("no simulated strikes generated")
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// eventLogBuffer is deep enough that a fast sim campaign rarely outruns the
+// log writer; anything it misses is counted under the "log" subscriber
+const eventLogBuffer = 4096
+
+// eventLogDrainTimeout bounds how long Close waits for queued log lines
+const eventLogDrainTimeout = 5 * time.Second
+
+// EventLogger writes the campaign's strike results, emergency stops and
+// final summary to the standard logger from the event bus, so the trading
+// loop only publishes
+type EventLogger struct {
+	sub     *eventSub
+	cancel  func()
+	done    chan struct{}
+	handled uint64
+	// logged is signalled after each event so Sync can wait without polling
+	logged chan struct{}
+	// after times Sync and Close; the engine points it at its Clock
+	after func(time.Duration) <-chan time.Time
+}
+
+// StartEventLogger subscribes to bus and logs its events until Close
+func StartEventLogger(bus *EventBus) *EventLogger {
+	l := &EventLogger{done: make(chan struct{}), logged: make(chan struct{}, 1), after: time.After}
+	l.sub, l.cancel = bus.Subscribe("log", eventLogBuffer)
+	go func() {
+		defer close(l.done)
+		for e := range l.sub.ch {
+			logEvent(e)
+			atomic.StoreUint64(&l.handled, e.Seq)
+			select {
+			case l.logged <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return l
+}
+
+// Sync waits up to timeout until every event already published has been
+// logged, for callers about to swap the logger's output
+func (l *EventLogger) Sync(timeout time.Duration) bool {
+	if l == nil {
+		return true
+	}
+	expired := l.after(timeout)
+	for atomic.LoadUint64(&l.handled) < atomic.LoadUint64(&l.sub.sent) {
+		select {
+		case <-l.logged:
+		case <-l.done:
+			return atomic.LoadUint64(&l.handled) >= atomic.LoadUint64(&l.sub.sent)
+		case <-expired:
+			return false
+		}
+	}
+	return true
+}
+
+// Close stops the subscription and waits up to timeout for the events
+// already queued to be logged
+func (l *EventLogger) Close(timeout time.Duration) bool {
+	if l == nil {
+		return true
+	}
+	l.cancel()
+	select {
+	case <-l.done:
+		return true
+	case <-l.after(timeout):
+		return false
+	}
+}
+
+// logEvent renders one event as the log lines the campaign has always printed
+func logEvent(e Event) {
+	switch e.Type {
+	case EventStrikeClosed:
+		status, _ := e.Data["status"].(string)
+		pnl, _ := e.Data["pnl"].(float64)
+		capital, _ := e.Data["capital"].(float64)
+		switch status {
+		case Hit.String():
+			log.Printf("✅ HIT: %s | PnL=$%.2f | Capital=$%.2f | Strike %d", e.Symbol, pnl, capital, e.StrikeID)
+		case Miss.String():
+			log.Printf("❌ MISS: %s | PnL=$%.2f | Capital=$%.2f | Strike %d", e.Symbol, pnl, capital, e.StrikeID)
+		default:
+			log.Printf("Error executing strike: %v", e.Data["error"])
+		}
+	case EventEmergencyStop:
+		log.Printf("🚨 EMERGENCY STOP: %v", e.Data["reason"])
+	case EventCampaignFinished:
+		res, ok := e.Data["result"].(*report.CampaignResult)
+		if !ok {
+			return
+		}
+		log.Printf("🏁 CAMPAIGN COMPLETE: %.1f%% return | Trades: %d/%v | Time: %.2fs",
+			res.ReturnPct, res.TradesCompleted, e.Data["trade_limit"], res.Elapsed.Seconds())
+		log.Printf("💸 Fees paid: $%.2f", res.TotalFees)
+		if levels, ok := e.Data["levels"].(map[string]LevelSourceStats); ok {
+			sources := make([]string, 0, len(levels))
+			for source := range levels {
+				sources = append(sources, source)
+			}
+			sort.Strings(sources)
+			for _, source := range sources {
+				st := levels[source]
+				log.Printf("Levels %-7s: %d strikes | hit rate %.1f%%", source, st.Strikes, st.HitRate*100.0)
+			}
+		}
+		log.Printf("Result: %d wins / %d losses / %d aborted | Max drawdown %.2f%% | Sharpe %.3f | Stop: %s",
+			res.Wins, res.Losses, res.Aborted, res.MaxDrawdownPct, res.Sharpe, res.StopReason)
+	}
+}
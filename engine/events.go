@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// eventSchemaVersion is bumped whenever an Event field changes meaning or
+// goes away; consumers should ignore fields they don't know
+const eventSchemaVersion = 1
+
+// EventType names a lifecycle event; its value is the type streamed at /events
+type EventType string
+
+// Event types published on the engine's event bus. The older wire names
+// (fill, exit, skip) are kept so /events consumers keep working.
+const (
+	EventStrikeGenerated  EventType = "strike_generated"
+	EventStrikeSkipped    EventType = "skip"
+	EventOrderPlaced      EventType = "order_placed"
+	EventOrderFilled      EventType = "fill"
+	EventStrikeClosed     EventType = "exit"
+	EventEmergencyStop    EventType = "emergency_stop"
+	EventCampaignFinished EventType = "campaign_finished"
+	EventHeartbeat        EventType = "heartbeat"
+)
+
+// Event stream tuning
+const eventStreamBuffer = 256
+
+// eventHeartbeatInterval is how often /events sends a heartbeat
+var eventHeartbeatInterval = 15 * time.Second
+
+// Event is one strike lifecycle event as streamed at /events
+type Event struct {
+	V        int                    `json:"v"`
+	Seq      uint64                 `json:"seq"`
+	Type     EventType              `json:"type"`
+	Time     time.Time              `json:"time"`
+	StrikeID uint64                 `json:"strike_id,omitempty"`
+	Symbol   string                 `json:"symbol,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// EventBus fans events out to subscribers without ever blocking the
+// publisher: a subscriber whose buffer is full misses the event, and the miss
+// is counted against the subscriber's name.
+type EventBus struct {
+	mu        sync.Mutex
+	subs      map[*eventSub]struct{}
+	seq       uint64
+	dropped   int64
+	droppedBy map[string]int64
+	closed    bool
+}
+
+type eventSub struct {
+	name    string
+	ch      chan Event
+	dropped int64
+	// seq of the last event handed to ch
+	sent uint64
+}
+
+// NewEventBus returns a bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*eventSub]struct{}), droppedBy: make(map[string]int64)}
+}
+
+// Publish stamps e with the schema version and next sequence number and
+// offers it to every subscriber
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.seq++
+	e.V, e.Seq = eventSchemaVersion, b.seq
+	for sub := range b.subs {
+		select {
+		case sub.ch <- e:
+			atomic.StoreUint64(&sub.sent, e.Seq)
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+			atomic.AddInt64(&b.dropped, 1)
+			b.droppedBy[sub.name]++
+		}
+	}
+}
+
+// Subscribe registers a subscriber with a buffer of size events; name labels
+// its drops. The channel is closed by cancel or when the bus closes.
+func (b *EventBus) Subscribe(name string, size int) (sub *eventSub, cancel func()) {
+	sub = &eventSub{name: name, ch: make(chan Event, size)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub, func() {}
+	}
+	b.subs[sub] = struct{}{}
+	return sub, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Dropped is the number of events subscribers have missed
+func (b *EventBus) Dropped() int64 {
+	if b == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&b.dropped)
+}
+
+// DroppedBy is the number of events missed per subscriber name
+func (b *EventBus) DroppedBy() map[string]int64 {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]int64, len(b.droppedBy))
+	for name, n := range b.droppedBy {
+		out[name] = n
+	}
+	return out
+}
+
+// Close ends every subscription; later events are discarded
+func (b *EventBus) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		close(sub.ch)
+	}
+	b.subs = nil
+}
+
+// publish sends a lifecycle event stamped with the engine clock
+func (te *TradingEngine) publish(typ EventType, strike *MacroStrike, data map[string]interface{}) {
+	e := Event{Type: typ, Time: te.Clock.Now().UTC(), Data: data}
+	if strike != nil {
+		e.StrikeID, e.Symbol = strike.ID, strike.Symbol
+	}
+	te.events.Publish(e)
+}
+
+// orderPlaced counts a placed order and publishes it
+func (te *TradingEngine) orderPlaced(strikeID uint64, pair, side, txid string) {
+	te.metrics.OrderPlaced(side)
+	te.events.Publish(Event{Type: EventOrderPlaced, Time: te.Clock.Now().UTC(), StrikeID: strikeID,
+		Data: map[string]interface{}{"pair": pair, "side": side, "txid": txid}})
+}
+
+// serveEvents streams bus events to one client as server-sent events, with a
+// heartbeat carrying capital so an idle stream still shows signs of life.
+// Asked for JSON, it returns the recent history instead.
+func (te *TradingEngine) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if wantsRecentEvents(r) {
+		te.serveRecentEvents(w, r)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok || te.events == nil {
+		http.Error(w, "event streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	sub, cancel := te.events.Subscribe("sse", eventStreamBuffer)
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var e Event
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			e = ev
+		case <-heartbeat.C:
+			e = Event{V: eventSchemaVersion, Type: EventHeartbeat, Time: te.Clock.Now().UTC(), Data: map[string]interface{}{
+				"capital":          Money(atomic.LoadInt64(&te.Capital)).Dollars(),
+				"trades_completed": atomic.LoadInt64(&te.TradesCompleted),
+				"dropped":          atomic.LoadInt64(&sub.dropped),
+			}}
+		}
+		body, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, body); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamCarriesStrikeLifecycle(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Err: newSkip(SkipLowConfidence, "scripted skip")},
+		{Strike: certainStrike(2, false)},
+	}}
+	srv := httptest.NewServer(te.statusHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	te.ExecuteCampaign(context.Background())
+	te.events.Close()
+
+	var types []string
+	var lastSeq uint64
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		if e.V != eventSchemaVersion || e.Seq <= lastSeq {
+			t.Errorf("event %+v: want schema v%d and seq after %d", e, eventSchemaVersion, lastSeq)
+		}
+		lastSeq = e.Seq
+		types = append(types, string(e.Type))
+	}
+	want := "strike_generated exit skip strike_generated exit campaign_finished"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestEventBusDropsForSlowSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	slow, cancel := bus.Subscribe("slow", 1)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: EventStrikeSkipped})
+	}
+	if got := (<-slow.ch).Seq; got != 1 {
+		t.Errorf("first event seq = %d, want 1", got)
+	}
+	if bus.Dropped() != 2 || slow.dropped != 2 {
+		t.Errorf("dropped bus=%d subscriber=%d, want 2", bus.Dropped(), slow.dropped)
+	}
+	if by := bus.DroppedBy(); by["slow"] != 2 {
+		t.Errorf("dropped by subscriber = %v, want slow: 2", by)
+	}
+	bus.Close()
+	if _, ok := <-slow.ch; ok {
+		t.Error("subscription still open after Close")
+	}
+}
+
+func TestEventLoggerWritesCampaignLines(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prev)
+
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, false)},
+	}}
+	te.ExecuteCampaign(context.Background())
+	te.emergencyStop("scripted stop")
+	if !te.eventLog.Close(5 * time.Second) {
+		t.Fatal("event log did not drain")
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"✅ HIT: WETH/USDC",
+		"❌ MISS: WETH/USDC",
+		"🏁 CAMPAIGN COMPLETE:",
+		"Result: 1 wins / 1 losses / 0 aborted",
+		"🚨 EMERGENCY STOP: scripted stop",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	if hit, done := strings.Index(out, "✅ HIT"), strings.Index(out, "🏁 CAMPAIGN COMPLETE"); hit > done {
+		t.Error("strike results logged after the campaign summary")
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"macro-strike-bot/exchange"
+	"macro-strike-bot/exchange/coinbase"
+)
+
+// tradeIDLister is implemented by exchanges that report the trades matched
+// against an order
+type tradeIDLister interface {
+	OrderTradeIDs(ctx context.Context, txid string) ([]string, error)
+}
+
+// exchange returns the venue live orders go to, Kraken unless configured otherwise
+func (te *TradingEngine) exchange() exchange.Exchange {
+	if te.Exchange != nil {
+		return te.Exchange
+	}
+	return KrakenExchange{te}
+}
+
+// KrakenExchange routes orders through the engine's Kraken client, which
+// carries the credentials, record/replay capture and latency tracking
+type KrakenExchange struct {
+	te *TradingEngine
+}
+
+func (k KrakenExchange) Name() string { return exchange.Kraken }
+
+func (k KrakenExchange) Pair(symbol string) string { return k.te.krakenPair(symbol) }
+
+func (k KrakenExchange) PlaceMarketOrder(ctx context.Context, pair, side string, usdSize, price float64) (string, error) {
+	return k.te.placeMarketOrder(ctx, pair, side, usdSize, price)
+}
+
+func (k KrakenExchange) PlaceMarketExit(ctx context.Context, pair string, volume float64) (string, error) {
+	return k.te.placeMarketExit(ctx, pair, volume)
+}
+
+func (k KrakenExchange) GetOrder(ctx context.Context, txid string) (exchange.OrderInfo, error) {
+	ord, err := k.te.getOrder(ctx, txid)
+	if err != nil {
+		return exchange.OrderInfo{}, err
+	}
+	result, ok := ord["result"].(map[string]interface{})
+	if !ok {
+		return exchange.OrderInfo{}, fmt.Errorf("unexpected kraken response")
+	}
+	info, ok := result[txid].(map[string]interface{})
+	if !ok {
+		return exchange.OrderInfo{}, fmt.Errorf("order %s not found", txid)
+	}
+	status, _ := info["status"].(string)
+	return exchange.OrderInfo{Status: status, VolExec: exchange.ParseNumericField(info["vol_exec"]), Price: exchange.ParseNumericField(info["price"]), Fee: exchange.ParseNumericField(info["fee"])}, nil
+}
+
+func (k KrakenExchange) CancelOrder(ctx context.Context, txid string) error {
+	return k.te.cancelOrder(ctx, txid)
+}
+
+func (k KrakenExchange) GetBalance(ctx context.Context) (map[string]float64, error) {
+	res, err := k.te.krakenPrivateWithRetry(ctx, "/0/private/Balance", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected kraken balance response")
+	}
+	balances := make(map[string]float64, len(result))
+	for asset, v := range result {
+		balances[asset] = exchange.ParseNumericField(v)
+	}
+	return balances, nil
+}
+
+func (k KrakenExchange) GetTicker(ctx context.Context, pair string) (float64, error) {
+	return k.te.krakenTicker(ctx, pair)
+}
+
+func (k KrakenExchange) OrderTradeIDs(ctx context.Context, txid string) ([]string, error) {
+	return k.te.orderTradeIDs(ctx, txid)
+}
+
+// newExchange builds the venue named by EXCHANGE
+func (te *TradingEngine) newExchange(name string) (exchange.Exchange, error) {
+	switch strings.ToLower(name) {
+	case "", exchange.Kraken:
+		return KrakenExchange{te}, nil
+	case exchange.Coinbase:
+		cb, err := coinbase.FromConfig(te.config, te.httpClient(), te.Clock)
+		if err != nil {
+			return nil, err
+		}
+		return cb, nil
+	default:
+		return nil, fmt.Errorf("EXCHANGE: %q is not kraken or coinbase", name)
+	}
+}
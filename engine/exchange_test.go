@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/exchange"
+)
+
+// fakeExchange is a venue other than Kraken: market orders fill at once, a
+// buy at buyPrice and a sell at sellPrice, and every order is recorded.
+// Request signing and the HTTP side live in each venue's own package tests.
+type fakeExchange struct {
+	name      string
+	pairs     map[string]string
+	buyPrice  float64
+	sellPrice float64
+
+	mu     sync.Mutex
+	orders []fakeOrder
+}
+
+type fakeOrder struct {
+	TxID, Pair, Side string
+	USD, Volume      float64
+}
+
+func (f *fakeExchange) Name() string { return f.name }
+
+func (f *fakeExchange) Pair(symbol string) string { return f.pairs[symbol] }
+
+func (f *fakeExchange) place(o fakeOrder) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o.TxID = fmt.Sprintf("%s-%d", strings.ToUpper(o.Side), len(f.orders)+1)
+	f.orders = append(f.orders, o)
+	return o.TxID
+}
+
+func (f *fakeExchange) PlaceMarketOrder(_ context.Context, pair, side string, usdSize, price float64) (string, error) {
+	return f.place(fakeOrder{Pair: pair, Side: side, USD: usdSize, Volume: usdSize / price}), nil
+}
+
+func (f *fakeExchange) PlaceMarketExit(_ context.Context, pair string, volume float64) (string, error) {
+	return f.place(fakeOrder{Pair: pair, Side: "sell", Volume: volume}), nil
+}
+
+func (f *fakeExchange) GetOrder(_ context.Context, txid string) (exchange.OrderInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, o := range f.orders {
+		if o.TxID != txid {
+			continue
+		}
+		price := f.buyPrice
+		if o.Side == "sell" {
+			price = f.sellPrice
+		}
+		return exchange.OrderInfo{Status: exchange.OrderClosed, VolExec: o.Volume, Price: price}, nil
+	}
+	return exchange.OrderInfo{}, fmt.Errorf("order %s not found", txid)
+}
+
+func (f *fakeExchange) CancelOrder(context.Context, string) error { return nil }
+
+func (f *fakeExchange) GetBalance(context.Context) (map[string]float64, error) {
+	return map[string]float64{"USD": 1000}, nil
+}
+
+func (f *fakeExchange) GetTicker(context.Context, string) (float64, error) { return f.buyPrice, nil }
+
+func TestLiveStrikeRoutesThroughConfiguredExchange(t *testing.T) {
+	te := NewTradingEngine()
+	te.LiveTrading = true
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.OrderUSDSize = 250
+	ex := &fakeExchange{name: exchange.Coinbase, pairs: map[string]string{"WETH/USDC": "ETH-USD"}, buyPrice: 2500, sellPrice: 2550}
+	te.Exchange = ex
+
+	strike := &MacroStrike{ID: 7, Symbol: "WETH/USDC", StrikeType: MacroArbitrage, EntryPrice: 2500, Confidence: 0.95}
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if len(ex.orders) != 2 {
+		t.Fatalf("placed %d orders, want a buy and a sell", len(ex.orders))
+	}
+	buy, sell := ex.orders[0], ex.orders[1]
+	if buy.Pair != "ETH-USD" || buy.Side != "buy" || buy.USD != 250 || sell.Side != "sell" || sell.Volume != 0.1 {
+		t.Errorf("orders = %+v", ex.orders)
+	}
+	if pnl != 5 || strike.Status != Hit || *strike.EntryTxID != buy.TxID || *strike.ExitTxID != sell.TxID {
+		t.Errorf("pnl %.2f status %s entry %s exit %s, want 5 hit %s %s", pnl, strike.Status, *strike.EntryTxID, *strike.ExitTxID, buy.TxID, sell.TxID)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions left open after a filled exit", len(te.openPositions))
+	}
+}
+
+func TestCoinbaseRejectsKrakenOnlyFeatures(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"LIVE_ENTRY_ORDER": "limit"})
+	te.Exchange = &fakeExchange{name: exchange.Coinbase}
+	if err := te.ValidateConfig(); err == nil || !strings.Contains(err.Error(), "limit entries") {
+		t.Errorf("ValidateConfig = %v, want limit entries rejected on coinbase", err)
+	}
+
+	// EXCHANGE=coinbase builds the Coinbase client, which wants its credentials
+	if err := NewTradingEngineFromConfig(config.Config{"EXCHANGE": "coinbase"}).ValidateConfig(); err == nil || !strings.Contains(err.Error(), "coinbase credentials not set") {
+		t.Errorf("ValidateConfig = %v, want the coinbase credentials required", err)
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"EXCHANGE": "binance"}).ValidateConfig(); err == nil || !strings.Contains(err.Error(), "EXCHANGE") {
+		t.Errorf("ValidateConfig = %v, want an unknown exchange rejected", err)
+	}
+}
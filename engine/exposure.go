@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+// defaultLiveHold bounds a live strike that carries no MaxExposureTimeMs
+const defaultLiveHold = 20 * time.Second
+
+// exposureLimit is how long a live strike may stay open before it is
+// force-exited at market
+func exposureLimit(strike *MacroStrike) time.Duration {
+	if strike.MaxExposureTimeMs == 0 {
+		return defaultLiveHold
+	}
+	return time.Duration(strike.MaxExposureTimeMs) * time.Millisecond
+}
+
+// liveMonitorHold watches the ticker through a live strike's hold and
+// returns why the position should exit: its target or stop was reached, or
+// deadline passed first (a time stop). The target and stop apply as moves
+// from the fill price, so slippage and the funding currency don't shift
+// them. A shutdown or cancelled ctx ends the watch early.
+func (te *TradingEngine) liveMonitorHold(ctx context.Context, strike *MacroStrike, pair string, buyPrice float64, deadline time.Time) string {
+	ex := te.exchange()
+	target, stop := holdLevels(strike, buyPrice)
+	pollInterval := te.holdPollInterval()
+	for !te.stopRequested() && ctx.Err() == nil {
+		te.beat()
+		left := deadline.Sub(te.Clock.Now())
+		if left <= 0 {
+			return ExitTimeStop
+		}
+		if price, err := ex.GetTicker(ctx, pair); err != nil {
+			te.debugf("strike %d: %s ticker unavailable during hold: %v", strike.ID, pair, err)
+		} else if target > 0 && price >= target {
+			return ExitTakeProfit
+		} else if stop > 0 && price <= stop {
+			return ExitStopLoss
+		}
+		if left > pollInterval {
+			left = pollInterval
+		}
+		clock.SleepContext(ctx, te.Clock, left)
+	}
+	return ExitHoldExpired
+}
+
+// holdLevels moves a strike's target and stop onto its fill price; either is
+// 0 when the strike doesn't set it
+func holdLevels(strike *MacroStrike, buyPrice float64) (target, stop float64) {
+	if strike.EntryPrice > 0 {
+		if strike.TargetPrice > strike.EntryPrice {
+			target = buyPrice * strike.TargetPrice / strike.EntryPrice
+		}
+		if strike.StopLoss > 0 && strike.StopLoss < strike.EntryPrice {
+			stop = buyPrice * strike.StopLoss / strike.EntryPrice
+		}
+	}
+	return target, stop
+}
+
+// holdPollInterval is how often a live hold checks the ticker
+func (te *TradingEngine) holdPollInterval() time.Duration {
+	if d := time.Duration(te.FillPollIntervalMs) * time.Millisecond; d > 0 {
+		return d
+	}
+	return stopPollInterval
+}
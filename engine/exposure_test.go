@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func exposureReplay(t *testing.T, tickers ...string) *TradingEngine {
+	t.Helper()
+	records := []krakenExchangeRecord{
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.04","price":"2450"}}`),
+	}
+	for _, price := range tickers {
+		records = append(records, krakenReply("/0/public/Ticker", `{"XETHZUSD":{"c":["`+price+`","1"]}}`))
+	}
+	te := replayEngine(t, records...)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 100
+	te.FillPollIntervalMs = 250
+	return te
+}
+
+func TestLiveStrikeTimeStopsAtMaxExposure(t *testing.T) {
+	// The ticker never reaches the 2512.50 target or 2450 stop
+	te := exposureReplay(t, "2501", "2499", "2505")
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	strike.TargetPrice = 2512.5
+	strike.StopLoss = 2450
+	strike.MaxExposureTimeMs = 5000
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if strike.ExitReason != ExitTimeStop {
+		t.Errorf("exit reason = %q, want %q", strike.ExitReason, ExitTimeStop)
+	}
+	if strike.Timings == nil || strike.Timings.HoldMs != 5000 {
+		t.Errorf("timings = %+v, want a 5000ms hold", strike.Timings)
+	}
+}
+
+func TestLiveStrikeExitsWhenStopReached(t *testing.T) {
+	te := exposureReplay(t, "2490", "2449")
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	strike.TargetPrice = 2512.5
+	strike.StopLoss = 2450
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if strike.ExitReason != ExitStopLoss || strike.Status != Miss {
+		t.Errorf("exit = %q %v, want a stop-loss miss", strike.ExitReason, strike.Status)
+	}
+	// One poll between the two ticker reads, well inside the 30s exposure limit
+	if strike.Timings == nil || strike.Timings.HoldMs != 250 {
+		t.Errorf("timings = %+v, want a 250ms hold", strike.Timings)
+	}
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// thinFill reports whether a live entry filled under MinFillRatio of the
+// requested volume
+func (te *TradingEngine) thinFill(filled, requested float64) bool {
+	return te.MinFillRatio > 0 && requested > 0 && filled/requested < te.MinFillRatio
+}
+
+// flattenThinFill backs out of an entry that filled too little to count as a
+// trade: what is left of the order is cancelled and whatever filled is sold
+// at market. The strike is aborted rather than counted, but the round trip's
+// PnL moves capital and its fees count toward the fees paid, as a completed
+// strike's would. It returns the error that aborts the strike; a failed sale
+// leaves the position tracked for the campaign-end flatten.
+func (te *TradingEngine) flattenThinFill(ctx context.Context, strike *MacroStrike, pair, txid string, filled, requested, entryFee, buyPrice, quoteRate float64, orderTxs *[]string) error {
+	ex := te.exchange()
+	if status, _, err := te.orderStatus(ctx, txid); err == nil && (status == "open" || status == "pending") {
+		if cerr := ex.CancelOrder(ctx, txid); cerr != nil {
+			log.Printf("⚠️ Cancel of thin entry %s for strike %d failed: %v", txid, strike.ID, cerr)
+		}
+		// Anything that filled before the cancel took effect is sold too
+		if _, volExec, err := te.orderStatus(ctx, txid); err == nil && volExec > filled {
+			filled = volExec
+		}
+	}
+	strike.EntryTxID = &txid
+	strike.ExitReason = ExitThinFill
+	pos := te.trackPosition(strike.ID, pair, filled, txid)
+	entryCost := buyPrice * filled
+	if entryFee <= 0 {
+		entryFee = entryCost * RoundTripFeePct / 2.0
+	}
+	te.lotLedger.Acquire(te.pairAsset(pair), strike.ID, filled, entryCost, entryFee, te.Clock.Now())
+
+	reason := fmt.Sprintf("entry %s filled %.8f of %.8f (%.1f%%, under MIN_FILL_RATIO %.1f%%)",
+		txid, filled, requested, 100*filled/requested, 100*te.MinFillRatio)
+	te.orderWAL.Intent(strike.ID, pair, strike.Direction.exitSide(), filled)
+	exitTx, err := ex.PlaceMarketExit(ctx, pair, filled)
+	if err != nil {
+		te.alert(AlertExitFailed, "flatten of thin entry %s %.8f for strike %d failed: %v", pair, filled, strike.ID, err)
+		return fmt.Errorf("%s; flatten failed: %v", reason, err)
+	}
+	*orderTxs = append(*orderTxs, exitTx)
+	te.orderPlaced(strike.ID, pair, strike.Direction.exitSide(), exitTx)
+	te.positionsMu.Lock()
+	pos.ExitTx = exitTx
+	te.positionsMu.Unlock()
+	strike.ExitTxID = &exitTx
+	sellPrice, exitFee := te.disposeFlattened(ctx, pair, filled, exitTx)
+	te.releasePosition(strike.ID)
+	if sellPrice <= 0 {
+		// Nothing to value the sale at; only the fees are booked
+		sellPrice = buyPrice
+	}
+	pnl := (sellPrice - buyPrice) * filled / quoteRate
+	fees := (entryFee + exitFee) / quoteRate
+	te.applyPnL(FromDollars(pnl).Cents())
+	te.countersMu.Lock()
+	atomic.AddInt64(&te.TotalFeesPaid, FromDollars(fees).Cents())
+	te.countersMu.Unlock()
+	strike.ExitPrice = &sellPrice
+	strike.PnL = &pnl
+	strike.Fees = fees
+	log.Printf("FLATTEN: %s sold %.8f at %.2f for thin strike %d, PnL=$%.2f (txid=%s)", pair, filled, sellPrice, strike.ID, pnl, exitTx)
+	return fmt.Errorf("%s; flattened", reason)
+}
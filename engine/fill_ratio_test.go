@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"macro-strike-bot/config"
+)
+
+func TestThinLiveFillIsFlattenedAndAborted(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.004","price":"2500"}}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.004","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["FLAT1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"FLAT1":{"status":"closed","vol_exec":"0.004","price":"2490","fee":"0.02"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 100
+	te.MinFillRatio = 0.5
+	start := te.Snapshot()
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	_, err := te.ExecuteStrike(context.Background(), strike)
+	if err == nil || !strings.Contains(err.Error(), "MIN_FILL_RATIO") {
+		t.Fatalf("ExecuteStrike error = %v, want the thin fill rejected", err)
+	}
+	if strike.ExitTxID == nil || *strike.ExitTxID != "FLAT1" || strike.ExitReason != ExitThinFill {
+		t.Errorf("exit %v reason %q, want the FLAT1 flatten", strike.ExitTxID, strike.ExitReason)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions left open after the flatten", len(te.openPositions))
+	}
+	if n := te.TotalStrikes; n != 0 {
+		t.Errorf("thin fill counted as %d trades", n)
+	}
+	// 0.004·(2490-2500) lost; fees are the modeled entry and the reported exit
+	after := te.Snapshot()
+	if strike.PnL == nil || math.Abs(*strike.PnL+0.04) > 1e-9 || after.Capital-start.Capital != -4 || after.TotalPnL-start.TotalPnL != -4 {
+		t.Errorf("pnl %v capital moved %d total pnl moved %d, want the $0.04 flatten loss booked", strike.PnL, after.Capital-start.Capital, after.TotalPnL-start.TotalPnL)
+	}
+	if wantFees := 0.004*2500*RoundTripFeePct/2 + 0.02; math.Abs(strike.Fees-wantFees) > 1e-9 || after.TotalFeesPaid-start.TotalFeesPaid != FromDollars(wantFees).Cents() {
+		t.Errorf("fees %.4f (total moved %d cents), want %.4f", strike.Fees, after.TotalFeesPaid-start.TotalFeesPaid, wantFees)
+	}
+}
+
+func TestMinFillRatioWaitsForTheSettledEntry(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		// The first poll sees a thin partial fill; the order then fills in full
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"open","vol_exec":"0.004","price":"2500"}}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"2500","fee":"0.1"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.04","price":"2510","fee":"0.1"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 100
+	te.MinFillRatio = 0.5
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v, want the settled full fill traded", err)
+	}
+	if strike.ExitReason == ExitThinFill || *strike.ExitTxID != "SELL1" || math.Abs(pnl-0.4) > 1e-9 {
+		t.Errorf("exit %s reason %q pnl %.4f, want SELL1 closing a 0.04 position for 0.4", *strike.ExitTxID, strike.ExitReason, pnl)
+	}
+}
+
+func TestMinFillRatioSetting(t *testing.T) {
+	if te := NewTradingEngineFromConfig(config.Config{}); te.thinFill(0.0001, 1) {
+		t.Error("the default MIN_FILL_RATIO should accept any fill")
+	}
+	te := NewTradingEngineFromConfig(config.Config{"MIN_FILL_RATIO": "0.25"})
+	if !te.thinFill(0.2, 1) || te.thinFill(0.25, 1) {
+		t.Error("MIN_FILL_RATIO=0.25 should reject only fills under a quarter")
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"MIN_FILL_RATIO": "1.5"}).ValidateConfig(); err == nil {
+		t.Error("a MIN_FILL_RATIO above 1 should be rejected")
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// parseFundingRates reads SYMBOL_FUNDING_RATES: SYMBOL=pct,... in percent
+// per hour, returned as fractions
+func parseFundingRates(v string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	if v == "" {
+		return rates, nil
+	}
+	pairs, err := config.ParseKeyValueList(v)
+	if err != nil {
+		return nil, err
+	}
+	for sym, raw := range pairs {
+		if !isKnownSymbol(sym) {
+			return nil, fmt.Errorf("unknown symbol %q", sym)
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("%s: %q is not a non-negative rate", sym, raw)
+		}
+		rates[sym] = f / 100.0
+	}
+	return rates, nil
+}
+
+// fundingRate is the hourly funding rate, as a fraction of levered notional,
+// charged on positions in symbol
+func (te *TradingEngine) fundingRate(symbol string) float64 {
+	if rate, ok := te.SymbolFundingRates[symbol]; ok {
+		return rate
+	}
+	return te.FundingRatePerHour
+}
+
+// simHold is how long a simulated strike is modeled as open: the
+// SIM_MIN_HOLD_MS hold (scaled by type) when one is slept, otherwise its whole
+// exposure window, since the sim resolves the strike without waiting
+func (te *TradingEngine) simHold(strike *MacroStrike) time.Duration {
+	if te.SimMinHoldMs > 0 {
+		return time.Duration(float64(te.SimMinHoldMs) * simHoldScale(strike.StrikeType) * float64(time.Millisecond))
+	}
+	return exposureLimit(strike)
+}
+
+// fundingCost is the funding a levered notional accrues over hold
+func (te *TradingEngine) fundingCost(symbol string, notional float64, hold time.Duration) float64 {
+	return notional * te.fundingRate(symbol) * hold.Hours()
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestParseFundingRates(t *testing.T) {
+	rates, err := parseFundingRates("WETH/USDC=0.01, WBTC/USDC=0.005")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates["WETH/USDC"] != 0.0001 || rates["WBTC/USDC"] != 0.00005 {
+		t.Errorf("rates = %v, want percent converted to fractions", rates)
+	}
+	if _, err := parseFundingRates("WETH/USDC=-1"); err == nil {
+		t.Error("negative rate accepted")
+	}
+}
+
+func TestUnknownFundingSymbolRejected(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "SYMBOL_FUNDING_RATES": "WETH/USDC=0.01,DOGE/USDC=0.02"})
+	if err := te.ValidateConfig(); err == nil || !strings.Contains(err.Error(), `SYMBOL_FUNDING_RATES: unknown symbol "DOGE/USDC"`) {
+		t.Errorf("ValidateConfig = %v, want the unknown symbol rejected", err)
+	}
+}
+
+func TestSimFundingChargedOverExposureWithoutMinHold(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "5", "FUNDING_RATE_PCT_PER_HOUR": "0.01"})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	strike := certainStrike(1, true)
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	// No time passes on the fake clock; the strike is charged its exposure window
+	want := strike.StrikeForce * 0.0001 * (time.Duration(MaxExposureTimeMs) * time.Millisecond).Hours()
+	if want <= 0 || math.Abs(strike.Funding-want) > 1e-9 {
+		t.Errorf("funding = %.8f, want %.8f over the %dms exposure window", strike.Funding, want, MaxExposureTimeMs)
+	}
+}
+
+func TestSimFundingChargedOverHold(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{
+		"SIM_MODE": "1", "SIM_MIN_HOLD_MS": "3600000", "RAND_SEED": "5",
+		"FUNDING_RATE_PCT_PER_HOUR": "0.01", "SYMBOL_FUNDING_RATES": "WETH/USDC=0.1",
+	})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	strike := certainStrike(1, true)
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	// An arbitrage strike holds SIM_MIN_HOLD_MS scaled by its type
+	hold := time.Duration(float64(time.Hour) * simHoldScale(strike.StrikeType))
+	want := strike.StrikeForce * 0.001 * hold.Hours()
+	if want <= 0 || math.Abs(strike.Funding-want) > 1e-6 {
+		t.Fatalf("funding = %.6f, want %.6f at the WETH/USDC override", strike.Funding, want)
+	}
+
+	// The same draw without funding pays exactly the funding more
+	free := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "SIM_MIN_HOLD_MS": "3600000", "RAND_SEED": "5"})
+	free.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	freePnL, err := free.ExecuteStrike(context.Background(), certainStrike(1, true))
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if math.Abs(freePnL-pnl-want) > 0.01 {
+		t.Errorf("PnL with funding %.4f vs without %.4f, want %.4f apart", pnl, freePnL, want)
+	}
+}
+
+func TestNegativeFundingRateRejected(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "FUNDING_RATE_PCT_PER_HOUR": "-0.01"})
+	if err := te.ValidateConfig(); err == nil || !strings.Contains(err.Error(), "FUNDING_RATE_PCT_PER_HOUR") {
+		t.Errorf("ValidateConfig = %v, want the negative funding rate rejected", err)
+	}
+}
@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe defaults; HEALTH_STALE_AFTER and READY_CACHE_TTL override them
+const (
+	defaultHealthStaleAfter = 5 * time.Minute
+	defaultReadyCacheTTL    = 30 * time.Second
+	analyzerPingTimeout     = 10 * time.Second
+)
+
+// beat records that the campaign loop is making progress
+func (te *TradingEngine) beat() {
+	atomic.StoreInt64(&te.loopHeartbeat, te.Clock.Now().UnixNano())
+}
+
+// HealthStatus is the /healthz body
+type HealthStatus struct {
+	Status        string     `json:"status"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	AgeSec        float64    `json:"age_sec"`
+	StaleAfterSec float64    `json:"stale_after_sec"`
+}
+
+// Health reports whether the campaign loop has beaten within
+// HealthStaleAfter. A loop that has not started yet counts as alive.
+func (te *TradingEngine) Health() (HealthStatus, bool) {
+	st := HealthStatus{Status: "ok", StaleAfterSec: te.HealthStaleAfter.Seconds()}
+	last := atomic.LoadInt64(&te.loopHeartbeat)
+	if last == 0 {
+		st.Status = "starting"
+		return st, true
+	}
+	at := time.Unix(0, last).UTC()
+	st.LastHeartbeat = &at
+	age := te.Clock.Since(at)
+	st.AgeSec = age.Seconds()
+	if age > te.HealthStaleAfter {
+		st.Status = "stale"
+		return st, false
+	}
+	return st, true
+}
+
+// ReadinessCheck is one dependency check in the /readyz body
+type ReadinessCheck struct {
+	Name      string    `json:"name"`
+	OK        bool      `json:"ok"`
+	Skipped   string    `json:"skipped,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ReadinessStatus is the /readyz body
+type ReadinessStatus struct {
+	Ready  bool             `json:"ready"`
+	Checks []ReadinessCheck `json:"checks"`
+}
+
+// readinessCache holds the last readiness result so probes don't hammer
+// the exchange or spawn the analyzer on every request
+type readinessCache struct {
+	mu     sync.Mutex
+	status ReadinessStatus
+	at     time.Time
+}
+
+// systemStatuser is implemented by exchanges with a cheap public status call
+type systemStatuser interface {
+	SystemStatus(ctx context.Context) (string, error)
+}
+
+// SystemStatus returns Kraken's trading status, "online" when healthy
+func (k KrakenExchange) SystemStatus(ctx context.Context) (string, error) {
+	res, err := k.te.krakenPublic(ctx, "/0/public/SystemStatus", url.Values{})
+	if err != nil {
+		return "", err
+	}
+	result, _ := res["result"].(map[string]interface{})
+	status, _ := result["status"].(string)
+	if status == "" {
+		return "", fmt.Errorf("unexpected kraken system status response")
+	}
+	return status, nil
+}
+
+// Readiness runs the exchange, credential and analyzer checks, reusing the
+// last result for ReadyCacheTTL. Exchange checks are skipped in replay mode,
+// where they would consume recorded responses, and credentials unless live
+// trading; the analyzer check is skipped in SIM_MODE.
+func (te *TradingEngine) Readiness(ctx context.Context) ReadinessStatus {
+	te.readiness.mu.Lock()
+	defer te.readiness.mu.Unlock()
+	if !te.readiness.at.IsZero() && te.Clock.Since(te.readiness.at) < te.ReadyCacheTTL {
+		return te.readiness.status
+	}
+	now := te.Clock.Now().UTC()
+	check := func(name, skipped string, run func() error) ReadinessCheck {
+		c := ReadinessCheck{Name: name, OK: true, Skipped: skipped, CheckedAt: now}
+		if skipped != "" {
+			return c
+		}
+		if err := run(); err != nil {
+			c.OK, c.Error = false, err.Error()
+		}
+		return c
+	}
+	ex := te.exchange()
+	var exchangeSkip, credentialSkip, analyzerSkip string
+	if te.ReplayMode {
+		exchangeSkip, credentialSkip = "replay mode", "replay mode"
+	} else if !te.LiveTrading {
+		credentialSkip = "not live trading"
+	}
+	if te.SimMode {
+		analyzerSkip = "SIM_MODE"
+	}
+	st := ReadinessStatus{Ready: true, Checks: []ReadinessCheck{
+		check("exchange", exchangeSkip, func() error {
+			if s, ok := ex.(systemStatuser); ok {
+				status, err := s.SystemStatus(ctx)
+				if err == nil && status != "online" {
+					err = fmt.Errorf("%s status %q", ex.Name(), status)
+				}
+				return err
+			}
+			_, err := ex.GetTicker(ctx, ex.Pair(symbols[0]))
+			return err
+		}),
+		check("credentials", credentialSkip, func() error {
+			_, held, err := te.fundingBalance(ctx)
+			if err == nil && !held && te.QuoteCurrency != defaultQuoteCurrency {
+				err = fmt.Errorf("no %s balance on the account", te.QuoteCurrency)
+			}
+			return err
+		}),
+		check("analyzer", analyzerSkip, func() error {
+			ctx, cancel := context.WithTimeout(ctx, analyzerPingTimeout)
+			defer cancel()
+			if out, err := exec.CommandContext(ctx, analyzerBinary, "--version").CombinedOutput(); err != nil {
+				return fmt.Errorf("%s --version: %v (%s)", analyzerBinary, err, out)
+			}
+			return nil
+		}),
+	}}
+	for _, c := range st.Checks {
+		if !c.OK {
+			st.Ready = false
+		}
+	}
+	te.readiness.status, te.readiness.at = st, te.Clock.Now()
+	return st
+}
+
+// serveHealthz answers the liveness probe: 503 once the loop is stale
+func (te *TradingEngine) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st, ok := te.Health()
+	writeProbe(w, ok, st)
+}
+
+// serveReadyz answers the readiness probe: 503 when any check fails
+func (te *TradingEngine) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st := te.Readiness(r.Context())
+	writeProbe(w, st.Ready, st)
+}
+
+func writeProbe(w http.ResponseWriter, ok bool, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(body)
+}
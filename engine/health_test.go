@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestHealthzGoesStaleWithoutHeartbeats(t *testing.T) {
+	t.Setenv("HEALTH_STALE_AFTER", "1m")
+	te := NewTradingEngine()
+	clock := clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Clock = clock
+	h := te.statusHandler()
+	probe := func() (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		var st HealthStatus
+		json.Unmarshal(rec.Body.Bytes(), &st)
+		return rec.Code, st
+	}
+
+	if code, st := probe(); code != 200 || st.Status != "starting" {
+		t.Errorf("before the loop: %d %+v, want 200 starting", code, st)
+	}
+	te.beat()
+	clock.Advance(30 * time.Second)
+	if code, st := probe(); code != 200 || st.Status != "ok" {
+		t.Errorf("30s after a beat: %d %+v, want 200 ok", code, st)
+	}
+	clock.Advance(time.Minute)
+	if code, st := probe(); code != http.StatusServiceUnavailable || st.Status != "stale" || st.AgeSec != 90 {
+		t.Errorf("90s after a beat: %d %+v, want 503 stale", code, st)
+	}
+}
+
+func TestReadyzReportsFailingChecksAndCaches(t *testing.T) {
+	status := "online"
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/public/SystemStatus":
+			calls++
+			w.Write([]byte(`{"error":[],"result":{"status":"` + status + `"}}`))
+		default:
+			w.Write([]byte(`{"error":["EAPI:Invalid key"]}`))
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.KrakenBaseURL = srv.URL
+	h := te.statusHandler()
+	probe := func() (int, ReadinessStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		var st ReadinessStatus
+		json.Unmarshal(rec.Body.Bytes(), &st)
+		return rec.Code, st
+	}
+
+	if code, st := probe(); code != 200 || !st.Ready || st.Checks[1].Skipped == "" || st.Checks[2].Skipped != "SIM_MODE" {
+		t.Errorf("paper trading: %d %+v, want ready with credentials and analyzer skipped", code, st)
+	}
+
+	// Cached: a status change isn't seen until ReadyCacheTTL passes
+	status = "maintenance"
+	te.LiveTrading = true
+	te.KrakenAPIKey, te.KrakenAPISecret = "key", "c2VjcmV0"
+	if code, _ := probe(); code != 200 || calls != 1 {
+		t.Errorf("within the cache TTL: %d after %d status calls, want the cached 200", code, calls)
+	}
+	te.Clock.(*clock.Fake).Advance(te.ReadyCacheTTL)
+	code, st := probe()
+	if code != http.StatusServiceUnavailable || st.Ready || st.Checks[0].OK || st.Checks[1].OK || st.Checks[1].Error == "" {
+		t.Errorf("exchange in maintenance with bad keys: %d %+v, want 503 naming both checks", code, st)
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// HTTPClientConfig tunes the engine's shared HTTP client
+type HTTPClientConfig struct {
+	Timeout             time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
+}
+
+// defaultHTTPClientConfig bounds every request so a hung connection can't
+// stall the campaign, and keeps a small pool of warm connections to Kraken
+var defaultHTTPClientConfig = HTTPClientConfig{
+	Timeout:             15 * time.Second,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+}
+
+// fallbackHTTPClient serves engines built without NewTradingEngine
+var fallbackHTTPClient = newHTTPClient(defaultHTTPClientConfig)
+
+// httpClientConfigFromConfig reads HTTP_TIMEOUT_MS, HTTP_MAX_IDLE_CONNS,
+// HTTP_MAX_IDLE_CONNS_PER_HOST, HTTP_IDLE_CONN_TIMEOUT_MS and HTTP_KEEPALIVE_MS
+func httpClientConfigFromConfig(settings config.Config) (HTTPClientConfig, []error) {
+	cfg := defaultHTTPClientConfig
+	var errs []error
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"HTTP_TIMEOUT_MS", &cfg.Timeout},
+		{"HTTP_IDLE_CONN_TIMEOUT_MS", &cfg.IdleConnTimeout},
+		{"HTTP_KEEPALIVE_MS", &cfg.KeepAlive},
+	} {
+		if v := settings.Get(d.name); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+				*d.dst = time.Duration(n) * time.Millisecond
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %q is not a positive integer", d.name, v))
+			}
+		}
+	}
+	for _, n := range []struct {
+		name string
+		dst  *int
+	}{
+		{"HTTP_MAX_IDLE_CONNS", &cfg.MaxIdleConns},
+		{"HTTP_MAX_IDLE_CONNS_PER_HOST", &cfg.MaxIdleConnsPerHost},
+	} {
+		if v := settings.Get(n.name); v != "" {
+			if i, err := strconv.Atoi(v); err == nil && i > 0 {
+				*n.dst = i
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %q is not a positive integer", n.name, v))
+			}
+		}
+	}
+	return cfg, errs
+}
+
+// newHTTPClient builds a pooled client with an overall request timeout
+func newHTTPClient(cfg HTTPClientConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: cfg.KeepAlive}).DialContext
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}
+}
+
+// httpClient returns the engine's shared client
+func (te *TradingEngine) httpClient() *http.Client {
+	if te.HTTPClient != nil {
+		return te.HTTPClient
+	}
+	return fallbackHTTPClient
+}
+
+// warmupHTTP opens a pooled connection to Kraken before the first order so
+// the TLS handshake isn't paid on the critical path
+func (te *TradingEngine) warmupHTTP(ctx context.Context) {
+	start := time.Now()
+	if _, err := te.krakenPublic(ctx, "/0/public/Time", url.Values{}); err != nil {
+		log.Printf("⚠️ HTTP warmup failed: %v", err)
+		return
+	}
+	log.Printf("HTTP client warmed up in %.0fms", float64(time.Since(start))/float64(time.Millisecond))
+}
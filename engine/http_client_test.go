@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientTimesOutHungConnections(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := defaultHTTPClientConfig
+	cfg.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	if _, err := newHTTPClient(cfg).Get(srv.URL); err == nil {
+		t.Fatal("request to a hung server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timed out after %v, want ~50ms", elapsed)
+	}
+}
+
+func TestHTTPClientConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTP_TIMEOUT_MS", "2500")
+	t.Setenv("HTTP_MAX_IDLE_CONNS_PER_HOST", "4")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if te.HTTPClient.Timeout != 2500*time.Millisecond {
+		t.Errorf("timeout = %v, want 2.5s", te.HTTPClient.Timeout)
+	}
+	if tr := te.HTTPClient.Transport.(*http.Transport); tr.MaxIdleConnsPerHost != 4 || tr.MaxIdleConns != defaultHTTPClientConfig.MaxIdleConns {
+		t.Errorf("pool = %d/%d, want 4/%d", tr.MaxIdleConnsPerHost, tr.MaxIdleConns, defaultHTTPClientConfig.MaxIdleConns)
+	}
+
+	t.Setenv("HTTP_TIMEOUT_MS", "0")
+	if err := NewTradingEngine().ValidateConfig(); err == nil {
+		t.Error("a zero timeout should fail validation")
+	}
+}
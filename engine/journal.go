@@ -0,0 +1,457 @@
+package engine
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// journalQueueSize bounds pending writes before RecordStrike blocks
+const journalQueueSize = 4096
+
+const journalSchema = `
+CREATE TABLE IF NOT EXISTS campaigns (
+	run_id             TEXT PRIMARY KEY,
+	started_at         INTEGER NOT NULL,
+	ended_at           INTEGER,
+	config_json        TEXT,
+	final_capital      REAL,
+	total_pnl          REAL,
+	trades_completed   INTEGER,
+	successful_strikes INTEGER,
+	failed_strikes     INTEGER
+);
+CREATE TABLE IF NOT EXISTS strikes (
+	run_id               TEXT NOT NULL,
+	id                   INTEGER NOT NULL,
+	symbol               TEXT NOT NULL,
+	strike_type          INTEGER NOT NULL,
+	strike_type_name     TEXT NOT NULL,
+	entry_price          REAL,
+	target_price         REAL,
+	stop_loss            REAL,
+	confidence           REAL,
+	expected_return      REAL,
+	max_exposure_time_ms INTEGER,
+	strike_force         REAL,
+	timestamp            INTEGER,
+	status               INTEGER,
+	hit_time             INTEGER,
+	exit_price           REAL,
+	pnl                  REAL,
+	leverage             INTEGER,
+	confidence_threshold REAL,
+	level_source         TEXT,
+	liquidity_factor     REAL,
+	momentum_factor      REAL,
+	entry_txid           TEXT,
+	exit_txid            TEXT,
+	fees                 REAL,
+	slippage             REAL,
+	exit_reason          TEXT,
+	trade_ids            TEXT,
+	order_payloads       TEXT,
+	performance_factor   REAL,
+	risk_reward          REAL,
+	duration_ms          INTEGER,
+	transitions          TEXT,
+	analysis             TEXT,
+	direction            TEXT,
+	take_profit_ladder   TEXT,
+	exits                TEXT,
+	timings              TEXT,
+	funding              REAL,
+	PRIMARY KEY (run_id, id)
+);
+CREATE INDEX IF NOT EXISTS strikes_symbol_time ON strikes (symbol, timestamp);
+`
+
+// journalAddedColumns were added to strikes after the first release; older
+// journals get them via ALTER TABLE on open
+var journalAddedColumns = []string{
+	"trade_ids TEXT", "order_payloads TEXT",
+	"performance_factor REAL", "risk_reward REAL", "duration_ms INTEGER", "transitions TEXT",
+	"analysis TEXT", "direction TEXT", "take_profit_ladder TEXT", "exits TEXT",
+	"timings TEXT", "funding REAL",
+}
+
+// Journal persists strikes and campaign summaries. Writes are queued and must
+// never block the strike path; Flush waits for queued writes to land.
+type Journal interface {
+	StartCampaign(runID string, startedAt time.Time, config map[string]interface{})
+	FinishCampaign(runID string, endedAt time.Time, summary CampaignSummary)
+	RecordStrike(runID string, strike *MacroStrike)
+	QueryStrikes(q StrikeQuery) ([]JournalStrike, error)
+	Flush()
+	Close() error
+}
+
+// JournalStrike is a strike row as stored in the journal
+type JournalStrike struct {
+	MacroStrike
+	RunID string `json:"run_id"`
+}
+
+// CampaignSummary is the final state written to the campaigns table
+type CampaignSummary struct {
+	FinalCapital      float64
+	TotalPnL          float64
+	TradesCompleted   int64
+	SuccessfulStrikes int64
+	FailedStrikes     int64
+}
+
+// SQLiteJournal persists strikes and campaigns to SQLite. All writes go
+// through a single goroutine so callers never contend on the database lock.
+type SQLiteJournal struct {
+	db   *sql.DB
+	ops  chan func(*sql.DB) error
+	done chan struct{}
+	once sync.Once
+}
+
+// OpenSQLiteJournal opens (creating if needed) the SQLite journal at path
+func OpenSQLiteJournal(path string) (*SQLiteJournal, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(journalSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("journal schema: %v", err)
+	}
+	for _, col := range journalAddedColumns {
+		if _, err := db.Exec("ALTER TABLE strikes ADD COLUMN " + col); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			db.Close()
+			return nil, fmt.Errorf("journal schema: %v", err)
+		}
+	}
+	j := &SQLiteJournal{
+		db:   db,
+		ops:  make(chan func(*sql.DB) error, journalQueueSize),
+		done: make(chan struct{}),
+	}
+	go j.writer()
+	return j, nil
+}
+
+// writer applies queued writes in order until the queue is closed
+func (j *SQLiteJournal) writer() {
+	defer close(j.done)
+	for op := range j.ops {
+		if err := op(j.db); err != nil {
+			log.Printf("⚠️ Journal write failed: %v", err)
+		}
+	}
+}
+
+// Close drains pending writes and closes the database
+func (j *SQLiteJournal) Close() error {
+	j.once.Do(func() { close(j.ops) })
+	<-j.done
+	return j.db.Close()
+}
+
+// StartCampaign records a new run with a snapshot of its configuration
+func (j *SQLiteJournal) StartCampaign(runID string, startedAt time.Time, config map[string]interface{}) {
+	cfg, _ := json.Marshal(config)
+	j.ops <- func(db *sql.DB) error {
+		_, err := db.Exec(`INSERT INTO campaigns (run_id, started_at, config_json) VALUES (?, ?, ?)
+			ON CONFLICT(run_id) DO UPDATE SET config_json = excluded.config_json`,
+			runID, startedAt.Unix(), string(cfg))
+		return err
+	}
+}
+
+// FinishCampaign stores the final statistics for a run
+func (j *SQLiteJournal) FinishCampaign(runID string, endedAt time.Time, summary CampaignSummary) {
+	j.ops <- func(db *sql.DB) error {
+		_, err := db.Exec(`UPDATE campaigns SET ended_at = ?, final_capital = ?, total_pnl = ?,
+			trades_completed = ?, successful_strikes = ?, failed_strikes = ? WHERE run_id = ?`,
+			endedAt.Unix(), summary.FinalCapital, summary.TotalPnL, summary.TradesCompleted,
+			summary.SuccessfulStrikes, summary.FailedStrikes, runID)
+		return err
+	}
+}
+
+// RecordStrike inserts or updates a strike row. The strike is copied at call
+// time so later mutations (e.g. a live exit) need another RecordStrike.
+func (j *SQLiteJournal) RecordStrike(runID string, strike *MacroStrike) {
+	args := strikeRowArgs(runID, strike)
+	j.ops <- func(db *sql.DB) error {
+		_, err := db.Exec(`INSERT INTO strikes (`+strikeInsertColumns+`)
+			VALUES (?`+strings.Repeat(", ?", len(args)-1)+`)
+			ON CONFLICT(run_id, id) DO UPDATE SET `+strikeUpsertSet, args...)
+		return err
+	}
+}
+
+// strikeInsertColumns is the column list strikeRowArgs fills, in order
+const strikeInsertColumns = `run_id, id, symbol, strike_type, strike_type_name,
+	entry_price, target_price, stop_loss, confidence, expected_return, max_exposure_time_ms,
+	strike_force, timestamp, status, hit_time, exit_price, pnl, leverage, confidence_threshold,
+	level_source, liquidity_factor, momentum_factor, entry_txid, exit_txid, fees, slippage, exit_reason,
+	trade_ids, order_payloads, performance_factor, risk_reward, duration_ms, transitions, analysis,
+	direction, take_profit_ladder, exits, timings, funding`
+
+// strikeUpsertSet lists the columns a later RecordStrike of the same strike may change
+const strikeUpsertSet = `strike_force = excluded.strike_force, status = excluded.status, hit_time = excluded.hit_time,
+	exit_price = excluded.exit_price, pnl = excluded.pnl, leverage = excluded.leverage,
+	entry_txid = excluded.entry_txid, exit_txid = excluded.exit_txid, fees = excluded.fees,
+	slippage = excluded.slippage, exit_reason = excluded.exit_reason,
+	trade_ids = excluded.trade_ids, order_payloads = excluded.order_payloads,
+	performance_factor = excluded.performance_factor, risk_reward = excluded.risk_reward,
+	duration_ms = excluded.duration_ms, transitions = excluded.transitions, exits = excluded.exits,
+	timings = excluded.timings, funding = excluded.funding`
+
+// strikeRowArgs snapshots a strike as insert arguments matching strikeInsertColumns
+func strikeRowArgs(runID string, strike *MacroStrike) []interface{} {
+	s := *strike
+	var hitTime sql.NullInt64
+	if s.HitTime != nil {
+		hitTime = sql.NullInt64{Int64: *s.HitTime, Valid: true}
+	}
+	var exitPrice, pnl sql.NullFloat64
+	if s.ExitPrice != nil {
+		exitPrice = sql.NullFloat64{Float64: *s.ExitPrice, Valid: true}
+	}
+	if s.PnL != nil {
+		pnl = sql.NullFloat64{Float64: *s.PnL, Valid: true}
+	}
+	return []interface{}{
+		runID, int64(s.ID), s.Symbol, int(s.StrikeType), s.StrikeType.String(),
+		s.EntryPrice, s.TargetPrice, s.StopLoss, s.Confidence, s.ExpectedReturn, int64(s.MaxExposureTimeMs),
+		s.StrikeForce, s.Timestamp, int(s.Status), hitTime, exitPrice, pnl, int64(s.Leverage), s.ConfidenceThreshold,
+		s.LevelSource, s.LiquidityFactor, s.MomentumFactor, nullString(s.EntryTxID), nullString(s.ExitTxID),
+		s.Fees, s.Slippage, s.ExitReason, nullJSON(s.TradeIDs), nullJSON(s.OrderPayloads),
+		s.PerformanceFactor, s.RiskReward, s.DurationMs, nullJSON(s.Transitions), nullJSON(s.Analysis),
+		s.Direction.String(), nullJSON(s.TakeProfitLadder), nullJSON(s.Exits),
+		nullJSON(s.Timings), s.Funding,
+	}
+}
+
+// nullString stores a nil pointer as NULL
+func nullString(p *string) sql.NullString {
+	if p == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *p, Valid: true}
+}
+
+// nullJSON stores an empty slice as NULL and anything else as JSON text
+func nullJSON(v interface{}) sql.NullString {
+	switch x := v.(type) {
+	case []string:
+		if len(x) == 0 {
+			return sql.NullString{}
+		}
+	case []OrderPayload:
+		if len(x) == 0 {
+			return sql.NullString{}
+		}
+	case []StateTransition:
+		if len(x) == 0 {
+			return sql.NullString{}
+		}
+	case []TakeProfitRung:
+		if len(x) == 0 {
+			return sql.NullString{}
+		}
+	case []StrikeExit:
+		if len(x) == 0 {
+			return sql.NullString{}
+		}
+	case *AnalysisSnapshot:
+		if x == nil {
+			return sql.NullString{}
+		}
+	case *StrikeTimings:
+		if x == nil {
+			return sql.NullString{}
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// StrikeQuery filters journal reads; zero values match everything
+type StrikeQuery struct {
+	RunID      string
+	Symbol     string
+	StrikeType *StrikeType
+	Status     *StrikeStatus
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+// QueryStrikes returns strikes matching q, oldest first. Reads do not wait for
+// queued writes; call Flush first if the latest rows matter.
+func (j *SQLiteJournal) QueryStrikes(q StrikeQuery) ([]JournalStrike, error) {
+	query, args := strikeSelect(q, func(int) string { return "?" })
+	rows, err := j.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanJournalStrikes(rows)
+}
+
+// strikeSelect builds the filtered strikes query; placeholder renders the
+// n-th (1-based) bind parameter in the driver's syntax
+func strikeSelect(q StrikeQuery, placeholder func(n int) string) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, cond+" "+placeholder(len(args)))
+	}
+	if q.RunID != "" {
+		add("run_id =", q.RunID)
+	}
+	if q.Symbol != "" {
+		add("symbol =", q.Symbol)
+	}
+	if q.StrikeType != nil {
+		add("strike_type =", int(*q.StrikeType))
+	}
+	if q.Status != nil {
+		add("status =", int(*q.Status))
+	}
+	if !q.Since.IsZero() {
+		add("timestamp >=", q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		add("timestamp <", q.Until.Unix())
+	}
+	query := `SELECT run_id, id, symbol, strike_type, entry_price, target_price, stop_loss, confidence,
+		expected_return, max_exposure_time_ms, strike_force, timestamp, status, hit_time, exit_price, pnl,
+		leverage, confidence_threshold, level_source, liquidity_factor, momentum_factor,
+		entry_txid, exit_txid, fees, slippage, exit_reason, trade_ids, order_payloads,
+		performance_factor, risk_reward, duration_ms, transitions, analysis, direction,
+		take_profit_ladder, exits, timings, funding FROM strikes`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY timestamp, id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+	return query, args
+}
+
+// scanJournalStrikes reads rows produced by strikeSelect
+func scanJournalStrikes(rows *sql.Rows) ([]JournalStrike, error) {
+	var out []JournalStrike
+	for rows.Next() {
+		var js JournalStrike
+		var strikeType, status int
+		var hitTime sql.NullInt64
+		var exitPrice, pnl sql.NullFloat64
+		var levelSource, entryTx, exitTx, exitReason, tradeIDs, payloads, transitions, analysis, direction sql.NullString
+		var ladder, exits, timings sql.NullString
+		var perfFactor, riskReward sql.NullFloat64
+		var funding sql.NullFloat64
+		var durationMs sql.NullInt64
+		if err := rows.Scan(&js.RunID, &js.ID, &js.Symbol, &strikeType, &js.EntryPrice, &js.TargetPrice,
+			&js.StopLoss, &js.Confidence, &js.ExpectedReturn, &js.MaxExposureTimeMs, &js.StrikeForce,
+			&js.Timestamp, &status, &hitTime, &exitPrice, &pnl, &js.Leverage, &js.ConfidenceThreshold,
+			&levelSource, &js.LiquidityFactor, &js.MomentumFactor, &entryTx, &exitTx, &js.Fees,
+			&js.Slippage, &exitReason, &tradeIDs, &payloads, &perfFactor, &riskReward, &durationMs,
+			&transitions, &analysis, &direction, &ladder, &exits, &timings, &funding); err != nil {
+			return nil, err
+		}
+		js.StrikeType = StrikeType(strikeType)
+		js.Status = StrikeStatus(status)
+		if hitTime.Valid {
+			v := hitTime.Int64
+			js.HitTime = &v
+		}
+		if exitPrice.Valid {
+			v := exitPrice.Float64
+			js.ExitPrice = &v
+		}
+		if pnl.Valid {
+			v := pnl.Float64
+			js.PnL = &v
+		}
+		js.LevelSource = levelSource.String
+		if entryTx.Valid {
+			v := entryTx.String
+			js.EntryTxID = &v
+		}
+		if exitTx.Valid {
+			v := exitTx.String
+			js.ExitTxID = &v
+		}
+		js.ExitReason = exitReason.String
+		if tradeIDs.Valid {
+			if err := json.Unmarshal([]byte(tradeIDs.String), &js.TradeIDs); err != nil {
+				return nil, fmt.Errorf("strike %d trade_ids: %v", js.ID, err)
+			}
+		}
+		if payloads.Valid {
+			if err := json.Unmarshal([]byte(payloads.String), &js.OrderPayloads); err != nil {
+				return nil, fmt.Errorf("strike %d order_payloads: %v", js.ID, err)
+			}
+		}
+		// Rows written before these columns existed read back as zero
+		js.PerformanceFactor, js.RiskReward, js.DurationMs = perfFactor.Float64, riskReward.Float64, durationMs.Int64
+		if transitions.Valid {
+			if err := json.Unmarshal([]byte(transitions.String), &js.Transitions); err != nil {
+				return nil, fmt.Errorf("strike %d transitions: %v", js.ID, err)
+			}
+		}
+		if analysis.Valid {
+			if err := json.Unmarshal([]byte(analysis.String), &js.Analysis); err != nil {
+				return nil, fmt.Errorf("strike %d analysis: %v", js.ID, err)
+			}
+		}
+		// Rows from before shorts existed have no direction and were long
+		if direction.Valid {
+			d, ok := directionByName(direction.String)
+			if !ok {
+				return nil, fmt.Errorf("strike %d direction: unknown %q", js.ID, direction.String)
+			}
+			js.Direction = d
+		}
+		if ladder.Valid {
+			if err := json.Unmarshal([]byte(ladder.String), &js.TakeProfitLadder); err != nil {
+				return nil, fmt.Errorf("strike %d take_profit_ladder: %v", js.ID, err)
+			}
+		}
+		if exits.Valid {
+			if err := json.Unmarshal([]byte(exits.String), &js.Exits); err != nil {
+				return nil, fmt.Errorf("strike %d exits: %v", js.ID, err)
+			}
+		}
+		js.Funding = funding.Float64
+		if timings.Valid {
+			if err := json.Unmarshal([]byte(timings.String), &js.Timings); err != nil {
+				return nil, fmt.Errorf("strike %d timings: %v", js.ID, err)
+			}
+		}
+		out = append(out, js)
+	}
+	return out, rows.Err()
+}
+
+// Flush blocks until every write queued so far has been applied
+func (j *SQLiteJournal) Flush() {
+	done := make(chan struct{})
+	j.ops <- func(*sql.DB) error {
+		close(done)
+		return nil
+	}
+	<-done
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestJournalRoundTripsStrikes(t *testing.T) {
+	j, err := OpenSQLiteJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteJournal: %v", err)
+	}
+	defer j.Close()
+
+	now := time.Now().Unix()
+	pnl, exit := 12.5, 3030.0
+	hit := &MacroStrike{
+		ID: 1, Symbol: "WETH/USDC", StrikeType: MacroVolatility, EntryPrice: 3000, TargetPrice: 3096,
+		StopLoss: 2940, Confidence: 0.9, ExpectedReturn: 0.032, MaxExposureTimeMs: MaxExposureTimeMs,
+		StrikeForce: 1500, Timestamp: now, Status: Hit, HitTime: &now, ExitPrice: &exit, PnL: &pnl,
+		Leverage: 5, ConfidenceThreshold: 0.8, LevelSource: LevelSourceAnalyst, LiquidityFactor: 0.9,
+		MomentumFactor: 1, PerformanceFactor: 0.5, RiskReward: 1.6, DurationMs: 1234,
+		Fees: 2.4, Funding: 0.35, Slippage: 0.001, ExitReason: ExitTakeProfit,
+		EntryTxID: strPtr("OENTRY-1"), ExitTxID: strPtr("OEXIT-1"), TradeIDs: []string{"TA-1", "TB-1"},
+		OrderPayloads: []OrderPayload{{Time: 1, Path: "/0/private/AddOrder", Request: map[string]string{"pair": "ETHUSD"},
+			Response: json.RawMessage(`{"error":[],"result":{"txid":["OENTRY-1"]}}`)}},
+		Transitions: []StateTransition{
+			{To: "targeting", At: time.Unix(now, 0).UTC(), Price: 3000, Reason: "generated"},
+			{From: "targeting", To: "striking", At: time.Unix(now, 0).UTC(), Price: 3000},
+		},
+		TakeProfitLadder: []TakeProfitRung{{Pct: 0.5, Portion: 0.5}, {Pct: 1, Portion: 0.5}},
+		Exits: []StrikeExit{
+			{Portion: 0.5, Price: 3015, Reason: ExitTakeProfit, TxID: "OEXIT-1A"},
+			{Portion: 0.5, Price: 3030, Reason: ExitTakeProfit, TxID: "OEXIT-1"},
+		},
+		Timings: &StrikeTimings{AnalysisMs: 40, OrderSubmitMs: 120, EntryFillMs: 250, HoldMs: 60000,
+			ExitSubmitMs: 110, ExitFillMs: 300},
+		Analysis: &AnalysisSnapshot{Confidence: 0.88, Volatility: 0.03, Momentum: 0.4, Liquidity: 0.7,
+			PrecisionScore: 0.95, Recommendation: "EXECUTE", Timestamp: now},
+	}
+	open := &MacroStrike{
+		ID: 2, Symbol: "AAVE/USDC", StrikeType: MacroMomentum, Direction: Short, EntryPrice: 120, Timestamp: now,
+		Status: Striking, Leverage: 3, EntryTxID: strPtr("OENTRY-2"),
+	}
+	j.StartCampaign("run-1", time.Now(), map[string]interface{}{"order_usd_size": 25.0})
+	j.RecordStrike("run-1", hit)
+	j.RecordStrike("run-1", open)
+
+	// Live exit updates the existing row rather than inserting a new one
+	loss := -3.0
+	open.Status = Miss
+	open.PnL = &loss
+	open.ExitTxID = strPtr("OEXIT-2")
+	open.ExitReason = ExitHoldExpired
+	j.RecordStrike("run-1", open)
+	j.Flush()
+
+	got, err := j.QueryStrikes(StrikeQuery{RunID: "run-1"})
+	if err != nil {
+		t.Fatalf("QueryStrikes: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d strikes, want 2", len(got))
+	}
+	first := got[0]
+	if first.ID != 1 || first.Symbol != "WETH/USDC" || first.StrikeType != MacroVolatility || first.Status != Hit {
+		t.Errorf("unexpected first strike: %+v", first)
+	}
+	if first.PnL == nil || *first.PnL != pnl || first.ExitPrice == nil || *first.ExitPrice != exit {
+		t.Errorf("pnl/exit not round-tripped: %+v", first)
+	}
+	if *first.EntryTxID != "OENTRY-1" || *first.ExitTxID != "OEXIT-1" || first.ExitReason != ExitTakeProfit {
+		t.Errorf("execution details not round-tripped: %+v", first)
+	}
+	if first.LevelSource != LevelSourceAnalyst || first.Fees != 2.4 || first.Funding != 0.35 || first.Slippage != 0.001 {
+		t.Errorf("strike metadata not round-tripped: %+v", first)
+	}
+	if first.PerformanceFactor != 0.5 || first.RiskReward != 1.6 || first.DurationMs != 1234 {
+		t.Errorf("sizing/timing fields not round-tripped: %+v", first)
+	}
+	if len(first.Transitions) != 2 || first.Transitions[1] != hit.Transitions[1] {
+		t.Errorf("transitions not round-tripped: %+v", first.Transitions)
+	}
+	if first.Analysis == nil || *first.Analysis != *hit.Analysis {
+		t.Errorf("analysis snapshot not round-tripped: %+v", first.Analysis)
+	}
+	if len(first.TakeProfitLadder) != 2 || first.TakeProfitLadder[1] != hit.TakeProfitLadder[1] ||
+		len(first.Exits) != 2 || first.Exits[0] != hit.Exits[0] {
+		t.Errorf("ladder/exits not round-tripped: %+v / %+v", first.TakeProfitLadder, first.Exits)
+	}
+	if first.Timings == nil || *first.Timings != *hit.Timings {
+		t.Errorf("stage timings not round-tripped: %+v", first.Timings)
+	}
+	second := got[1]
+	if len(first.TradeIDs) != 2 || first.TradeIDs[1] != "TB-1" || len(first.OrderPayloads) != 1 ||
+		first.OrderPayloads[0].Request["pair"] != "ETHUSD" {
+		t.Errorf("trade IDs/payloads not round-tripped: %+v / %+v", first.TradeIDs, first.OrderPayloads)
+	}
+	if first.Direction != Long || second.Direction != Short {
+		t.Errorf("directions read back %s/%s, want long/short", first.Direction, second.Direction)
+	}
+	if second.Status != Miss || second.PnL == nil || *second.PnL != loss || *second.ExitTxID != "OEXIT-2" {
+		t.Errorf("live exit update not applied: %+v", second)
+	}
+	if second.TradeIDs != nil || second.OrderPayloads != nil || second.Analysis != nil || second.Exits != nil ||
+		second.Timings != nil {
+		t.Errorf("missing trade IDs/payloads should read back as nil: %+v", second)
+	}
+	if second.ExitPrice != nil {
+		t.Errorf("exit price should stay NULL, got %v", *second.ExitPrice)
+	}
+
+	miss := Miss
+	misses, err := j.QueryStrikes(StrikeQuery{Symbol: "AAVE/USDC", Status: &miss})
+	if err != nil {
+		t.Fatalf("QueryStrikes filtered: %v", err)
+	}
+	if len(misses) != 1 || misses[0].ID != 2 {
+		t.Errorf("filtered query returned %+v", misses)
+	}
+}
+
+func TestAbortedLiveStrikeJournalsTerminalRow(t *testing.T) {
+	t.Setenv("JOURNAL_DB", filepath.Join(t.TempDir(), "journal.db"))
+	exitDown := krakenExchangeRecord{Path: "/0/private/AddOrder", Error: "EService:Unavailable"}
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["O1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"O1":{"status":"closed","vol_exec":"0.01","price":"3000"}}`),
+		exitDown, exitDown, exitDown,
+		// Campaign-end flatten sells the stranded entry
+		krakenReply("/0/private/AddOrder", `{"txid":["FLAT1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"FLAT1":{"status":"closed","price":"2990"}}`),
+	)
+	defer te.Close()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: certainStrike(1, true)}}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.Aborted != 1 {
+		t.Fatalf("aborted = %d, want 1", result.Aborted)
+	}
+	te.journal.Flush()
+	got, err := te.journal.QueryStrikes(StrikeQuery{RunID: te.RunID})
+	if err != nil {
+		t.Fatalf("QueryStrikes: %v", err)
+	}
+	if len(got) != 1 || got[0].Status != Aborted {
+		t.Fatalf("journal rows = %+v, want strike 1 aborted", got)
+	}
+	if *got[0].EntryTxID != "O1" || got[0].Transitions[len(got[0].Transitions)-1].To != "aborted" {
+		t.Errorf("aborted row lost its entry or timeline: %+v", got[0])
+	}
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// defaultKillTimeout bounds how long POST /kill waits for the engine to go flat
+const defaultKillTimeout = 60 * time.Second
+
+// KillResult is the reply to POST /kill
+type KillResult struct {
+	Flat          bool     `json:"flat"`
+	ReportFlushed bool     `json:"report_flushed"`
+	Remaining     []string `json:"remaining,omitempty"`
+}
+
+// Kill is the remote kill switch: an emergency stop that opens nothing new,
+// lets the campaign end flatten live exposure and write its reports, and
+// waits up to timeout for that to finish. The result describes whatever is
+// still outstanding when the wait ends.
+func (te *TradingEngine) Kill(timeout time.Duration) KillResult {
+	if atomic.CompareAndSwapInt32(&te.killed, 0, 1) {
+		te.emergencyStop("kill switch triggered")
+		te.Stop()
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	select {
+	case <-te.campaignDone:
+	case <-deadline.C:
+		log.Printf("⚠️ Kill switch: not flat after %v; the campaign is still winding down", timeout)
+	}
+
+	var res KillResult
+	select {
+	case <-te.campaignDone:
+		res.ReportFlushed = true
+	default:
+		res.Remaining = append(res.Remaining, "campaign still winding down; final report not written yet")
+	}
+	te.positionsMu.Lock()
+	ids := make([]uint64, 0, len(te.openPositions))
+	for id := range te.openPositions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		pos := te.openPositions[id]
+		res.Remaining = append(res.Remaining, fmt.Sprintf("strike %d: %s %.8f not confirmed flat", id, pos.Pair, pos.Volume))
+	}
+	te.positionsMu.Unlock()
+	res.Flat = len(ids) == 0
+	return res
+}
+
+// killRequested reports whether the kill switch has been thrown
+func (te *TradingEngine) killRequested() bool {
+	return atomic.LoadInt32(&te.killed) == 1
+}
+
+// shutdownStopReason is the stop reason for a campaign ended by Stop
+func (te *TradingEngine) shutdownStopReason() string {
+	if te.killRequested() {
+		return report.StopKilled
+	}
+	return report.StopShutdown
+}
+
+// serveKill answers POST /kill: 200 once flat with the report written,
+// 202 with the remaining work when KillTimeout passes first
+func (te *TradingEngine) serveKill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	res := te.Kill(te.KillTimeout)
+	w.Header().Set("Content-Type", "application/json")
+	if !res.Flat || !res.ReportFlushed {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// requireControlToken guards the control endpoints with CONTROL_TOKEN as a
+// bearer token. Without a token configured /pause and /resume stay open, but
+// an endpoint marked always refuses: /kill is never one anonymous request away.
+func (te *TradingEngine) requireControlToken(always bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if te.ControlToken == "" {
+			if always {
+				http.Error(w, "set CONTROL_TOKEN to enable this endpoint", http.StatusForbidden)
+				return
+			}
+			next(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(te.ControlToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="msb"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
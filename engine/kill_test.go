@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+// stopWaitingGenerator hands out its first strike, then signals reached and
+// holds the second until the engine is stopped, as a slow analysis would
+type stopWaitingGenerator struct {
+	te      *TradingEngine
+	calls   int
+	reached chan struct{}
+}
+
+func (g *stopWaitingGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	g.calls++
+	if g.calls == 2 {
+		close(g.reached)
+		for !g.te.stopRequested() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return certainStrike(uint64(g.calls), true), nil
+}
+
+func TestControlEndpointsRequireBearerToken(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	h := te.statusHandler()
+	post := func(path, auth string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Without CONTROL_TOKEN pause/resume stay open and the kill switch is off
+	if code := post("/pause", ""); code != 200 {
+		t.Errorf("POST /pause without a token configured = %d, want 200", code)
+	}
+	if code := post("/kill", ""); code != 403 {
+		t.Errorf("POST /kill without a token configured = %d, want 403", code)
+	}
+
+	te.ControlToken = "s3cret"
+	for _, path := range []string{"/pause", "/resume", "/kill"} {
+		for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+			if code := post(path, auth); code != 401 {
+				t.Errorf("POST %s with %q = %d, want 401", path, auth, code)
+			}
+		}
+	}
+	if code := post("/resume", "Bearer s3cret"); code != 200 {
+		t.Errorf("POST /resume with the token = %d, want 200", code)
+	}
+	if te.killRequested() {
+		t.Error("an unauthorized /kill stopped the engine")
+	}
+}
+
+func TestKillStopsCampaignAndWaitsForReport(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "CONTROL_TOKEN": "s3cret"})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.CampaignStart = te.Clock.Now()
+	gen := &stopWaitingGenerator{te: te, reached: make(chan struct{})}
+	te.Generator = gen
+
+	results := make(chan *report.CampaignResult, 1)
+	go func() { results <- te.ExecuteCampaign(context.Background()) }()
+	<-gen.reached
+
+	req := httptest.NewRequest("POST", "/kill", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	te.statusHandler().ServeHTTP(rec, req)
+	var res KillResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode /kill: %v (%s)", err, rec.Body)
+	}
+	if rec.Code != 200 || !res.Flat || !res.ReportFlushed {
+		t.Errorf("POST /kill = %d %+v, want 200, flat, report flushed", rec.Code, res)
+	}
+
+	result := <-results
+	if result.StopReason != report.StopKilled || result.TradesCompleted != 1 {
+		t.Errorf("campaign ended %s after %d trades, want %s after 1: the strike generated during the kill must not open",
+			result.StopReason, result.TradesCompleted, report.StopKilled)
+	}
+}
+
+func TestKillReportsRemainingWorkAfterTimeout(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	te.trackPosition(7, "ETHUSD", 0.5, "BUY7")
+
+	res := te.Kill(10 * time.Millisecond)
+	if res.Flat || res.ReportFlushed || !te.stopRequested() {
+		t.Errorf("Kill = %+v, want a stopped engine that is neither flat nor reported", res)
+	}
+	remaining := strings.Join(res.Remaining, "\n")
+	if !strings.Contains(remaining, "strike 7: ETHUSD 0.50000000") || !strings.Contains(remaining, "final report") {
+		t.Errorf("remaining work = %q, want the open position and the pending report", remaining)
+	}
+}
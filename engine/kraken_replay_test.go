@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+// fakeKraken serves just enough of Kraken's API for one live market strike:
+// every order fills immediately, entries at 3000 and exits at 3012
+func fakeKraken(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	orders := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/0/public/Ticker":
+			fmt.Fprint(w, `{"error":[],"result":{"XETHZUSD":{"c":["3001.5","0.1"],"b":["3001.0","1"],"a":["3002.0","1"]}}}`)
+		case "/0/public/AssetPairs":
+			fmt.Fprint(w, `{"error":[],"result":{"XETHZUSD":{"pair_decimals":2,"lot_decimals":8,"ordermin":"0.002"}}}`)
+		case "/0/private/AddOrder":
+			orders++
+			fmt.Fprintf(w, `{"error":[],"result":{"txid":["O%d"]}}`, orders)
+		case "/0/private/QueryOrders":
+			tx := r.Form.Get("txid")
+			price := "3000.0"
+			if tx == "O2" {
+				price = "3012.0"
+			}
+			fmt.Fprintf(w, `{"error":[],"result":{%q:{"status":"closed","vol_exec":"0.00833333","price":%q,"trades":["T-%s"]}}}`, tx, price, tx)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRecordedLiveStrikeReplaysDeterministically(t *testing.T) {
+	capture := filepath.Join(t.TempDir(), "kraken.jsonl")
+	srv := fakeKraken(t)
+
+	run := func(env map[string]string) (*MacroStrike, float64, float64) {
+		t.Helper()
+		for k, v := range env {
+			t.Setenv(k, v)
+		}
+		te := NewTradingEngine()
+		if err := te.ValidateConfig(); err != nil {
+			t.Fatalf("ValidateConfig: %v", err)
+		}
+		te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+		price, err := te.tickerPrice(context.Background(), "WETH/USDC")
+		if err != nil {
+			t.Fatalf("tickerPrice: %v", err)
+		}
+		strike := &MacroStrike{ID: 1, Symbol: "WETH/USDC", StrikeType: MacroArbitrage, EntryPrice: 3000,
+			TargetPrice: 3015, StopLoss: 2940, Confidence: 0.9}
+		pnl, err := te.ExecuteStrike(context.Background(), strike)
+		if err != nil {
+			t.Fatalf("ExecuteStrike: %v", err)
+		}
+		te.Close()
+		return strike, pnl, price
+	}
+
+	recorded, recPnL, recTicker := run(map[string]string{
+		"LIVE_TRADING": "1", "KRAKEN_API_KEY": "key", "KRAKEN_API_SECRET": "c2VjcmV0",
+		"KRAKEN_API_URL": srv.URL, "KRAKEN_RECORD_FILE": capture,
+	})
+	// Replay must not need the exchange, or credentials, at all
+	srv.Close()
+	replayed, repPnL, repTicker := run(map[string]string{
+		"LIVE_TRADING": "1", "KRAKEN_API_KEY": "", "KRAKEN_API_SECRET": "",
+		"KRAKEN_RECORD_FILE": "", "KRAKEN_REPLAY_FILE": capture,
+	})
+
+	if recPnL != 0.00833333*12 {
+		t.Errorf("recorded PnL = %v, want the fake exchange's 12 points on 0.00833333", recPnL)
+	}
+	if repPnL != recPnL || repTicker != recTicker {
+		t.Errorf("replay PnL %v ticker %v, recorded %v / %v", repPnL, repTicker, recPnL, recTicker)
+	}
+	if *replayed.EntryTxID != *recorded.EntryTxID || *replayed.ExitTxID != *recorded.ExitTxID {
+		t.Errorf("replay txids %s/%s, recorded %s/%s", *replayed.EntryTxID, *replayed.ExitTxID, *recorded.EntryTxID, *recorded.ExitTxID)
+	}
+	if *replayed.ExitPrice != *recorded.ExitPrice || fmt.Sprint(replayed.TradeIDs) != fmt.Sprint(recorded.TradeIDs) {
+		t.Errorf("replay exit %v trades %v, recorded %v / %v", *replayed.ExitPrice, replayed.TradeIDs, *recorded.ExitPrice, recorded.TradeIDs)
+	}
+}
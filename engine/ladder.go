@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"macro-strike-bot/exchange"
+)
+
+// TakeProfitRung scales out Portion of a position once price is Pct percent
+// above entry
+type TakeProfitRung struct {
+	Pct     float64 `json:"pct"`
+	Portion float64 `json:"portion"`
+}
+
+// StrikeExit is one slice of a strike's exit: a ladder rung or the remainder
+type StrikeExit struct {
+	Portion float64 `json:"portion"`
+	Price   float64 `json:"price"`
+	Reason  string  `json:"reason"`
+	TxID    string  `json:"txid,omitempty"`
+}
+
+// ladderEpsilon absorbs rounding when portions add up to the whole position
+const ladderEpsilon = 1e-9
+
+// parseTakeProfitLadder reads TP_LADDER as "pct:portion,..." e.g.
+// "0.5:0.5,1:0.5" sells half at +0.5% and half at +1%. Rungs are sorted by
+// level; portions must be positive and add up to at most 1, and whatever
+// they leave exits at the stop or time limit.
+func parseTakeProfitLadder(v string) ([]TakeProfitRung, error) {
+	var ladder []TakeProfitRung
+	total := 0.0
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pctStr, portionStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not pct:portion", part)
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSpace(pctStr), 64)
+		if err != nil || pct <= 0 || math.IsInf(pct, 0) {
+			return nil, fmt.Errorf("%q: level must be a positive percent", part)
+		}
+		portion, err := strconv.ParseFloat(strings.TrimSpace(portionStr), 64)
+		if err != nil || portion <= 0 || portion > 1 {
+			return nil, fmt.Errorf("%q: portion must be in (0, 1]", part)
+		}
+		total += portion
+		ladder = append(ladder, TakeProfitRung{Pct: pct, Portion: portion})
+	}
+	if total > 1+ladderEpsilon {
+		return nil, fmt.Errorf("portions add up to %.2f, more than the whole position", total)
+	}
+	sort.Slice(ladder, func(i, j int) bool { return ladder[i].Pct < ladder[j].Pct })
+	return ladder, nil
+}
+
+// strikeLadder gives a strike the engine's ladder unless it carries its
+// own. Stablecoin strikes keep their single peg-scaled target: percent
+// rungs around a ~$1 peg never fill.
+func (te *TradingEngine) strikeLadder(strike *MacroStrike) []TakeProfitRung {
+	if len(strike.TakeProfitLadder) == 0 && !te.StablecoinSymbols[strike.Symbol] {
+		strike.TakeProfitLadder = te.TakeProfitLadder
+	}
+	return strike.TakeProfitLadder
+}
+
+// simLadderExit scales a simulated strike out along its ladder. draw is the
+// strike's hit draw: a rung is reached when the draw also clears the hit
+// probability of a target at that rung, so nearer rungs fill first. The
+// remainder keeps the single-exit outcome: singleGross and exitPrice for the
+// whole position, at the time limit if the strike hit or the stop if not.
+// Returns the gross PnL, the portion-weighted exit price and the last exit's
+// reason.
+func (te *TradingEngine) simLadderExit(strike *MacroStrike, ladder []TakeProfitRung, size, draw float64, hit bool, singleGross, exitPrice float64) (float64, float64, string) {
+	gross, filled := 0.0, 0.0
+	strike.Exits = nil
+	for _, rung := range ladder {
+		target := strike.EntryPrice * (1 + rung.Pct/100)
+		if draw >= te.SimHitModel.HitProbability(strike.EntryPrice, target, strike.StopLoss, strike.Confidence) {
+			break
+		}
+		gross += size * rung.Portion * rung.Pct / 100 * float64(strike.Leverage)
+		filled += rung.Portion
+		strike.Exits = append(strike.Exits, StrikeExit{Portion: rung.Portion, Price: target, Reason: ExitTakeProfit})
+	}
+	if rest := 1 - filled; rest > ladderEpsilon {
+		reason := ExitStopLoss
+		if hit {
+			reason = ExitHoldExpired
+		}
+		gross += rest * singleGross
+		strike.Exits = append(strike.Exits, StrikeExit{Portion: rest, Price: exitPrice, Reason: reason})
+	}
+	avgPrice := 0.0
+	for _, e := range strike.Exits {
+		avgPrice += e.Portion * e.Price
+	}
+	return gross, avgPrice, strike.Exits[len(strike.Exits)-1].Reason
+}
+
+// liveLadderHold watches the ticker through a live strike's hold and sells
+// each rung's portion as price reaches it. It returns the volume left for
+// the final exit, the PnL and fees booked by the rungs, the txid of the last
+// rung sold, and why the remainder should exit. The stop is checked on every
+// tick, rungs or not; once the rungs are done or abandoned, liveMonitorHold
+// watches the remainder against the target, stop and deadline. A failed
+// rung ends the ladder; the final exit sells everything still held.
+func (te *TradingEngine) liveLadderHold(ctx context.Context, strike *MacroStrike, pos *openPosition, pair string, buyPrice, filled float64, deadline time.Time, orderTxs *[]string) (float64, float64, float64, string, string) {
+	ex := te.exchange()
+	ladder := te.strikeLadder(strike)
+	remaining := filled
+	var pnl, fees float64
+	var lastTx string
+	_, stop := holdLevels(strike, buyPrice)
+	pollInterval := te.holdPollInterval()
+	next := 0
+	for next < len(ladder) && !te.stopRequested() && ctx.Err() == nil {
+		te.beat()
+		if !te.Clock.Now().Before(deadline) {
+			return remaining, pnl, fees, lastTx, ExitTimeStop
+		}
+		price, err := ex.GetTicker(ctx, pair)
+		if err != nil {
+			te.debugf("strike %d: %s ticker unavailable during hold: %v", strike.ID, pair, err)
+		} else if stop > 0 && price <= stop {
+			return remaining, pnl, fees, lastTx, ExitStopLoss
+		}
+		for err == nil && next < len(ladder) && price >= buyPrice*(1+ladder[next].Pct/100) {
+			rung := ladder[next]
+			next++
+			volume := te.rungVolume(ctx, pair, filled*rung.Portion)
+			if volume <= 0 || volume > remaining {
+				continue
+			}
+			_, span := te.tracer.Start(ctx, "take_profit", "pair", pair, "order.side", strike.Direction.exitSide(), "exit.volume", volume, "rung.pct", rung.Pct)
+			te.orderWAL.Intent(strike.ID, pair, strike.Direction.exitSide(), volume)
+			te.orderProgress(strike.ID, "placing take-profit rung", "")
+			tx, perr := ex.PlaceMarketExit(ctx, pair, volume)
+			if perr != nil {
+				span.Fail(perr)
+				log.Printf("⚠️ %s take-profit rung +%.2f%% for strike %d failed: %v; holding the rest for the final exit", pair, rung.Pct, strike.ID, perr)
+				next = len(ladder)
+				break
+			}
+			*orderTxs = append(*orderTxs, tx)
+			te.orderWAL.Placed(strike.ID, strike.Direction.exitSide(), tx)
+			te.orderPlaced(strike.ID, pair, strike.Direction.exitSide(), tx)
+			sellPrice, fee := price, 0.0
+			if ord, gerr := ex.GetOrder(ctx, tx); gerr == nil {
+				if ord.Price > 0 {
+					sellPrice = ord.Price
+				}
+				fee = ord.Fee
+			}
+			if fee <= 0 {
+				fee = sellPrice * volume * RoundTripFeePct / 2.0
+			}
+			// A market sell fills at once; the position shrinks so a flatten never resells it
+			remaining -= volume
+			te.positionsMu.Lock()
+			pos.Volume = remaining
+			te.positionsMu.Unlock()
+			te.lotLedger.Dispose(te.pairAsset(pair), volume, sellPrice*volume, fee, te.Clock.Now())
+			pnl += (sellPrice - buyPrice) * volume
+			fees += fee
+			lastTx = tx
+			span.SetAttrs("txid", tx, "exit.price", sellPrice)
+			span.End()
+			strike.Exits = append(strike.Exits, StrikeExit{Portion: volume / filled, Price: sellPrice, Reason: ExitTakeProfit, TxID: tx})
+			te.publish(EventOrderFilled, strike, map[string]interface{}{"txid": tx, "side": strike.Direction.exitSide(), "price": sellPrice, "volume": volume})
+			log.Printf("LIVE TAKE PROFIT: %s sold %.8f at %.2f (+%.2f%% rung, txid=%s)", pair, volume, sellPrice, rung.Pct, tx)
+		}
+		if remaining <= lotEpsilon {
+			return remaining, pnl, fees, lastTx, ExitTakeProfit
+		}
+		if next >= len(ladder) {
+			break
+		}
+		wait := deadline.Sub(te.Clock.Now())
+		if wait > pollInterval {
+			wait = pollInterval
+		}
+		te.sleepUnlessStopped(ctx, wait)
+	}
+	// Rungs done or abandoned: the remainder is watched like an unladdered hold
+	return remaining, pnl, fees, lastTx, te.liveMonitorHold(ctx, strike, pair, buyPrice, deadline)
+}
+
+// rungVolume rounds a rung's volume down to the pair's lot increment
+func (te *TradingEngine) rungVolume(ctx context.Context, pair string, volume float64) float64 {
+	if te.exchange().Name() == exchange.Kraken {
+		if info, err := te.pairInfo(ctx, pair); err == nil {
+			return roundVolumeDown(volume, info.LotDecimals)
+		}
+	}
+	return volume
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestParseTakeProfitLadder(t *testing.T) {
+	ladder, err := parseTakeProfitLadder("1:0.25, 0.5:0.5")
+	if err != nil {
+		t.Fatalf("parseTakeProfitLadder: %v", err)
+	}
+	want := []TakeProfitRung{{Pct: 0.5, Portion: 0.5}, {Pct: 1, Portion: 0.25}}
+	if len(ladder) != len(want) || ladder[0] != want[0] || ladder[1] != want[1] {
+		t.Errorf("ladder = %+v, want %+v", ladder, want)
+	}
+	if ladder, err := parseTakeProfitLadder(""); err != nil || ladder != nil {
+		t.Errorf("empty ladder = %+v, %v; want none", ladder, err)
+	}
+	for _, bad := range []string{"0.5", "x:0.5", "0:0.5", "0.5:0", "0.5:1.5", "0.5:0.6,1:0.6"} {
+		if _, err := parseTakeProfitLadder(bad); err == nil {
+			t.Errorf("%q parsed, want an error", bad)
+		}
+	}
+}
+
+func TestSimLadderAggregatesPartialExits(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "7", "TP_LADDER": "0.2:0.5,0.4:0.3"})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+
+	for i := uint64(1); i <= 20; i++ {
+		strike := certainStrike(i, i%2 == 0)
+		strike.Confidence = 0.5
+		pnl, err := te.ExecuteStrike(context.Background(), strike)
+		if err != nil {
+			t.Fatalf("strike %d: %v", i, err)
+		}
+		if len(strike.Exits) == 0 {
+			t.Fatalf("strike %d recorded no exits", i)
+		}
+		portion, price := 0.0, 0.0
+		for _, e := range strike.Exits {
+			portion += e.Portion
+			price += e.Portion * e.Price
+		}
+		if math.Abs(portion-1) > 1e-9 {
+			t.Errorf("strike %d exits cover %.4f of the position", i, portion)
+		}
+		if math.Abs(price-*strike.ExitPrice) > 1e-9 {
+			t.Errorf("strike %d exit price %.4f, want the portion-weighted %.4f", i, *strike.ExitPrice, price)
+		}
+		if *strike.PnL != pnl || (pnl > 0) != (strike.Status == Hit) {
+			t.Errorf("strike %d: pnl %.4f status %v", i, pnl, strike.Status)
+		}
+		if last := strike.Exits[len(strike.Exits)-1]; strike.ExitReason != last.Reason {
+			t.Errorf("strike %d exit reason %q, want the last exit's %q", i, strike.ExitReason, last.Reason)
+		}
+	}
+}
+
+func TestLiveLadderSellsRungThenRemainder(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"2500","fee":"0.1"}}`),
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"c":["2520","1"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["TP1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"TP1":{"status":"closed","vol_exec":"0.02","price":"2520","fee":"0.05"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.02","price":"2490","fee":"0.05"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 100
+	te.TakeProfitLadder = []TakeProfitRung{{Pct: 0.5, Portion: 0.5}}
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if len(strike.Exits) != 2 || strike.Exits[0].TxID != "TP1" || strike.Exits[1].TxID != "SELL1" {
+		t.Fatalf("exits = %+v, want the TP1 rung then SELL1", strike.Exits)
+	}
+	// 0.02·(2520-2500) + 0.02·(2490-2500)
+	if math.Abs(pnl-0.2) > 1e-9 {
+		t.Errorf("pnl = %.6f, want 0.2 across both exits", pnl)
+	}
+	if math.Abs(*strike.ExitPrice-2505) > 1e-9 {
+		t.Errorf("exit price = %.4f, want the portion-weighted 2505", *strike.ExitPrice)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions left open", len(te.openPositions))
+	}
+}
+
+func TestLiveLadderExitsAtStopWhileRungsRest(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"3000","fee":"0.1"}}`),
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"c":["2900","1"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.04","price":"2900","fee":"0.1"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 120
+	te.TakeProfitLadder = []TakeProfitRung{{Pct: 0.5, Portion: 0.5}}
+
+	// Stop at 2940; the first tick is already through it
+	strike := certainStrike(1, true)
+	start := te.Clock.Now()
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if strike.ExitReason != ExitStopLoss || strike.Status != Miss {
+		t.Errorf("exit reason %q status %s, want a stop-loss miss", strike.ExitReason, strike.Status)
+	}
+	if len(strike.Exits) != 0 || *strike.ExitTxID != "SELL1" || *strike.ExitPrice != 2900 {
+		t.Errorf("exits %+v, exit %s @ %.2f, want the whole position sold by SELL1 at 2900", strike.Exits, *strike.ExitTxID, *strike.ExitPrice)
+	}
+	if held := te.Clock.Since(start); held >= exposureLimit(strike) {
+		t.Errorf("held %v, want the stop to cut the hold short of %v", held, exposureLimit(strike))
+	}
+}
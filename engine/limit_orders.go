@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/exchange"
+)
+
+// Live entry order types
+const (
+	EntryOrderMarket = "market"
+	EntryOrderLimit  = "limit"
+)
+
+// limitChasePollInterval is how often a resting chase order is checked for fills
+const limitChasePollInterval = 500 * time.Millisecond
+
+// bookTop returns the current best bid and ask for a Kraken pair, uncached
+func (te *TradingEngine) bookTop(ctx context.Context, pair string) (float64, float64, error) {
+	res, err := te.krakenPublic(ctx, "/0/public/Ticker", url.Values{"pair": {pair}})
+	if err != nil {
+		return 0, 0, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected kraken ticker response")
+	}
+	for _, v := range result {
+		info, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		bid, ask := firstNumeric(info["b"]), firstNumeric(info["a"])
+		if bid > 0 && ask > 0 {
+			return bid, ask, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no book for %s", pair)
+}
+
+// firstNumeric reads the leading price out of a Kraken ticker array
+func firstNumeric(v interface{}) float64 {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) == 0 {
+		return 0
+	}
+	return exchange.ParseNumericField(arr[0])
+}
+
+// placeLimitOrder rests a post-only limit order and returns its txid
+func (te *TradingEngine) placeLimitOrder(ctx context.Context, pair, side string, volume, price float64, info pairInfo) (string, error) {
+	vals := url.Values{}
+	vals.Set("pair", pair)
+	vals.Set("type", side)
+	vals.Set("ordertype", "limit")
+	vals.Set("oflags", "post")
+	vals.Set("price", strconv.FormatFloat(price, 'f', info.PairDecimals, 64))
+	vals.Set("volume", strconv.FormatFloat(volume, 'f', info.LotDecimals, 64))
+
+	res, err := te.krakenPrivateWithRetry(ctx, "/0/private/AddOrder", vals)
+	if err != nil {
+		return "", err
+	}
+	if result, ok := res["result"].(map[string]interface{}); ok {
+		if txids, ok := result["txid"].([]interface{}); ok && len(txids) > 0 {
+			return fmt.Sprintf("%v", txids[0]), nil
+		}
+	}
+	return "", fmt.Errorf("unexpected kraken response")
+}
+
+// cancelOrder cancels a resting order
+func (te *TradingEngine) cancelOrder(ctx context.Context, txid string) error {
+	vals := url.Values{}
+	vals.Set("txid", txid)
+	_, err := te.krakenPrivateWithRetry(ctx, "/0/private/CancelOrder", vals)
+	return err
+}
+
+// chaseLimitOrder works a post-only limit entry at the touch, cancelling and
+// re-placing at the updated book price when it hasn't filled within
+// LimitChaseWait, up to maxChases re-placements. Partial fills carry over
+// between attempts; txid is the last order placed and avgPrice the
+// volume-weighted price over every fill. Any filled volume counts as an
+// entry, even when a later book read or placement fails; if nothing fills
+// the strike is aborted.
+func (te *TradingEngine) chaseLimitOrder(ctx context.Context, pair, side string, usdSize float64, maxChases int) (txid string, filledVol, avgPrice float64, err error) {
+	return te.chaseLimit(ctx, pair, side, usdSize, maxChases, nil)
+}
+
+// chaseLimit is chaseLimitOrder with a hook called for every order placed
+func (te *TradingEngine) chaseLimit(ctx context.Context, pair, side string, usdSize float64, maxChases int, placed func(txid string)) (txid string, filledVol, avgPrice float64, err error) {
+	info, err := te.pairInfo(ctx, pair)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("limit entry needs AssetPairs for %s: %v", pair, err)
+	}
+	// The book is priced in the funding currency
+	rate, err := te.quotePerUSD(ctx)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	var target, filledCost float64
+	// filled returns what has executed so far; a failure after a partial fill
+	// still hands the filled volume back so the caller tracks the position
+	filled := func(cause error) (string, float64, float64, error) {
+		if filledVol <= 0 {
+			return txid, 0, 0, cause
+		}
+		if cause != nil {
+			log.Printf("⚠️ %s limit chase stopped after a partial fill of %.8f: %v", pair, filledVol, cause)
+		}
+		return txid, filledVol, filledCost / filledVol, nil
+	}
+	for attempt := 0; attempt <= maxChases; attempt++ {
+		bid, ask, err := te.bookTop(ctx, pair)
+		if err != nil {
+			return filled(fmt.Errorf("book for %s: %v", pair, err))
+		}
+		price := bid
+		if side == "sell" {
+			price = ask
+		}
+		if target == 0 {
+			target = roundVolumeDown(usdSize*rate/price, info.LotDecimals)
+			if target <= 0 {
+				return "", 0, 0, fmt.Errorf("order of $%.2f rounds to zero volume for %s", usdSize, pair)
+			}
+		}
+		remaining := roundVolumeDown(target-filledVol, info.LotDecimals)
+		if remaining <= 0 {
+			return filled(nil)
+		}
+
+		tx, err := te.placeLimitOrder(ctx, pair, side, remaining, price, info)
+		if err != nil {
+			return filled(err)
+		}
+		txid = tx
+		if placed != nil {
+			placed(txid)
+		}
+		te.debugf("limit chase %d/%d: %s %s %.8f @ %.8f (txid=%s)", attempt, maxChases, pair, side, remaining, price, txid)
+
+		status, volExec := te.waitLimitFill(ctx, txid, te.LimitChaseWait)
+		if status == "open" || status == "pending" || status == "" {
+			// A cancelled ctx must not leave the order resting on the book
+			settle := context.WithoutCancel(ctx)
+			if err := te.cancelOrder(settle, txid); err != nil {
+				log.Printf("⚠️ Cancel of chase order %s failed: %v", txid, err)
+			}
+			// Re-read after the cancel: the order may have filled in the meantime
+			if s, v, err := te.orderStatus(settle, txid); err == nil {
+				volExec = v
+				status = s
+			}
+		}
+		// Post-only orders only ever execute at their limit price
+		filledVol += volExec
+		filledCost += volExec * price
+		if status == "closed" {
+			return filled(nil)
+		}
+	}
+	if filledVol > 0 {
+		log.Printf("⚠️ %s limit entry partially filled after %d chase(s): %.8f of %.8f", pair, maxChases, filledVol, target)
+		return filled(nil)
+	}
+	return txid, 0, 0, fmt.Errorf("limit entry for %s unfilled after %d chase(s)", pair, maxChases)
+}
+
+// waitLimitFill polls an order until it closes, wait elapses or ctx is done,
+// returning the last status and executed volume seen
+func (te *TradingEngine) waitLimitFill(ctx context.Context, txid string, wait time.Duration) (string, float64) {
+	var status string
+	var volExec float64
+	start := te.Clock.Now()
+	for {
+		if s, v, err := te.orderStatus(ctx, txid); err == nil {
+			status, volExec = s, v
+			if status == "closed" || status == "canceled" || status == "expired" {
+				return status, volExec
+			}
+		}
+		if te.Clock.Since(start) >= wait {
+			return status, volExec
+		}
+		if clock.SleepContext(ctx, te.Clock, limitChasePollInterval) != nil {
+			return status, volExec
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// chaseEngine replays a limit chase on XETHZUSD with the pair metadata cached
+// and no resting wait, so each attempt polls once before cancelling
+func chaseEngine(t *testing.T, records ...krakenExchangeRecord) *TradingEngine {
+	t.Helper()
+	te := replayEngine(t, records...)
+	te.pairInfoCache["XETHZUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.LimitChaseWait = 0
+	return te
+}
+
+func TestChaseLimitAveragesPriceOverEveryFill(t *testing.T) {
+	te := chaseEngine(t,
+		// Attempt 1 rests at 100 and fills 4 of 10 before the cancel
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"b":["100.0"],"a":["100.5"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["CHASE1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"CHASE1":{"status":"open","vol_exec":"4.00000000"}}`),
+		krakenReply("/0/private/CancelOrder", `{"count":1}`),
+		krakenReply("/0/private/QueryOrders", `{"CHASE1":{"status":"canceled","vol_exec":"4.00000000"}}`),
+		// Attempt 2 re-places the remaining 6 at 98 and fills
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"b":["98.0"],"a":["98.5"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["CHASE2"]}`),
+		krakenReply("/0/private/QueryOrders", `{"CHASE2":{"status":"closed","vol_exec":"6.00000000","price":"98.0"}}`),
+	)
+
+	txid, filled, avg, err := te.chaseLimitOrder(context.Background(), "XETHZUSD", "buy", 1000, 2)
+	if err != nil {
+		t.Fatalf("chaseLimitOrder: %v", err)
+	}
+	if txid != "CHASE2" || filled != 10 {
+		t.Errorf("txid=%s filled=%.8f, want CHASE2 and 10", txid, filled)
+	}
+	// (4·100 + 6·98) / 10, not the last order's 98
+	if math.Abs(avg-98.8) > 1e-9 {
+		t.Errorf("average price = %.6f, want 98.8", avg)
+	}
+}
+
+func TestChaseLimitKeepsPartialFillWhenReplacementFails(t *testing.T) {
+	te := chaseEngine(t,
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"b":["100.0"],"a":["100.5"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["CHASE1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"CHASE1":{"status":"open","vol_exec":"4.00000000"}}`),
+		krakenReply("/0/private/CancelOrder", `{"count":1}`),
+		krakenReply("/0/private/QueryOrders", `{"CHASE1":{"status":"canceled","vol_exec":"4.00000000"}}`),
+		// The book read for attempt 2 fails
+		krakenExchangeRecord{Path: "/0/public/Ticker", Error: "EService:Unavailable"},
+	)
+
+	txid, filled, avg, err := te.chaseLimitOrder(context.Background(), "XETHZUSD", "buy", 1000, 2)
+	if err != nil {
+		t.Fatalf("a partial fill must be returned as an entry, got error %v", err)
+	}
+	if txid != "CHASE1" || filled != 4 || avg != 100 {
+		t.Errorf("txid=%s filled=%.8f avg=%.4f, want CHASE1, 4 @ 100", txid, filled, avg)
+	}
+}
+
+func TestChaseLimitFailsWhenNothingFilled(t *testing.T) {
+	te := chaseEngine(t,
+		krakenReply("/0/public/Ticker", `{"XETHZUSD":{"b":["100.0"],"a":["100.5"]}}`),
+		krakenExchangeRecord{Path: "/0/private/AddOrder", Error: "EOrder:Insufficient funds"},
+	)
+
+	if _, filled, _, err := te.chaseLimitOrder(context.Background(), "XETHZUSD", "buy", 1000, 2); err == nil || filled != 0 {
+		t.Errorf("filled=%.8f err=%v, want an error with nothing filled", filled, err)
+	}
+}
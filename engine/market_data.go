@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"macro-strike-bot/analysis"
+	"macro-strike-bot/exchange"
+	"macro-strike-bot/exchange/kraken"
+)
+
+// tickerCacheTTL bounds how stale a cached ticker price may be
+const tickerCacheTTL = 5 * time.Second
+
+// tickerQuote is a cached last-trade price for a Kraken pair
+type tickerQuote struct {
+	Price float64
+	At    time.Time
+}
+
+// krakenPublic performs an unauthenticated GET against Kraken's public API.
+// Like private calls, it is captured in record mode and served from the
+// capture in replay mode, so a replayed run never touches the network.
+func (te *TradingEngine) krakenPublic(ctx context.Context, path string, params url.Values) (res map[string]interface{}, err error) {
+	defer func() { te.metrics.KrakenError(err) }()
+	if te.ReplayMode {
+		body, err := te.krakenReplayer.next(path)
+		if err != nil {
+			return nil, err
+		}
+		return kraken.DecodeResponse(body)
+	}
+	req, err := kraken.NewPublicRequest(ctx, te.krakenBaseURL(), path, params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := te.httpClient().Do(req)
+	if err != nil {
+		if te.RecordMode {
+			te.krakenRecorder.record(path, params, nil, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if te.RecordMode {
+		te.krakenRecorder.record(path, params, body, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return kraken.DecodeResponse(body)
+}
+
+// tickerPrice returns the last trade price for a symbol on the trading
+// exchange, served from a short cache
+func (te *TradingEngine) tickerPrice(ctx context.Context, symbol string) (float64, error) {
+	ex := te.exchange()
+	pair := ex.Pair(symbol)
+	if pair == "" {
+		return 0, fmt.Errorf("no %s pair for %s", ex.Name(), symbol)
+	}
+	return te.pairTicker(ctx, pair)
+}
+
+// pairTicker returns a pair's last trade price, served from the same cache
+func (te *TradingEngine) pairTicker(ctx context.Context, pair string) (float64, error) {
+	ex := te.exchange()
+	te.tickerMu.Lock()
+	if q, ok := te.tickerCache[pair]; ok && te.Clock.Since(q.At) < tickerCacheTTL {
+		te.tickerMu.Unlock()
+		return q.Price, nil
+	}
+	te.tickerMu.Unlock()
+
+	price, err := ex.GetTicker(ctx, pair)
+	if err != nil {
+		return 0, err
+	}
+	te.tickerMu.Lock()
+	te.tickerCache[pair] = tickerQuote{Price: price, At: te.Clock.Now()}
+	te.tickerMu.Unlock()
+	return price, nil
+}
+
+// krakenTicker fetches a pair's last trade price from Kraken
+func (te *TradingEngine) krakenTicker(ctx context.Context, pair string) (float64, error) {
+	res, err := te.krakenPublic(ctx, "/0/public/Ticker", url.Values{"pair": {pair}})
+	if err != nil {
+		return 0, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("unexpected kraken ticker response")
+	}
+	// Kraken keys the result by its canonical pair name, which may differ from ours
+	for _, v := range result {
+		info, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		last, ok := info["c"].([]interface{})
+		if !ok || len(last) == 0 {
+			continue
+		}
+		priceStr, _ := last[0].(string)
+		price, err := strconv.ParseFloat(priceStr, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		return price, nil
+	}
+	return 0, fmt.Errorf("no ticker price for %s", pair)
+}
+
+// checkAnalysisPrice rejects strikes whose analysis price strays too far from
+// the live ticker. When required (live mode) a missing ticker is also a skip.
+func (te *TradingEngine) checkAnalysisPrice(ctx context.Context, symbol string, analysisPrice float64, required bool) error {
+	market, err := te.tickerPrice(ctx, symbol)
+	if err != nil {
+		if required {
+			return newSkip(SkipTickerUnavailable, "%s ticker unavailable: %v", symbol, err)
+		}
+		te.debugf("%s ticker unavailable, price cross-check skipped: %v", symbol, err)
+		return nil
+	}
+	if te.QuoteCurrency != defaultQuoteCurrency {
+		// The ticker is in the funding currency; analysis prices are in USD
+		rate, err := te.quotePerUSD(ctx)
+		if err != nil {
+			if required {
+				return newSkip(SkipTickerUnavailable, "%s ticker unavailable: %v", symbol, err)
+			}
+			te.debugf("%s price cross-check skipped: %v", symbol, err)
+			return nil
+		}
+		market /= rate
+	}
+	deviation := math.Abs(analysisPrice-market) / market
+	if deviation > te.PriceDeviationTolerance {
+		log.Printf("⚠️ %s analysis price %.6f deviates %.2f%% from ticker %.6f (tolerance %.2f%%)",
+			symbol, analysisPrice, deviation*100.0, market, te.PriceDeviationTolerance*100.0)
+		return newSkip(SkipPriceDeviation, "%s analysis price %.6f vs ticker %.6f", symbol, analysisPrice, market)
+	}
+	return nil
+}
+
+// candleCacheEntry holds recently loaded candles for a pair
+type candleCacheEntry struct {
+	Candles []analysis.Candle
+	At      time.Time
+}
+
+// loadCandles fetches recent OHLC bars for a symbol, cached for one interval
+func (te *TradingEngine) loadCandles(ctx context.Context, symbol string, intervalMin int) ([]analysis.Candle, error) {
+	pair := te.krakenPair(symbol)
+	if pair == "" {
+		return nil, fmt.Errorf("no kraken pair for %s", symbol)
+	}
+	te.candleMu.Lock()
+	if c, ok := te.candleCache[pair]; ok && te.Clock.Since(c.At) < time.Duration(intervalMin)*time.Minute {
+		te.candleMu.Unlock()
+		return c.Candles, nil
+	}
+	te.candleMu.Unlock()
+
+	res, err := te.krakenPublic(ctx, "/0/public/OHLC", url.Values{
+		"pair":     {pair},
+		"interval": {strconv.Itoa(intervalMin)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected kraken OHLC response")
+	}
+	var candles []analysis.Candle
+	for key, v := range result {
+		if key == "last" {
+			continue
+		}
+		rows, ok := v.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, row := range rows {
+			fields, ok := row.([]interface{})
+			if !ok || len(fields) < 5 {
+				continue
+			}
+			c := analysis.Candle{}
+			if t, ok := fields[0].(float64); ok {
+				c.Time = int64(t)
+			}
+			c.Open = exchange.ParseNumericField(fields[1])
+			c.High = exchange.ParseNumericField(fields[2])
+			c.Low = exchange.ParseNumericField(fields[3])
+			c.Close = exchange.ParseNumericField(fields[4])
+			candles = append(candles, c)
+		}
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles for %s", pair)
+	}
+	te.candleMu.Lock()
+	te.candleCache[pair] = candleCacheEntry{Candles: candles, At: te.Clock.Now()}
+	te.candleMu.Unlock()
+	return candles, nil
+}
+
+// atrStop returns entry - ATRStopMultiple×ATR when ATR stops are enabled and
+// candle data is available; ok is false when the percentage stop should apply
+func (te *TradingEngine) atrStop(ctx context.Context, symbol string, entryPrice float64) (float64, bool) {
+	if te.ATRStopMultiple <= 0 {
+		return 0, false
+	}
+	candles, err := te.loadCandles(ctx, symbol, te.ATRIntervalMin)
+	if err != nil {
+		te.debugf("%s: ATR unavailable, using percentage stop: %v", symbol, err)
+		return 0, false
+	}
+	atr, ok := analysis.AverageTrueRange(candles, te.ATRPeriod)
+	if !ok || atr <= 0 {
+		return 0, false
+	}
+	stop := entryPrice - te.ATRStopMultiple*atr
+	if stop <= 0 || stop >= entryPrice {
+		return 0, false
+	}
+	return stop, true
+}
+
+// pairInfo holds the trading constraints Kraken publishes for a pair
+type pairInfo struct {
+	LotDecimals  int
+	PairDecimals int
+	OrderMin     float64
+	CostMin      float64
+	// Quote asset as Kraken names it, e.g. ZUSD
+	Quote string
+}
+
+// pairInfo returns the cached AssetPairs constraints for a Kraken pair
+func (te *TradingEngine) pairInfo(ctx context.Context, pair string) (pairInfo, error) {
+	te.pairInfoMu.Lock()
+	if info, ok := te.pairInfoCache[pair]; ok {
+		te.pairInfoMu.Unlock()
+		return info, nil
+	}
+	te.pairInfoMu.Unlock()
+
+	res, err := te.krakenPublic(ctx, "/0/public/AssetPairs", url.Values{"pair": {pair}})
+	if err != nil {
+		return pairInfo{}, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return pairInfo{}, fmt.Errorf("unexpected kraken AssetPairs response")
+	}
+	for _, v := range result {
+		raw, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		info := pairInfo{LotDecimals: 8, PairDecimals: 8}
+		if d, ok := raw["lot_decimals"].(float64); ok {
+			info.LotDecimals = int(d)
+		}
+		if d, ok := raw["pair_decimals"].(float64); ok {
+			info.PairDecimals = int(d)
+		}
+		info.OrderMin = exchange.ParseNumericField(raw["ordermin"])
+		info.CostMin = exchange.ParseNumericField(raw["costmin"])
+		info.Quote, _ = raw["quote"].(string)
+		te.pairInfoMu.Lock()
+		te.pairInfoCache[pair] = info
+		te.pairInfoMu.Unlock()
+		return info, nil
+	}
+	return pairInfo{}, fmt.Errorf("no AssetPairs entry for %s", pair)
+}
+
+// roundVolumeDown truncates volume to the pair's lot increment
+func roundVolumeDown(volume float64, lotDecimals int) float64 {
+	scale := math.Pow(10, float64(lotDecimals))
+	// Nudge by a tiny epsilon so 0.3/0.1-style float error doesn't drop a whole lot
+	return math.Floor(volume*scale+1e-9) / scale
+}
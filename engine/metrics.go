@@ -0,0 +1,351 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// counterVec is a Prometheus counter family keyed by label values
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc adds one to the series with the given label values, in label order
+func (c *counterVec) Inc(values ...string) {
+	key := strings.Join(values, "\x00")
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, strings.Split(k, "\x00")), formatMetric(c.values[k]))
+	}
+	c.mu.Unlock()
+}
+
+// histogram is a Prometheus histogram with fixed upper bounds
+type histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	mu      sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(name, help string, buckets ...float64) *histogram {
+	return &histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe records one sample
+func (h *histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	h.writeSeries(w, "")
+}
+
+// writeSeries writes the bucket, sum and count lines; labels, when set, is a
+// rendered "name=\"value\"" list placed ahead of le
+func (h *histogram) writeSeries(w io.Writer, labels string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	prefix, set := "", ""
+	if labels != "" {
+		prefix, set = labels+",", "{"+labels+"}"
+	}
+	for i, le := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", h.name, prefix, formatMetric(le), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n%s_sum%s %s\n%s_count%s %d\n", h.name, prefix, h.count, h.name, set, formatMetric(h.sum), h.name, set, h.count)
+}
+
+// histogramVec is a histogram family split by one label
+type histogramVec struct {
+	name    string
+	help    string
+	label   string
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+func newHistogramVec(name, help, label string, buckets ...float64) *histogramVec {
+	return &histogramVec{name: name, help: help, label: label, buckets: buckets, values: make(map[string]*histogram)}
+}
+
+// Observe records one sample under a label value
+func (hv *histogramVec) Observe(value string, v float64) {
+	hv.mu.Lock()
+	h, ok := hv.values[value]
+	if !ok {
+		h = newHistogram(hv.name, hv.help, hv.buckets...)
+		hv.values[value] = h
+	}
+	hv.mu.Unlock()
+	h.Observe(v)
+}
+
+func (hv *histogramVec) write(w io.Writer) {
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+	keys := make([]string, 0, len(hv.values))
+	for k := range hv.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", hv.name, hv.help, hv.name)
+	for _, k := range keys {
+		hv.values[k].writeSeries(w, strings.Trim(labelPairs([]string{hv.label}, []string{k}), "{}"))
+	}
+}
+
+// writeGauge writes a single unlabelled gauge
+func writeGauge(w io.Writer, name, help string, v float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatMetric(v))
+}
+
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		// Go's quoting escapes backslash, quote and newline as the format requires
+		pairs[i] = n + "=" + strconv.Quote(v)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatMetric(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// EngineMetrics holds the trading loop's Prometheus series. Gauges are read
+// from the engine at scrape time; every method is a no-op on nil.
+type EngineMetrics struct {
+	strikes         *counterVec
+	orders          *counterVec
+	krakenErrors    *counterVec
+	alerts          *counterVec
+	exits           *counterVec
+	strikePnL       *histogram
+	fillLatency     *histogram
+	exposure        *histogram
+	analyzerLatency *histogram
+	stageLatency    *histogramVec
+}
+
+// NewEngineMetrics returns empty metric series
+func NewEngineMetrics() *EngineMetrics {
+	return &EngineMetrics{
+		strikes:         newCounterVec("macro_strikes_total", "Strikes resolved, by final status and symbol.", "status", "symbol"),
+		orders:          newCounterVec("macro_orders_placed_total", "Orders placed on the exchange, by side.", "side"),
+		krakenErrors:    newCounterVec("macro_kraken_errors_total", "Failed Kraken API calls, by error class.", "class"),
+		alerts:          newCounterVec("macro_alerts_total", "Webhook alerts, by kind and delivery outcome.", "kind", "outcome"),
+		exits:           newCounterVec("macro_strike_exits_total", "Completed strikes, by exit reason.", "reason"),
+		strikePnL:       newHistogram("macro_strike_pnl_usd", "Realized PnL per strike in USD.", -1000, -250, -100, -50, -10, -1, 0, 1, 10, 50, 100, 250, 1000),
+		fillLatency:     newHistogram("macro_fill_latency_seconds", "Time from placing a live entry to seeing it filled.", 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30),
+		exposure:        newHistogram("macro_exposure_duration_seconds", "Time from a strike's execution start to its resolution.", 1, 5, 10, 20, 30, 60, 120, 300, 600),
+		analyzerLatency: newHistogram("macro_analyzer_latency_seconds", "Market analysis script run time.", 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+		stageLatency:    newHistogramVec("macro_stage_latency_seconds", "Time each strike spent in a pipeline stage, by stage.", "stage", 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+	}
+}
+
+// metricSymbol bounds the symbol label to the symbols the engine trades
+func metricSymbol(symbol string) string {
+	if isKnownSymbol(symbol) {
+		return symbol
+	}
+	return "other"
+}
+
+// StrikeResolved counts a finished or aborted strike; completed strikes also
+// feed the PnL and exposure histograms
+func (m *EngineMetrics) StrikeResolved(strike *MacroStrike) {
+	if m == nil {
+		return
+	}
+	m.strikes.Inc(strike.Status.String(), metricSymbol(strike.Symbol))
+	if strike.ExitReason != "" {
+		m.exits.Inc(strike.ExitReason)
+	}
+	if strike.PnL != nil {
+		m.strikePnL.Observe(*strike.PnL)
+		m.exposure.Observe(float64(strike.DurationMs) / 1000.0)
+	}
+}
+
+// OrderPlaced counts one order sent to the exchange
+func (m *EngineMetrics) OrderPlaced(side string) {
+	if m == nil {
+		return
+	}
+	m.orders.Inc(side)
+}
+
+// krakenErrorClasses are the error prefixes Kraken documents; anything else is "api"
+var krakenErrorClasses = map[string]bool{
+	"EGeneral": true, "EAPI": true, "EQuery": true, "EOrder": true,
+	"ETrade": true, "EFunding": true, "EService": true, "ESession": true,
+}
+
+// KrakenError counts a failed Kraken call: transport failures, undecodable
+// bodies, or the API's own error class
+func (m *EngineMetrics) KrakenError(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	m.krakenErrors.Inc(krakenErrorClass(err))
+}
+
+func krakenErrorClass(err error) string {
+	var syntaxErr *json.SyntaxError
+	switch msg := err.Error(); {
+	case strings.HasPrefix(msg, "kraken error: ["):
+		class, _, _ := strings.Cut(strings.TrimPrefix(msg, "kraken error: ["), ":")
+		if krakenErrorClasses[class] {
+			return class
+		}
+		return "api"
+	case errors.As(err, &syntaxErr):
+		return "decode"
+	default:
+		return "transport"
+	}
+}
+
+// KrakenErrorCounts returns failed Kraken calls so far by error class
+func (m *EngineMetrics) KrakenErrorCounts() map[string]int64 {
+	if m == nil {
+		return nil
+	}
+	m.krakenErrors.mu.Lock()
+	defer m.krakenErrors.mu.Unlock()
+	counts := make(map[string]int64, len(m.krakenErrors.values))
+	for class, n := range m.krakenErrors.values {
+		counts[class] = int64(n)
+	}
+	return counts
+}
+
+// AlertOutcome counts a webhook alert as sent, failed, dropped or rate_limited
+func (m *EngineMetrics) AlertOutcome(kind, outcome string) {
+	if m == nil {
+		return
+	}
+	m.alerts.Inc(kind, outcome)
+}
+
+// FillLatency records how long a live entry took to fill
+func (m *EngineMetrics) FillLatency(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.fillLatency.Observe(d.Seconds())
+}
+
+// AnalyzerLatency records one market analysis run
+func (m *EngineMetrics) AnalyzerLatency(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.analyzerLatency.Observe(d.Seconds())
+}
+
+// StageLatency records how long one strike spent in a pipeline stage
+func (m *EngineMetrics) StageLatency(stage string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.stageLatency.Observe(stage, d.Seconds())
+}
+
+// WriteMetrics renders every series in the Prometheus text format
+func (te *TradingEngine) WriteMetrics(w io.Writer) {
+	capital := atomic.LoadInt64(&te.Capital)
+	peak := atomic.LoadInt64(&te.PeakCapital)
+	var drawdown float64
+	if peak > 0 && capital < peak {
+		drawdown = float64(peak-capital) / float64(peak) * 100.0
+	}
+	te.positionsMu.Lock()
+	open := len(te.openPositions)
+	te.positionsMu.Unlock()
+	writeGauge(w, "macro_capital_usd", "Current capital in USD.", Money(capital).Dollars())
+	writeGauge(w, "macro_peak_capital_usd", "Peak capital in USD.", Money(peak).Dollars())
+	writeGauge(w, "macro_drawdown_pct", "Current drawdown from peak, percent.", drawdown)
+	writeGauge(w, "macro_consecutive_misses", "Consecutive losing strikes.", float64(atomic.LoadInt64(&te.ConsecutiveMisses)))
+	writeGauge(w, "macro_open_positions", "Live positions not yet confirmed flat.", float64(open))
+	dropped := newCounterVec("macro_events_dropped_total", "Lifecycle events missed by slow subscribers, by subscriber.", "subscriber")
+	for name, n := range te.events.DroppedBy() {
+		dropped.values[name] = float64(n)
+	}
+	dropped.write(w)
+
+	// Skip reasons are a fixed set already counted by recordSkip
+	skips := newCounterVec("macro_skips_total", "Strike setups skipped, by reason.", "reason")
+	for reason, n := range te.SkipCounts() {
+		skips.values[reason] = float64(n)
+	}
+	skips.write(w)
+
+	m := te.metrics
+	if m == nil {
+		return
+	}
+	m.strikes.write(w)
+	m.orders.write(w)
+	m.krakenErrors.write(w)
+	m.alerts.write(w)
+	m.exits.write(w)
+	m.strikePnL.write(w)
+	m.fillLatency.write(w)
+	m.exposure.write(w)
+	m.analyzerLatency.write(w)
+	m.stageLatency.write(w)
+}
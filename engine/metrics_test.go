@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMetricsExposeCampaignCounters(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	unknown := certainStrike(3, true)
+	unknown.Symbol = "PEPE/USDC"
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Err: newSkip(SkipLowConfidence, "scripted skip")},
+		{Strike: certainStrike(2, false)},
+		{Strike: unknown},
+	}}
+	te.ExecuteCampaign(context.Background())
+
+	var buf bytes.Buffer
+	te.WriteMetrics(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE macro_strikes_total counter",
+		`macro_strikes_total{status="hit",symbol="WETH/USDC"} 1`,
+		`macro_strikes_total{status="miss",symbol="WETH/USDC"} 1`,
+		// Symbols outside the configured set share one series
+		`macro_strikes_total{status="hit",symbol="other"} 1`,
+		`macro_skips_total{reason="low_confidence"} 1`,
+		"# TYPE macro_capital_usd gauge",
+		"macro_open_positions 0",
+		`macro_strike_pnl_usd_bucket{le="+Inf"} 3`,
+		"macro_strike_pnl_usd_count 3",
+		"macro_exposure_duration_seconds_count 3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestKrakenErrorClasses(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{errors.New("kraken error: [EOrder:Insufficient funds]"), "EOrder"},
+		{errors.New("kraken error: [EService:Unavailable]"), "EService"},
+		{errors.New("kraken error: [something new]"), "api"},
+		{syntaxErr, "decode"},
+		{errors.New("dial tcp: connection refused"), "transport"},
+	} {
+		if got := krakenErrorClass(tc.err); got != tc.want {
+			t.Errorf("class(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// Money is an amount in cents, the unit the engine keeps capital, PnL and
+// fees in. Converting through it keeps the /100 in one place.
+type Money int64
+
+// FromDollars converts a dollar amount, rounded to the nearest cent
+func FromDollars(usd float64) Money {
+	return Money(math.Round(usd * 100))
+}
+
+// Cents returns the amount in cents
+func (m Money) Cents() int64 { return int64(m) }
+
+// Dollars returns the amount in dollars
+func (m Money) Dollars() float64 { return float64(m) / 100.0 }
+
+// String formats the amount as dollars, e.g. $1234.56 or -$0.05
+func (m Money) String() string {
+	c := int64(m)
+	if c < 0 {
+		return fmt.Sprintf("-$%d.%02d", -c/100, -c%100)
+	}
+	return fmt.Sprintf("$%d.%02d", c/100, c%100)
+}
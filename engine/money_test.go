@@ -0,0 +1,35 @@
+package engine
+
+import "testing"
+
+func TestMoneyString(t *testing.T) {
+	cases := map[Money]string{
+		0:       "$0.00",
+		5:       "$0.05",
+		-5:      "-$0.05",
+		123456:  "$1234.56",
+		-123400: "-$1234.00",
+	}
+	for m, want := range cases {
+		if got := m.String(); got != want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(m), got, want)
+		}
+	}
+}
+
+func TestFromDollarsRoundsToCents(t *testing.T) {
+	cases := map[float64]int64{
+		10:        1000,
+		0.1 + 0.2: 30,
+		-0.004:    0,
+		1234.567:  123457,
+	}
+	for usd, want := range cases {
+		if got := FromDollars(usd).Cents(); got != want {
+			t.Errorf("FromDollars(%v).Cents() = %d, want %d", usd, got, want)
+		}
+	}
+	if got := FromDollars(12.34).Dollars(); got != 12.34 {
+		t.Errorf("round trip gave %v, want 12.34", got)
+	}
+}
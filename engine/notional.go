@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"log"
+)
+
+// reserveNotional books a strike's levered notional against MaxNotionalUSD,
+// reducing it to the remaining headroom or skipping the strike when none is
+// left. Simulated strikes count their levered strike size; live spot orders
+// are unlevered and count their order size. The strike also takes one of its
+// symbol's MaxPositionsPerSymbol slots. The booking lasts until
+// releaseNotional.
+func (te *TradingEngine) reserveNotional(strike *MacroStrike, usd float64) (float64, error) {
+	te.notionalMu.Lock()
+	defer te.notionalMu.Unlock()
+	if te.MaxPositionsPerSymbol > 0 {
+		if n := te.symbolPositionsLocked(strike.Symbol); n >= te.MaxPositionsPerSymbol {
+			log.Printf("⏭️ %s skipped: %d of %d positions already open", strike.Symbol, n, te.MaxPositionsPerSymbol)
+			return 0, newSkip(SkipSymbolPositions, "%s already has %d of %d positions open", strike.Symbol, n, te.MaxPositionsPerSymbol)
+		}
+	}
+	if te.MaxNotionalUSD > 0 {
+		headroom := te.MaxNotionalUSD - te.openNotionalLocked()
+		if headroom < 0.01 {
+			log.Printf("⏭️ %s $%.2f notional skipped: $%.2f of the $%.2f cap already open",
+				strike.Symbol, usd, te.MaxNotionalUSD-headroom, te.MaxNotionalUSD)
+			return 0, newSkip(SkipNotionalCap, "%s $%.2f notional with no headroom under the $%.2f cap", strike.Symbol, usd, te.MaxNotionalUSD)
+		}
+		if usd > headroom {
+			log.Printf("✂️ %s notional reduced from $%.2f to $%.2f to stay under the $%.2f cap",
+				strike.Symbol, usd, headroom, te.MaxNotionalUSD)
+			usd = headroom
+		}
+	}
+	te.openNotional[strike.ID] = usd
+	te.openSymbols[strike.ID] = strike.Symbol
+	return usd, nil
+}
+
+// releaseNotional frees a strike's booking; releasing twice is harmless
+func (te *TradingEngine) releaseNotional(strikeID uint64) {
+	te.notionalMu.Lock()
+	delete(te.openNotional, strikeID)
+	delete(te.openSymbols, strikeID)
+	te.notionalMu.Unlock()
+}
+
+// checkSymbolPositions skips generating a strike on a symbol that already has
+// MaxPositionsPerSymbol strikes booked
+func (te *TradingEngine) checkSymbolPositions(strike *MacroStrike) error {
+	if te.MaxPositionsPerSymbol <= 0 {
+		return nil
+	}
+	if n := te.SymbolPositions(strike.Symbol); n >= te.MaxPositionsPerSymbol {
+		return newSkip(SkipSymbolPositions, "%s already has %d of %d positions open", strike.Symbol, n, te.MaxPositionsPerSymbol)
+	}
+	return nil
+}
+
+// SymbolPositions is the number of strikes currently booked on symbol
+func (te *TradingEngine) SymbolPositions(symbol string) int {
+	te.notionalMu.Lock()
+	defer te.notionalMu.Unlock()
+	return te.symbolPositionsLocked(symbol)
+}
+
+func (te *TradingEngine) symbolPositionsLocked(symbol string) int {
+	n := 0
+	for _, s := range te.openSymbols {
+		if s == symbol {
+			n++
+		}
+	}
+	return n
+}
+
+// releaseNotionalUnlessOpen frees a live strike's booking unless its
+// position is still awaiting a confirmed exit; releasePosition frees it then
+func (te *TradingEngine) releaseNotionalUnlessOpen(strikeID uint64) {
+	te.positionsMu.Lock()
+	_, open := te.openPositions[strikeID]
+	te.positionsMu.Unlock()
+	if !open {
+		te.releaseNotional(strikeID)
+	}
+}
+
+// OpenNotional is the levered notional currently booked across open strikes
+func (te *TradingEngine) OpenNotional() float64 {
+	te.notionalMu.Lock()
+	defer te.notionalMu.Unlock()
+	return te.openNotionalLocked()
+}
+
+func (te *TradingEngine) openNotionalLocked() float64 {
+	total := 0.0
+	for _, usd := range te.openNotional {
+		total += usd
+	}
+	return total
+}
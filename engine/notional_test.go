@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestMaxNotionalReducesThenSkipsStrikes(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "MAX_NOTIONAL_USD": "50"})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+
+	strike := certainStrike(1, true)
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if strike.StrikeForce != 50 {
+		t.Errorf("levered strike size = %.2f, want it cut to the $50 cap", strike.StrikeForce)
+	}
+	if open := te.Stats().OpenNotional; open != 0 {
+		t.Errorf("open notional after the exit = %.2f, want 0", open)
+	}
+
+	// Another strike's exposure still open leaves no room
+	te.openNotional[99] = 50
+	if got := te.Stats().OpenNotional; got != 50 {
+		t.Errorf("/stats open notional = %.2f, want 50", got)
+	}
+	_, err := te.ExecuteStrike(context.Background(), certainStrike(2, true))
+	var skip *skipError
+	if !errors.As(err, &skip) || skip.Reason != SkipNotionalCap {
+		t.Errorf("ExecuteStrike at the cap = %v, want a %s skip", err, SkipNotionalCap)
+	}
+}
+
+func TestLiveNotionalHeldUntilPositionReleased(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"MAX_NOTIONAL_USD": "100"})
+	strike := certainStrike(1, true)
+	if _, err := te.reserveNotional(strike, 60); err != nil {
+		t.Fatal(err)
+	}
+	te.trackPosition(1, "ETHUSD", 0.02, "BUY1")
+
+	// The strike returned with its exit unconfirmed: the exposure still counts
+	te.releaseNotionalUnlessOpen(1)
+	other := certainStrike(2, true)
+	other.Symbol = "WBTC/USDC"
+	if got, _ := te.reserveNotional(other, 60); got != 40 {
+		t.Errorf("second strike booked $%.2f, want the $40 left under the cap", got)
+	}
+	te.releaseNotional(2)
+
+	te.releasePosition(1)
+	if open := te.OpenNotional(); open != 0 {
+		t.Errorf("open notional after the position closed = %.2f, want 0", open)
+	}
+}
+
+func TestSymbolPositionLimitHeldUntilPositionReleased(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{})
+	if te.MaxPositionsPerSymbol != 1 {
+		t.Fatalf("MaxPositionsPerSymbol = %d, want the default of 1", te.MaxPositionsPerSymbol)
+	}
+	if _, err := te.reserveNotional(certainStrike(1, true), 60); err != nil {
+		t.Fatal(err)
+	}
+	te.trackPosition(1, "ETHUSD", 0.02, "BUY1")
+	te.releaseNotionalUnlessOpen(1)
+
+	// Generation and execution both pass over the symbol while it is held
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: certainStrike(2, true)}}}
+	var skip *skipError
+	if _, err := te.GenerateStrike(context.Background()); !errors.As(err, &skip) || skip.Reason != SkipSymbolPositions {
+		t.Errorf("GenerateStrike on a held symbol = %v, want a %s skip", err, SkipSymbolPositions)
+	}
+	if _, err := te.reserveNotional(certainStrike(3, true), 60); !errors.As(err, &skip) || skip.Reason != SkipSymbolPositions {
+		t.Errorf("reserveNotional on a held symbol = %v, want a %s skip", err, SkipSymbolPositions)
+	}
+	other := certainStrike(4, true)
+	other.Symbol = "WBTC/USDC"
+	if _, err := te.reserveNotional(other, 60); err != nil {
+		t.Errorf("another symbol was refused: %v", err)
+	}
+
+	te.releasePosition(1)
+	if n := te.SymbolPositions("WETH/USDC"); n != 0 {
+		t.Errorf("WETH/USDC positions after the release = %d, want 0", n)
+	}
+	if _, err := te.reserveNotional(certainStrike(5, true), 60); err != nil {
+		t.Errorf("symbol still refused after its position closed: %v", err)
+	}
+}
+
+func TestSymbolPositionLimitConfig(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"MAX_POSITIONS_PER_SYMBOL": "0"})
+	for id := uint64(1); id <= 3; id++ {
+		if _, err := te.reserveNotional(certainStrike(id, true), 10); err != nil {
+			t.Fatalf("strike %d refused with the limit disabled: %v", id, err)
+		}
+	}
+	if n := te.SymbolPositions("WETH/USDC"); n != 3 {
+		t.Errorf("WETH/USDC positions = %d, want 3", n)
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "MAX_POSITIONS_PER_SYMBOL": "-1"}).ValidateConfig(); err == nil {
+		t.Error("a negative MAX_POSITIONS_PER_SYMBOL was accepted")
+	}
+}
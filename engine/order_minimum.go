@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+
+	"macro-strike-bot/exchange"
+)
+
+// orderMinimumBumpMargin lifts a bumped order clear of the minimum so lot
+// rounding can't drop it back below
+const orderMinimumBumpMargin = 1.01
+
+// checkOrderMinimum compares a live entry against the pair's published order
+// and cost minimums. An undersized order is a skip, or with AUTO_BUMP_MIN=1
+// is raised to the minimum; either way the choice is logged. Without
+// AssetPairs data the order goes out as sized and Kraken has the final word.
+func (te *TradingEngine) checkOrderMinimum(ctx context.Context, pair string, usdSize, price float64) (float64, error) {
+	if te.exchange().Name() != exchange.Kraken || price <= 0 {
+		return usdSize, nil
+	}
+	info, err := te.pairInfo(ctx, pair)
+	if err != nil {
+		return usdSize, nil
+	}
+	// costmin is in the pair's quote currency, the funding currency
+	costMin := info.CostMin
+	if rate, err := te.quotePerUSD(ctx); err == nil {
+		costMin /= rate
+	}
+	minUSD := math.Max(info.OrderMin*price, costMin)
+	if minUSD <= 0 || usdSize >= minUSD {
+		return usdSize, nil
+	}
+	if te.AutoBumpMin {
+		bumped := minUSD * orderMinimumBumpMargin
+		log.Printf("⬆️ %s order $%.2f below the $%.2f minimum; bumped to $%.2f (AUTO_BUMP_MIN=1)", pair, usdSize, minUSD, bumped)
+		return bumped, nil
+	}
+	log.Printf("⏭️ %s order $%.2f below the $%.2f minimum; skipping (set AUTO_BUMP_MIN=1 to bump)", pair, usdSize, minUSD)
+	return 0, newSkip(SkipOrderMinimum, "%s order $%.2f below the $%.2f minimum", pair, usdSize, minUSD)
+}
+
+// isOrderMinimumError reports whether Kraken refused an order for being
+// under the pair's volume or cost minimum
+func isOrderMinimumError(err error) bool {
+	if !isKrakenAPIError(err) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "EOrder:Order minimum not met") || strings.Contains(msg, "EOrder:Cost minimum not met")
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestOrderBelowPairMinimumIsSkipped(t *testing.T) {
+	// No replies recorded: any Kraken call fails the strike with an error
+	te := replayEngine(t)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2, OrderMin: 0.002}
+	te.OrderUSDSize = 1
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	_, err := te.ExecuteStrike(context.Background(), strike)
+	var skip *skipError
+	if !errors.As(err, &skip) || skip.Reason != SkipOrderMinimum {
+		t.Fatalf("ExecuteStrike = %v, want an %s skip", err, SkipOrderMinimum)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions opened for a skipped order", len(te.openPositions))
+	}
+}
+
+func TestAutoBumpMinRaisesOrderToPairMinimum(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.00202","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.00202","price":"2510"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2, OrderMin: 0.002}
+	te.OrderUSDSize = 1
+	te.AutoBumpMin = true
+	te.Stop()
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	placed := false
+	for _, p := range strike.OrderPayloads {
+		if p.Path != "/0/private/AddOrder" {
+			continue
+		}
+		placed = true
+		if vol, _ := strconv.ParseFloat(p.Request["volume"], 64); vol < 0.002 {
+			t.Errorf("entry volume %s, want at least the 0.002 pair minimum", p.Request["volume"])
+		}
+	}
+	if !placed {
+		t.Error("no entry AddOrder captured")
+	}
+}
+
+func TestKrakenOrderMinimumRejectionIsSkipped(t *testing.T) {
+	te := replayEngine(t, krakenExchangeRecord{Path: "/0/private/AddOrder", Response: json.RawMessage(`{"error":["EOrder:Order minimum not met"]}`)})
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	_, err := te.ExecuteStrike(context.Background(), strike)
+	var skip *skipError
+	if !errors.As(err, &skip) || skip.Reason != SkipOrderMinimum {
+		t.Fatalf("ExecuteStrike = %v, want an %s skip", err, SkipOrderMinimum)
+	}
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// OrderPayload is one redacted private API exchange about a strike's order
+type OrderPayload struct {
+	Time     int64             `json:"time"` // unix milliseconds
+	Path     string            `json:"path"`
+	Request  map[string]string `json:"request"`
+	Response json.RawMessage   `json:"response,omitempty"`
+}
+
+// redactedKrakenFields never leave the process: the nonce is replayable
+// signing input and otp is a credential. API-Sign travels as a header and is
+// never part of the form data.
+var redactedKrakenFields = map[string]bool{"nonce": true, "otp": true}
+
+// redactKrakenRequest flattens form data for storage without signing material
+func redactKrakenRequest(data url.Values) map[string]string {
+	req := make(map[string]string, len(data))
+	for k := range data {
+		if redactedKrakenFields[strings.ToLower(k)] || strings.Contains(strings.ToLower(k), "sign") {
+			continue
+		}
+		req[k] = data.Get(k)
+	}
+	return req
+}
+
+// orderPaths are the private endpoints whose traffic is kept with strikes
+var orderPaths = map[string]bool{
+	"/0/private/AddOrder":      true,
+	"/0/private/AddOrderBatch": true,
+	"/0/private/QueryOrders":   true,
+	"/0/private/CancelOrder":   true,
+}
+
+// captureOrderPayload holds an order exchange until the owning strike claims
+// it, keyed by txid: the new orders for AddOrder and AddOrderBatch, the
+// queried one otherwise
+func (te *TradingEngine) captureOrderPayload(path string, data url.Values, body []byte) {
+	if !orderPaths[path] || !json.Valid(body) {
+		return
+	}
+	p := OrderPayload{Time: te.Clock.Now().UnixMilli(), Path: path, Request: redactKrakenRequest(data), Response: json.RawMessage(body)}
+	var txids []string
+	if path == "/0/private/AddOrder" {
+		var res struct {
+			Result struct {
+				TxID []string `json:"txid"`
+			} `json:"result"`
+		}
+		json.Unmarshal(body, &res)
+		txids = res.Result.TxID
+	} else if path == "/0/private/AddOrderBatch" {
+		var res struct {
+			Result struct {
+				Orders []struct {
+					TxID string `json:"txid"`
+				} `json:"orders"`
+			} `json:"result"`
+		}
+		json.Unmarshal(body, &res)
+		for _, o := range res.Result.Orders {
+			if o.TxID != "" {
+				txids = append(txids, o.TxID)
+			}
+		}
+	} else if tx := data.Get("txid"); tx != "" {
+		txids = strings.Split(tx, ",")
+	}
+	if len(txids) == 0 {
+		return
+	}
+	te.payloadMu.Lock()
+	defer te.payloadMu.Unlock()
+	for _, tx := range txids {
+		te.orderPayloads[tx] = append(te.orderPayloads[tx], p)
+	}
+}
+
+// takeOrderPayloads removes and returns the payloads captured for txids,
+// oldest first; an exchange shared by several txids is returned once
+func (te *TradingEngine) takeOrderPayloads(txids ...string) []OrderPayload {
+	te.payloadMu.Lock()
+	defer te.payloadMu.Unlock()
+	var out []OrderPayload
+	seen := make(map[string]bool)
+	for _, tx := range txids {
+		for _, p := range te.orderPayloads[tx] {
+			key := p.Path + "\x00" + string(p.Response)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, p)
+		}
+		delete(te.orderPayloads, tx)
+	}
+	return out
+}
+
+// orderTradeIDs returns the trade IDs Kraken matched against an order
+func (te *TradingEngine) orderTradeIDs(ctx context.Context, txid string) ([]string, error) {
+	vals := url.Values{}
+	vals.Set("txid", txid)
+	vals.Set("trades", "true")
+	res, err := te.krakenPrivateWithRetry(ctx, "/0/private/QueryOrders", vals)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := res["result"].(map[string]interface{})
+	info, _ := result[txid].(map[string]interface{})
+	raw, _ := info["trades"].([]interface{})
+	ids := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// attachOrderDetails records trade IDs and the captured order traffic on a
+// completed live strike
+func (te *TradingEngine) attachOrderDetails(ctx context.Context, strike *MacroStrike, txids []string) {
+	if lister, ok := te.exchange().(tradeIDLister); ok {
+		for _, tx := range txids {
+			ids, err := lister.OrderTradeIDs(ctx, tx)
+			if err != nil {
+				te.debugf("trade IDs for %s unavailable: %v", tx, err)
+				continue
+			}
+			strike.TradeIDs = append(strike.TradeIDs, ids...)
+		}
+	}
+	payloads := te.takeOrderPayloads(txids...)
+	sort.SliceStable(payloads, func(i, j int) bool { return payloads[i].Time < payloads[j].Time })
+	strike.OrderPayloads = payloads
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOrderPayloadsAreRedactedAndClaimedByTxid(t *testing.T) {
+	te := NewTradingEngine()
+	req := url.Values{"pair": {"ETHUSD"}, "type": {"buy"}, "nonce": {"1700000000000"}, "otp": {"123456"}}
+	te.captureOrderPayload("/0/private/AddOrder", req, []byte(`{"error":[],"result":{"txid":["OABC-1"]}}`))
+	te.captureOrderPayload("/0/private/QueryOrders", url.Values{"txid": {"OABC-1"}, "nonce": {"1700000000001"}},
+		[]byte(`{"error":[],"result":{"OABC-1":{"status":"closed"}}}`))
+	te.captureOrderPayload("/0/private/Balance", url.Values{}, []byte(`{"error":[],"result":{}}`))
+
+	got := te.takeOrderPayloads("OABC-1")
+	if len(got) != 2 {
+		t.Fatalf("want 2 payloads, got %+v", got)
+	}
+	for _, p := range got {
+		data, _ := json.Marshal(p)
+		if strings.Contains(string(data), "nonce") || strings.Contains(string(data), "123456") {
+			t.Errorf("payload not redacted: %s", data)
+		}
+	}
+	if got[0].Request["pair"] != "ETHUSD" {
+		t.Errorf("order fields should be kept: %+v", got[0].Request)
+	}
+	if again := te.takeOrderPayloads("OABC-1"); len(again) != 0 {
+		t.Errorf("payloads should be claimed once, got %+v", again)
+	}
+}
+
+func TestSimStrikeSerializesOrderDetailsAsNull(t *testing.T) {
+	data, err := json.Marshal(&MacroStrike{ID: 1, Symbol: "WETH/USDC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"entry_txid":null`, `"exit_txid":null`, `"trade_ids":null`, `"order_payloads":null`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("missing %s in %s", field, data)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// orderWALVersion is bumped whenever the WAL record layout changes incompatibly
+const orderWALVersion = 1
+
+// WAL operations
+const (
+	walIntent   = "intent"
+	walPlaced   = "placed"
+	walResolved = "resolved"
+)
+
+// orderWALRecord is one line of the order write-ahead log
+type orderWALRecord struct {
+	Version  int     `json:"v"`
+	Op       string  `json:"op"`
+	RunID    string  `json:"run_id"`
+	StrikeID uint64  `json:"strike_id"`
+	Pair     string  `json:"pair,omitempty"`
+	Side     string  `json:"side,omitempty"`
+	Size     float64 `json:"size,omitempty"`
+	TxID     string  `json:"txid,omitempty"`
+	Time     int64   `json:"ts"`
+}
+
+// walEntry is the folded view of one strike's unresolved live orders
+type walEntry struct {
+	RunID    string
+	StrikeID uint64
+	Pair     string
+	BuyTxs   []string
+	SellTxs  []string
+}
+
+// OrderWAL is an fsync'd append-only record of live orders between submission
+// and a confirmed exit. Anything left unresolved at startup was in flight when
+// the previous process died and must be reconciled against Kraken.
+type OrderWAL struct {
+	mu    sync.Mutex
+	file  *os.File
+	runID string
+	// now stamps records; the engine points it at its Clock
+	now func() time.Time
+}
+
+// walKey identifies a strike across runs, since strike IDs restart each run
+type walKey struct {
+	RunID    string
+	StrikeID uint64
+}
+
+// OpenOrderWAL loads path, compacts it down to the unresolved entries and
+// opens it for appending records of runID. The unresolved entries are
+// returned oldest first.
+func OpenOrderWAL(path, runID string) (*OrderWAL, []walEntry, error) {
+	pending, kept, err := readOrderWAL(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	for _, rec := range kept {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return nil, nil, fmt.Errorf("compact: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &OrderWAL{file: f, runID: runID, now: time.Now}, pending, nil
+}
+
+// readOrderWAL folds the log into unresolved entries, also returning the raw
+// records that belong to them
+func readOrderWAL(path string) ([]walEntry, []orderWALRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[walKey]*walEntry)
+	records := make(map[walKey][]orderWALRecord)
+	var order []walKey
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var rec orderWALRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			// A torn final write from a crash is expected; anything earlier is corruption
+			log.Printf("⚠️ Order WAL line %d unreadable, ignoring: %v", line, err)
+			continue
+		}
+		if rec.Version != orderWALVersion {
+			return nil, nil, fmt.Errorf("line %d: unsupported WAL version %d", line, rec.Version)
+		}
+		key := walKey{rec.RunID, rec.StrikeID}
+		if rec.Op == walResolved {
+			delete(entries, key)
+			delete(records, key)
+			continue
+		}
+		e, ok := entries[key]
+		if !ok {
+			e = &walEntry{RunID: rec.RunID, StrikeID: rec.StrikeID}
+			entries[key] = e
+			order = append(order, key)
+		}
+		if rec.Pair != "" {
+			e.Pair = rec.Pair
+		}
+		if rec.Op == walPlaced && rec.TxID != "" {
+			if rec.Side == "sell" {
+				e.SellTxs = append(e.SellTxs, rec.TxID)
+			} else {
+				e.BuyTxs = append(e.BuyTxs, rec.TxID)
+			}
+		}
+		records[key] = append(records[key], rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var pending []walEntry
+	var kept []orderWALRecord
+	for _, key := range order {
+		e, ok := entries[key]
+		if !ok {
+			continue
+		}
+		// A key resolved and then reused would otherwise appear twice
+		delete(entries, key)
+		pending = append(pending, *e)
+		kept = append(kept, records[key]...)
+	}
+	return pending, kept, nil
+}
+
+// append writes and fsyncs one record
+func (w *OrderWAL) append(rec orderWALRecord) {
+	if w == nil {
+		return
+	}
+	rec.Version = orderWALVersion
+	if rec.RunID == "" {
+		rec.RunID = w.runID
+	}
+	rec.Time = w.now().UnixMilli()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		log.Printf("🚨 Order WAL write failed: %v", err)
+		return
+	}
+	if err := w.file.Sync(); err != nil {
+		log.Printf("🚨 Order WAL fsync failed: %v", err)
+	}
+}
+
+// Intent records an order about to be submitted; size is USD for entries and
+// base volume for exits
+func (w *OrderWAL) Intent(strikeID uint64, pair, side string, size float64) {
+	w.append(orderWALRecord{Op: walIntent, StrikeID: strikeID, Pair: pair, Side: side, Size: size})
+}
+
+// Placed records the txid Kraken assigned to a submitted order
+func (w *OrderWAL) Placed(strikeID uint64, side, txid string) {
+	w.append(orderWALRecord{Op: walPlaced, StrikeID: strikeID, Side: side, TxID: txid})
+}
+
+// Resolved marks a strike's live orders as settled
+func (w *OrderWAL) Resolved(strikeID uint64) {
+	if w == nil {
+		return
+	}
+	w.resolve(w.runID, strikeID)
+}
+
+// resolve marks a strike from any run as settled
+func (w *OrderWAL) resolve(runID string, strikeID uint64) {
+	w.append(orderWALRecord{Op: walResolved, RunID: runID, StrikeID: strikeID})
+}
+
+// Close closes the WAL file
+func (w *OrderWAL) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// ReconcileOrderWAL settles the order WAL as a campaign does before its first
+// strike, returning how many unresolved strikes it found
+func (te *TradingEngine) ReconcileOrderWAL(ctx context.Context) int {
+	pending := len(te.walPending)
+	te.reconcileOrderWAL(ctx)
+	return pending
+}
+
+// reconcileOrderWAL settles orders left unresolved by a previous process:
+// resting entries are cancelled, filled volume not yet sold is adopted as an
+// open position and flattened, and settled entries are marked resolved.
+func (te *TradingEngine) reconcileOrderWAL(ctx context.Context) {
+	if len(te.walPending) == 0 {
+		return
+	}
+	if !te.LiveTrading {
+		log.Printf("⚠️ Order WAL has %d unresolved strike(s); enable LIVE_TRADING to reconcile them", len(te.walPending))
+		return
+	}
+	log.Printf("♻️ Reconciling %d unresolved strike(s) from the order WAL", len(te.walPending))
+	var adopted []walEntry
+	for _, e := range te.walPending {
+		var bought, sold float64
+		queryFailed := false
+		for _, tx := range e.BuyTxs {
+			status, volExec, err := te.orderStatus(ctx, tx)
+			if err != nil {
+				log.Printf("⚠️ WAL: could not query entry %s for strike %d: %v", tx, e.StrikeID, err)
+				queryFailed = true
+				continue
+			}
+			if status == "open" || status == "pending" {
+				if err := te.exchange().CancelOrder(ctx, tx); err != nil {
+					log.Printf("⚠️ WAL: cancel of entry %s failed: %v", tx, err)
+				}
+			}
+			bought += volExec
+		}
+		for _, tx := range e.SellTxs {
+			_, volExec, err := te.orderStatus(ctx, tx)
+			if err != nil {
+				log.Printf("⚠️ WAL: could not query exit %s for strike %d: %v", tx, e.StrikeID, err)
+				queryFailed = true
+				continue
+			}
+			sold += volExec
+		}
+		if queryFailed {
+			// Leave it in the WAL so the next start tries again
+			continue
+		}
+		if len(e.BuyTxs) == 0 {
+			log.Printf("🚨 WAL: strike %d (run %s) recorded an entry intent on %s but no txid; check Kraken trade history", e.StrikeID, e.RunID, e.Pair)
+		}
+		remaining := bought - sold
+		if remaining <= 0 {
+			log.Printf("WAL: strike %d already flat (bought %.8f, sold %.8f)", e.StrikeID, bought, sold)
+			te.orderWAL.resolve(e.RunID, e.StrikeID)
+			continue
+		}
+		entryTx := ""
+		if len(e.BuyTxs) > 0 {
+			entryTx = e.BuyTxs[len(e.BuyTxs)-1]
+		}
+		log.Printf("WAL: adopting %.8f %s left open by strike %d", remaining, e.Pair, e.StrikeID)
+		te.trackPosition(e.StrikeID, e.Pair, remaining, entryTx)
+		if asset := te.pairAsset(e.Pair); !te.lotLedger.Holds(asset, e.StrikeID) {
+			te.lotLedger.AcquireUnknownBasis(asset, e.StrikeID, remaining)
+		}
+		adopted = append(adopted, e)
+	}
+	te.walPending = nil
+	if len(adopted) == 0 {
+		return
+	}
+	te.flattenOpenPositions(ctx, "WAL reconciliation")
+	te.positionsMu.Lock()
+	defer te.positionsMu.Unlock()
+	for _, e := range adopted {
+		if _, open := te.openPositions[e.StrikeID]; !open {
+			te.orderWAL.resolve(e.RunID, e.StrikeID)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOrderWALKeepsOnlyUnresolvedStrikes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	w, pending, err := OpenOrderWAL(path, "run-a")
+	if err != nil {
+		t.Fatalf("OpenOrderWAL: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("fresh WAL has %d pending entries", len(pending))
+	}
+	w.Intent(1, "XETHZUSD", "buy", 25)
+	w.Placed(1, "buy", "TX-1")
+	w.Intent(1, "XETHZUSD", "sell", 0.01)
+	w.Placed(1, "sell", "TX-2")
+	w.Resolved(1)
+	w.Intent(2, "XXBTZUSD", "buy", 25)
+	w.Placed(2, "buy", "TX-3")
+	w.Intent(2, "XXBTZUSD", "sell", 0.0005)
+	w.Placed(2, "sell", "TX-4")
+	w.Close()
+
+	// Same strike ID in a later run must not be confused with run-a's strike 2
+	w, pending, err = OpenOrderWAL(path, "run-b")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	w.Intent(2, "XLTCZUSD", "buy", 25)
+	w.Resolved(2)
+	w.Close()
+
+	_, pending, err = OpenOrderWAL(path, "run-c")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("want 1 pending entry, got %d: %+v", len(pending), pending)
+	}
+	e := pending[0]
+	if e.RunID != "run-a" || e.StrikeID != 2 || e.Pair != "XXBTZUSD" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if len(e.BuyTxs) != 1 || e.BuyTxs[0] != "TX-3" || len(e.SellTxs) != 1 || e.SellTxs[0] != "TX-4" {
+		t.Errorf("unexpected txids buy=%v sell=%v", e.BuyTxs, e.SellTxs)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "TX-1") {
+		t.Error("resolved strike was not compacted away")
+	}
+}
+
+func TestOrderWALRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	if err := os.WriteFile(path, []byte(`{"v":99,"op":"intent","run_id":"r","strike_id":1,"ts":0}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := OpenOrderWAL(path, "r"); err == nil {
+		t.Fatal("expected an error for an unknown WAL version")
+	}
+}
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"macro-strike-bot/report"
+)
+
+// parquetRowGroupSize is how many rows are buffered before a row group is
+// flushed, which bounds memory for long sweeps
+const parquetRowGroupSize = 10000
+
+// parquetStrike is the typed Parquet schema for a completed strike. Columns
+// mirror csvColumns; exit and pnl are null when unset.
+type parquetStrike struct {
+	ID         int64     `parquet:"id"`
+	Timestamp  time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	Symbol     string    `parquet:"symbol,dict"`
+	StrikeType string    `parquet:"strike_type,dict"`
+	Side       string    `parquet:"side,dict"`
+	Entry      float64   `parquet:"entry"`
+	Exit       *float64  `parquet:"exit,optional"`
+	Stop       float64   `parquet:"stop"`
+	Target     float64   `parquet:"target"`
+	Size       float64   `parquet:"size"`
+	Leverage   int64     `parquet:"leverage"`
+	Confidence float64   `parquet:"confidence"`
+	Fees       float64   `parquet:"fees"`
+	Funding    float64   `parquet:"funding"`
+	PnL        *float64  `parquet:"pnl,optional"`
+	Status     string    `parquet:"status,dict"`
+	ExitReason string    `parquet:"exit_reason,dict"`
+	DurationMs int64     `parquet:"duration_ms"`
+}
+
+// parquetEquityPoint is the typed Parquet schema for the equity curve
+type parquetEquityPoint struct {
+	Trade   int64     `parquet:"trade"`
+	Time    time.Time `parquet:"time,timestamp(millisecond)"`
+	Capital float64   `parquet:"capital"`
+}
+
+// parquetStrikeRow converts a strike to its Parquet row
+func parquetStrikeRow(s *MacroStrike) parquetStrike {
+	return parquetStrike{
+		ID:         int64(s.ID),
+		Timestamp:  time.Unix(s.Timestamp, 0).UTC(),
+		Symbol:     s.Symbol,
+		StrikeType: s.StrikeType.String(),
+		Side:       strikeSide(s),
+		Entry:      s.EntryPrice,
+		Exit:       s.ExitPrice,
+		Stop:       s.StopLoss,
+		Target:     s.TargetPrice,
+		Size:       s.StrikeForce,
+		Leverage:   int64(s.Leverage),
+		Confidence: s.Confidence,
+		Fees:       s.Fees,
+		Funding:    s.Funding,
+		PnL:        s.PnL,
+		Status:     s.Status.String(),
+		ExitReason: s.ExitReason,
+		DurationMs: s.DurationMs,
+	}
+}
+
+// parquetStream writes rows of T to a file one row group at a time
+type parquetStream[T any] struct {
+	file *os.File
+	w    *parquet.GenericWriter[T]
+	buf  []T
+}
+
+func createParquetStream[T any](path string) (*parquetStream[T], error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetStream[T]{file: f, w: parquet.NewGenericWriter[T](f)}, nil
+}
+
+// Write buffers a row, flushing a row group once the buffer is full
+func (s *parquetStream[T]) Write(row T) error {
+	s.buf = append(s.buf, row)
+	if len(s.buf) >= parquetRowGroupSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *parquetStream[T]) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	if _, err := s.w.Write(s.buf); err != nil {
+		return err
+	}
+	s.buf = s.buf[:0]
+	return s.w.Flush()
+}
+
+// Close flushes the last row group, writes the footer and closes the file
+func (s *parquetStream[T]) Close() error {
+	err := s.flush()
+	if cerr := s.w.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ParquetExporter streams completed strikes to a Parquet file. Parquet files
+// cannot be appended to, so a resumed run starts a fresh export.
+type ParquetExporter struct {
+	mu     sync.Mutex
+	stream *parquetStream[parquetStrike]
+	err    error
+}
+
+// NewParquetExporter creates (or truncates) a strike export at path
+func NewParquetExporter(path string) (*ParquetExporter, error) {
+	s, err := createParquetStream[parquetStrike](path)
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetExporter{stream: s}, nil
+}
+
+// Record adds a completed strike; the first write error is reported by Close
+func (e *ParquetExporter) Record(strike *MacroStrike) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil {
+		e.err = e.stream.Write(parquetStrikeRow(strike))
+	}
+}
+
+// Close finishes the file
+func (e *ParquetExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	err := e.stream.Close()
+	if e.err != nil {
+		return e.err
+	}
+	return err
+}
+
+// WriteEquityCurveParquet writes an equity curve to path
+func WriteEquityCurveParquet(path string, curve []report.EquityPoint) error {
+	s, err := createParquetStream[parquetEquityPoint](path)
+	if err != nil {
+		return err
+	}
+	for _, p := range curve {
+		if err := s.Write(parquetEquityPoint{Trade: p.Trade, Time: p.Time.UTC(), Capital: p.Capital}); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	return s.Close()
+}
+
+// writeEquityParquet exports the campaign equity curve when PARQUET_EQUITY_PATH is set
+func (te *TradingEngine) writeEquityParquet() {
+	if te.ParquetEquityPath == "" {
+		return
+	}
+	curve := te.campaignStats.Snapshot().EquityCurve
+	if err := WriteEquityCurveParquet(te.ParquetEquityPath, curve); err != nil {
+		log.Printf("⚠️ Parquet equity curve failed: %v", err)
+		return
+	}
+	log.Printf("📄 Parquet equity curve written to %s", te.ParquetEquityPath)
+}
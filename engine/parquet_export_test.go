@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"macro-strike-bot/report"
+)
+
+func TestParquetExportRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "strikes.parquet")
+	pe, err := NewParquetExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enough strikes to span several row groups
+	n := parquetRowGroupSize*2 + 17
+	var want []parquetStrike
+	for i := 0; i < n; i++ {
+		s := &MacroStrike{
+			ID: uint64(i + 1), Timestamp: 1736164800 + int64(i), Symbol: "WETH/USDC", StrikeType: MacroMomentum,
+			EntryPrice: 3000 + float64(i), StopLoss: 2990, TargetPrice: 3030, StrikeForce: 0.5,
+			Leverage: 3, Confidence: 0.91, Fees: 1.25, Funding: 0.05, Status: Hit, ExitReason: "target", DurationMs: int64(i),
+		}
+		// Every other strike is still open: exit and pnl stay null
+		if i%2 == 0 {
+			exit, pnl := 3030.0, 15.5
+			s.ExitPrice, s.PnL = &exit, &pnl
+		}
+		pe.Record(s)
+		want = append(want, parquetStrikeRow(s))
+	}
+	if err := pe.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := parquet.ReadFile[parquetStrike](path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(got) != n {
+		t.Fatalf("read %d rows, want %d", len(got), n)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if (g.Exit == nil) != (w.Exit == nil) || (g.PnL == nil) != (w.PnL == nil) {
+			t.Fatalf("row %d: null mismatch", i)
+		}
+		if w.Exit != nil && (*g.Exit != *w.Exit || *g.PnL != *w.PnL) {
+			t.Fatalf("row %d: exit/pnl = %v/%v, want %v/%v", i, *g.Exit, *g.PnL, *w.Exit, *w.PnL)
+		}
+		g.Exit, g.PnL, w.Exit, w.PnL = nil, nil, nil, nil
+		if !g.Timestamp.Equal(w.Timestamp) {
+			t.Fatalf("row %d: timestamp %v, want %v", i, g.Timestamp, w.Timestamp)
+		}
+		g.Timestamp, w.Timestamp = time.Time{}, time.Time{}
+		if g != w {
+			t.Fatalf("row %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestEquityCurveParquetRoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "equity.parquet")
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	curve := []report.EquityPoint{
+		{Trade: 0, Time: start, Capital: 1000},
+		{Trade: 1, Time: start.Add(90 * time.Second), Capital: 1012.5},
+	}
+	if err := WriteEquityCurveParquet(path, curve); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parquet.ReadFile[parquetEquityPoint](path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(got) != len(curve) {
+		t.Fatalf("read %d points, want %d", len(got), len(curve))
+	}
+	for i, p := range curve {
+		if got[i].Trade != p.Trade || !got[i].Time.Equal(p.Time) || got[i].Capital != p.Capital {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], p)
+		}
+	}
+}
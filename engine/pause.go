@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"log"
+	"time"
+)
+
+// pausePollInterval is how often a paused campaign checks for Resume
+const pausePollInterval = time.Second
+
+// Pause stops the campaign from opening new strikes until Resume. A strike
+// already in flight runs to its exit as usual. Pausing twice is a no-op.
+func (te *TradingEngine) Pause() {
+	te.pauseMu.Lock()
+	defer te.pauseMu.Unlock()
+	if te.paused {
+		return
+	}
+	te.paused = true
+	te.pausedSince = te.Clock.Now()
+	log.Printf("⏸️ Trading paused: no new strikes until resumed")
+}
+
+// Resume lets a paused campaign open strikes again
+func (te *TradingEngine) Resume() {
+	te.pauseMu.Lock()
+	defer te.pauseMu.Unlock()
+	if !te.paused {
+		return
+	}
+	d := te.Clock.Since(te.pausedSince)
+	te.pausedTotal += d
+	te.paused = false
+	te.pausedSince = time.Time{}
+	log.Printf("▶️ Trading resumed after %v paused", d.Round(time.Second))
+}
+
+// Paused reports whether the campaign is paused, and since when
+func (te *TradingEngine) Paused() (bool, time.Time) {
+	te.pauseMu.Lock()
+	defer te.pauseMu.Unlock()
+	return te.paused, te.pausedSince
+}
+
+// pausedFor is the total time spent paused, including a pause still running
+func (te *TradingEngine) pausedFor() time.Duration {
+	te.pauseMu.Lock()
+	defer te.pauseMu.Unlock()
+	d := te.pausedTotal
+	if te.paused {
+		d += te.Clock.Since(te.pausedSince)
+	}
+	return d
+}
+
+// campaignElapsed is the time counted against the CampaignDays window;
+// paused time is left out when PauseExtendsWindow is set
+func (te *TradingEngine) campaignElapsed() time.Duration {
+	elapsed := te.Clock.Since(te.CampaignStart)
+	if te.PauseExtendsWindow {
+		elapsed -= te.pausedFor()
+	}
+	return elapsed
+}
+
+// campaignWindowEnd is when the CampaignDays window closes at the current
+// pause total
+func (te *TradingEngine) campaignWindowEnd() time.Time {
+	end := te.CampaignStart.Add(time.Duration(te.CampaignDays) * 24 * time.Hour)
+	if te.PauseExtendsWindow {
+		end = end.Add(te.pausedFor())
+	}
+	return end
+}
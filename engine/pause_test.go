@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+// pausingGenerator pauses the engine while handing out its pauseAt'th strike
+type pausingGenerator struct {
+	te      *TradingEngine
+	inner   StrikeGenerator
+	pauseAt int
+	calls   int
+}
+
+func (g *pausingGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	g.calls++
+	if g.calls == g.pauseAt {
+		g.te.Pause()
+	}
+	return g.inner.NextStrike(ctx)
+}
+
+// resumingClock resumes trading over HTTP once the paused loop has slept
+// through resumeAfter of fake time
+type resumingClock struct {
+	*clock.Fake
+	te          *TradingEngine
+	t           *testing.T
+	resumeAfter time.Duration
+	status      EngineStatus
+}
+
+func (c *resumingClock) Sleep(d time.Duration) {
+	c.Fake.Sleep(d)
+	paused, since := c.te.Paused()
+	if !paused || c.Since(since) < c.resumeAfter {
+		return
+	}
+	h := c.te.statusHandler()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &c.status); err != nil {
+		c.t.Fatalf("decode /status: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/resume", nil))
+	if rec.Code != 200 {
+		c.t.Fatalf("POST /resume = %d", rec.Code)
+	}
+}
+
+func TestPauseHoldsNewStrikesUntilResume(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("PAUSE_EXTENDS_WINDOW", "1")
+	te := NewTradingEngine()
+	clock := &resumingClock{Fake: clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)), te: te, t: t, resumeAfter: time.Hour}
+	te.Clock = clock
+	te.CampaignStart = clock.Now()
+	te.Generator = &pausingGenerator{te: te, pauseAt: 2, inner: &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, true)},
+		{Strike: certainStrike(3, true)},
+	}}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.TradesCompleted != 3 {
+		t.Errorf("trades completed = %d, want all 3 after resuming", result.TradesCompleted)
+	}
+	if !clock.status.Paused || clock.status.PausedSince == nil {
+		t.Errorf("/status while paused = %+v, want paused", clock.status)
+	}
+	if paused, _ := te.Paused(); paused {
+		t.Error("still paused after POST /resume")
+	}
+	// The hour spent paused doesn't count against the campaign window
+	if wall, counted := te.Clock.Since(te.CampaignStart), te.campaignElapsed(); wall-counted < time.Hour {
+		t.Errorf("elapsed %v of %v wall time, want the pause excluded", counted, wall)
+	}
+
+	rec := httptest.NewRecorder()
+	te.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/pause", nil))
+	if rec.Code != 405 {
+		t.Errorf("GET /pause = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// perfStoreVersion is bumped whenever the performance store format changes
+const perfStoreVersion = 1
+
+// PerfStats is an exponentially decayed outcome tally. Every field decays by
+// half each half-life, so Trades is an effective (recent-weighted) count.
+type PerfStats struct {
+	Trades  float64   `json:"trades"`
+	Wins    float64   `json:"wins"`
+	PnL     float64   `json:"pnl"`
+	Updated time.Time `json:"updated"`
+}
+
+// decayed returns the stats aged to now
+func (s PerfStats) decayed(now time.Time, halfLife time.Duration) PerfStats {
+	if halfLife <= 0 || s.Updated.IsZero() || !now.After(s.Updated) {
+		return s
+	}
+	f := math.Pow(0.5, float64(now.Sub(s.Updated))/float64(halfLife))
+	return PerfStats{Trades: s.Trades * f, Wins: s.Wins * f, PnL: s.PnL * f, Updated: now}
+}
+
+// WinRate is the decayed win fraction, 0 with no history
+func (s PerfStats) WinRate() float64 {
+	if s.Trades <= 0 {
+		return 0
+	}
+	return s.Wins / s.Trades
+}
+
+// AvgPnL is the decayed average PnL per trade
+func (s PerfStats) AvgPnL() float64 {
+	if s.Trades <= 0 {
+		return 0
+	}
+	return s.PnL / s.Trades
+}
+
+// PerfSnapshot is the persisted and reported form of the store
+type PerfSnapshot struct {
+	Version  int                  `json:"version"`
+	BySymbol map[string]PerfStats `json:"by_symbol"`
+	ByType   map[string]PerfStats `json:"by_type"`
+}
+
+// PerformanceStore keeps decayed per-symbol and per-strike-type statistics
+// that survive restarts, unlike CampaignStats which starts fresh each run
+type PerformanceStore struct {
+	mu       sync.Mutex
+	halfLife time.Duration
+	bySymbol map[string]*PerfStats
+	byType   map[string]*PerfStats
+}
+
+// NewPerformanceStore returns an empty store
+func NewPerformanceStore(halfLife time.Duration) *PerformanceStore {
+	return &PerformanceStore{
+		halfLife: halfLife,
+		bySymbol: make(map[string]*PerfStats),
+		byType:   make(map[string]*PerfStats),
+	}
+}
+
+// LoadPerformanceStore reads a store saved by Save; a missing file is an empty store
+func LoadPerformanceStore(path string, halfLife time.Duration) (*PerformanceStore, error) {
+	ps := NewPerformanceStore(halfLife)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snap PerfSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != perfStoreVersion {
+		return nil, fmt.Errorf("unsupported performance store version %d", snap.Version)
+	}
+	for k, v := range snap.BySymbol {
+		v := v
+		ps.bySymbol[k] = &v
+	}
+	for k, v := range snap.ByType {
+		v := v
+		ps.byType[k] = &v
+	}
+	return ps, nil
+}
+
+// Record folds one completed strike into the symbol and strike-type tallies
+func (ps *PerformanceStore) Record(symbol, strikeType string, at time.Time, pnl float64, win bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, bucket := range []struct {
+		m   map[string]*PerfStats
+		key string
+	}{{ps.bySymbol, symbol}, {ps.byType, strikeType}} {
+		s, ok := bucket.m[bucket.key]
+		if !ok {
+			s = &PerfStats{}
+			bucket.m[bucket.key] = s
+		}
+		*s = s.decayed(at, ps.halfLife)
+		s.Trades++
+		if win {
+			s.Wins++
+		}
+		s.PnL += pnl
+		if at.After(s.Updated) {
+			s.Updated = at
+		}
+	}
+}
+
+// Symbol returns the decayed stats for a symbol as of now
+func (ps *PerformanceStore) Symbol(symbol string, now time.Time) PerfStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if s, ok := ps.bySymbol[symbol]; ok {
+		return s.decayed(now, ps.halfLife)
+	}
+	return PerfStats{}
+}
+
+// Type returns the decayed stats for a strike type as of now
+func (ps *PerformanceStore) Type(strikeType string, now time.Time) PerfStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if s, ok := ps.byType[strikeType]; ok {
+		return s.decayed(now, ps.halfLife)
+	}
+	return PerfStats{}
+}
+
+// Snapshot returns every tally decayed to now
+func (ps *PerformanceStore) Snapshot(now time.Time) PerfSnapshot {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	snap := PerfSnapshot{
+		Version:  perfStoreVersion,
+		BySymbol: make(map[string]PerfStats, len(ps.bySymbol)),
+		ByType:   make(map[string]PerfStats, len(ps.byType)),
+	}
+	for k, v := range ps.bySymbol {
+		snap.BySymbol[k] = v.decayed(now, ps.halfLife)
+	}
+	for k, v := range ps.byType {
+		snap.ByType[k] = v.decayed(now, ps.halfLife)
+	}
+	return snap
+}
+
+// clone returns an independent copy of the store
+func (ps *PerformanceStore) clone() *PerformanceStore {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	c := NewPerformanceStore(ps.halfLife)
+	for k, v := range ps.bySymbol {
+		v := *v
+		c.bySymbol[k] = &v
+	}
+	for k, v := range ps.byType {
+		v := *v
+		c.byType[k] = &v
+	}
+	return c
+}
+
+// Save writes the store to path atomically
+func (ps *PerformanceStore) Save(path string, now time.Time) error {
+	data, err := json.MarshalIndent(ps.Snapshot(now), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// performanceFactor sizes a strike from its symbol and strike-type history.
+// Either bucket with at least PerfMinTrades effective trades and a win rate
+// under PerfExcludeWinRate excludes the strike; under PerfWinRateFloor it
+// takes the PerfHaircut multiplier. Exclusion lifts by itself as the
+// history decays below PerfMinTrades.
+func (te *TradingEngine) performanceFactor(strike *MacroStrike) (float64, error) {
+	factor := 1.0
+	if te.perfStore == nil {
+		return factor, nil
+	}
+	now := te.Clock.Now()
+	for _, b := range []struct {
+		kind, key string
+		stats     PerfStats
+	}{
+		{"symbol", strike.Symbol, te.perfStore.Symbol(strike.Symbol, now)},
+		{"strike type", strike.StrikeType.String(), te.perfStore.Type(strike.StrikeType.String(), now)},
+	} {
+		if b.stats.Trades < te.PerfMinTrades {
+			continue
+		}
+		wr := b.stats.WinRate()
+		if te.PerfExcludeWinRate > 0 && wr < te.PerfExcludeWinRate {
+			return 0, newSkip(SkipPoorPerformance, "%s %s trailing win rate %.1f%% over %.1f trades", b.kind, b.key, wr*100, b.stats.Trades)
+		}
+		if te.PerfWinRateFloor > 0 && wr < te.PerfWinRateFloor {
+			te.debugf("%s %s trailing win rate %.1f%% below floor, size ×%.2f", b.kind, b.key, wr*100, te.PerfHaircut)
+			factor *= te.PerfHaircut
+		}
+	}
+	return factor, nil
+}
+
+// savePerformanceStore persists the store when PERF_STORE_FILE is set
+func (te *TradingEngine) savePerformanceStore() {
+	if te.perfStore == nil || te.PerfStoreFile == "" {
+		return
+	}
+	if err := te.perfStore.Save(te.PerfStoreFile, te.Clock.Now()); err != nil {
+		log.Printf("⚠️ Performance store save failed: %v", err)
+	}
+}
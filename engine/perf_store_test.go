@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestPerformanceStoreDecaysByHalfLife(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	ps := NewPerformanceStore(24 * time.Hour)
+	for i := 0; i < 10; i++ {
+		ps.Record("WETH/USDC", "MacroFlash", start, -2, false)
+	}
+	got := ps.Symbol("WETH/USDC", start.Add(48*time.Hour))
+	if math.Abs(got.Trades-2.5) > 1e-9 || math.Abs(got.PnL+5) > 1e-9 {
+		t.Errorf("after two half-lives: trades=%.4f pnl=%.4f, want 2.5 and -5", got.Trades, got.PnL)
+	}
+	// Fresh wins now dominate the decayed losses
+	for i := 0; i < 5; i++ {
+		ps.Record("WETH/USDC", "MacroFlash", start.Add(48*time.Hour), 1, true)
+	}
+	if wr := ps.Symbol("WETH/USDC", start.Add(48*time.Hour)).WinRate(); math.Abs(wr-5/7.5) > 1e-9 {
+		t.Errorf("win rate = %.4f, want %.4f", wr, 5/7.5)
+	}
+	if tr := ps.Type("MacroFlash", start.Add(48*time.Hour)).Trades; math.Abs(tr-7.5) > 1e-9 {
+		t.Errorf("strike type trades = %.4f, want 7.5", tr)
+	}
+}
+
+func TestPerformanceStoreFeedsSizingAcrossRestarts(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	path := filepath.Join(t.TempDir(), "perf.json")
+	t.Setenv("PERF_STORE_FILE", path)
+	t.Setenv("PERF_MIN_TRADES", "4") // 5 trades decay just below 5 within the hour
+	now := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(now)
+	// WETH wins 2 of 5 (haircut), WBTC wins 1 of 5 (excluded)
+	for i := 0; i < 5; i++ {
+		te.perfStore.Record("WETH/USDC", "MacroLiquidity", now, 1, i < 2)
+		te.perfStore.Record("WBTC/USDC", "MacroLiquidity", now, 1, i < 1)
+	}
+	te.Close()
+
+	te = NewTradingEngine()
+	te.Clock = clock.NewFake(now.Add(time.Hour))
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+	factor, err := te.performanceFactor(&MacroStrike{Symbol: "WETH/USDC", StrikeType: MacroFlash})
+	if err != nil || factor != 0.5 {
+		t.Errorf("WETH factor = %v, %v; want a 0.5 haircut", factor, err)
+	}
+	if _, err := te.performanceFactor(&MacroStrike{Symbol: "WBTC/USDC", StrikeType: MacroFlash}); err == nil {
+		t.Error("WBTC should be excluded below PERF_EXCLUDE_WIN_RATE")
+	} else if se, ok := err.(*skipError); !ok || se.Reason != SkipPoorPerformance {
+		t.Errorf("err = %v, want a %s skip", err, SkipPoorPerformance)
+	}
+	// Strike type history counts too: MacroLiquidity won 3 of 10
+	if factor, err := te.performanceFactor(&MacroStrike{Symbol: "LINK/USDC", StrikeType: MacroLiquidity}); err != nil || factor != 0.5 {
+		t.Errorf("MacroLiquidity factor = %v, %v; want a 0.5 haircut", factor, err)
+	}
+
+	// After enough half-lives the history no longer counts and WBTC trades again
+	te.Clock = clock.NewFake(now.Add(3 * 7 * 24 * time.Hour))
+	if factor, err := te.performanceFactor(&MacroStrike{Symbol: "WBTC/USDC", StrikeType: MacroFlash}); err != nil || factor != 1 {
+		t.Errorf("decayed WBTC factor = %v, %v; want 1", factor, err)
+	}
+
+	t.Setenv("PERF_STORE_RESET", "1")
+	if got := NewTradingEngine().perfStore.Symbol("WBTC/USDC", now).Trades; got != 0 {
+		t.Errorf("reset store still has %v WBTC trades", got)
+	}
+}
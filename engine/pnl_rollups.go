@@ -0,0 +1,10 @@
+package engine
+
+import (
+	"macro-strike-bot/report"
+)
+
+// PnLRollups returns the current day and hour-of-day buckets
+func (te *TradingEngine) PnLRollups() report.PnLRollupSnapshot {
+	return te.pnlRollups.Snapshot()
+}
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPnLRollupsSurviveStateRoundTrip(t *testing.T) {
+	te := NewTradingEngine()
+	at := time.Date(2025, 1, 6, 14, 0, 0, 0, time.UTC)
+	te.pnlRollups.Record(at, 12.5, true)
+	te.pnlRollups.Record(at.Add(time.Hour), -2.5, false)
+
+	resumed := NewTradingEngine()
+	resumed.restoreState(te.captureState())
+	resumed.pnlRollups.Record(at.Add(2*time.Hour), 5, true)
+
+	snap := resumed.PnLRollups()
+	if len(snap.ByDay) != 1 || snap.ByDay[0].Trades != 3 || snap.ByDay[0].PnL != 15 {
+		t.Fatalf("day bucket after resume = %+v", snap.ByDay)
+	}
+	if h := snap.ByHour[15]; h.Trades != 1 || h.PnL != -2.5 {
+		t.Errorf("hour 15 after resume = %+v", h)
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/exchange"
+)
+
+// fillPollInitial is the first wait between fill polls; each later wait
+// doubles, up to FILL_POLL_INTERVAL_MS
+const fillPollInitial = 200 * time.Millisecond
+
+// fillPollJitter spreads each wait by up to this fraction either way, so
+// concurrent polls don't hit the API in lockstep
+const fillPollJitter = 0.2
+
+var (
+	// errPollTimeout: the predicate was not met within FILL_TIMEOUT_MS
+	errPollTimeout = errors.New("fill poll timed out")
+	// errPollAborted: the watchdog aborted the strike mid-poll
+	errPollAborted = errors.New("aborted by watchdog")
+)
+
+// orderTerminalError is an order that reached a final state without
+// satisfying the poll's predicate
+type orderTerminalError struct {
+	TxID   string
+	Status string
+}
+
+func (e *orderTerminalError) Error() string {
+	return fmt.Sprintf("order %s %s", e.TxID, e.Status)
+}
+
+// pollOrder queries txid until done reports it satisfied, backing off
+// exponentially with jitter between queries. It stops early when the order
+// is closed, canceled or expired without satisfying done, when ctx is done, when the watchdog aborts the
+// strike, or after FILL_TIMEOUT_MS. The last order state seen is returned
+// either way; what labels the watchdog's progress reports.
+func (te *TradingEngine) pollOrder(ctx context.Context, strikeID uint64, txid, what string, done func(exchange.OrderInfo) bool) (exchange.OrderInfo, error) {
+	ex := te.exchange()
+	timeout := time.Duration(te.FillTimeoutMs) * time.Millisecond
+	maxWait := time.Duration(te.FillPollIntervalMs) * time.Millisecond
+	wait := fillPollInitial
+	rng := te.strikeRand(strikeID, streamPoll)
+	start := te.Clock.Now()
+	var last exchange.OrderInfo
+	for {
+		te.orderProgress(strikeID, what, txid)
+		if ord, err := ex.GetOrder(ctx, txid); err == nil {
+			last = ord
+			if done(ord) {
+				return ord, nil
+			}
+			// A final order never changes again, so polling it further only waits out the timeout
+			if ord.Status == exchange.OrderClosed || ord.Status == exchange.OrderCanceled || ord.Status == exchange.OrderExpired {
+				return ord, &orderTerminalError{TxID: txid, Status: ord.Status}
+			}
+		}
+		if te.stallAborted(strikeID) {
+			return last, errPollAborted
+		}
+		left := timeout - te.Clock.Since(start)
+		if left <= 0 {
+			return last, errPollTimeout
+		}
+		if wait > maxWait {
+			wait = maxWait
+		}
+		sleep := time.Duration(float64(wait) * (1 + fillPollJitter*(2*rng.Float64()-1)))
+		if sleep > left {
+			sleep = left
+		}
+		if err := clock.SleepContext(ctx, te.Clock, sleep); err != nil {
+			return last, err
+		}
+		wait *= 2
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-strike-bot/exchange"
+)
+
+func closedOrder(o exchange.OrderInfo) bool { return o.Status == exchange.OrderClosed }
+
+// restingExchange reports every order open and unfilled
+type restingExchange struct {
+	frozenExchange
+}
+
+func (e *restingExchange) GetOrder(ctx context.Context, txid string) (exchange.OrderInfo, error) {
+	return exchange.OrderInfo{Status: exchange.OrderOpen}, nil
+}
+
+func TestPollOrderBacksOffToCap(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"open","vol_exec":"0"}}`),
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"open","vol_exec":"0"}}`),
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"open","vol_exec":"0"}}`),
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"closed","vol_exec":"0.1","price":"2500"}}`),
+	)
+	te.FillPollIntervalMs = 500
+	te.RandSeed = 42
+	start := te.Clock.Now()
+	ord, err := te.pollOrder(context.Background(), 1, "TX1", "polling entry fill", closedOrder)
+	if err != nil || ord.Price != 2500 {
+		t.Fatalf("pollOrder = %+v, %v; want the closed order", ord, err)
+	}
+	// Waits of 200, 400 and then the 500ms cap, each jittered by up to 20% from the seeded stream
+	if want := 1211955891 * time.Nanosecond; te.Clock.Since(start) != want {
+		t.Errorf("waited %v across three backoffs, want %v", te.Clock.Since(start), want)
+	}
+}
+
+func TestPollOrderStopsOnTerminalStatus(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"open","vol_exec":"0"}}`),
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"canceled","vol_exec":"0"}}`),
+	)
+	start := te.Clock.Now()
+	_, err := te.pollOrder(context.Background(), 1, "TX1", "polling entry fill", closedOrder)
+	var terminal *orderTerminalError
+	if !errors.As(err, &terminal) || terminal.Status != exchange.OrderCanceled {
+		t.Fatalf("pollOrder = %v, want the cancellation reported", err)
+	}
+	if waited := te.Clock.Since(start); waited > time.Second {
+		t.Errorf("waited %v on a canceled order, want one backoff", waited)
+	}
+}
+
+func TestPollOrderStopsOnClosedOrderMissingPredicate(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/QueryOrders", `{"TX1":{"status":"closed","vol_exec":"0"}}`),
+	)
+	te.FillTimeoutMs = 30000
+	start := te.Clock.Now()
+	filled := func(o exchange.OrderInfo) bool { return o.VolExec > 0 }
+	_, err := te.pollOrder(context.Background(), 1, "TX1", "polling entry fill", filled)
+	var terminal *orderTerminalError
+	if !errors.As(err, &terminal) || terminal.Status != exchange.OrderClosed {
+		t.Fatalf("pollOrder = %v, want the closed order reported", err)
+	}
+	if waited := te.Clock.Since(start); waited != 0 {
+		t.Errorf("waited %v on a closed order, want no backoff", waited)
+	}
+}
+
+func TestPollOrderTimesOut(t *testing.T) {
+	te := replayEngine(t)
+	te.Exchange = &restingExchange{}
+	te.FillTimeoutMs = 5000
+	start := te.Clock.Now()
+	if _, err := te.pollOrder(context.Background(), 1, "TX1", "polling entry fill", closedOrder); !errors.Is(err, errPollTimeout) {
+		t.Fatalf("pollOrder = %v, want a timeout", err)
+	}
+	if waited := te.Clock.Since(start); waited != 5*time.Second {
+		t.Errorf("waited %v, want exactly the 5s fill timeout", waited)
+	}
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPositionManaged is returned by ExecuteStrike when a filled live entry
+// was handed to the position manager; its result arrives from Next or Poll
+var ErrPositionManaged = errors.New("position handed to position manager")
+
+// positionResult is the outcome of a managed position's exit
+type positionResult struct {
+	Strike *MacroStrike
+	PnL    float64
+	Err    error
+}
+
+// PositionManager runs the post-fill half of live strikes (hold, exit, exit
+// polling and PnL booking) on their own goroutines, so the campaign loop can
+// look for the next setup while positions are open. Results come back in
+// completion order. A nil manager manages nothing.
+type PositionManager struct {
+	results chan positionResult
+
+	mu      sync.Mutex
+	pending int
+}
+
+// NewPositionManager returns nil, keeping exits inline, when maxOpen is 1 or
+// less
+func NewPositionManager(maxOpen int) *PositionManager {
+	if maxOpen <= 1 {
+		return nil
+	}
+	return &PositionManager{results: make(chan positionResult, maxOpen)}
+}
+
+// Go finishes strike on a new goroutine
+func (pm *PositionManager) Go(strike *MacroStrike, finish func() (float64, error)) {
+	pm.mu.Lock()
+	pm.pending++
+	pm.mu.Unlock()
+	go func() {
+		pnl, err := finish()
+		pm.results <- positionResult{Strike: strike, PnL: pnl, Err: err}
+	}()
+}
+
+// Pending is the number of positions whose result has not been collected
+func (pm *PositionManager) Pending() int {
+	if pm == nil {
+		return 0
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.pending
+}
+
+// Next waits for the next position to finish. It must only be called while
+// Pending is non-zero.
+func (pm *PositionManager) Next() positionResult {
+	r := <-pm.results
+	pm.collected()
+	return r
+}
+
+// Poll returns a finished position's result without waiting
+func (pm *PositionManager) Poll() (positionResult, bool) {
+	if pm == nil {
+		return positionResult{}, false
+	}
+	select {
+	case r := <-pm.results:
+		pm.collected()
+		return r, true
+	default:
+		return positionResult{}, false
+	}
+}
+
+func (pm *PositionManager) collected() {
+	pm.mu.Lock()
+	pm.pending--
+	pm.mu.Unlock()
+}
+
+// collectPositions settles managed positions that have exited, first waiting
+// for one when MaxOpenPositions are already open. It returns the first stop
+// reason settle reports.
+func (te *TradingEngine) collectPositions(settle func(*MacroStrike, float64, error) string) string {
+	if te.positions != nil && te.positions.Pending() >= te.MaxOpenPositions {
+		r := te.positions.Next()
+		if reason := settle(r.Strike, r.PnL, r.Err); reason != "" {
+			return reason
+		}
+	}
+	for {
+		r, ok := te.positions.Poll()
+		if !ok {
+			return ""
+		}
+		if reason := settle(r.Strike, r.PnL, r.Err); reason != "" {
+			return reason
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPositionManagerDisabledAtOnePosition(t *testing.T) {
+	if pm := NewPositionManager(1); pm != nil {
+		t.Fatalf("manager created for one open position")
+	}
+	var pm *PositionManager
+	if pm.Pending() != 0 {
+		t.Errorf("nil manager has pending positions")
+	}
+	if _, ok := pm.Poll(); ok {
+		t.Errorf("nil manager returned a result")
+	}
+}
+
+func TestPositionManagerReportsResults(t *testing.T) {
+	pm := NewPositionManager(2)
+	release := make(chan struct{})
+	strike := certainStrike(1, true)
+	pm.Go(strike, func() (float64, error) {
+		<-release
+		return 4.5, nil
+	})
+	if pm.Pending() != 1 {
+		t.Fatalf("pending = %d, want 1", pm.Pending())
+	}
+	if _, ok := pm.Poll(); ok {
+		t.Fatalf("polled a result before the position finished")
+	}
+	close(release)
+	r := pm.Next()
+	if r.Strike != strike || r.PnL != 4.5 || r.Err != nil {
+		t.Errorf("result = %+v, want strike 1 with 4.5 PnL", r)
+	}
+	if pm.Pending() != 0 {
+		t.Errorf("pending after collecting = %d, want 0", pm.Pending())
+	}
+}
+
+func TestManagedLiveStrikeExitsInBackground(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.1","price":"2500","fee":"0"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.1","price":"2600","fee":"0"}}`),
+	)
+	te.MaxOpenPositions = 2
+	te.positions = NewPositionManager(2)
+	te.OrderUSDSize = 250
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	start := te.Snapshot().Capital
+	te.Stop()
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	if _, err := te.ExecuteStrike(context.Background(), strike); !errors.Is(err, ErrPositionManaged) {
+		t.Fatalf("ExecuteStrike = %v, want the position handed off", err)
+	}
+	r := te.positions.Next()
+	if r.Err != nil || r.Strike != strike {
+		t.Fatalf("managed result = %+v", r)
+	}
+	if r.PnL != 10 {
+		t.Errorf("PnL = %.2f, want 10", r.PnL)
+	}
+	if moved := te.Snapshot().Capital - start; moved != 1000 {
+		t.Errorf("capital moved %d cents, want 1000", moved)
+	}
+	if open := te.OpenNotional(); open != 0 {
+		t.Errorf("open notional after the exit = %.2f, want 0", open)
+	}
+}
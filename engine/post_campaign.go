@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"macro-strike-bot/report"
+)
+
+// PostCampaignAction is what the process does once a campaign ends
+type PostCampaignAction string
+
+// Post-campaign actions, chosen with POST_CAMPAIGN
+const (
+	// PostCampaignFlatten exits once the campaign-end flatten has run
+	PostCampaignFlatten PostCampaignAction = "flatten"
+	// PostCampaignHold keeps the process and its HTTP API up until a
+	// signal, /kill or Stop
+	PostCampaignHold PostCampaignAction = "hold"
+	// PostCampaignLoop starts a new campaign from the current capital
+	PostCampaignLoop PostCampaignAction = "loop"
+)
+
+// loopStopReasons are the campaign endings POST_CAMPAIGN=loop starts another
+// campaign after; every other ending needs an operator
+var loopStopReasons = map[string]bool{
+	report.StopTradesCompleted: true,
+	report.StopTargetReached:   true,
+	report.StopCampaignWindow:  true,
+}
+
+// parsePostCampaignAction reads POST_CAMPAIGN; empty means flatten
+func parsePostCampaignAction(v string) (PostCampaignAction, error) {
+	switch a := PostCampaignAction(v); a {
+	case "":
+		return PostCampaignFlatten, nil
+	case PostCampaignFlatten, PostCampaignHold, PostCampaignLoop:
+		return a, nil
+	default:
+		return PostCampaignFlatten, fmt.Errorf("POST_CAMPAIGN: %q is not flatten, hold or loop", v)
+	}
+}
+
+// RunCampaigns runs campaigns under signal handling, then carries out the
+// post-campaign action
+func (te *TradingEngine) RunCampaigns(ctx context.Context) {
+	for {
+		result := te.runCampaignWithSignals(ctx)
+		if !te.afterCampaign(ctx, result) {
+			return
+		}
+	}
+}
+
+// afterCampaign carries out the post-campaign action for result and reports
+// whether another campaign should start. Every campaign already flattens its
+// live positions before it returns.
+func (te *TradingEngine) afterCampaign(ctx context.Context, result *report.CampaignResult) bool {
+	switch te.PostCampaign {
+	case PostCampaignLoop:
+		if !loopStopReasons[result.StopReason] || te.stopRequested() {
+			log.Printf("🔁 POST_CAMPAIGN=loop: not starting another campaign after %s", result.StopReason)
+			return false
+		}
+		te.nextCampaign()
+		log.Printf("🔁 POST_CAMPAIGN=loop: starting campaign %s at %v", te.RunID, Money(atomic.LoadInt64(&te.Capital)))
+		return true
+	case PostCampaignHold:
+		te.holdAfterCampaign(ctx)
+	}
+	return false
+}
+
+// holdAfterCampaign keeps the process alive for monitoring until ctx ends
+// (SIGINT or SIGTERM), /kill or Stop
+func (te *TradingEngine) holdAfterCampaign(ctx context.Context) {
+	if te.statusServer == nil {
+		log.Printf("⏸️ POST_CAMPAIGN=hold without STATUS_ADDR: nothing to monitor, holding until signalled")
+	} else {
+		log.Printf("⏸️ POST_CAMPAIGN=hold: campaign over, status API still serving; signal or POST /kill to exit")
+	}
+	select {
+	case <-ctx.Done():
+		log.Printf("🛑 %v: leaving hold", context.Cause(ctx))
+	case <-te.stopCh:
+		log.Printf("🛑 Stop requested: leaving hold")
+	}
+}
+
+// nextCampaign resets the per-campaign counters so a looped campaign runs
+// its own trade limit, window and report from the capital the last one left
+func (te *TradingEngine) nextCampaign() {
+	te.countersMu.Lock()
+	for _, n := range []*int64{&te.TradesCompleted, &te.TotalStrikes, &te.SuccessfulStrikes, &te.FailedStrikes,
+		&te.AbortedStrikes, &te.TotalPnL, &te.TotalFeesPaid} {
+		atomic.StoreInt64(n, 0)
+	}
+	te.countersMu.Unlock()
+	te.runs++
+	te.RunID = newRunID(te.Clock.Now(), te.RandSeed, te.runs)
+	te.CampaignStart = te.Clock.Now()
+	te.pauseMu.Lock()
+	te.pausedTotal = 0
+	te.pauseMu.Unlock()
+	te.Resumed = false
+	te.statsRestored = false
+	te.campaignDone = make(chan struct{})
+	te.campaignDoneOnce = sync.Once{}
+}
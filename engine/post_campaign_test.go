@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+func TestPostCampaignSetting(t *testing.T) {
+	if te := NewTradingEngineFromConfig(config.Config{}); te.PostCampaign != PostCampaignFlatten {
+		t.Errorf("default POST_CAMPAIGN = %q, want %q", te.PostCampaign, PostCampaignFlatten)
+	}
+	if te := NewTradingEngineFromConfig(config.Config{"POST_CAMPAIGN": "loop"}); te.PostCampaign != PostCampaignLoop {
+		t.Errorf("POST_CAMPAIGN=loop gave %q", te.PostCampaign)
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"POST_CAMPAIGN": "restart"}).ValidateConfig(); err == nil {
+		t.Error("an unknown POST_CAMPAIGN should be rejected")
+	}
+}
+
+func TestLoopStartsFreshCampaignOnlyAfterNormalEnd(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "POST_CAMPAIGN": "loop"})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	atomic.StoreInt64(&te.TradesCompleted, 5)
+	atomic.StoreInt64(&te.SuccessfulStrikes, 3)
+	atomic.StoreInt64(&te.Capital, 123456)
+	te.campaignDoneOnce.Do(func() { close(te.campaignDone) })
+	runID := te.RunID
+
+	if !te.afterCampaign(context.Background(), &report.CampaignResult{StopReason: report.StopTradesCompleted}) {
+		t.Fatal("loop did not start another campaign after the trade limit")
+	}
+	if n := atomic.LoadInt64(&te.TradesCompleted); n != 0 {
+		t.Errorf("trades completed = %d, want 0 for the new campaign", n)
+	}
+	if n := atomic.LoadInt64(&te.SuccessfulStrikes); n != 0 {
+		t.Errorf("wins = %d, want 0 for the new campaign", n)
+	}
+	if c := atomic.LoadInt64(&te.Capital); c != 123456 {
+		t.Errorf("capital = %d, want it carried into the new campaign", c)
+	}
+	if te.RunID == runID {
+		t.Error("the new campaign reused the previous run ID")
+	}
+	select {
+	case <-te.campaignDone:
+		t.Error("the new campaign starts already marked done")
+	default:
+	}
+
+	for _, reason := range []string{report.StopEmergency, report.StopKilled, report.StopShutdown, report.StopGeneratorExhausted} {
+		if te.afterCampaign(context.Background(), &report.CampaignResult{StopReason: reason}) {
+			t.Errorf("loop started another campaign after %s", reason)
+		}
+	}
+}
+
+func TestHoldWaitsForStop(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "POST_CAMPAIGN": "hold"})
+	done := make(chan bool)
+	go func() {
+		done <- te.afterCampaign(context.Background(), &report.CampaignResult{StopReason: report.StopTradesCompleted})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("hold returned before Stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+	te.Stop()
+	select {
+	case again := <-done:
+		if again {
+			t.Error("hold asked for another campaign")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("hold did not return after Stop")
+	}
+}
@@ -0,0 +1,424 @@
+package engine
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// Postgres journal tuning
+const (
+	pgBatchSize       = 200
+	pgFlushInterval   = time.Second
+	pgMaxRetryBackoff = 30 * time.Second
+	pgFlushTimeout    = 10 * time.Second
+)
+
+// pgMigrations are applied in order and recorded in journal_schema_migrations
+var pgMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS campaigns (
+		instance_id        TEXT NOT NULL,
+		run_id             TEXT NOT NULL,
+		started_at         BIGINT NOT NULL,
+		ended_at           BIGINT,
+		config_json        TEXT,
+		final_capital      DOUBLE PRECISION,
+		total_pnl          DOUBLE PRECISION,
+		trades_completed   BIGINT,
+		successful_strikes BIGINT,
+		failed_strikes     BIGINT,
+		PRIMARY KEY (instance_id, run_id)
+	);
+	CREATE TABLE IF NOT EXISTS strikes (
+		instance_id          TEXT NOT NULL,
+		run_id               TEXT NOT NULL,
+		id                   BIGINT NOT NULL,
+		symbol               TEXT NOT NULL,
+		strike_type          INTEGER NOT NULL,
+		strike_type_name     TEXT NOT NULL,
+		entry_price          DOUBLE PRECISION,
+		target_price         DOUBLE PRECISION,
+		stop_loss            DOUBLE PRECISION,
+		confidence           DOUBLE PRECISION,
+		expected_return      DOUBLE PRECISION,
+		max_exposure_time_ms BIGINT,
+		strike_force         DOUBLE PRECISION,
+		timestamp            BIGINT,
+		status               INTEGER,
+		hit_time             BIGINT,
+		exit_price           DOUBLE PRECISION,
+		pnl                  DOUBLE PRECISION,
+		leverage             BIGINT,
+		confidence_threshold DOUBLE PRECISION,
+		level_source         TEXT,
+		liquidity_factor     DOUBLE PRECISION,
+		momentum_factor      DOUBLE PRECISION,
+		entry_txid           TEXT,
+		exit_txid            TEXT,
+		fees                 DOUBLE PRECISION,
+		slippage             DOUBLE PRECISION,
+		exit_reason          TEXT,
+		PRIMARY KEY (instance_id, run_id, id)
+	);
+	CREATE INDEX IF NOT EXISTS strikes_symbol_time ON strikes (symbol, timestamp);`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS trade_ids TEXT,
+		ADD COLUMN IF NOT EXISTS order_payloads TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS performance_factor DOUBLE PRECISION,
+		ADD COLUMN IF NOT EXISTS risk_reward DOUBLE PRECISION,
+		ADD COLUMN IF NOT EXISTS duration_ms BIGINT,
+		ADD COLUMN IF NOT EXISTS transitions TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS analysis TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS direction TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS take_profit_ladder TEXT,
+		ADD COLUMN IF NOT EXISTS exits TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS timings TEXT;`,
+	`ALTER TABLE strikes ADD COLUMN IF NOT EXISTS funding DOUBLE PRECISION;`,
+}
+
+// pgOp is one queued journal write. Strike rows are batched; campaign
+// statements run individually in queue order.
+type pgOp struct {
+	strikeKey  string
+	strikeArgs []interface{}
+	stmt       string
+	stmtArgs   []interface{}
+}
+
+// PostgresJournal journals to a shared Postgres database. Writes are buffered
+// in memory and flushed in batches by a background goroutine, so a slow or
+// unreachable database never blocks the strike path; when the buffer is full
+// the oldest writes are dropped.
+type PostgresJournal struct {
+	db         *sql.DB
+	instanceID string
+	maxBuffer  int
+
+	mu      sync.Mutex
+	pending []pgOp
+	dropped int64
+	drained *sync.Cond
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// OpenPostgresJournal connects to dsn, applies pending migrations and starts
+// the background writer. maxBuffer caps writes held while Postgres is down;
+// now stamps applied migrations.
+func OpenPostgresJournal(dsn, instanceID string, maxBuffer int, now func() time.Time) (*PostgresJournal, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migratePostgresJournal(db, now); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("journal migration: %v", err)
+	}
+	j := &PostgresJournal{
+		db:         db,
+		instanceID: instanceID,
+		maxBuffer:  maxBuffer,
+		wake:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	j.drained = sync.NewCond(&j.mu)
+	go j.writer()
+	return j, nil
+}
+
+// migratePostgresJournal applies every migration newer than the recorded version
+func migratePostgresJournal(db *sql.DB, now func() time.Time) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS journal_schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM journal_schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	for v := current + 1; v <= len(pgMigrations); v++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(pgMigrations[v-1]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", v, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO journal_schema_migrations (version, applied_at) VALUES ($1, $2)`, v, now().Unix()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Journal schema migrated to version %d", v)
+	}
+	return nil
+}
+
+// enqueue buffers a write, dropping the oldest one if the buffer is full
+func (j *PostgresJournal) enqueue(op pgOp) {
+	j.mu.Lock()
+	if len(j.pending) >= j.maxBuffer {
+		j.pending = j.pending[1:]
+		j.dropped++
+		if j.dropped == 1 || j.dropped%1000 == 0 {
+			log.Printf("🚨 Postgres journal buffer full: %d write(s) dropped", j.dropped)
+		}
+	}
+	j.pending = append(j.pending, op)
+	full := len(j.pending) >= pgBatchSize
+	j.mu.Unlock()
+	if full {
+		j.signal()
+	}
+}
+
+func (j *PostgresJournal) signal() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writer flushes batches on a timer or when a batch fills, backing off while
+// Postgres is unreachable
+func (j *PostgresJournal) writer() {
+	defer close(j.done)
+	backoff := time.Duration(0)
+	for {
+		wait := pgFlushInterval
+		if backoff > 0 {
+			wait = backoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-j.stop:
+			timer.Stop()
+			return
+		case <-j.wake:
+			timer.Stop()
+			if backoff > 0 {
+				continue
+			}
+		case <-timer.C:
+		}
+		for {
+			n, err := j.flushBatch()
+			if err != nil {
+				if backoff == 0 {
+					log.Printf("⚠️ Postgres journal write failed, buffering: %v", err)
+					backoff = pgFlushInterval
+				} else if backoff *= 2; backoff > pgMaxRetryBackoff {
+					backoff = pgMaxRetryBackoff
+				}
+				break
+			}
+			if backoff > 0 {
+				log.Printf("✅ Postgres journal reconnected")
+				backoff = 0
+			}
+			if n == 0 {
+				break
+			}
+		}
+	}
+}
+
+// flushBatch writes up to pgBatchSize queued ops in one transaction and
+// removes them from the buffer once committed
+func (j *PostgresJournal) flushBatch() (int, error) {
+	j.mu.Lock()
+	n := len(j.pending)
+	if n > pgBatchSize {
+		n = pgBatchSize
+	}
+	batch := append([]pgOp(nil), j.pending[:n]...)
+	droppedBefore := j.dropped
+	j.mu.Unlock()
+	if n == 0 {
+		j.mu.Lock()
+		j.drained.Broadcast()
+		j.mu.Unlock()
+		return 0, nil
+	}
+
+	tx, err := j.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for _, group := range groupPgOps(batch) {
+		if group[0].stmt != "" {
+			_, err = tx.Exec(group[0].stmt, group[0].stmtArgs...)
+		} else {
+			err = j.upsertStrikes(tx, group)
+		}
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	j.mu.Lock()
+	// Drops come off the front, so any while we wrote consumed this batch first
+	sent := n - int(j.dropped-droppedBefore)
+	if sent > 0 {
+		j.pending = j.pending[sent:]
+	}
+	if len(j.pending) == 0 {
+		j.drained.Broadcast()
+	}
+	j.mu.Unlock()
+	return n, nil
+}
+
+// groupPgOps splits a batch into runs of strike upserts and single
+// statements, preserving order
+func groupPgOps(batch []pgOp) [][]pgOp {
+	var groups [][]pgOp
+	for _, op := range batch {
+		last := len(groups) - 1
+		if op.stmt == "" && last >= 0 && groups[last][0].stmt == "" {
+			groups[last] = append(groups[last], op)
+			continue
+		}
+		groups = append(groups, []pgOp{op})
+	}
+	return groups
+}
+
+// coalesceStrikes keeps only the latest row per strike; Postgres rejects an
+// upsert that touches the same row twice in one statement
+func coalesceStrikes(ops []pgOp) []pgOp {
+	latest := make(map[string]int, len(ops))
+	for i, op := range ops {
+		latest[op.strikeKey] = i
+	}
+	out := make([]pgOp, 0, len(latest))
+	for i, op := range ops {
+		if latest[op.strikeKey] == i {
+			out = append(out, op)
+		}
+	}
+	return out
+}
+
+// upsertStrikes writes a group of strike rows as one multi-row upsert
+func (j *PostgresJournal) upsertStrikes(tx *sql.Tx, ops []pgOp) error {
+	ops = coalesceStrikes(ops)
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO strikes (instance_id, " + strikeInsertColumns + ") VALUES ")
+	args := make([]interface{}, 0, len(ops)*(len(ops[0].strikeArgs)+1))
+	for i, op := range ops {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		row := append([]interface{}{j.instanceID}, op.strikeArgs...)
+		for k := range row {
+			if k > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "$%d", len(args)+k+1)
+		}
+		sb.WriteString(")")
+		args = append(args, row...)
+	}
+	sb.WriteString(" ON CONFLICT (instance_id, run_id, id) DO UPDATE SET " + strikeUpsertSet)
+	_, err := tx.Exec(sb.String(), args...)
+	return err
+}
+
+// StartCampaign records a new run with a snapshot of its configuration
+func (j *PostgresJournal) StartCampaign(runID string, startedAt time.Time, config map[string]interface{}) {
+	cfg, _ := json.Marshal(config)
+	j.enqueue(pgOp{
+		stmt: `INSERT INTO campaigns (instance_id, run_id, started_at, config_json) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (instance_id, run_id) DO UPDATE SET config_json = excluded.config_json`,
+		stmtArgs: []interface{}{j.instanceID, runID, startedAt.Unix(), string(cfg)},
+	})
+}
+
+// FinishCampaign stores the final statistics for a run
+func (j *PostgresJournal) FinishCampaign(runID string, endedAt time.Time, summary CampaignSummary) {
+	j.enqueue(pgOp{
+		stmt: `UPDATE campaigns SET ended_at = $1, final_capital = $2, total_pnl = $3,
+			trades_completed = $4, successful_strikes = $5, failed_strikes = $6 WHERE instance_id = $7 AND run_id = $8`,
+		stmtArgs: []interface{}{endedAt.Unix(), summary.FinalCapital, summary.TotalPnL, summary.TradesCompleted,
+			summary.SuccessfulStrikes, summary.FailedStrikes, j.instanceID, runID},
+	})
+}
+
+// RecordStrike buffers an insert or update of a strike row
+func (j *PostgresJournal) RecordStrike(runID string, strike *MacroStrike) {
+	j.enqueue(pgOp{
+		strikeKey:  fmt.Sprintf("%s/%d", runID, strike.ID),
+		strikeArgs: strikeRowArgs(runID, strike),
+	})
+}
+
+// QueryStrikes returns this instance's strikes matching q, oldest first
+func (j *PostgresJournal) QueryStrikes(q StrikeQuery) ([]JournalStrike, error) {
+	query, args := strikeSelect(q, func(n int) string { return fmt.Sprintf("$%d", n+1) })
+	// Scope to this instance; the filter is bound as $1
+	if strings.Contains(query, " WHERE ") {
+		query = strings.Replace(query, " WHERE ", " WHERE instance_id = $1 AND ", 1)
+	} else {
+		query = strings.Replace(query, " ORDER BY ", " WHERE instance_id = $1 ORDER BY ", 1)
+	}
+	rows, err := j.db.Query(query, append([]interface{}{j.instanceID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanJournalStrikes(rows)
+}
+
+// Flush waits until the buffer has drained, giving up after pgFlushTimeout
+// if Postgres is unreachable
+func (j *PostgresJournal) Flush() {
+	j.signal()
+	deadline := time.AfterFunc(pgFlushTimeout, func() {
+		j.mu.Lock()
+		j.drained.Broadcast()
+		j.mu.Unlock()
+	})
+	defer deadline.Stop()
+	start := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for len(j.pending) > 0 && time.Since(start) < pgFlushTimeout {
+		j.drained.Wait()
+	}
+}
+
+// Close flushes what it can, stops the writer and closes the database.
+// Writes still buffered or dropped are reported as an error.
+func (j *PostgresJournal) Close() error {
+	j.Flush()
+	j.once.Do(func() { close(j.stop) })
+	<-j.done
+	j.mu.Lock()
+	lost, dropped := len(j.pending), j.dropped
+	j.mu.Unlock()
+	err := j.db.Close()
+	if lost > 0 || dropped > 0 {
+		return fmt.Errorf("%d buffered write(s) unflushed, %d dropped", lost, dropped)
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package engine
+
+import "testing"
+
+func TestPostgresJournalBatching(t *testing.T) {
+	strike := func(key string) pgOp { return pgOp{strikeKey: key, strikeArgs: []interface{}{key}} }
+	stmt := pgOp{stmt: "UPDATE campaigns SET ended_at = $1"}
+
+	groups := groupPgOps([]pgOp{strike("r/1"), strike("r/2"), stmt, strike("r/1"), strike("r/3")})
+	if len(groups) != 3 || len(groups[0]) != 2 || groups[1][0].stmt == "" || len(groups[2]) != 2 {
+		t.Fatalf("unexpected grouping: %+v", groups)
+	}
+
+	// A live exit re-records the same strike; only its latest row may be sent
+	got := coalesceStrikes([]pgOp{strike("r/1"), strike("r/2"), {strikeKey: "r/1", strikeArgs: []interface{}{"exit"}}})
+	if len(got) != 2 || got[0].strikeKey != "r/2" || got[1].strikeArgs[0] != "exit" {
+		t.Errorf("coalesce = %+v", got)
+	}
+}
+
+func TestPostgresJournalBufferDropsOldest(t *testing.T) {
+	j := &PostgresJournal{maxBuffer: 2, wake: make(chan struct{}, 1)}
+	for _, key := range []string{"r/1", "r/2", "r/3"} {
+		j.enqueue(pgOp{strikeKey: key})
+	}
+	if j.dropped != 1 || len(j.pending) != 2 || j.pending[0].strikeKey != "r/2" {
+		t.Errorf("dropped=%d pending=%+v", j.dropped, j.pending)
+	}
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// Projection extrapolates the campaign from the per-trade PnL observed so far
+type Projection struct {
+	Samples              int64     `json:"samples"`
+	WinRate              float64   `json:"win_rate"`
+	AvgWin               float64   `json:"avg_win"`
+	AvgLoss              float64   `json:"avg_loss"`
+	MeanPnL              float64   `json:"mean_pnl"`
+	StdDevPnL            float64   `json:"stddev_pnl"`
+	RemainingTrades      int64     `json:"remaining_trades"`
+	ExpectedFinalCapital float64   `json:"expected_final_capital"`
+	TargetCapital        float64   `json:"target_capital"`
+	ProbHitTarget        float64   `json:"prob_hit_target"`
+	ExpectedCompletion   time.Time `json:"expected_completion"`
+	WindowEnd            time.Time `json:"window_end"`
+	WindowRemainingSec   float64   `json:"window_remaining_sec"`
+	CompletesInWindow    bool      `json:"completes_in_window"`
+	// Win rate the remaining trades need, at the observed average win and
+	// loss, to finish at TargetCapital; above 1 the target is out of reach.
+	// Omitted until both a win and a loss have been seen.
+	RequiredWinRate *float64 `json:"required_win_rate,omitempty"`
+}
+
+// defaultProgressLogEvery is the progress log interval without PROGRESS_LOG_EVERY
+const defaultProgressLogEvery = 100
+
+// projectionInputs is everything projectCampaign needs, captured at one instant
+type projectionInputs struct {
+	dist        report.TradeDistribution
+	capital     float64
+	target      float64
+	totalTrades int64
+	tradesDone  int64
+	statsStart  time.Time
+	now         time.Time
+	windowEnd   time.Time
+}
+
+// Project estimates final capital, the chance of reaching TargetCapital and
+// when the remaining trades will finish at the current pace. Each remaining
+// trade is treated as an independent draw from the observed distribution,
+// so the sum is approximately normal.
+func (te *TradingEngine) Project() Projection {
+	dist, statsStart := te.campaignStats.Distribution()
+	return projectCampaign(projectionInputs{
+		dist:        dist,
+		capital:     Money(atomic.LoadInt64(&te.Capital)).Dollars(),
+		target:      Money(te.TargetCapital).Dollars(),
+		totalTrades: TotalTrades,
+		tradesDone:  atomic.LoadInt64(&te.TradesCompleted),
+		statsStart:  statsStart,
+		now:         te.Clock.Now(),
+		windowEnd:   te.campaignWindowEnd(),
+	})
+}
+
+func projectCampaign(in projectionInputs) Projection {
+	d := in.dist
+	p := Projection{
+		Samples:              d.N,
+		RemainingTrades:      in.totalTrades - in.tradesDone,
+		ExpectedFinalCapital: in.capital,
+		TargetCapital:        in.target,
+		WindowEnd:            in.windowEnd,
+	}
+	if p.RemainingTrades < 0 {
+		p.RemainingTrades = 0
+	}
+	if d.N > 0 {
+		p.WinRate = float64(d.Wins) / float64(d.N)
+		p.MeanPnL = d.Mean
+	}
+	if d.Wins > 0 {
+		p.AvgWin = d.WinSum / float64(d.Wins)
+	}
+	if d.Losses > 0 {
+		p.AvgLoss = d.LossSum / float64(d.Losses)
+	}
+	if d.N > 1 {
+		p.StdDevPnL = math.Sqrt(d.M2 / float64(d.N-1))
+	}
+
+	remaining := float64(p.RemainingTrades)
+	p.ExpectedFinalCapital = in.capital + remaining*p.MeanPnL
+	need := in.target - in.capital
+	switch {
+	case need <= 0:
+		p.ProbHitTarget = 1
+	case remaining == 0 || d.N == 0:
+		p.ProbHitTarget = 0
+	case p.StdDevPnL == 0:
+		if remaining*p.MeanPnL >= need {
+			p.ProbHitTarget = 1
+		}
+	default:
+		// P(N(remaining·μ, remaining·σ²) ≥ need)
+		z := (need - remaining*p.MeanPnL) / (p.StdDevPnL * math.Sqrt(remaining))
+		p.ProbHitTarget = 0.5 * math.Erfc(z/math.Sqrt2)
+	}
+	switch {
+	case need <= 0:
+		zero := 0.0
+		p.RequiredWinRate = &zero
+	case remaining > 0 && d.Wins > 0 && d.Losses > 0 && p.AvgWin > p.AvgLoss:
+		// Solve r·AvgWin + (1-r)·AvgLoss = need/remaining for r
+		r := (need/remaining - p.AvgLoss) / (p.AvgWin - p.AvgLoss)
+		if r < 0 {
+			r = 0
+		}
+		p.RequiredWinRate = &r
+	}
+	if left := in.windowEnd.Sub(in.now); left > 0 {
+		p.WindowRemainingSec = left.Seconds()
+	}
+
+	if d.N > 0 {
+		perTrade := in.now.Sub(in.statsStart) / time.Duration(d.N)
+		p.ExpectedCompletion = in.now.Add(perTrade * time.Duration(p.RemainingTrades))
+		p.CompletesInWindow = !p.ExpectedCompletion.After(in.windowEnd)
+	}
+	return p
+}
+
+// PaceSummary is the projection as one progress log line
+func (p Projection) PaceSummary() string {
+	need := "n/a"
+	if p.RequiredWinRate != nil {
+		need = fmt.Sprintf("%.1f%%", *p.RequiredWinRate*100)
+	}
+	done := "n/a"
+	if !p.ExpectedCompletion.IsZero() {
+		done = p.ExpectedCompletion.UTC().Format(time.RFC3339)
+		if !p.CompletesInWindow {
+			done += " (after the window)"
+		}
+	}
+	return fmt.Sprintf("projected $%.2f vs target $%.2f (%.0f%% likely) | done %s | needs %s wins over %d trades | window left %s",
+		p.ExpectedFinalCapital, p.TargetCapital, p.ProbHitTarget*100, done, need, p.RemainingTrades,
+		(time.Duration(p.WindowRemainingSec) * time.Second).Round(time.Minute))
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+func TestProjectCampaign(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	// 100 trades: 60 wins of +$20, 40 losses of -$10 → mean $8, taking 100 minutes
+	var d report.TradeDistribution
+	for i := 0; i < 100; i++ {
+		pnl := 20.0
+		if i%5 >= 3 {
+			pnl = -10.0
+		}
+		d.N++
+		delta := pnl - d.Mean
+		d.Mean += delta / float64(d.N)
+		d.M2 += delta * (pnl - d.Mean)
+		if pnl >= 0 {
+			d.Wins++
+			d.WinSum += pnl
+		} else {
+			d.Losses++
+			d.LossSum += pnl
+		}
+	}
+	in := projectionInputs{
+		dist:        d,
+		capital:     100800,
+		target:      118500,
+		totalTrades: 2500,
+		tradesDone:  100,
+		statsStart:  start,
+		now:         start.Add(100 * time.Minute),
+		windowEnd:   start.Add(5 * 24 * time.Hour),
+	}
+	p := projectCampaign(in)
+
+	if p.WinRate != 0.6 || p.AvgWin != 20 || p.AvgLoss != -10 {
+		t.Errorf("win stats = %.2f / %.2f / %.2f", p.WinRate, p.AvgWin, p.AvgLoss)
+	}
+	if p.RemainingTrades != 2400 || math.Abs(p.ExpectedFinalCapital-(100800+2400*8)) > 1e-6 {
+		t.Errorf("expected final capital = %.2f over %d trades", p.ExpectedFinalCapital, p.RemainingTrades)
+	}
+	// Need $17,700 against an expected $19,200: comfortably above a coin flip
+	if p.ProbHitTarget <= 0.5 || p.ProbHitTarget >= 1 {
+		t.Errorf("prob hit target = %.4f", p.ProbHitTarget)
+	}
+	if want := in.now.Add(2400 * time.Minute); !p.ExpectedCompletion.Equal(want) || !p.CompletesInWindow {
+		t.Errorf("completion = %v (in window %v), want %v", p.ExpectedCompletion, p.CompletesInWindow, want)
+	}
+	// $17,700 over 2400 trades at +$20/-$10 needs r·20 - (1-r)·10 = 7.375
+	if p.RequiredWinRate == nil || math.Abs(*p.RequiredWinRate-17.375/30) > 1e-9 {
+		t.Errorf("required win rate = %v, want %.4f", p.RequiredWinRate, 17.375/30)
+	}
+	if want := (5*24*time.Hour - 100*time.Minute).Seconds(); p.WindowRemainingSec != want {
+		t.Errorf("window remaining = %.0fs, want %.0fs", p.WindowRemainingSec, want)
+	}
+
+	// Already at target
+	in.capital = 120000
+	if p := projectCampaign(in); p.ProbHitTarget != 1 || p.RequiredWinRate == nil || *p.RequiredWinRate != 0 {
+		t.Errorf("prob at target = %.4f, required win rate %v, want 1 and 0", p.ProbHitTarget, p.RequiredWinRate)
+	}
+
+	// No trades yet: nothing to extrapolate from
+	in.dist = report.TradeDistribution{}
+	in.capital = 100000
+	if p := projectCampaign(in); p.ProbHitTarget != 0 || !p.ExpectedCompletion.IsZero() || p.ExpectedFinalCapital != 100000 || p.RequiredWinRate != nil {
+		t.Errorf("empty projection = %+v", p)
+	}
+	if s := projectCampaign(in).PaceSummary(); !strings.Contains(s, "needs n/a wins") || !strings.Contains(s, "done n/a") {
+		t.Errorf("pace summary without trades = %q", s)
+	}
+}
+
+func TestProgressLogEverySetting(t *testing.T) {
+	if te := NewTradingEngineFromConfig(config.Config{}); te.ProgressLogEvery != defaultProgressLogEvery {
+		t.Errorf("default PROGRESS_LOG_EVERY = %d, want %d", te.ProgressLogEvery, defaultProgressLogEvery)
+	}
+	if te := NewTradingEngineFromConfig(config.Config{"PROGRESS_LOG_EVERY": "0"}); te.ProgressLogEvery != 0 {
+		t.Errorf("PROGRESS_LOG_EVERY=0 gave %d", te.ProgressLogEvery)
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"PROGRESS_LOG_EVERY": "-5"}).ValidateConfig(); err == nil {
+		t.Error("a negative PROGRESS_LOG_EVERY should be rejected")
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// defaultQuoteCurrency is the funding currency when ACCOUNT_QUOTE_CURRENCY is unset
+const defaultQuoteCurrency = "USD"
+
+// parseQuoteCurrency reads ACCOUNT_QUOTE_CURRENCY, an asset code like EUR
+func parseQuoteCurrency(raw string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if code == "" {
+		return defaultQuoteCurrency, nil
+	}
+	if len(code) < 3 || len(code) > 4 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("ACCOUNT_QUOTE_CURRENCY: %q is not a currency code like USD or EUR", raw)
+	}
+	return code, nil
+}
+
+// quotePair rewrites a USD-quoted pair to the account's funding currency,
+// e.g. ETHUSD to ETHEUR; PAIR_OVERRIDES entries are taken as given
+func (te *TradingEngine) quotePair(pair string) string {
+	if te.QuoteCurrency == defaultQuoteCurrency || !strings.HasSuffix(pair, "USD") {
+		return pair
+	}
+	return strings.TrimSuffix(pair, "USD") + te.QuoteCurrency
+}
+
+// quotePerUSD is how many units of the funding currency one USD buys, from
+// the public ticker for the currency's USD pair (EURUSD for EUR). Capital,
+// PnL and OrderUSDSize stay in USD; orders and fills are in the funding
+// currency, and this rate converts between them.
+func (te *TradingEngine) quotePerUSD(ctx context.Context) (float64, error) {
+	if te.QuoteCurrency == defaultQuoteCurrency {
+		return 1, nil
+	}
+	pair := te.QuoteCurrency + "USD"
+	usd, err := te.pairTicker(ctx, pair)
+	if err != nil {
+		return 0, fmt.Errorf("%s rate: %v", pair, err)
+	}
+	return 1 / usd, nil
+}
+
+// krakenAssetCode strips the X/Z prefix from Kraken's legacy four-letter
+// asset codes, so ZEUR reads as EUR
+func krakenAssetCode(code string) string {
+	if len(code) == 4 && (code[0] == 'X' || code[0] == 'Z') {
+		return code[1:]
+	}
+	return code
+}
+
+// fundingBalance returns the account's balance in the funding currency and
+// whether the account holds that currency at all
+func (te *TradingEngine) fundingBalance(ctx context.Context) (float64, bool, error) {
+	balances, err := te.exchange().GetBalance(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	total, held := 0.0, false
+	for asset, v := range balances {
+		if krakenAssetCode(asset) == te.QuoteCurrency {
+			total += v
+			held = true
+		}
+	}
+	return total, held, nil
+}
+
+// checkQuotePairs makes sure a live run funded in something other than USD
+// can trade every symbol against its funding currency, and that the USD
+// rate orders are sized with is available, before the first strike
+func (te *TradingEngine) checkQuotePairs(ctx context.Context) error {
+	if !te.LiveTrading || te.QuoteCurrency == defaultQuoteCurrency {
+		return nil
+	}
+	ex := te.exchange()
+	for _, symbol := range symbols {
+		pair := ex.Pair(symbol)
+		if pair == "" {
+			return fmt.Errorf("no %s pair for %s", ex.Name(), symbol)
+		}
+		info, err := te.pairInfo(ctx, pair)
+		if err != nil {
+			return fmt.Errorf("%s (%s) is not tradeable against %s: %v", symbol, pair, te.QuoteCurrency, err)
+		}
+		if info.Quote != "" && krakenAssetCode(info.Quote) != te.QuoteCurrency {
+			return fmt.Errorf("%s (%s) is quoted in %s, not the %s the account is funded in", symbol, pair, krakenAssetCode(info.Quote), te.QuoteCurrency)
+		}
+	}
+	rate, err := te.quotePerUSD(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("💱 Funding currency %s: 1 USD = %.4f %s", te.QuoteCurrency, rate, te.QuoteCurrency)
+	return nil
+}
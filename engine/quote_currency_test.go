@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseQuoteCurrency(t *testing.T) {
+	for raw, want := range map[string]string{"": "USD", "eur": "EUR", " GBP ": "GBP", "USDT": "USDT"} {
+		if got, err := parseQuoteCurrency(raw); err != nil || got != want {
+			t.Errorf("parseQuoteCurrency(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"EU", "EURO1", "€"} {
+		if _, err := parseQuoteCurrency(raw); err == nil {
+			t.Errorf("parseQuoteCurrency(%q) accepted", raw)
+		}
+	}
+}
+
+func TestQuoteCurrencyRewritesPairsAndRate(t *testing.T) {
+	te := replayEngine(t, krakenReply("/0/public/Ticker", `{"ZEURZUSD":{"c":["1.25","1"]}}`))
+	te.QuoteCurrency = "EUR"
+	te.PairOverrides = map[string]string{"LINK/USDC": "LINKUSD"}
+	if got := te.krakenPair("WETH/USDC"); got != "ETHEUR" {
+		t.Errorf("WETH/USDC pair = %q, want ETHEUR", got)
+	}
+	if got := te.krakenPair("LINK/USDC"); got != "LINKUSD" {
+		t.Errorf("override rewritten to %q", got)
+	}
+	if got := te.pairAsset("ETHEUR"); got != "ETH" {
+		t.Errorf("ETHEUR asset = %q, want ETH", got)
+	}
+	rate, err := te.quotePerUSD(context.Background())
+	if err != nil || rate != 0.8 {
+		t.Fatalf("EUR per USD = %v, %v; want 0.8", rate, err)
+	}
+	// Served from the ticker cache: the replay has nothing more to give
+	if rate, err := te.quotePerUSD(context.Background()); err != nil || rate != 0.8 {
+		t.Errorf("cached EUR per USD = %v, %v; want 0.8", rate, err)
+	}
+}
+
+func TestCheckQuotePairsRejectsUSDOnlyPair(t *testing.T) {
+	te := replayEngine(t, krakenReply("/0/public/Ticker", `{"ZEURZUSD":{"c":["1.25","1"]}}`))
+	te.QuoteCurrency = "EUR"
+	for _, symbol := range symbols {
+		te.pairInfoCache[te.krakenPair(symbol)] = pairInfo{LotDecimals: 8, PairDecimals: 2, Quote: "ZEUR"}
+	}
+	if err := te.checkQuotePairs(context.Background()); err != nil {
+		t.Fatalf("every pair in EUR: %v", err)
+	}
+
+	te.pairInfoCache["ETHEUR"] = pairInfo{LotDecimals: 8, PairDecimals: 2, Quote: "ZUSD"}
+	if err := te.checkQuotePairs(context.Background()); err == nil || !strings.Contains(err.Error(), "quoted in USD") {
+		t.Errorf("a USD-quoted pair = %v, want it rejected", err)
+	}
+
+	te.LiveTrading = false
+	if err := te.checkQuotePairs(context.Background()); err != nil {
+		t.Errorf("checked pairs outside live trading: %v", err)
+	}
+}
+
+func TestEURFundedLiveStrikeBooksUSD(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/public/Ticker", `{"ZEURZUSD":{"c":["1.25","1"]}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.1","price":"2000","fee":"0.8"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.1","price":"2100","fee":"0.8"}}`),
+	)
+	te.QuoteCurrency = "EUR"
+	te.OrderUSDSize = 250
+	te.pairInfoCache["ETHEUR"] = pairInfo{LotDecimals: 8, PairDecimals: 2, Quote: "ZEUR"}
+	start := te.Snapshot().Capital
+	te.Stop()
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500 // USD, 2000 EUR at 1.25
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	// 100 EUR of move on 0.1 ETH is 10 EUR, or 12.50 USD; fees 1.60 EUR are 2 USD
+	if strike.PnL == nil || math.Abs(*strike.PnL-12.5) > 1e-9 {
+		t.Errorf("PnL = %v, want 12.50 USD", strike.PnL)
+	}
+	if math.Abs(strike.Fees-2) > 1e-9 {
+		t.Errorf("fees = %.4f, want 2 USD", strike.Fees)
+	}
+	if moved := te.Snapshot().Capital - start; moved != 1250 {
+		t.Errorf("capital moved %d cents, want 1250", moved)
+	}
+	if gains := te.lotLedger.Realized(); len(gains) != 1 || gains[0].Asset != "ETH" {
+		t.Errorf("realized gains = %+v, want one ETH disposal", gains)
+	}
+}
@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lotEpsilon is the volume below which a lot remainder is float noise
+const lotEpsilon = 1e-12
+
+// realizedGainColumns is the column order of the realized-gains CSV
+var realizedGainColumns = []string{
+	"asset", "volume", "acquired", "disposed", "proceeds", "basis", "gain",
+	"holding_period_s", "basis_known", "strike_id",
+}
+
+// Lot is an acquired quantity of an asset awaiting disposal. Cost includes
+// the entry fee.
+type Lot struct {
+	Asset      string    `json:"asset"`
+	StrikeID   uint64    `json:"strike_id"`
+	Volume     float64   `json:"volume"`
+	Cost       float64   `json:"cost"`
+	Acquired   time.Time `json:"acquired"`
+	BasisKnown bool      `json:"basis_known"`
+}
+
+// RealizedGain is one disposal matched against (part of) one lot
+type RealizedGain struct {
+	Asset      string    `json:"asset"`
+	StrikeID   uint64    `json:"strike_id"`
+	Volume     float64   `json:"volume"`
+	Acquired   time.Time `json:"acquired"`
+	Disposed   time.Time `json:"disposed"`
+	Proceeds   float64   `json:"proceeds"`
+	Basis      float64   `json:"basis"`
+	Gain       float64   `json:"gain"`
+	BasisKnown bool      `json:"basis_known"`
+}
+
+// HoldingPeriod is how long the matched quantity was held
+func (g RealizedGain) HoldingPeriod() time.Duration {
+	if g.Acquired.IsZero() {
+		return 0
+	}
+	return g.Disposed.Sub(g.Acquired)
+}
+
+// LotLedger tracks open lots per asset and matches disposals FIFO
+type LotLedger struct {
+	mu       sync.Mutex
+	lots     map[string][]*Lot
+	realized []RealizedGain
+}
+
+// NewLotLedger returns an empty ledger
+func NewLotLedger() *LotLedger {
+	return &LotLedger{lots: make(map[string][]*Lot)}
+}
+
+// pairAsset maps a pair in the funding currency (Kraken "ETHUSD" or Coinbase
+// "ETH-USD") to the asset being bought and sold
+func (te *TradingEngine) pairAsset(pair string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(pair, te.QuoteCurrency), "-")
+}
+
+// Acquire opens a lot; cost and fee are in USD
+func (l *LotLedger) Acquire(asset string, strikeID uint64, volume, cost, fee float64, at time.Time) {
+	l.add(&Lot{Asset: asset, StrikeID: strikeID, Volume: volume, Cost: cost + fee, Acquired: at, BasisKnown: true})
+}
+
+// AcquireUnknownBasis opens a lot whose cost and acquisition time are not
+// known, e.g. a position adopted during startup reconciliation
+func (l *LotLedger) AcquireUnknownBasis(asset string, strikeID uint64, volume float64) {
+	l.add(&Lot{Asset: asset, StrikeID: strikeID, Volume: volume})
+}
+
+func (l *LotLedger) add(lot *Lot) {
+	if lot.Volume <= lotEpsilon {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lots[lot.Asset] = append(l.lots[lot.Asset], lot)
+}
+
+// Dispose matches a sale against the oldest lots, splitting a lot when only
+// part of it is sold. Proceeds are net of the exit fee and allocated by
+// volume. Volume beyond what the ledger holds is recorded with unknown basis.
+func (l *LotLedger) Dispose(asset string, volume, proceeds, fee float64, at time.Time) []RealizedGain {
+	if volume <= lotEpsilon {
+		return nil
+	}
+	netPerUnit := (proceeds - fee) / volume
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []RealizedGain
+	remaining := volume
+	queue := l.lots[asset]
+	for remaining > lotEpsilon && len(queue) > 0 {
+		lot := queue[0]
+		take := lot.Volume
+		if take > remaining {
+			take = remaining
+		}
+		g := RealizedGain{
+			Asset: asset, StrikeID: lot.StrikeID, Volume: take, Acquired: lot.Acquired, Disposed: at,
+			Proceeds: take * netPerUnit, BasisKnown: lot.BasisKnown,
+		}
+		if lot.BasisKnown {
+			g.Basis = lot.Cost * take / lot.Volume
+			g.Gain = g.Proceeds - g.Basis
+		}
+		lot.Cost -= lot.Cost * take / lot.Volume
+		lot.Volume -= take
+		remaining -= take
+		if lot.Volume <= lotEpsilon {
+			queue = queue[1:]
+		}
+		out = append(out, g)
+	}
+	l.lots[asset] = queue
+	if remaining > lotEpsilon {
+		log.Printf("⚠️ Disposal of %.8f %s exceeds tracked lots by %.8f; basis unknown", volume, asset, remaining)
+		out = append(out, RealizedGain{Asset: asset, Volume: remaining, Disposed: at, Proceeds: remaining * netPerUnit})
+	}
+	l.realized = append(l.realized, out...)
+	return out
+}
+
+// Holds reports whether a lot for the strike is still open
+func (l *LotLedger) Holds(asset string, strikeID uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, lot := range l.lots[asset] {
+		if lot.StrikeID == strikeID {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenLots returns a copy of the lots not yet disposed, oldest first per asset
+func (l *LotLedger) OpenLots() []Lot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []Lot
+	for _, queue := range l.lots {
+		for _, lot := range queue {
+			out = append(out, *lot)
+		}
+	}
+	return out
+}
+
+// Realized returns a copy of every matched disposal so far
+func (l *LotLedger) Realized() []RealizedGain {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]RealizedGain, len(l.realized))
+	copy(out, l.realized)
+	return out
+}
+
+// Restore replaces the ledger contents with persisted lots and gains
+func (l *LotLedger) Restore(lots []Lot, realized []RealizedGain) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lots = make(map[string][]*Lot)
+	for i := range lots {
+		lot := lots[i]
+		l.lots[lot.Asset] = append(l.lots[lot.Asset], &lot)
+	}
+	l.realized = append([]RealizedGain(nil), realized...)
+}
+
+// WriteRealizedGainsCSV writes every realized gain so far as CSV
+func (l *LotLedger) WriteRealizedGainsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(realizedGainColumns)
+	for _, g := range l.Realized() {
+		row := []string{
+			g.Asset, formatCSVFloat(g.Volume), "", g.Disposed.UTC().Format(time.RFC3339),
+			formatCSVFloat(g.Proceeds), "", "", "", strconv.FormatBool(g.BasisKnown), "",
+		}
+		if !g.Acquired.IsZero() {
+			row[2] = g.Acquired.UTC().Format(time.RFC3339)
+			row[7] = strconv.FormatInt(int64(g.HoldingPeriod()/time.Second), 10)
+		}
+		if g.BasisKnown {
+			row[5] = formatCSVFloat(g.Basis)
+			row[6] = formatCSVFloat(g.Gain)
+		}
+		if g.StrikeID != 0 {
+			row[9] = strconv.FormatUint(g.StrikeID, 10)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeRealizedGains writes the realized-gains CSV and warns about dust lots
+// too small to sell
+func (te *TradingEngine) writeRealizedGains(ctx context.Context) {
+	for _, lot := range te.lotLedger.OpenLots() {
+		if info, err := te.pairInfo(ctx, lot.Asset+"USD"); err == nil && lot.Volume < info.OrderMin {
+			log.Printf("⚠️ Dust lot: %.8f %s from strike %d is below the %.8f order minimum", lot.Volume, lot.Asset, lot.StrikeID, info.OrderMin)
+		}
+	}
+	if te.RealizedGainsPath == "" {
+		return
+	}
+	f, err := os.Create(te.RealizedGainsPath)
+	if err != nil {
+		log.Printf("⚠️ Realized gains export failed: %v", err)
+		return
+	}
+	if err := te.lotLedger.WriteRealizedGainsCSV(f); err != nil {
+		f.Close()
+		log.Printf("⚠️ Realized gains export failed: %v", err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		log.Printf("⚠️ Realized gains export failed: %v", err)
+		return
+	}
+	log.Printf("📄 Realized gains written to %s", te.RealizedGainsPath)
+}
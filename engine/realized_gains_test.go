@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"testing"
+	"time"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestLotLedgerMatchesFIFOAndSplitsLots(t *testing.T) {
+	l := NewLotLedger()
+	t0 := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	l.Acquire("ETH", 1, 1.0, 3000, 3, t0)                // basis 3003/ETH
+	l.Acquire("ETH", 2, 1.0, 3100, 4, t0.Add(time.Hour)) // basis 3104/ETH
+
+	// Sell 1.5: all of lot 1 and half of lot 2
+	gains := l.Dispose("ETH", 1.5, 4800, 6, t0.Add(2*time.Hour))
+	if len(gains) != 2 {
+		t.Fatalf("want 2 matched gains, got %+v", gains)
+	}
+	net := (4800.0 - 6.0) / 1.5
+	if g := gains[0]; g.StrikeID != 1 || !approx(g.Volume, 1) || !approx(g.Basis, 3003) || !approx(g.Proceeds, net) {
+		t.Errorf("first match = %+v", g)
+	}
+	if g := gains[1]; g.StrikeID != 2 || !approx(g.Volume, 0.5) || !approx(g.Basis, 1552) || !approx(g.Gain, 0.5*net-1552) {
+		t.Errorf("second match = %+v", g)
+	}
+	if hp := gains[1].HoldingPeriod(); hp != time.Hour {
+		t.Errorf("holding period = %v, want 1h", hp)
+	}
+
+	open := l.OpenLots()
+	if len(open) != 1 || !approx(open[0].Volume, 0.5) || !approx(open[0].Cost, 1552) {
+		t.Fatalf("remaining lots = %+v", open)
+	}
+}
+
+func TestLotLedgerUnknownBasis(t *testing.T) {
+	l := NewLotLedger()
+	at := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	l.AcquireUnknownBasis("XBT", 7, 0.01)
+
+	// Selling more than tracked: the adopted lot and the excess both lack basis
+	gains := l.Dispose("XBT", 0.015, 1500, 0, at)
+	if len(gains) != 2 {
+		t.Fatalf("want 2 gains, got %+v", gains)
+	}
+	for _, g := range gains {
+		if g.BasisKnown || g.Basis != 0 || g.Gain != 0 {
+			t.Errorf("gain should have unknown basis: %+v", g)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := l.WriteRealizedGainsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("want header + 2 rows, got %d", len(rows))
+	}
+	// acquired, basis, gain and holding period stay blank rather than zero
+	if r := rows[1]; r[2] != "" || r[5] != "" || r[6] != "" || r[7] != "" || r[8] != "false" || r[9] != "7" {
+		t.Errorf("unknown-basis row = %v", r)
+	}
+}
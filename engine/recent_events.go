@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultEventHistory is how many recent events /events keeps without
+// EVENT_HISTORY_SIZE
+const defaultEventHistory = 200
+
+// recentEventTypes are the events kept for the /events feed: strike results
+// and what stopped the campaign
+var recentEventTypes = map[EventType]bool{
+	EventStrikeClosed:     true,
+	EventEmergencyStop:    true,
+	EventCampaignFinished: true,
+}
+
+// EventRing keeps the last events it is given, oldest overwritten first
+type EventRing struct {
+	mu   sync.Mutex
+	buf  []Event
+	next int
+	full bool
+}
+
+// NewEventRing returns a ring holding up to size events
+func NewEventRing(size int) *EventRing {
+	return &EventRing{buf: make([]Event, size)}
+}
+
+// Add stores e, evicting the oldest event once the ring is full
+func (r *EventRing) Add(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) == 0 {
+		return
+	}
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent returns up to limit of the newest events, oldest first; limit <= 0
+// returns them all
+func (r *EventRing) Recent(limit int) []Event {
+	if r == nil {
+		return []Event{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Event
+	if r.full {
+		out = append(out, r.buf[r.next:]...)
+	}
+	out = append(out, r.buf[:r.next]...)
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	if out == nil {
+		out = []Event{}
+	}
+	return out
+}
+
+// recordRecentEvents feeds the ring from the bus until the bus closes
+func recordRecentEvents(bus *EventBus, ring *EventRing) {
+	sub, _ := bus.Subscribe("recent", eventStreamBuffer)
+	go func() {
+		for e := range sub.ch {
+			if recentEventTypes[e.Type] {
+				ring.Add(e)
+			}
+		}
+	}()
+}
+
+// wantsRecentEvents reports whether an /events request asks for the recent
+// history as JSON rather than the live stream
+func wantsRecentEvents(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// serveRecentEvents writes the recent history, oldest first; ?limit=N keeps
+// the newest N
+func (te *TradingEngine) serveRecentEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("bad limit %q", v), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(te.recentEvents.Recent(limit))
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+func TestEventRingKeepsNewest(t *testing.T) {
+	r := NewEventRing(3)
+	if got := r.Recent(0); got == nil || len(got) != 0 {
+		t.Fatalf("empty ring = %v, want an empty slice", got)
+	}
+	for i := uint64(1); i <= 5; i++ {
+		r.Add(Event{Seq: i})
+	}
+	got := r.Recent(0)
+	if len(got) != 3 || got[0].Seq != 3 || got[2].Seq != 5 {
+		t.Fatalf("Recent(0) = %+v, want seqs 3..5", got)
+	}
+	if got := r.Recent(2); len(got) != 2 || got[0].Seq != 4 {
+		t.Fatalf("Recent(2) = %+v, want seqs 4..5", got)
+	}
+}
+
+func TestEventsServesRecentHistoryAsJSON(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "EVENT_HISTORY_SIZE": "2"})
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Err: newSkip(SkipLowConfidence, "scripted skip")},
+		{Strike: certainStrike(2, false)},
+	}}
+	te.ExecuteCampaign(context.Background())
+
+	var events []Event
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		te.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/events?format=json", nil))
+		if rec.Code != 200 {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &events); err != nil {
+			t.Fatalf("decode %q: %v", rec.Body.String(), err)
+		}
+		if len(events) == 2 && events[1].Type == EventCampaignFinished || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Two slots keep the last strike result and the campaign summary; skips are not kept
+	if len(events) != 2 || events[0].Type != EventStrikeClosed || events[0].StrikeID != 2 ||
+		events[1].Type != EventCampaignFinished {
+		t.Fatalf("history = %+v, want strike 2's exit then campaign_finished", events)
+	}
+	for _, e := range events {
+		if e.Time.IsZero() {
+			t.Errorf("event %+v has no timestamp", e)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/events?limit=1", nil)
+	req.Header.Set("Accept", "application/json")
+	te.statusHandler().ServeHTTP(rec, req)
+	if err := json.Unmarshal(rec.Body.Bytes(), &events); err != nil || len(events) != 1 {
+		t.Fatalf("Accept: application/json with limit=1 = %q (%v)", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	te.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/events?format=json&limit=x", nil))
+	if rec.Code != 400 {
+		t.Errorf("bad limit status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+)
+
+// RedactLogs routes the standard logger through the engine's redactor and
+// returns a func that restores the previous output
+func (te *TradingEngine) RedactLogs() func() {
+	prev := log.Writer()
+	log.SetOutput(te.redactor.Writer(prev))
+	return func() { log.SetOutput(prev) }
+}
+
+// String identifies the engine without dumping its fields, which include
+// exchange credentials
+func (te *TradingEngine) String() string {
+	return fmt.Sprintf("TradingEngine{run=%s live=%v}", te.RunID, te.LiveTrading)
+}
+
+// GoString keeps %#v from dumping credentials too
+func (te *TradingEngine) GoString() string {
+	return te.String()
+}
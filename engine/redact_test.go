@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestSecretsNeverReachArtifacts(t *testing.T) {
+	secrets := map[string]string{
+		"KRAKEN_API_KEY":    "fakeKrakenKey1234567890abcdefXYZ",
+		"KRAKEN_API_SECRET": "c2VjcmV0LXNpZ25pbmcta2V5/ZmFrZS1rcmFrZW4tc2VjcmV0+bm90LXJlYWw=",
+		"SMTP_PASSWORD":     "hunter2-smtp-pass",
+		"CONTROL_TOKEN":     "ctl-token-9f8e7d6c",
+	}
+	cfg := config.Config{"SIM_MODE": "1", "RAND_SEED": "1"}
+	for k, v := range secrets {
+		cfg[k] = v
+	}
+	te := NewTradingEngineFromConfig(cfg)
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.CampaignStart = te.Clock.Now()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, false)},
+	}}
+
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	restore := te.RedactLogs()
+	result := te.ExecuteCampaign(context.Background())
+	log.Printf("request failed: %v", errors.New("bad key "+secrets["KRAKEN_API_KEY"]))
+	log.Printf("headers API-Key: %s API-Sign: %s", secrets["KRAKEN_API_KEY"], "dGhpcyBpcyBhIGZha2Ugc2lnbmF0dXJlIGZvciB0ZXN0cw==")
+	log.Printf("engine %+v %#v, config %v", te, te, cfg)
+	restore()
+	log.SetOutput(prev)
+
+	artifacts := map[string]string{"logs": logs.String()}
+	rep := te.BuildReport(result)
+	dir := t.TempDir()
+	if err := rep.WriteJSON(filepath.Join(dir, "report.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rep.WriteHTML(filepath.Join(dir, "report.html")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"report.json", "report.html"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifacts[name] = string(data)
+	}
+	var summary bytes.Buffer
+	rep.WriteSummary(&summary)
+	artifacts["summary"] = summary.String()
+
+	for _, path := range []string{"/stats", "/status", "/metrics"} {
+		rec := httptest.NewRecorder()
+		te.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		artifacts[path] = rec.Body.String()
+	}
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts["config json"] = string(cfgJSON)
+	artifacts["config"] = fmt.Sprintf("%v %+v %s", cfg, cfg, cfg)
+	artifacts["engine"] = fmt.Sprintf("%v %+v %#v", te, te, te)
+
+	for name, out := range artifacts {
+		for setting, secret := range secrets {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s leaks %s:\n%s", name, setting, out)
+			}
+		}
+	}
+	if strings.Contains(logs.String(), "dGhpcyBpcyBh") {
+		t.Errorf("logs leak an API-Sign value:\n%s", logs.String())
+	}
+	if !strings.Contains(artifacts["config json"], `"SIM_MODE":"1"`) {
+		t.Errorf("config JSON lost ordinary settings: %s", artifacts["config json"])
+	}
+}
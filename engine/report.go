@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"log"
+
+	"macro-strike-bot/report"
+)
+
+// BuildReport assembles the campaign report from the engine's stats
+func (te *TradingEngine) BuildReport(result *report.CampaignResult) *report.CampaignReport {
+	stats := te.campaignStats.Snapshot()
+	rollups := te.pnlRollups.Snapshot()
+	return &report.CampaignReport{
+		SchemaVersion: report.SchemaVersion,
+		GeneratedAt:   te.Clock.Now().UTC(),
+		Result:        result,
+		Config:        te.configSnapshot(),
+		BySymbol:      stats.BySymbol,
+		ByStrikeType:  stats.ByType,
+		SkipReasons:   te.SkipCounts(),
+		PnLByDay:      rollups.ByDay,
+		PnLByHour:     rollups.ByHour,
+		EquityCurve:   stats.EquityCurve,
+		Redactor:      te.redactor,
+	}
+}
+
+// writeReports writes the configured JSON and HTML campaign reports
+func (te *TradingEngine) writeReports(result *report.CampaignResult) {
+	if te.ReportJSONPath == "" && te.ReportHTMLPath == "" {
+		return
+	}
+	doc := te.BuildReport(result)
+	if te.ReportJSONPath != "" {
+		if err := doc.WriteJSON(te.ReportJSONPath); err != nil {
+			log.Printf("⚠️ JSON report failed: %v", err)
+		} else {
+			log.Printf("📄 JSON report written to %s", te.ReportJSONPath)
+		}
+	}
+	if te.ReportHTMLPath != "" {
+		if err := doc.WriteHTML(te.ReportHTMLPath); err != nil {
+			log.Printf("⚠️ HTML report failed: %v", err)
+		} else {
+			log.Printf("📄 HTML report written to %s", te.ReportHTMLPath)
+		}
+	}
+}
+
+// statsTrade is the part of a completed strike the campaign stats aggregate
+func (s *MacroStrike) statsTrade() report.Trade {
+	t := report.Trade{
+		Symbol:     s.Symbol,
+		StrikeType: s.StrikeType.String(),
+		Win:        s.Status == Hit,
+		Loss:       s.Status == Miss,
+		Fees:       s.Fees,
+		Funding:    s.Funding,
+	}
+	if s.PnL != nil {
+		t.PnL = *s.PnL
+	}
+	return t
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+func TestHTMLReportRendersZeroTradeCampaign(t *testing.T) {
+	te := NewTradingEngine()
+	te.campaignStats = report.NewCampaignStats(time.Now(), 100000)
+	rep := te.BuildReport(&report.CampaignResult{RunID: "empty", StartCapital: 100000, FinalCapital: 100000, StopReason: report.StopEmergency})
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := rep.WriteHTML(path); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	html, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "No completed trades.") {
+		t.Error("zero-trade report should explain the empty charts")
+	}
+	if strings.Contains(string(html), "<polyline") {
+		t.Error("zero-trade report should not draw a polyline")
+	}
+}
+
+func TestReportAggregatesBySymbolAndStrikeType(t *testing.T) {
+	te := NewTradingEngine()
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	te.campaignStats = report.NewCampaignStats(start, 100000)
+	capital := 100000.0
+	for i, s := range []struct {
+		symbol string
+		typ    StrikeType
+		status StrikeStatus
+		pnl    float64
+	}{
+		{"WETH/USDC", MacroArbitrage, Hit, 500},
+		{"WETH/USDC", MacroMomentum, Miss, -200},
+		{"WBTC/USDC", MacroArbitrage, Hit, 300},
+	} {
+		pnl := s.pnl
+		capital += pnl
+		strike := &MacroStrike{ID: uint64(i + 1), Symbol: s.symbol, StrikeType: s.typ, Status: s.status, PnL: &pnl, Fees: 1}
+		te.campaignStats.Record(strike.statsTrade(), start.Add(time.Duration(i+1)*time.Minute), capital)
+	}
+	rep := te.BuildReport(&report.CampaignResult{RunID: "agg", StartCapital: 100000, FinalCapital: capital})
+
+	weth := rep.BySymbol["WETH/USDC"]
+	if weth.Strikes != 2 || weth.Wins != 1 || weth.Losses != 1 || weth.PnL != 300 || weth.Fees != 2 || weth.WinRate != 0.5 {
+		t.Errorf("WETH/USDC = %+v", weth)
+	}
+	if wbtc := rep.BySymbol["WBTC/USDC"]; wbtc.Strikes != 1 || wbtc.PnL != 300 || wbtc.WinRate != 1 {
+		t.Errorf("WBTC/USDC = %+v", wbtc)
+	}
+	if arb := rep.ByStrikeType[MacroArbitrage.String()]; arb.Strikes != 2 || arb.Wins != 2 || arb.PnL != 800 {
+		t.Errorf("%s = %+v", MacroArbitrage, arb)
+	}
+	if mom := rep.ByStrikeType[MacroMomentum.String()]; mom.Strikes != 1 || mom.Losses != 1 || mom.PnL != -200 {
+		t.Errorf("%s = %+v", MacroMomentum, mom)
+	}
+	if n := len(rep.EquityCurve); n != 4 || rep.EquityCurve[3].Capital != capital {
+		t.Errorf("equity curve = %+v, want start plus 3 trades ending at %.2f", rep.EquityCurve, capital)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := rep.WriteJSON(path); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"schema_version", "generated_at", "result", "config", "by_symbol", "by_strike_type", "skip_reasons", "pnl_by_day", "pnl_by_hour", "equity_curve"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("report JSON missing %q", key)
+		}
+	}
+	if v := string(doc["schema_version"]); v != strconv.Itoa(report.SchemaVersion) {
+		t.Errorf("schema_version = %s, want %d", v, report.SchemaVersion)
+	}
+	var symbols map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(doc["by_symbol"], &symbols); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"strikes", "wins", "losses", "pnl", "fees", "funding", "win_rate"} {
+		if _, ok := symbols["WETH/USDC"][key]; !ok {
+			t.Errorf("by_symbol entry missing %q", key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// retryBudgetWindow is the rolling window RETRY_BUDGET_PER_HOUR counts over
+const retryBudgetWindow = time.Hour
+
+// RetryBudget counts exchange API retries across the campaign. Per-call
+// retries hide an API that is failing all the time; once more than PerHour
+// retries land in a rolling hour the budget is exhausted, which trips the
+// breaker and pauses trading. Calls keep retrying either way, so an exit
+// order is never left unretried. A nil budget counts nothing.
+type RetryBudget struct {
+	PerHour int
+
+	mu      sync.Mutex
+	spent   []time.Time
+	tripped bool
+}
+
+// RetryBudgetStats is the /stats view of the retry budget
+type RetryBudgetStats struct {
+	PerHour   int  `json:"per_hour"`
+	Used      int  `json:"used"`
+	Remaining int  `json:"remaining"`
+	Tripped   bool `json:"tripped"`
+}
+
+// NewRetryBudget returns nil, disabling the budget, when perHour is 0
+func NewRetryBudget(perHour int) *RetryBudget {
+	if perHour <= 0 {
+		return nil
+	}
+	return &RetryBudget{PerHour: perHour}
+}
+
+// Limit is the retries allowed per hour, 0 when disabled
+func (b *RetryBudget) Limit() int {
+	if b == nil {
+		return 0
+	}
+	return b.PerHour
+}
+
+// Spend records one retry at now. It reports true only for the retry that
+// exhausts the budget; the breaker re-arms once the window has room again.
+func (b *RetryBudget) Spend(now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	b.spent = append(b.spent, now)
+	if len(b.spent) <= b.PerHour {
+		b.tripped = false
+		return false
+	}
+	if b.tripped {
+		return false
+	}
+	b.tripped = true
+	return true
+}
+
+// pruneLocked drops retries older than the window
+func (b *RetryBudget) pruneLocked(now time.Time) {
+	cutoff := now.Add(-retryBudgetWindow)
+	i := 0
+	for i < len(b.spent) && !b.spent[i].After(cutoff) {
+		i++
+	}
+	b.spent = b.spent[i:]
+}
+
+// Stats returns the budget's use over the window ending at now; nil when
+// the budget is disabled
+func (b *RetryBudget) Stats(now time.Time) *RetryBudgetStats {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	st := &RetryBudgetStats{PerHour: b.PerHour, Used: len(b.spent), Tripped: b.tripped}
+	if st.Used < b.PerHour {
+		st.Remaining = b.PerHour - st.Used
+	}
+	return st
+}
+
+// spendRetry charges a retry of path against the campaign's retry budget,
+// pausing trading and alerting when it runs out
+func (te *TradingEngine) spendRetry(path string, cause error) {
+	if !te.retryBudget.Spend(te.Clock.Now()) {
+		return
+	}
+	log.Printf("🔌 Retry budget of %d/hour exhausted retrying %s (%v); pausing trading", te.retryBudget.PerHour, path, cause)
+	te.alert(AlertRetryBudget, "retry budget of %d/hour exhausted retrying %s: %v; trading paused", te.retryBudget.PerHour, path, cause)
+	te.Pause()
+}
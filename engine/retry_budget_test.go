@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryBudgetTripsOncePerExhaustion(t *testing.T) {
+	b := NewRetryBudget(2)
+	now := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	if b.Spend(now) || b.Spend(now.Add(time.Minute)) {
+		t.Fatal("tripped within the budget")
+	}
+	if !b.Spend(now.Add(2 * time.Minute)) {
+		t.Fatal("third retry in the hour did not trip")
+	}
+	if b.Spend(now.Add(3 * time.Minute)) {
+		t.Error("tripped again while still exhausted")
+	}
+	if st := b.Stats(now.Add(3 * time.Minute)); st.Used != 4 || st.Remaining != 0 || !st.Tripped {
+		t.Errorf("stats = %+v, want 4 used, none remaining, tripped", *st)
+	}
+
+	// Once the early retries age out the breaker re-arms
+	later := now.Add(time.Hour + 2*time.Minute)
+	if st := b.Stats(later); st.Used != 1 || st.Remaining != 1 {
+		t.Errorf("stats an hour on = %+v, want 1 used, 1 remaining", *st)
+	}
+	if b.Spend(later) {
+		t.Error("tripped with room in the window")
+	}
+	if !b.Spend(later.Add(time.Second)) {
+		t.Error("did not trip again after re-arming")
+	}
+
+	var disabled *RetryBudget
+	if disabled.Spend(now) || disabled.Stats(now) != nil || disabled.Limit() != 0 {
+		t.Error("a nil budget counted retries")
+	}
+}
+
+func TestRetryBudgetExhaustionPausesTrading(t *testing.T) {
+	fail := krakenExchangeRecord{Path: "/0/private/Balance", Response: json.RawMessage(`{"error":["EService:Unavailable"]}`)}
+	te := replayEngine(t, fail, fail, fail)
+	te.retryBudget = NewRetryBudget(1)
+
+	if _, err := te.krakenPrivateWithRetry(context.Background(), "/0/private/Balance", url.Values{}); err == nil {
+		t.Fatal("want the last Kraken error back")
+	}
+	if paused, _ := te.Paused(); !paused {
+		t.Error("trading not paused after the retry budget ran out")
+	}
+	st := te.Stats().RetryBudget
+	if st == nil || st.PerHour != 1 || st.Used != 2 || st.Remaining != 0 || !st.Tripped {
+		t.Errorf("/stats retry budget = %+v, want 2 of 1 used and tripped", st)
+	}
+}
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// RotationPolicy controls when an append-only sink starts a new file.
+// A zero MaxBytes or MaxAge disables that trigger; Keep is how many rotated
+// files (path.1 newest … path.Keep oldest) survive, or keepAll to never prune.
+type RotationPolicy struct {
+	MaxBytes int64
+	MaxAge   time.Duration
+	Keep     int
+	Compress bool
+}
+
+// keepAll retains every rotated file
+const keepAll = -1
+
+// Default rotation per sink; the Kraken capture is not rotated by default
+// since replay reads a single file. The strike log is the record of every
+// trade, so it only rotates when configured and only prunes when
+// STRIKE_LOG_ROTATE_KEEP is set.
+var (
+	defaultStrikeLogRotation = RotationPolicy{Keep: keepAll, Compress: true}
+	defaultCSVExportRotation = RotationPolicy{MaxBytes: 100 << 20, Keep: 5}
+)
+
+// enabled reports whether any rotation trigger is set
+func (p RotationPolicy) enabled() bool {
+	return p.MaxBytes > 0 || p.MaxAge > 0
+}
+
+// rotationPolicyFromConfig overrides def with <prefix>_ROTATE_MAX_MB,
+// <prefix>_ROTATE_MAX_AGE (a Go duration), <prefix>_ROTATE_KEEP and
+// <prefix>_ROTATE_COMPRESS
+func rotationPolicyFromConfig(cfg config.Config, prefix string, def RotationPolicy) (RotationPolicy, []error) {
+	p := def
+	var errs []error
+	if v := cfg.Get(prefix + "_ROTATE_MAX_MB"); v != "" {
+		if mb, err := strconv.ParseFloat(v, 64); err == nil && mb >= 0 {
+			p.MaxBytes = int64(mb * 1024 * 1024)
+		} else {
+			errs = append(errs, fmt.Errorf("%s_ROTATE_MAX_MB: %q is not a non-negative number", prefix, v))
+		}
+	}
+	if v := cfg.Get(prefix + "_ROTATE_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			p.MaxAge = d
+		} else {
+			errs = append(errs, fmt.Errorf("%s_ROTATE_MAX_AGE: %q is not a non-negative duration", prefix, v))
+		}
+	}
+	if v := cfg.Get(prefix + "_ROTATE_KEEP"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			p.Keep = n
+		} else {
+			errs = append(errs, fmt.Errorf("%s_ROTATE_KEEP: %q is not a non-negative integer", prefix, v))
+		}
+	}
+	if v := cfg.Get(prefix + "_ROTATE_COMPRESS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			p.Compress = b
+		} else {
+			errs = append(errs, fmt.Errorf("%s_ROTATE_COMPRESS: %q is not a boolean", prefix, v))
+		}
+	}
+	return p, errs
+}
+
+// sinkRotation reads a sink's rotation policy, recording invalid settings as
+// config errors
+func (te *TradingEngine) sinkRotation(prefix string, def RotationPolicy) RotationPolicy {
+	p, errs := rotationPolicyFromConfig(te.config, prefix, def)
+	te.configErrors = append(te.configErrors, errs...)
+	return p
+}
+
+// RotatingFile is an append-only file that rolls over per its policy.
+// Rotation only happens between Write calls, so callers that write one
+// whole record per Write never see a record split across files. The live
+// file is renamed before its replacement is opened; if the reopen fails,
+// writes continue on the renamed file rather than being dropped.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	policy   RotationPolicy
+	header   []byte
+	perm     os.FileMode
+	file     *os.File
+	size     int64
+	opened   time.Time
+	now      func() time.Time
+	compress sync.WaitGroup
+}
+
+// OpenRotatingFile opens path for appending. header, if any, is written to
+// every new (empty) file. Age is measured from when this process opened or
+// created the live file.
+func OpenRotatingFile(path string, perm os.FileMode, policy RotationPolicy, header []byte) (*RotatingFile, error) {
+	rf := &RotatingFile{path: path, policy: policy, header: header, perm: perm, now: time.Now}
+	f, size, err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+	rf.file, rf.size, rf.opened = f, size, rf.now()
+	return rf, nil
+}
+
+// open opens the live path, writing the header when it is empty
+func (rf *RotatingFile) open() (*os.File, int64, error) {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, rf.perm)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	size := info.Size()
+	if size == 0 && len(rf.header) > 0 {
+		n, err := f.Write(rf.header)
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		size = int64(n)
+	}
+	return f, size, nil
+}
+
+// Write appends p, rotating first if p would push the file past its limits
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.shouldRotate(len(p)) {
+		rf.rotate()
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// shouldRotate reports whether the live file has content beyond its header
+// and is over size or age
+func (rf *RotatingFile) shouldRotate(next int) bool {
+	if !rf.policy.enabled() || rf.size <= int64(len(rf.header)) {
+		return false
+	}
+	if rf.policy.MaxBytes > 0 && rf.size+int64(next) > rf.policy.MaxBytes {
+		return true
+	}
+	return rf.policy.MaxAge > 0 && rf.now().Sub(rf.opened) >= rf.policy.MaxAge
+}
+
+// rotate shifts older files up one slot, renames the live file to path.1 and
+// opens a fresh live file
+func (rf *RotatingFile) rotate() {
+	if err := rf.file.Sync(); err != nil {
+		log.Printf("⚠️ Rotate %s: sync: %v", rf.path, err)
+	}
+	// A previous compression may still be reading path.1
+	rf.compress.Wait()
+	oldest := rf.policy.Keep
+	if oldest == keepAll {
+		// Shift every existing rotated file; nothing falls off the end
+		oldest = 1
+		for rf.rotatedExists(oldest) {
+			oldest++
+		}
+	}
+	for i := oldest; i >= 1; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			src := rf.rotatedName(i) + ext
+			if _, err := os.Stat(src); err != nil {
+				continue
+			}
+			if i == rf.policy.Keep {
+				os.Remove(src)
+			} else {
+				os.Rename(src, rf.rotatedName(i+1)+ext)
+			}
+		}
+	}
+	rotated := rf.rotatedName(1)
+	if rf.policy.Keep == 0 {
+		// Nothing is kept, but rename first so the old file stays intact until the new one opens
+		rotated = rf.path + ".discard"
+	}
+	if err := os.Rename(rf.path, rotated); err != nil {
+		log.Printf("⚠️ Rotate %s: %v; continuing in the current file", rf.path, err)
+		return
+	}
+	f, size, err := rf.open()
+	if err != nil {
+		// The old descriptor still points at the renamed file, so keep writing there
+		log.Printf("⚠️ Rotate %s: reopen: %v; continuing in %s", rf.path, err, rotated)
+		return
+	}
+	oldSize := rf.size
+	rf.file.Close()
+	rf.file, rf.size, rf.opened = f, size, rf.now()
+
+	if rf.policy.Keep == 0 {
+		os.Remove(rotated)
+		log.Printf("♻️ Rotated %s (%d bytes discarded)", rf.path, oldSize)
+		return
+	}
+	log.Printf("♻️ Rotated %s → %s (%d bytes)", rf.path, rotated, oldSize)
+	if rf.policy.Compress {
+		rf.compress.Add(1)
+		go func() {
+			defer rf.compress.Done()
+			if err := gzipFile(rotated); err != nil {
+				log.Printf("⚠️ Compress %s: %v", rotated, err)
+			}
+		}()
+	}
+}
+
+// rotatedExists reports whether the i-th rotated file exists, compressed or not
+func (rf *RotatingFile) rotatedExists(i int) bool {
+	for _, ext := range []string{"", ".gz"} {
+		if _, err := os.Stat(rf.rotatedName(i) + ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rotatedName returns the path of the i-th rotated file
+func (rf *RotatingFile) rotatedName(i int) string {
+	return rf.path + "." + strconv.Itoa(i)
+}
+
+// Close waits for pending compression and closes the live file
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.compress.Wait()
+	return rf.file.Close()
+}
+
+// gzipFile replaces path with path.gz, removing the original only once the
+// compressed copy is safely on disk
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
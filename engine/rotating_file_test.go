@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// readLines returns the lines of path, transparently gunzipping .gz files
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	}
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
+
+func TestRotatingFileKeepsEveryRecordAndHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.csv")
+	policy := RotationPolicy{MaxBytes: 64, Keep: 100, Compress: true}
+	rf, err := OpenRotatingFile(path, 0644, policy, []byte("id,pnl\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const records = 40
+	for i := 0; i < records; i++ {
+		if _, err := fmt.Fprintf(rf, "%d,%d.5\n", i, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Oldest rotated file first, live file last
+	files, _ := filepath.Glob(path + ".*.gz")
+	if len(files) < 2 {
+		t.Fatalf("expected several compressed rotations, got %v", files)
+	}
+	var ordered []string
+	for i := len(files); i >= 1; i-- {
+		ordered = append(ordered, fmt.Sprintf("%s.%d.gz", path, i))
+	}
+	ordered = append(ordered, path)
+
+	next := 0
+	for _, f := range ordered {
+		lines := readLines(t, f)
+		if len(lines) == 0 || lines[0] != "id,pnl" {
+			t.Fatalf("%s does not start with the header: %q", f, lines)
+		}
+		for _, l := range lines[1:] {
+			if want := fmt.Sprintf("%d,%d.5", next, next); l != want {
+				t.Fatalf("%s: got record %q, want %q", f, l, want)
+			}
+			next++
+		}
+	}
+	if next != records {
+		t.Fatalf("recovered %d records across rotations, want %d", next, records)
+	}
+	if left, _ := filepath.Glob(path + ".*[0-9]"); len(left) != 0 {
+		t.Errorf("uncompressed rotations left behind: %v", left)
+	}
+}
+
+func TestRotatingFileAgeTriggerAndKeepLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	rf, err := OpenRotatingFile(path, 0600, RotationPolicy{MaxAge: time.Hour, Keep: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return now }
+	rf.opened = now
+
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(rf, "record %d\n", i)
+		// Another record in the same hour never rotates
+		fmt.Fprintf(rf, "record %d bis\n", i)
+		now = now.Add(time.Hour)
+	}
+	rf.Close()
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("only 2 rotated files should be kept")
+	}
+	for suffix, want := range map[string]string{"": "record 4", ".1": "record 3", ".2": "record 2"} {
+		lines := readLines(t, path+suffix)
+		if len(lines) != 2 || lines[0] != want {
+			t.Errorf("%s%s = %q, want it to start with %q", path, suffix, lines, want)
+		}
+	}
+}
+
+func TestStrikeLogRotationPrunesOnlyWhenConfigured(t *testing.T) {
+	if defaultStrikeLogRotation.enabled() {
+		t.Fatalf("strike log rotates by default: %+v", defaultStrikeLogRotation)
+	}
+
+	// A size limit alone rotates but keeps every file
+	t.Setenv("STRIKE_LOG_ROTATE_MAX_MB", "0.0001")
+	t.Setenv("STRIKE_LOG_ROTATE_COMPRESS", "false")
+	policy, errs := rotationPolicyFromConfig(config.EnvConfig(), "STRIKE_LOG", defaultStrikeLogRotation)
+	if len(errs) != 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	rf, err := OpenRotatingFile(path, 0600, policy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const records = 60
+	for i := 0; i < records; i++ {
+		if _, err := fmt.Fprintf(rf, "{\"id\":%d}\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rotated, _ := filepath.Glob(path + ".*")
+	if len(rotated) < 2 {
+		t.Fatalf("expected several rotations, got %v", rotated)
+	}
+	total := len(readLines(t, path))
+	for _, f := range rotated {
+		total += len(readLines(t, f))
+	}
+	if total != records {
+		t.Errorf("%d records survive rotation, want all %d", total, records)
+	}
+}
+
+func TestRotationPolicyFromConfig(t *testing.T) {
+	t.Setenv("STRIKE_LOG_ROTATE_MAX_MB", "0.5")
+	t.Setenv("STRIKE_LOG_ROTATE_MAX_AGE", "24h")
+	t.Setenv("STRIKE_LOG_ROTATE_COMPRESS", "false")
+	p, errs := rotationPolicyFromConfig(config.EnvConfig(), "STRIKE_LOG", defaultStrikeLogRotation)
+	if len(errs) != 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+	want := RotationPolicy{MaxBytes: 512 << 10, MaxAge: 24 * time.Hour, Keep: defaultStrikeLogRotation.Keep}
+	if p != want {
+		t.Errorf("policy = %+v, want %+v", p, want)
+	}
+
+	t.Setenv("CSV_EXPORT_ROTATE_KEEP", "-1")
+	if _, errs := rotationPolicyFromConfig(config.EnvConfig(), "CSV_EXPORT", defaultCSVExportRotation); len(errs) != 1 {
+		t.Errorf("negative keep should be rejected, got %v", errs)
+	}
+}
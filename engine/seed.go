@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+// Per-strike random streams. Each strike draws from its own source derived
+// from RandSeed and the strike ID, so one strike replays without replaying
+// the campaign before it: generation and execution get separate streams,
+// keeping a change to one from shifting the other.
+const (
+	streamGenerate uint64 = iota
+	streamExecute
+)
+
+// streamPoll draws fill-poll jitter, so a replayed live strike polls on the
+// same schedule. It sits in the top bit, clear of the generate/execute bit
+// and of any real strike ID shifted past it.
+const streamPoll uint64 = 1 << 63
+
+// strikeRand returns the random stream for one phase of one strike
+func (te *TradingEngine) strikeRand(strikeID, stream uint64) *rand.Rand {
+	return rand.New(rand.NewSource(int64(splitmix64(uint64(te.RandSeed) ^ splitmix64(strikeID<<1|stream)))))
+}
+
+// splitmix64 spreads nearby inputs (consecutive strike IDs) over the seed space
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// PointSeed derives the seed of sweep point i from the sweep's base seed, so
+// each point draws independent streams yet the whole sweep reruns from one
+// RAND_SEED. The top bit is dropped since RAND_SEED is non-negative.
+func PointSeed(base int64, i int) int64 {
+	return int64(splitmix64(uint64(base)^uint64(i)) >> 1)
+}
+
+// newRunID returns a sortable identifier for one engine run: the time from
+// the engine clock and a suffix from the run's seed, so a seeded rerun on a
+// fake clock is named the same. run tells looped campaigns apart.
+func newRunID(now time.Time, seed int64, run uint64) string {
+	return fmt.Sprintf("%s-%04x", now.UTC().Format("20060102T150405"), splitmix64(uint64(seed)^run)&0xffff)
+}
+
+// replayGroups are the settings groups a reproduction keeps: the ones that
+// shape how a simulated strike is generated and executed. Sinks, alerts,
+// state files and servers stay with the engine being replayed.
+var replayGroups = map[string]bool{"Mode": true, "Selection": true, "Risk": true, "Orders": true, "Performance": true}
+
+// ReproduceStrike regenerates and re-executes one simulated strike of the
+// run seeded with seed, on a throwaway copy of the engine with a fake clock,
+// and returns it. Levels, confidence and the hit/miss draw match the
+// original; PnL scales with te.Capital, so set it to the capital the strike
+// log recorded for the strike first. te itself is left untouched: nothing
+// is booked, journaled or sent. Analyzer-driven and live strikes depend on
+// market data and cannot be replayed this way.
+func (te *TradingEngine) ReproduceStrike(ctx context.Context, seed int64, strikeID uint64) (*MacroStrike, error) {
+	if te.LiveTrading || !te.SimMode {
+		return nil, fmt.Errorf("only simulated strikes can be reproduced")
+	}
+	if strikeID == 0 {
+		return nil, fmt.Errorf("strike IDs start at 1")
+	}
+	cfg := make(config.Config)
+	for _, s := range config.Settings {
+		if v, ok := te.config.Lookup(s.Env); ok && replayGroups[s.Group] {
+			cfg[s.Env] = v
+		}
+	}
+	delete(cfg, "PERF_STORE_FILE")
+	cfg["RAND_SEED"] = strconv.FormatInt(seed, 10)
+	replay, err := NewValidated(cfg, clock.NewFake(te.Clock.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer replay.Close()
+	replay.perfStore = te.perfStore.clone()
+	atomic.StoreInt64(&replay.Capital, atomic.LoadInt64(&te.Capital))
+	atomic.StoreInt64(&replay.PeakCapital, atomic.LoadInt64(&te.PeakCapital))
+	atomic.StoreUint64(&replay.NextStrikeID, strikeID-1)
+
+	strike, err := replay.GenerateStrike(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("strike %d was not generated: %w", strikeID, err)
+	}
+	if _, err := replay.ExecuteStrike(ctx, strike); err != nil {
+		return strike, err
+	}
+	return strike, nil
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestReproduceStrikeReplaysOneSimulatedStrike(t *testing.T) {
+	newEngine := func() *TradingEngine {
+		te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "42"})
+		te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+		return te
+	}
+
+	type outcome struct {
+		capital int64
+		strike  MacroStrike
+	}
+	te := newEngine()
+	var runs []outcome
+	for len(runs) < 6 {
+		strike, err := te.GenerateStrike(context.Background())
+		if err != nil {
+			continue
+		}
+		capital := atomic.LoadInt64(&te.Capital)
+		if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+			t.Fatalf("strike %d: %v", strike.ID, err)
+		}
+		runs = append(runs, outcome{capital, *strike})
+	}
+
+	want := runs[4]
+	replay := newEngine()
+	atomic.StoreInt64(&replay.Capital, want.capital)
+	got, err := replay.ReproduceStrike(context.Background(), 42, want.strike.ID)
+	if err != nil {
+		t.Fatalf("ReproduceStrike: %v", err)
+	}
+	if got.Symbol != want.strike.Symbol || got.StrikeType != want.strike.StrikeType || got.Confidence != want.strike.Confidence ||
+		got.Status != want.strike.Status || *got.ExitPrice != *want.strike.ExitPrice || *got.PnL != *want.strike.PnL {
+		t.Errorf("replayed %s %v conf %.4f %v exit %.4f pnl %.4f, want %s %v conf %.4f %v exit %.4f pnl %.4f",
+			got.Symbol, got.StrikeType, got.Confidence, got.Status, *got.ExitPrice, *got.PnL,
+			want.strike.Symbol, want.strike.StrikeType, want.strike.Confidence, want.strike.Status, *want.strike.ExitPrice, *want.strike.PnL)
+	}
+
+	if _, err := replayEngine(t).ReproduceStrike(context.Background(), 42, 1); err == nil {
+		t.Error("a live engine reproduced a strike")
+	}
+}
+
+func TestReproduceStrikeLeavesTheEngineUntouched(t *testing.T) {
+	strikeLog := filepath.Join(t.TempDir(), "strikes.log")
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "42", "STRIKE_LOG": strikeLog})
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	defer te.Close()
+	before := te.Snapshot()
+	seed, next := te.RandSeed, atomic.LoadUint64(&te.NextStrikeID)
+	perf := te.perfStore.Snapshot(te.Clock.Now())
+
+	strike, err := te.ReproduceStrike(context.Background(), 7, 3)
+	if err != nil {
+		t.Fatalf("ReproduceStrike: %v", err)
+	}
+	if strike.ID != 3 || strike.PnL == nil {
+		t.Fatalf("reproduced strike %d pnl %v, want strike 3 executed", strike.ID, strike.PnL)
+	}
+	if after := te.Snapshot(); after != before {
+		t.Errorf("snapshot %+v after reproducing, want %+v", after, before)
+	}
+	if te.RandSeed != seed || atomic.LoadUint64(&te.NextStrikeID) != next {
+		t.Errorf("seed %d next ID %d, want %d and %d", te.RandSeed, atomic.LoadUint64(&te.NextStrikeID), seed, next)
+	}
+	if got := te.perfStore.Snapshot(te.Clock.Now()); !reflect.DeepEqual(got, perf) {
+		t.Errorf("performance store %+v after reproducing, want %+v", got, perf)
+	}
+	te.StrikeLog.Close()
+	if data, err := os.ReadFile(strikeLog); err == nil && len(data) > 0 {
+		t.Errorf("strike log written by a reproduction: %s", data)
+	}
+}
+
+func TestConcurrentEnginesWithOneSeedMatch(t *testing.T) {
+	run := func() int64 {
+		te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "7"})
+		te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+		for executed := 0; executed < 50; {
+			strike, err := te.generateAnalyzedStrike(context.Background())
+			if err != nil {
+				continue
+			}
+			if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+				t.Errorf("strike %d: %v", strike.ID, err)
+				return 0
+			}
+			executed++
+		}
+		return atomic.LoadInt64(&te.Capital)
+	}
+
+	// Engines share no random state, so interleaving cannot change a result
+	capitals := make([]int64, 4)
+	done := make(chan struct{})
+	for i := range capitals {
+		go func(i int) {
+			capitals[i] = run()
+			done <- struct{}{}
+		}(i)
+	}
+	for range capitals {
+		<-done
+	}
+	for i, c := range capitals {
+		if c != capitals[0] {
+			t.Errorf("engine %d ended with %d cents, engine 0 with %d", i, c, capitals[0])
+		}
+	}
+}
+
+func TestSweepPointSeedsAreIndependentAndRepeatable(t *testing.T) {
+	seen := make(map[int64]bool)
+	for i := 0; i < 8; i++ {
+		s := PointSeed(42, i)
+		if s < 0 || seen[s] || s == 42 {
+			t.Fatalf("point %d seed %d is negative, repeated or the base", i, s)
+		}
+		seen[s] = true
+		if s != PointSeed(42, i) {
+			t.Fatalf("point %d seed is not repeatable", i)
+		}
+	}
+	if PointSeed(42, 0) == PointSeed(43, 0) {
+		t.Error("different base seeds gave point 0 the same seed")
+	}
+}
+
+func TestRunIDFollowsClockAndSeed(t *testing.T) {
+	start := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+	newEngine := func() *TradingEngine {
+		te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "42"})
+		te.Clock = clock.NewFake(start)
+		te.nextCampaign()
+		return te
+	}
+	a, b := newEngine(), newEngine()
+	if a.RunID != b.RunID || !strings.HasPrefix(a.RunID, "20250106T093000-") {
+		t.Errorf("run IDs %q and %q, want one stamped from the fake clock", a.RunID, b.RunID)
+	}
+	first := a.RunID
+	a.nextCampaign()
+	if a.RunID == first {
+		t.Errorf("looped campaign reused run ID %q", first)
+	}
+}
+
+func TestDefaultSeedComesFromTheEngineClock(t *testing.T) {
+	start := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+	a := New(config.Config{"SIM_MODE": "1"}, clock.NewFake(start))
+	b := New(config.Config{"SIM_MODE": "1"}, clock.NewFake(start))
+	if a.RandSeed != start.UnixNano() || a.RandSeed != b.RandSeed || a.RunID != b.RunID {
+		t.Errorf("seeds %d/%d run IDs %q/%q, want both drawn from the fake clock", a.RandSeed, b.RandSeed, a.RunID, b.RunID)
+	}
+}
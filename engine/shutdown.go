@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/report"
+)
+
+// stopPollInterval is how often interruptible waits check for a stop request
+const stopPollInterval = 250 * time.Millisecond
+
+// defaultShutdownGrace bounds how long a shutdown waits for the in-flight strike
+const defaultShutdownGrace = 60 * time.Second
+
+// Stop asks the campaign to wind down: no new strikes are generated, and a
+// live strike in its hold period moves straight to its exit. Safe to call
+// more than once and from any goroutine.
+func (te *TradingEngine) Stop() {
+	te.stopOnce.Do(func() { close(te.stopCh) })
+}
+
+// stopRequested reports whether Stop has been called
+func (te *TradingEngine) stopRequested() bool {
+	if te.stopCh == nil {
+		return false
+	}
+	select {
+	case <-te.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepUnlessStopped waits d on the engine clock, returning early once a stop
+// is requested or ctx is done. The wait keeps the loop heartbeat fresh: a long pause is
+// not a wedged loop.
+func (te *TradingEngine) sleepUnlessStopped(ctx context.Context, d time.Duration) {
+	deadline := te.Clock.Now().Add(d)
+	for !te.stopRequested() {
+		te.beat()
+		left := deadline.Sub(te.Clock.Now())
+		if left <= 0 {
+			return
+		}
+		if left > stopPollInterval {
+			left = stopPollInterval
+		}
+		if clock.SleepContext(ctx, te.Clock, left) != nil {
+			return
+		}
+	}
+}
+
+// runCampaignWithSignals runs the campaign, turning the end of ctx (main's
+// SIGINT/SIGTERM context) into a graceful stop. The in-flight strike gets
+// ShutdownGrace to reach its exit; past that, the campaign's own context is
+// cancelled, open positions are flattened and the process exits after
+// flushing. A second signal exits immediately. SIGUSR1 pauses new strikes
+// and SIGUSR2 resumes them.
+func (te *TradingEngine) runCampaignWithSignals(ctx context.Context) *report.CampaignResult {
+	shutdown := ctx
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	controls := make(chan os.Signal, 1)
+	signal.Notify(controls, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(controls)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-controls:
+				if sig == syscall.SIGUSR1 {
+					te.Pause()
+				} else {
+					te.Resume()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		select {
+		case <-shutdown.Done():
+		case <-done:
+			return
+		}
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigs)
+		log.Printf("🛑 %v: no new strikes, waiting up to %v for the in-flight strike (signal again to force exit)", context.Cause(shutdown), te.ShutdownGrace)
+		te.Stop()
+		deadline := time.NewTimer(te.ShutdownGrace)
+		defer deadline.Stop()
+		select {
+		case sig := <-sigs:
+			log.Printf("🛑 %v received again: exiting immediately", sig)
+			os.Exit(130)
+		case <-deadline.C:
+			log.Printf("🚨 Shutdown deadline of %v passed; flattening open positions", te.ShutdownGrace)
+			cancel()
+			// The flatten is owed even though the campaign's context is over
+			te.flattenOpenPositions(context.WithoutCancel(ctx), "Shutdown deadline flatten")
+			if err := te.SaveState(); err != nil {
+				log.Printf("⚠️ State snapshot failed: %v", err)
+			}
+			te.Close()
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	return te.ExecuteCampaign(ctx)
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/exchange"
+	"macro-strike-bot/report"
+)
+
+// stoppingGenerator requests a stop while handing out its stopAt'th strike,
+// as a signal arriving mid-campaign would
+type stoppingGenerator struct {
+	te     *TradingEngine
+	inner  StrikeGenerator
+	stopAt int
+	calls  int
+}
+
+func (g *stoppingGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	g.calls++
+	if g.calls == g.stopAt {
+		g.te.Stop()
+	}
+	return g.inner.NextStrike(ctx)
+}
+
+func TestStopFinishesInFlightStrikeAndFlushes(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("STATE_FILE", filepath.Join(t.TempDir(), "state.json"))
+	te := NewTradingEngine()
+	te.Generator = &stoppingGenerator{te: te, stopAt: 2, inner: &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, true)},
+		{Strike: certainStrike(3, true)},
+	}}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopShutdown {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopShutdown)
+	}
+	// The strike generated as the stop arrived still runs to its exit
+	if result.TradesCompleted != 2 {
+		t.Errorf("trades completed = %d, want 2", result.TradesCompleted)
+	}
+	if _, err := os.Stat(te.StateFile); err != nil {
+		t.Errorf("state not flushed on shutdown: %v", err)
+	}
+	te.Stop() // a second stop is harmless
+}
+
+func TestStopCutsLiveHoldShort(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.01","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.01","price":"2510"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.Stop()
+
+	start := te.Clock.Now()
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if *strike.ExitTxID != "SELL1" || pnl <= 0 {
+		t.Errorf("exit %s pnl %.2f, want the position sold at 2510", *strike.ExitTxID, pnl)
+	}
+	if held := te.Clock.Since(start); held >= 20*time.Second {
+		t.Errorf("held %v after a stop, want the 20s hold skipped", held)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions left open", len(te.openPositions))
+	}
+}
+
+// cancelingGenerator cancels the campaign's context while handing out its
+// cancelAt'th strike
+type cancelingGenerator struct {
+	inner    StrikeGenerator
+	cancel   context.CancelFunc
+	cancelAt int
+	calls    int
+}
+
+func (g *cancelingGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	g.calls++
+	if g.calls == g.cancelAt {
+		g.cancel()
+	}
+	return g.inner.NextStrike(ctx)
+}
+
+func TestCancelledContextStopsCampaign(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	te.Generator = &cancelingGenerator{cancel: cancel, cancelAt: 2, inner: &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, true)},
+		{Strike: certainStrike(3, true)},
+	}}}
+
+	result := te.ExecuteCampaign(ctx)
+	if result.StopReason != report.StopCanceled || result.TradesCompleted != 2 {
+		t.Errorf("stop %q after %d trades, want %q after the in-flight strike", result.StopReason, result.TradesCompleted, report.StopCanceled)
+	}
+}
+
+// cancelingExchange cancels the strike's context once the entry fill is read
+type cancelingExchange struct {
+	exchange.Exchange
+	cancel context.CancelFunc
+}
+
+func (e cancelingExchange) GetOrder(ctx context.Context, txid string) (exchange.OrderInfo, error) {
+	if txid == "BUY1" {
+		defer e.cancel()
+	}
+	return e.Exchange.GetOrder(ctx, txid)
+}
+
+func TestCancelledContextStillExitsLivePosition(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.01","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.01","price":"2510"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	te.Exchange = cancelingExchange{Exchange: KrakenExchange{te}, cancel: cancel}
+
+	start := te.Clock.Now()
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	pnl, err := te.ExecuteStrike(ctx, strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if strike.ExitTxID == nil || *strike.ExitTxID != "SELL1" || pnl <= 0 {
+		t.Errorf("exit %v pnl %.2f, want the position sold at 2510 despite the cancel", strike.ExitTxID, pnl)
+	}
+	if held := te.Clock.Since(start); held >= 20*time.Second {
+		t.Errorf("held %v after the cancel, want the 20s hold cut short", held)
+	}
+	if len(te.openPositions) != 0 {
+		t.Errorf("%d positions left open", len(te.openPositions))
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSimLevelSourcesProduceDifferentHitRates(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+
+	// Same confidence, different levels: the analyst bracket favours the target
+	run := func(source string, target, stop float64) {
+		for i := 0; i < 400; i++ {
+			strike := &MacroStrike{ID: uint64(i + 1), Symbol: "WETH/USDC", StrikeType: MacroArbitrage, EntryPrice: 3000,
+				TargetPrice: target, StopLoss: stop, Confidence: 0.6, LevelSource: source}
+			if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+				t.Fatalf("ExecuteStrike: %v", err)
+			}
+			if want := map[StrikeStatus]float64{Hit: target, Miss: stop}[strike.Status]; *strike.ExitPrice != want {
+				t.Fatalf("%s strike exited at %.2f, want the touched level %.2f", strike.Status, *strike.ExitPrice, want)
+			}
+			te.recordLevelOutcome(strike)
+		}
+	}
+	run(LevelSourceAnalyst, 3030, 2910)
+	run(LevelSourceFormula, 3090, 2970)
+
+	stats := te.LevelStats()
+	analyst, formula := stats[LevelSourceAnalyst].HitRate, stats[LevelSourceFormula].HitRate
+	// Expected ~0.80 vs ~0.42; 400 draws each keep the gap well clear of noise
+	if analyst-formula < 0.25 {
+		t.Errorf("analyst hit rate %.3f vs formula %.3f; level geometry should separate them", analyst, formula)
+	}
+}
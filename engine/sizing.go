@@ -0,0 +1,59 @@
+package engine
+
+// sizingFactor is the product of the liquidity, momentum and performance
+// factors recorded on a strike. A zero factor means "not computed" and is
+// treated as neutral.
+func sizingFactor(strike *MacroStrike) float64 {
+	factor := 1.0
+	for _, f := range []float64{strike.LiquidityFactor, strike.MomentumFactor, strike.PerformanceFactor} {
+		if f > 0 {
+			factor *= f
+		}
+	}
+	return factor
+}
+
+// baseStrikeSize returns the unlevered USD size of a strike: StrikeForce of
+// capital scaled by confidence and the strike's sizing factors
+func baseStrikeSize(capital float64, strike *MacroStrike) float64 {
+	return capital * StrikeForce * strike.Confidence * sizingFactor(strike)
+}
+
+// liveOrderUSD is the notional of a live entry: OrderUSDSize scaled by the
+// same sizing factors as the simulation, so thin books trade smaller live too
+func (te *TradingEngine) liveOrderUSD(strike *MacroStrike) float64 {
+	return te.OrderUSDSize * sizingFactor(strike)
+}
+
+// liquidityFactor shrinks size on thin books around a neutral score of 0.5:
+// neutral or better keeps full size (with the default max of 1), lower
+// scores scale down by weight, clamped to [min, max]
+func liquidityFactor(liquidity, weight, min, max float64) float64 {
+	return clampFloat(1.0+weight*(liquidity-0.5)*2.0, min, max)
+}
+
+// momentumFactor boosts or penalises MacroMomentum strikes around a neutral
+// score of 0.5; other strike types are unaffected
+func momentumFactor(strikeType StrikeType, momentum, weight, min, max float64) float64 {
+	if strikeType != MacroMomentum {
+		return 1.0
+	}
+	return clampFloat(1.0+weight*(momentum-0.5)*2.0, min, max)
+}
+
+// precisionConfidence blends the analysis precision score into confidence:
+// weight 0 ignores precision, weight 1 multiplies it in fully
+func precisionConfidence(confidence, precision, weight float64) float64 {
+	return confidence * (1.0 - weight + weight*precision)
+}
+
+// clampFloat limits v to [lo, hi]
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLiquidityFactorIsNeutralAtHalf(t *testing.T) {
+	for _, tc := range []struct {
+		liquidity, want float64
+	}{
+		{0.5, 1.0}, // neutral score keeps full size
+		{0.9, 1.0}, // deep books are capped at full size
+		{0.25, 0.75},
+		{0.0, 0.5},
+	} {
+		if got := liquidityFactor(tc.liquidity, 0.5, 0.25, 1.0); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("liquidityFactor(%.2f) = %.3f, want %.3f", tc.liquidity, got, tc.want)
+		}
+	}
+	if got := liquidityFactor(0.0, 1.0, 0.25, 1.0); got != 0.25 {
+		t.Errorf("full weight on an empty book = %.3f, want the 0.25 floor", got)
+	}
+}
+
+func TestMomentumFactorOnlyScalesMomentumStrikes(t *testing.T) {
+	if got := momentumFactor(MacroMomentum, 0.75, 0.5, 0.5, 1.5); got != 1.25 {
+		t.Errorf("strong momentum = %.3f, want 1.25", got)
+	}
+	if got := momentumFactor(MacroMomentum, 0.0, 1.0, 0.5, 1.5); got != 0.5 {
+		t.Errorf("no momentum at full weight = %.3f, want the 0.5 floor", got)
+	}
+	if got := momentumFactor(MacroFlash, 1.0, 0.5, 0.5, 1.5); got != 1.0 {
+		t.Errorf("non-momentum strike = %.3f, want 1", got)
+	}
+}
+
+func TestPrecisionWeightBlendsPrecisionIntoConfidence(t *testing.T) {
+	for _, tc := range []struct {
+		weight, want float64
+	}{
+		{1.0, 0.45}, // the default multiplies precision in fully
+		{0.0, 0.90}, // ignores precision
+		{0.5, 0.675},
+	} {
+		if got := precisionConfidence(0.9, 0.5, tc.weight); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("weight %.1f: confidence = %.4f, want %.4f", tc.weight, got, tc.want)
+		}
+	}
+	t.Setenv("PRECISION_WEIGHT", "1.5")
+	if err := NewTradingEngine().ValidateConfig(); err == nil || !strings.Contains(err.Error(), "precision weight") {
+		t.Errorf("PRECISION_WEIGHT=1.5 validation error = %v", err)
+	}
+}
+
+func TestStrikeSizingAppliesFactorsInSimAndLive(t *testing.T) {
+	te := &TradingEngine{OrderUSDSize: 100}
+	strike := &MacroStrike{Confidence: 0.8, LiquidityFactor: 0.5, MomentumFactor: 1.2, PerformanceFactor: 0.5}
+
+	// $10k × 15% × 0.8 confidence × 0.5 × 1.2 × 0.5
+	if got := baseStrikeSize(10000, strike); math.Abs(got-360) > 1e-9 {
+		t.Errorf("baseStrikeSize = %.4f, want 360", got)
+	}
+	// Live ignores confidence but shrinks by the same factors
+	if got := te.liveOrderUSD(strike); math.Abs(got-30) > 1e-9 {
+		t.Errorf("liveOrderUSD = %.4f, want 30", got)
+	}
+
+	// Factors that were never computed are neutral
+	unset := &MacroStrike{Confidence: 1}
+	if got := baseStrikeSize(10000, unset); got != 1500 {
+		t.Errorf("baseStrikeSize with unset factors = %.4f, want 1500", got)
+	}
+	if got := te.liveOrderUSD(unset); got != 100 {
+		t.Errorf("liveOrderUSD with unset factors = %.4f, want 100", got)
+	}
+}
+
+func TestInvalidSizingSettingsAreConfigErrors(t *testing.T) {
+	t.Setenv("LIQUIDITY_WEIGHT", "half")
+	t.Setenv("MOMENTUM_FACTOR_MAX", "-1")
+	te := NewTradingEngine()
+	if te.LiquidityWeight != 0.5 || te.MomentumFactorMax != 1.5 {
+		t.Errorf("invalid values should keep the defaults, got %.2f / %.2f", te.LiquidityWeight, te.MomentumFactorMax)
+	}
+	err := te.ValidateConfig()
+	if err == nil {
+		t.Fatal("unparseable sizing settings passed validation")
+	}
+	for _, name := range []string{"LIQUIDITY_WEIGHT", "MOMENTUM_FACTOR_MAX"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("validation error %q does not mention %s", err, name)
+		}
+	}
+}
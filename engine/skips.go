@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Skip reasons tallied in the campaign skip stats
+const (
+	SkipAnalysisUnavailable = "analysis_unavailable"
+	SkipLowConfidence       = "low_confidence"
+	SkipPriceDeviation      = "price_deviation"
+	SkipTickerUnavailable   = "ticker_unavailable"
+	SkipStablecoinType      = "stablecoin_type"
+	SkipPoorPerformance     = "poor_performance"
+	SkipRiskReward          = "risk_reward"
+	SkipVolatility          = "volatility"
+	SkipDirection           = "direction"
+	SkipSymbolCooldown      = "symbol_cooldown"
+	SkipOrderMinimum        = "order_minimum"
+	SkipNotionalCap         = "notional_cap"
+	SkipSymbolPositions     = "symbol_positions"
+	SkipCostHaircut         = "cost_haircut"
+	SkipNonFinite           = "nonfinite_analysis"
+	SkipOther               = "other"
+)
+
+// skipError marks a setup the campaign passes over without counting a trade.
+// Its message keeps the "skip:" prefix the campaign loop has always matched on.
+type skipError struct {
+	Reason string
+	Detail string
+}
+
+func (e *skipError) Error() string {
+	return "skip: " + e.Detail
+}
+
+// newSkip builds a skipError with a stable reason and a formatted detail
+func newSkip(reason, format string, args ...interface{}) error {
+	return &skipError{Reason: reason, Detail: fmt.Sprintf(format, args...)}
+}
+
+// recordSkip counts a skipped setup under its reason
+func (te *TradingEngine) recordSkip(err error) {
+	reason := SkipOther
+	var se *skipError
+	if errors.As(err, &se) {
+		reason = se.Reason
+	}
+	te.skipMu.Lock()
+	te.skipCounts[reason]++
+	te.skipMu.Unlock()
+	if te.SkipLog {
+		log.Printf("⏭️ Skipped (%s): %s", reason, strings.TrimPrefix(err.Error(), "skip: "))
+	}
+	te.publish(EventStrikeSkipped, nil, map[string]interface{}{"reason": reason, "detail": err.Error()})
+}
+
+// SkipCounts returns the number of skipped setups per reason
+func (te *TradingEngine) SkipCounts() map[string]int64 {
+	te.skipMu.Lock()
+	defer te.skipMu.Unlock()
+	out := make(map[string]int64, len(te.skipCounts))
+	for reason, n := range te.skipCounts {
+		out[reason] = n
+	}
+	return out
+}
+
+// logSkipSummary logs the campaign's skips per reason when SKIP_LOG is set
+func (te *TradingEngine) logSkipSummary() {
+	if !te.SkipLog {
+		return
+	}
+	counts := te.SkipCounts()
+	if len(counts) == 0 {
+		log.Printf("⏭️ No setups skipped")
+		return
+	}
+	reasons := make([]string, 0, len(counts))
+	var total int64
+	for reason, n := range counts {
+		reasons = append(reasons, reason)
+		total += n
+	}
+	// Most frequent first, so the dominant filter leads the line
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s=%d", reason, counts[reason])
+	}
+	log.Printf("⏭️ %d setups skipped: %s", total, strings.Join(parts, " "))
+}
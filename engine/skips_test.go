@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"macro-strike-bot/config"
+)
+
+func TestSkipLogReportsEachSkipAndTally(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prev)
+
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	te.recordSkip(newSkip(SkipLowConfidence, "WETH/USDC confidence 0.40"))
+	te.logSkipSummary()
+	if buf.Len() != 0 {
+		t.Fatalf("skips logged without SKIP_LOG:\n%s", buf.String())
+	}
+
+	te = NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "SKIP_LOG": "1"})
+	te.recordSkip(newSkip(SkipLowConfidence, "WETH/USDC confidence 0.40"))
+	te.recordSkip(newSkip(SkipSymbolCooldown, "WBTC/USDC cooling down"))
+	te.recordSkip(newSkip(SkipLowConfidence, "WBTC/USDC confidence 0.55"))
+	te.logSkipSummary()
+	out := buf.String()
+	if !strings.Contains(out, "Skipped (low_confidence): WETH/USDC confidence 0.40") {
+		t.Errorf("skip line missing its reason and detail:\n%s", out)
+	}
+	if !strings.Contains(out, "3 setups skipped: low_confidence=2 symbol_cooldown=1") {
+		t.Errorf("campaign-end tally missing:\n%s", out)
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// EngineSnapshot is one consistent reading of the engine's counters. Money is
+// in cents, as the engine keeps it.
+type EngineSnapshot struct {
+	Taken             time.Time `json:"taken"`
+	Capital           int64     `json:"capital_cents"`
+	PeakCapital       int64     `json:"peak_capital_cents"`
+	TotalPnL          int64     `json:"total_pnl_cents"`
+	TotalFeesPaid     int64     `json:"total_fees_paid_cents"`
+	TradesCompleted   int64     `json:"trades_completed"`
+	TotalStrikes      int64     `json:"total_strikes"`
+	SuccessfulStrikes int64     `json:"successful_strikes"`
+	FailedStrikes     int64     `json:"failed_strikes"`
+	AbortedStrikes    int64     `json:"aborted_strikes"`
+	ConsecutiveMisses int64     `json:"consecutive_misses"`
+	BlownUp           bool      `json:"blown_up"`
+	// Derived from the counters above
+	WinRate     float64 `json:"win_rate"`
+	DrawdownPct float64 `json:"drawdown_pct"`
+}
+
+// Snapshot reads every counter under countersMu, so no strike is half
+// counted in it
+func (te *TradingEngine) Snapshot() EngineSnapshot {
+	te.countersMu.RLock()
+	s := EngineSnapshot{
+		Taken:             te.Clock.Now(),
+		Capital:           atomic.LoadInt64(&te.Capital),
+		PeakCapital:       atomic.LoadInt64(&te.PeakCapital),
+		TotalPnL:          atomic.LoadInt64(&te.TotalPnL),
+		TotalFeesPaid:     atomic.LoadInt64(&te.TotalFeesPaid),
+		TradesCompleted:   atomic.LoadInt64(&te.TradesCompleted),
+		TotalStrikes:      atomic.LoadInt64(&te.TotalStrikes),
+		SuccessfulStrikes: atomic.LoadInt64(&te.SuccessfulStrikes),
+		FailedStrikes:     atomic.LoadInt64(&te.FailedStrikes),
+		AbortedStrikes:    atomic.LoadInt64(&te.AbortedStrikes),
+		ConsecutiveMisses: atomic.LoadInt64(&te.ConsecutiveMisses),
+		BlownUp:           te.BlownUp(),
+	}
+	te.countersMu.RUnlock()
+	if s.TotalStrikes > 0 {
+		s.WinRate = float64(s.SuccessfulStrikes) / float64(s.TotalStrikes)
+	}
+	if s.PeakCapital > 0 && s.Capital < s.PeakCapital {
+		s.DrawdownPct = float64(s.PeakCapital-s.Capital) / float64(s.PeakCapital) * 100.0
+	}
+	return s
+}
+
+// GetStats returns the per-symbol, per-strike-type and equity-curve stats the
+// campaign report is built from
+func (te *TradingEngine) GetStats() report.CampaignStatsSnapshot {
+	return te.campaignStats.Snapshot()
+}
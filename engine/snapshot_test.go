@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"macro-strike-bot/config"
+)
+
+// Run with -race: Snapshot is read while strikes settle
+func TestSnapshotIsConsistentUnderConcurrentStrikes(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "1"})
+	start := te.Snapshot()
+	const strikes = 200
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 1; i <= strikes; i++ {
+			if _, err := te.ExecuteStrike(context.Background(), certainStrike(uint64(i), i%3 != 0)); err != nil {
+				t.Errorf("strike %d: %v", i, err)
+				return
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				s := te.Snapshot()
+				if s.SuccessfulStrikes+s.FailedStrikes != s.TotalStrikes {
+					t.Errorf("torn outcome counters: %d + %d != %d", s.SuccessfulStrikes, s.FailedStrikes, s.TotalStrikes)
+					return
+				}
+				if s.Capital-start.Capital != s.TotalPnL {
+					t.Errorf("torn PnL: capital moved %d, total PnL %d", s.Capital-start.Capital, s.TotalPnL)
+					return
+				}
+				if s.PeakCapital < s.Capital {
+					t.Errorf("peak %d below capital %d", s.PeakCapital, s.Capital)
+					return
+				}
+				te.Stats()
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := te.Snapshot()
+	if s.TotalStrikes != strikes || s.SuccessfulStrikes != 134 {
+		t.Errorf("final snapshot %d strikes, %d wins; want %d and 134", s.TotalStrikes, s.SuccessfulStrikes, strikes)
+	}
+	if want := float64(134) / strikes; s.WinRate != want {
+		t.Errorf("win rate = %.4f, want %.4f", s.WinRate, want)
+	}
+	if stats := te.GetStats(); stats.Trades.N != strikes {
+		t.Errorf("GetStats recorded %d trades, want %d", stats.Trades.N, strikes)
+	}
+}
+
+// Run with -race: the peak must end at the highest capital any delta reached
+func TestPeakCapitalTracksTrueMaximumUnderConcurrentPnL(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	start := atomic.LoadInt64(&te.Capital)
+	const workers, deltas = 16, 500
+
+	var wg sync.WaitGroup
+	highs := make([]int64, workers)
+	sums := make([]int64, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < deltas; i++ {
+				delta := rng.Int63n(20001) - 10000
+				sums[w] += delta
+				if c := te.applyPnL(delta); c > highs[w] {
+					highs[w] = c
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	want, total := start, start
+	for w := 0; w < workers; w++ {
+		if highs[w] > want {
+			want = highs[w]
+		}
+		total += sums[w]
+	}
+	if peak := atomic.LoadInt64(&te.PeakCapital); peak != want {
+		t.Errorf("peak = %d, want the highest capital reached, %d", peak, want)
+	}
+	if capital := atomic.LoadInt64(&te.Capital); capital != total {
+		t.Errorf("capital = %d, want %d after every delta", capital, total)
+	}
+}
+
+// Run with -race: storeMax and addFloored hold up without countersMu
+func TestCounterSwapsHoldWithoutTheLock(t *testing.T) {
+	var peak, capital int64
+	capital = 1000
+	var wg sync.WaitGroup
+	for w := int64(1); w <= 32; w++ {
+		wg.Add(1)
+		go func(w int64) {
+			defer wg.Done()
+			for i := int64(0); i < 1000; i++ {
+				storeMax(&peak, w*1000+i)
+				addFloored(&capital, 1)
+				addFloored(&capital, -1)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if peak != 32*1000+999 {
+		t.Errorf("peak = %d, want %d", peak, 32*1000+999)
+	}
+	if capital != 1000 {
+		t.Errorf("capital = %d, want 1000 after balanced deltas", capital)
+	}
+	if got, booked := addFloored(&capital, -5000); got != 0 || booked != -1000 {
+		t.Errorf("addFloored past zero = %d booked %d, want 0 booked -1000", got, booked)
+	}
+}
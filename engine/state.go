@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// engineStateVersion is bumped whenever EngineState changes incompatibly
+const engineStateVersion = 1
+
+// EngineState is the persisted snapshot used to resume an interrupted campaign
+type EngineState struct {
+	Version           int                       `json:"version"`
+	RunID             string                    `json:"run_id"`
+	SavedAt           time.Time                 `json:"saved_at"`
+	CampaignStart     time.Time                 `json:"campaign_start"`
+	Capital           int64                     `json:"capital"`
+	PeakCapital       int64                     `json:"peak_capital"`
+	Drawdown          *DrawdownState            `json:"drawdown,omitempty"`
+	TotalPnL          int64                     `json:"total_pnl"`
+	TotalFeesPaid     int64                     `json:"total_fees_paid,omitempty"`
+	NextStrikeID      uint64                    `json:"next_strike_id"`
+	ConsecutiveMisses int64                     `json:"consecutive_misses"`
+	TotalStrikes      int64                     `json:"total_strikes"`
+	SuccessfulStrikes int64                     `json:"successful_strikes"`
+	FailedStrikes     int64                     `json:"failed_strikes"`
+	TradesCompleted   int64                     `json:"trades_completed"`
+	AbortedStrikes    int64                     `json:"aborted_strikes"`
+	BlownUp           bool                      `json:"blown_up"`
+	OpenPositions     []openPosition            `json:"open_positions"`
+	PnLRollups        *report.PnLRollupSnapshot `json:"pnl_rollups,omitempty"`
+	OpenLots          []Lot                     `json:"open_lots,omitempty"`
+	RealizedGains     []RealizedGain            `json:"realized_gains,omitempty"`
+
+	// Campaign-wide totals so a resumed run reports on every trade, not only
+	// those since the restart
+	StartCapital  int64                         `json:"start_capital,omitempty"`
+	Returns       *trackerState                 `json:"returns,omitempty"`
+	CampaignStats *report.CampaignStatsSnapshot `json:"campaign_stats,omitempty"`
+	RecentWinRate *float64                      `json:"recent_win_rate,omitempty"`
+	// Time paused so far, so a resumed run keeps its extended window
+	PausedNs int64 `json:"paused_ns,omitempty"`
+}
+
+// captureState takes a snapshot of the engine counters and open positions
+func (te *TradingEngine) captureState() EngineState {
+	drawdown := te.Drawdown()
+	st := EngineState{
+		Version:           engineStateVersion,
+		RunID:             te.RunID,
+		SavedAt:           te.Clock.Now().UTC(),
+		CampaignStart:     te.CampaignStart,
+		Capital:           atomic.LoadInt64(&te.Capital),
+		PeakCapital:       atomic.LoadInt64(&te.PeakCapital),
+		Drawdown:          &drawdown,
+		TotalPnL:          atomic.LoadInt64(&te.TotalPnL),
+		TotalFeesPaid:     atomic.LoadInt64(&te.TotalFeesPaid),
+		NextStrikeID:      atomic.LoadUint64(&te.NextStrikeID),
+		ConsecutiveMisses: atomic.LoadInt64(&te.ConsecutiveMisses),
+		TotalStrikes:      atomic.LoadInt64(&te.TotalStrikes),
+		SuccessfulStrikes: atomic.LoadInt64(&te.SuccessfulStrikes),
+		FailedStrikes:     atomic.LoadInt64(&te.FailedStrikes),
+		TradesCompleted:   atomic.LoadInt64(&te.TradesCompleted),
+		AbortedStrikes:    atomic.LoadInt64(&te.AbortedStrikes),
+		BlownUp:           te.BlownUp(),
+	}
+	te.positionsMu.Lock()
+	for _, pos := range te.openPositions {
+		st.OpenPositions = append(st.OpenPositions, *pos)
+	}
+	te.positionsMu.Unlock()
+	rollups := te.pnlRollups.Snapshot()
+	st.PnLRollups = &rollups
+	st.OpenLots = te.lotLedger.OpenLots()
+	st.RealizedGains = te.lotLedger.Realized()
+	st.StartCapital = te.StartCapital
+	st.PausedNs = int64(te.pausedFor())
+	if v, ok := te.winRate.Value(); ok {
+		st.RecentWinRate = &v
+	}
+	if te.tracker != nil {
+		returns := te.tracker.state()
+		st.Returns = &returns
+	}
+	if te.campaignStats != nil {
+		stats := te.campaignStats.Snapshot()
+		st.CampaignStats = &stats
+	}
+	return st
+}
+
+// restoreState loads a snapshot into the engine
+func (te *TradingEngine) restoreState(st EngineState) {
+	te.RunID = st.RunID
+	te.CampaignStart = st.CampaignStart
+	te.countersMu.Lock()
+	atomic.StoreInt64(&te.Capital, st.Capital)
+	atomic.StoreInt64(&te.PeakCapital, st.PeakCapital)
+	if st.Drawdown != nil {
+		te.drawdownMu.Lock()
+		te.drawdown = *st.Drawdown
+		te.drawdownMu.Unlock()
+	}
+	atomic.StoreInt64(&te.TotalPnL, st.TotalPnL)
+	atomic.StoreInt64(&te.TotalFeesPaid, st.TotalFeesPaid)
+	atomic.StoreUint64(&te.NextStrikeID, st.NextStrikeID)
+	atomic.StoreInt64(&te.ConsecutiveMisses, st.ConsecutiveMisses)
+	atomic.StoreInt64(&te.TotalStrikes, st.TotalStrikes)
+	atomic.StoreInt64(&te.SuccessfulStrikes, st.SuccessfulStrikes)
+	atomic.StoreInt64(&te.FailedStrikes, st.FailedStrikes)
+	atomic.StoreInt64(&te.TradesCompleted, st.TradesCompleted)
+	atomic.StoreInt64(&te.AbortedStrikes, st.AbortedStrikes)
+	if st.BlownUp {
+		atomic.StoreInt32(&te.blownUp, 1)
+	}
+	te.countersMu.Unlock()
+	te.positionsMu.Lock()
+	for i := range st.OpenPositions {
+		pos := st.OpenPositions[i]
+		te.openPositions[pos.StrikeID] = &pos
+	}
+	te.positionsMu.Unlock()
+	if st.PnLRollups != nil {
+		te.pnlRollups.Restore(*st.PnLRollups)
+	}
+	te.lotLedger.Restore(st.OpenLots, st.RealizedGains)
+	te.StartCapital = st.StartCapital
+	te.resumedReturns = st.Returns
+	te.pausedTotal = time.Duration(st.PausedNs)
+	if st.RecentWinRate != nil {
+		te.winRate.restore(*st.RecentWinRate)
+	}
+	if st.CampaignStats != nil {
+		if cs, err := report.RestoreCampaignStats(*st.CampaignStats); err != nil {
+			log.Printf("⚠️ Campaign stats not restored, report covers only post-resume trades: %v", err)
+		} else {
+			te.campaignStats = cs
+			te.statsRestored = true
+		}
+	}
+}
+
+// SaveState writes a snapshot of the engine to StateFile atomically
+func (te *TradingEngine) SaveState() error {
+	if te.StateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(te.captureState(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(te.StateFile, data)
+}
+
+// writeFileAtomic replaces path with data: a temp file in the same directory
+// is fsync'd and renamed over the previous contents.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadState reads a snapshot written by SaveState
+func loadState(path string) (EngineState, error) {
+	var st EngineState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, err
+	}
+	if st.Version != engineStateVersion {
+		return st, fmt.Errorf("unsupported state version %d", st.Version)
+	}
+	return st, nil
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumedCampaignReportsWholeCampaign(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("STATE_FILE", filepath.Join(t.TempDir(), "state.json"))
+
+	first := NewTradingEngine()
+	first.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, false)},
+	}}
+	if result := first.ExecuteCampaign(context.Background()); result.TradesCompleted != 2 {
+		t.Fatalf("first run traded %d times, want 2", result.TradesCompleted)
+	}
+	first.Close()
+
+	t.Setenv("RESUME", "1")
+	te := NewTradingEngine()
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{{Strike: certainStrike(3, true)}}}
+	result := te.ExecuteCampaign(context.Background())
+
+	initial := float64(InitialCapital) / 100.0
+	if result.StartCapital != initial {
+		t.Errorf("start capital = %.2f, want the original %.2f", result.StartCapital, initial)
+	}
+	wantReturn := (result.FinalCapital - result.StartCapital) / result.StartCapital * 100.0
+	if math.Abs(result.ReturnPct-wantReturn) > 1e-9 {
+		t.Errorf("return = %.6f%%, want %.6f%% measured from the start capital", result.ReturnPct, wantReturn)
+	}
+
+	rep := te.BuildReport(result)
+	if result.TradesCompleted != 3 {
+		t.Fatalf("trades completed = %d, want 3", result.TradesCompleted)
+	}
+	if g := rep.BySymbol["WETH/USDC"]; g.Strikes != 3 || g.Wins != 2 || g.Losses != 1 {
+		t.Errorf("by-symbol = %+v, want 3 strikes, 2 wins, 1 loss across the resume", g)
+	}
+	if g := rep.ByStrikeType[MacroArbitrage.String()]; g.Strikes != 3 {
+		t.Errorf("by-type strikes = %d, want 3", g.Strikes)
+	}
+	if n := len(rep.EquityCurve); n != 4 {
+		t.Fatalf("equity curve has %d points, want the start plus 3 trades", n)
+	}
+	if rep.EquityCurve[0].Capital != initial {
+		t.Errorf("equity curve starts at %.2f, want %.2f", rep.EquityCurve[0].Capital, initial)
+	}
+	if got := rep.EquityCurve[3].Capital; got != result.FinalCapital {
+		t.Errorf("equity curve ends at %.2f, want final capital %.2f", got, result.FinalCapital)
+	}
+}
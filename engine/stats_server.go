@@ -0,0 +1,359 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// latencyWindowSize is the number of recent samples in each rolling average
+const latencyWindowSize = 100
+
+// latencyWindow keeps a rolling average over the most recent request durations
+type latencyWindow struct {
+	samples [latencyWindowSize]time.Duration
+	next    int
+	count   int
+	sum     time.Duration
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	if w.count == latencyWindowSize {
+		w.sum -= w.samples[w.next]
+	} else {
+		w.count++
+	}
+	w.samples[w.next] = d
+	w.sum += d
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+func (w *latencyWindow) averageMs() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return float64(w.sum) / float64(w.count) / float64(time.Millisecond)
+}
+
+// LatencyTracker records Kraken round-trip times overall and per endpoint
+type LatencyTracker struct {
+	mu     sync.Mutex
+	all    latencyWindow
+	byPath map[string]*latencyWindow
+}
+
+// NewLatencyTracker creates an empty latency tracker
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{byPath: make(map[string]*latencyWindow)}
+}
+
+// Observe records one request duration for path
+func (lt *LatencyTracker) Observe(path string, d time.Duration) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.all.add(d)
+	w, ok := lt.byPath[path]
+	if !ok {
+		w = &latencyWindow{}
+		lt.byPath[path] = w
+	}
+	w.add(d)
+}
+
+// LatencyStats is the JSON view of the rolling latency averages
+type LatencyStats struct {
+	AvgMs       float64            `json:"avg_ms"`
+	Samples     int                `json:"samples"`
+	ByPathAvgMs map[string]float64 `json:"by_path_avg_ms"`
+}
+
+// Stats returns the current rolling averages
+func (lt *LatencyTracker) Stats() LatencyStats {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	out := LatencyStats{
+		AvgMs:       lt.all.averageMs(),
+		Samples:     lt.all.count,
+		ByPathAvgMs: make(map[string]float64, len(lt.byPath)),
+	}
+	for path, w := range lt.byPath {
+		out.ByPathAvgMs[path] = w.averageMs()
+	}
+	return out
+}
+
+// EngineStats is the JSON document served at /stats
+type EngineStats struct {
+	Capital           float64                        `json:"capital"`
+	PeakCapital       float64                        `json:"peak_capital"`
+	Drawdown          DrawdownState                  `json:"drawdown"`
+	TotalPnL          float64                        `json:"total_pnl"`
+	TotalFeesPaid     float64                        `json:"total_fees_paid"`
+	OpenNotional      float64                        `json:"open_notional_usd"`
+	MaxNotional       float64                        `json:"max_notional_usd,omitempty"`
+	RetryBudget       *RetryBudgetStats              `json:"retry_budget,omitempty"`
+	TradesCompleted   int64                          `json:"trades_completed"`
+	SuccessfulStrikes int64                          `json:"successful_strikes"`
+	FailedStrikes     int64                          `json:"failed_strikes"`
+	ConsecutiveMisses int64                          `json:"consecutive_misses"`
+	RecentWinRate     float64                        `json:"recent_win_rate"`
+	KrakenLatency     LatencyStats                   `json:"kraken_latency"`
+	StageLatency      map[string]report.StageLatency `json:"stage_latency"`
+	LevelSources      map[string]LevelSourceStats    `json:"level_sources"`
+	SkipReasons       map[string]int64               `json:"skip_reasons"`
+	PnLRollups        report.PnLRollupSnapshot       `json:"pnl_rollups"`
+	Projection        Projection                     `json:"projection"`
+	Performance       PerfSnapshot                   `json:"performance"`
+}
+
+// Stats collects the engine counters for the stats endpoint
+func (te *TradingEngine) Stats() EngineStats {
+	snap := te.Snapshot()
+	return EngineStats{
+		Capital:           Money(snap.Capital).Dollars(),
+		PeakCapital:       Money(snap.PeakCapital).Dollars(),
+		Drawdown:          te.Drawdown(),
+		TotalPnL:          Money(snap.TotalPnL).Dollars(),
+		TotalFeesPaid:     Money(snap.TotalFeesPaid).Dollars(),
+		OpenNotional:      te.OpenNotional(),
+		MaxNotional:       te.MaxNotionalUSD,
+		RetryBudget:       te.retryBudget.Stats(te.Clock.Now()),
+		TradesCompleted:   snap.TradesCompleted,
+		SuccessfulStrikes: snap.SuccessfulStrikes,
+		FailedStrikes:     snap.FailedStrikes,
+		ConsecutiveMisses: snap.ConsecutiveMisses,
+		RecentWinRate:     te.RecentWinRate(),
+		KrakenLatency:     te.krakenLatency.Stats(),
+		StageLatency:      te.stageLatency.Stats(),
+		LevelSources:      te.LevelStats(),
+		SkipReasons:       te.SkipCounts(),
+		PnLRollups:        te.PnLRollups(),
+		Projection:        te.Project(),
+		Performance:       te.perfStore.Snapshot(te.Clock.Now()),
+	}
+}
+
+// statusRecentStrikes is how many completed strikes /status lists
+const statusRecentStrikes = 10
+
+// statusShutdownTimeout bounds how long in-flight status requests may delay shutdown
+const statusShutdownTimeout = 5 * time.Second
+
+// OpenStrikeStatus is the strike the trading loop is currently executing
+type OpenStrikeStatus struct {
+	ID          uint64    `json:"id"`
+	Symbol      string    `json:"symbol"`
+	StrikeType  string    `json:"strike_type"`
+	Status      string    `json:"status"`
+	EntryPrice  float64   `json:"entry_price"`
+	TargetPrice float64   `json:"target_price"`
+	StopLoss    float64   `json:"stop_loss"`
+	StrikeForce float64   `json:"strike_force"`
+	Since       time.Time `json:"since"`
+}
+
+// CompletedStrikeStatus summarizes a finished (or aborted) strike for /status
+type CompletedStrikeStatus struct {
+	ID         uint64   `json:"id"`
+	Symbol     string   `json:"symbol"`
+	StrikeType string   `json:"strike_type"`
+	Status     string   `json:"status"`
+	EntryPrice float64  `json:"entry_price"`
+	ExitPrice  *float64 `json:"exit_price,omitempty"`
+	PnL        *float64 `json:"pnl,omitempty"`
+	ExitReason string   `json:"exit_reason,omitempty"`
+}
+
+// EngineStatus is the JSON document served at /status
+type EngineStatus struct {
+	Capital         float64 `json:"capital"`
+	PeakCapital     float64 `json:"peak_capital"`
+	DrawdownPct     float64 `json:"drawdown_pct"`
+	TradesCompleted int64   `json:"trades_completed"`
+	// Omitted in INFINITE mode
+	TradesRemaining   *int64                  `json:"trades_remaining,omitempty"`
+	ConsecutiveMisses int64                   `json:"consecutive_misses"`
+	OpenStrike        *OpenStrikeStatus       `json:"open_strike"`
+	RecentStrikes     []CompletedStrikeStatus `json:"recent_strikes"`
+	CampaignStart     time.Time               `json:"campaign_start"`
+	ElapsedSec        float64                 `json:"elapsed_sec"`
+	RemainingSec      float64                 `json:"remaining_sec"`
+	Paused            bool                    `json:"paused"`
+	PausedSince       *time.Time              `json:"paused_since,omitempty"`
+	// Pace toward TargetCapital at the current per-trade PnL and trade rate
+	Projection Projection `json:"projection"`
+}
+
+// trackOpenStrike keeps the in-flight strike current for /status: a strike is
+// open from Striking until it resolves
+func (te *TradingEngine) trackOpenStrike(strike *MacroStrike, at time.Time) {
+	te.strikesMu.Lock()
+	defer te.strikesMu.Unlock()
+	if strike.Status != Striking {
+		if te.openStrike != nil && te.openStrike.ID == strike.ID {
+			te.openStrike = nil
+		}
+		return
+	}
+	te.openStrike = &OpenStrikeStatus{
+		ID:          strike.ID,
+		Symbol:      strike.Symbol,
+		StrikeType:  strike.StrikeType.String(),
+		Status:      strike.Status.String(),
+		EntryPrice:  strike.EntryPrice,
+		TargetPrice: strike.TargetPrice,
+		StopLoss:    strike.StopLoss,
+		StrikeForce: strike.StrikeForce,
+		Since:       at,
+	}
+}
+
+// Status snapshots the live campaign. Counters are read atomically and the
+// strike lists under strikesMu, the same synchronization the loop writes with.
+func (te *TradingEngine) Status() EngineStatus {
+	snap := te.Snapshot()
+	st := EngineStatus{
+		Capital:           Money(snap.Capital).Dollars(),
+		PeakCapital:       Money(snap.PeakCapital).Dollars(),
+		DrawdownPct:       snap.DrawdownPct,
+		TradesCompleted:   snap.TradesCompleted,
+		ConsecutiveMisses: snap.ConsecutiveMisses,
+		CampaignStart:     te.CampaignStart,
+		RecentStrikes:     []CompletedStrikeStatus{},
+		Projection:        te.Project(),
+	}
+	if !te.InfiniteTrades {
+		remaining := TotalTrades - st.TradesCompleted
+		if remaining < 0 {
+			remaining = 0
+		}
+		st.TradesRemaining = &remaining
+	}
+	elapsed := te.campaignElapsed()
+	st.ElapsedSec = elapsed.Seconds()
+	if remaining := time.Duration(te.CampaignDays)*24*time.Hour - elapsed; remaining > 0 {
+		st.RemainingSec = remaining.Seconds()
+	}
+	if paused, since := te.Paused(); paused {
+		st.Paused = true
+		st.PausedSince = &since
+	}
+
+	te.strikesMu.Lock()
+	defer te.strikesMu.Unlock()
+	if te.openStrike != nil {
+		open := *te.openStrike
+		st.OpenStrike = &open
+	}
+	from := len(te.Strikes) - statusRecentStrikes
+	if from < 0 {
+		from = 0
+	}
+	for i := len(te.Strikes) - 1; i >= from; i-- {
+		s := te.Strikes[i]
+		st.RecentStrikes = append(st.RecentStrikes, CompletedStrikeStatus{
+			ID:         s.ID,
+			Symbol:     s.Symbol,
+			StrikeType: s.StrikeType.String(),
+			Status:     s.Status.String(),
+			EntryPrice: s.EntryPrice,
+			ExitPrice:  s.ExitPrice,
+			PnL:        s.PnL,
+			ExitReason: s.ExitReason,
+		})
+	}
+	return st
+}
+
+// statusHandler routes the status server's endpoints
+func (te *TradingEngine) statusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(te.Stats())
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(te.Status())
+	})
+	control := func(action func()) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			action()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(te.Status())
+		}
+	}
+	mux.HandleFunc("/pause", te.requireControlToken(false, control(te.Pause)))
+	mux.HandleFunc("/resume", te.requireControlToken(false, control(te.Resume)))
+	mux.HandleFunc("/kill", te.requireControlToken(true, te.serveKill))
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		te.WriteMetrics(w)
+	})
+	mux.HandleFunc("/events", te.serveEvents)
+	mux.HandleFunc("/healthz", te.serveHealthz)
+	mux.HandleFunc("/readyz", te.serveReadyz)
+	mux.HandleFunc("/realized-gains", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		te.lotLedger.WriteRealizedGainsCSV(w)
+	})
+	return te.redactor.Handler(mux)
+}
+
+// StartStatusServer serves engine stats on addr in the background until Close
+func (te *TradingEngine) StartStatusServer(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: te.statusHandler(), ReadHeaderTimeout: 10 * time.Second}
+	// Event streams never finish on their own; end them so Shutdown can
+	srv.RegisterOnShutdown(te.events.Close)
+	te.statusServer = srv
+	go func() {
+		log.Printf("Status server listening on %s", ln.Addr())
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("Status server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// stopStatusServer shuts the status server down, letting in-flight requests finish
+func (te *TradingEngine) stopStatusServer() {
+	if te.statusServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusShutdownTimeout)
+	defer cancel()
+	if err := te.statusServer.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ Status server shutdown: %v", err)
+	}
+	te.statusServer = nil
+}
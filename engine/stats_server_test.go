@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestStatusReportsCampaignAndOpenStrike(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.CampaignStart = te.Clock.Now()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, false)},
+		{Strike: certainStrike(3, true)},
+	}}
+	te.ExecuteCampaign(context.Background())
+
+	rec := httptest.NewRecorder()
+	te.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+	var st EngineStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatalf("decode /status: %v (%s)", err, rec.Body.String())
+	}
+	if st.TradesCompleted != 3 || st.TradesRemaining == nil || *st.TradesRemaining != TotalTrades-3 {
+		t.Errorf("trades completed %d remaining %v, want 3 and %d", st.TradesCompleted, st.TradesRemaining, TotalTrades-3)
+	}
+	if st.OpenStrike != nil {
+		t.Errorf("open strike %+v after the campaign ended", st.OpenStrike)
+	}
+	if len(st.RecentStrikes) != 3 || st.RecentStrikes[0].ID != 3 || st.RecentStrikes[1].Status != "miss" {
+		t.Errorf("recent strikes = %+v, want 3, 2, 1 newest first", st.RecentStrikes)
+	}
+	if st.ElapsedSec <= 0 || st.RemainingSec != float64(te.CampaignDays)*86400-st.ElapsedSec {
+		t.Errorf("elapsed %.0fs remaining %.0fs", st.ElapsedSec, st.RemainingSec)
+	}
+
+	// A strike is open from Striking until it resolves
+	strike := certainStrike(4, true)
+	te.transition(strike, Striking, strike.EntryPrice, "")
+	if open := te.Status().OpenStrike; open == nil || open.ID != 4 || open.Symbol != "WETH/USDC" {
+		t.Errorf("open strike = %+v, want strike 4", open)
+	}
+	te.transition(strike, Aborted, strike.EntryPrice, "scripted abort")
+	if open := te.Status().OpenStrike; open != nil {
+		t.Errorf("open strike = %+v after it aborted", open)
+	}
+
+	te.InfiniteTrades = true
+	if st := te.Status(); st.TradesRemaining != nil {
+		t.Errorf("trades remaining = %d in INFINITE mode, want it omitted", *st.TradesRemaining)
+	}
+}
+
+func TestStatusServerStopsWithEngine(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	te := NewTradingEngine()
+	if err := te.StartStatusServer(addr); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + addr + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /status = %s", resp.Status)
+	}
+
+	te.Close()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: time.Second}
+	if resp, err := client.Get("http://" + addr + "/status"); err == nil {
+		resp.Body.Close()
+		t.Error("status server still serving after Close")
+	}
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMoreStrikes is returned by a StrikeGenerator that has nothing left to
+// offer; the campaign stops instead of polling it again
+var ErrNoMoreStrikes = errors.New("no more strikes")
+
+// ErrAnalyzerMissing means the Julia binary has gone missing; retrying every
+// strike would only spin, so the campaign stops
+var ErrAnalyzerMissing = errors.New("market analyzer not installed")
+
+// StrikeGenerator supplies the campaign loop with strikes. Returning a skip
+// error (see newSkip) passes over a setup without counting a trade. ctx is
+// the campaign's; a generator doing I/O should give up once it is done.
+type StrikeGenerator interface {
+	NextStrike(ctx context.Context) (*MacroStrike, error)
+}
+
+// analyzedStrikeGenerator is the default generator: Julia analysis when live,
+// the simulation model in SIM_MODE
+type analyzedStrikeGenerator struct {
+	te *TradingEngine
+}
+
+func (g analyzedStrikeGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	return g.te.generateAnalyzedStrike(ctx)
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+	"macro-strike-bot/report"
+)
+
+// scriptedStrike is one step of a scriptedStrikeGenerator: a strike, or an
+// error such as a skip
+type scriptedStrike struct {
+	Strike *MacroStrike
+	Err    error
+}
+
+// scriptedStrikeGenerator yields a fixed sequence, then ErrNoMoreStrikes.
+// Each strike is copied so the script can be replayed.
+type scriptedStrikeGenerator struct {
+	steps []scriptedStrike
+	next  int
+}
+
+func (g *scriptedStrikeGenerator) NextStrike(ctx context.Context) (*MacroStrike, error) {
+	if g.next >= len(g.steps) {
+		return nil, ErrNoMoreStrikes
+	}
+	step := g.steps[g.next]
+	g.next++
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	s := *step.Strike
+	return &s, nil
+}
+
+// certainStrike builds a strike whose sim outcome is fixed: confidence 1
+// always hits and confidence 0 always misses
+func certainStrike(id uint64, hit bool) *MacroStrike {
+	conf := 0.0
+	if hit {
+		conf = 1.0
+	}
+	return &MacroStrike{
+		ID: id, Symbol: "WETH/USDC", StrikeType: MacroArbitrage, EntryPrice: 3000, TargetPrice: 3015,
+		StopLoss: 2940, Confidence: conf, ExpectedReturn: 0.005, MaxExposureTimeMs: MaxExposureTimeMs,
+		Status: Targeting, Leverage: 1, LevelSource: LevelSourceFormula, LiquidityFactor: 1, MomentumFactor: 1,
+	}
+}
+
+func TestCampaignRunsScriptedStrikes(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Err: newSkip(SkipLowConfidence, "scripted skip")},
+		{Strike: certainStrike(2, false)},
+		{Strike: certainStrike(3, true)},
+	}}
+
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopGeneratorExhausted {
+		t.Errorf("stop reason = %q, want %q", result.StopReason, report.StopGeneratorExhausted)
+	}
+	if result.TradesCompleted != 3 || result.Wins != 2 || result.Losses != 1 {
+		t.Errorf("trades=%d wins=%d losses=%d, want 3/2/1", result.TradesCompleted, result.Wins, result.Losses)
+	}
+	if got := te.SkipCounts()[SkipLowConfidence]; got != 1 {
+		t.Errorf("low_confidence skips = %d, want 1", got)
+	}
+	if result.FinalCapital <= result.StartCapital {
+		t.Errorf("capital %.2f -> %.2f, want growth from 2 wins and a loss", result.StartCapital, result.FinalCapital)
+	}
+}
+
+func TestMissingAnalyzerFailsFastOrFallsBack(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("SIM_MODE", "")
+	te := NewTradingEngine()
+	if err := te.checkAnalyzerInstalled(); err == nil {
+		t.Error("missing julia accepted without JULIA_MISSING")
+	}
+
+	// Were the check skipped, the campaign still stops at the first analysis
+	result := te.ExecuteCampaign(context.Background())
+	if result.StopReason != report.StopAnalyzerMissing || result.TradesCompleted != 0 {
+		t.Errorf("stop %q after %d trades, want %q before any", result.StopReason, result.TradesCompleted, report.StopAnalyzerMissing)
+	}
+
+	te.JuliaMissing = "sim"
+	te.LiveTrading = true
+	if err := te.checkAnalyzerInstalled(); err == nil {
+		t.Error("fell back to simulated strikes while live trading")
+	}
+	te.LiveTrading = false
+	if err := te.checkAnalyzerInstalled(); err != nil || !te.SimMode {
+		t.Errorf("JULIA_MISSING=sim: err %v SimMode=%v, want the SIM_MODE fallback", err, te.SimMode)
+	}
+}
+
+func TestAnalysisHonoursContextDeadline(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, analyzerBinary), []byte("#!/bin/sh\nexec /bin/sleep 5\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	te := NewTradingEngineFromConfig(config.Config{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := te.GetMarketAnalysis(ctx, "ETHUSD", "MacroMomentum"); err == nil {
+		t.Fatal("analysis succeeded past its deadline")
+	}
+	if waited := time.Since(start); waited > 2*time.Second {
+		t.Errorf("analysis ran %v past a 50ms deadline", waited)
+	}
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// StrikeLogger receives every completed and skipped strike
+type StrikeLogger interface {
+	LogStrike(strike *MacroStrike, capitalAfter float64)
+	LogSkip(err error, capital float64)
+	Close() error
+}
+
+// nopStrikeLogger discards everything; used when no log path is configured
+type nopStrikeLogger struct{}
+
+func (nopStrikeLogger) LogStrike(*MacroStrike, float64) {}
+func (nopStrikeLogger) LogSkip(error, float64)          {}
+func (nopStrikeLogger) Close() error                    { return nil }
+
+// StrikeLogRecord is one line of the JSONL strike log; read it back through
+// DecodeStrikeRecord so older versions are upgraded
+type StrikeLogRecord struct {
+	Version      int          `json:"v"`
+	Type         string       `json:"type"` // "strike" or "skip"
+	Time         int64        `json:"time"` // unix milliseconds
+	RunID        string       `json:"run_id"`
+	Strike       *MacroStrike `json:"strike,omitempty"`
+	SkipReason   string       `json:"skip_reason,omitempty"`
+	SkipDetail   string       `json:"skip_detail,omitempty"`
+	CapitalAfter float64      `json:"capital_after"`
+}
+
+// jsonlStrikeLogger appends one JSON object per line. Each record is
+// written with a single write on an O_APPEND descriptor, so a crash can at
+// worst truncate the record in flight, never earlier ones, and rotation
+// never splits a record. In audit mode every record is hash-chained to the
+// one before it (see audit_log.go), continuing across restarts and rotations.
+type jsonlStrikeLogger struct {
+	mu       sync.Mutex
+	file     *RotatingFile
+	runID    string
+	audit    bool
+	lastHash string
+	// now stamps records; the engine points it at its Clock
+	now func() time.Time
+}
+
+// NewJSONLStrikeLogger opens path for appending strike records. An audit
+// chain can only be verified from its genesis record, so audit mode rotates
+// but never prunes.
+func NewJSONLStrikeLogger(path, runID string, rotation RotationPolicy, audit bool) (StrikeLogger, error) {
+	return newJSONLStrikeLogger(path, runID, rotation, audit)
+}
+
+func newJSONLStrikeLogger(path, runID string, rotation RotationPolicy, audit bool) (*jsonlStrikeLogger, error) {
+	l := &jsonlStrikeLogger{runID: runID, audit: audit, now: time.Now}
+	if audit {
+		if rotation.enabled() && rotation.Keep != keepAll {
+			log.Printf("⚠️ Strike log audit mode keeps every rotated file; ignoring keep=%d", rotation.Keep)
+			rotation.Keep = keepAll
+		}
+		last, err := lastAuditHash(path)
+		if err != nil {
+			return nil, fmt.Errorf("audit chain: %v", err)
+		}
+		l.lastHash = last
+	}
+	f, err := OpenRotatingFile(path, 0644, rotation, nil)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+	return l, nil
+}
+
+func (l *jsonlStrikeLogger) LogStrike(strike *MacroStrike, capitalAfter float64) {
+	l.write(StrikeLogRecord{
+		Type:         "strike",
+		Time:         l.now().UnixMilli(),
+		RunID:        l.runID,
+		Strike:       strike,
+		CapitalAfter: capitalAfter,
+	})
+}
+
+func (l *jsonlStrikeLogger) LogSkip(err error, capital float64) {
+	rec := StrikeLogRecord{
+		Type:         "skip",
+		Time:         l.now().UnixMilli(),
+		RunID:        l.runID,
+		SkipReason:   SkipOther,
+		SkipDetail:   err.Error(),
+		CapitalAfter: capital,
+	}
+	var se *skipError
+	if errors.As(err, &se) {
+		rec.SkipReason = se.Reason
+		rec.SkipDetail = se.Detail
+	}
+	l.write(rec)
+}
+
+func (l *jsonlStrikeLogger) write(rec StrikeLogRecord) {
+	rec.Version = strikeLogVersion
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.audit {
+		chained, hash, err := chainAuditRecord(l.lastHash, line)
+		if err != nil {
+			log.Printf("⚠️ Audit chain: %v", err)
+			return
+		}
+		if _, err := l.file.Write(append(chained, '\n')); err != nil {
+			log.Printf("⚠️ Audit log write: %v", err)
+			return
+		}
+		l.lastHash = hash
+		return
+	}
+	l.file.Write(append(line, '\n'))
+}
+
+func (l *jsonlStrikeLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// recordExecutedStrike adds a strike to the in-memory Strikes accumulator
+func (te *TradingEngine) recordExecutedStrike(strike *MacroStrike) {
+	te.strikesMu.Lock()
+	te.Strikes = append(te.Strikes, strike)
+	te.strikesMu.Unlock()
+}
+
+// WriteStrikesJSON writes every executed strike to path as a JSON array
+func (te *TradingEngine) WriteStrikesJSON(path string) error {
+	te.strikesMu.Lock()
+	strikes := make([]*MacroStrike, len(te.Strikes))
+	copy(strikes, te.Strikes)
+	te.strikesMu.Unlock()
+	data, err := json.MarshalIndent(strikes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+func TestWriteStrikesJSONDumpsExecutedStrikes(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "strikes.json")
+
+	// No strikes yet still produces a valid, empty array
+	if err := te.WriteStrikesJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "[]" {
+		t.Errorf("empty dump = %s, want []", data)
+	}
+
+	for i := 0; i < 3; i++ {
+		strike := &MacroStrike{ID: uint64(i + 1), Symbol: "WETH/USDC", StrikeType: MacroMomentum, EntryPrice: 3000, Confidence: 0.9}
+		if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+			t.Fatalf("ExecuteStrike: %v", err)
+		}
+	}
+	if err := te.WriteStrikesJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []MacroStrike
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("dumped %d strikes, want 3", len(got))
+	}
+	for i, s := range got {
+		if s.ID != uint64(i+1) || s.ExitPrice == nil || s.PnL == nil || s.HitTime == nil {
+			t.Errorf("strike %d missing final fields: %+v", i, s)
+		}
+		if *s.PnL != *te.Strikes[i].PnL {
+			t.Errorf("strike %d pnl = %v, want %v", i, *s.PnL, *te.Strikes[i].PnL)
+		}
+	}
+}
+
+func TestStrikeLogStampsRecordsFromEngineClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "STRIKE_LOG": path})
+	defer te.Close()
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	te.Clock = clock.NewFake(start)
+
+	te.StrikeLog.LogSkip(newSkip(SkipLowConfidence, "conf=0.5"), 1000)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec StrikeLogRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Time != start.UnixMilli() {
+		t.Errorf("record time = %d, want the fake clock's %d", rec.Time, start.UnixMilli())
+	}
+}
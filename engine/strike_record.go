@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// strikeLogVersion is the record version the strike log writes. Records
+// without a "v" key predate versioning and are read as version 1.
+//
+//	v1: the original record; the strike carries only the fields up to leverage
+//	v2: "v" envelope key; the strike adds exit reason, fees, slippage, level
+//	    source, sizing factors, risk/reward, duration, exchange IDs and transitions
+const strikeLogVersion = 2
+
+// UnsupportedRecordVersionError rejects a record written by a newer release
+type UnsupportedRecordVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedRecordVersionError) Error() string {
+	return fmt.Sprintf("strike record version %d is newer than supported version %d", e.Version, strikeLogVersion)
+}
+
+// DecodeStrikeRecord reads one strike log line of any supported version and
+// upgrades it to the current layout
+func DecodeStrikeRecord(line []byte) (StrikeLogRecord, error) {
+	var rec StrikeLogRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return rec, err
+	}
+	switch rec.Version {
+	case 0, 1:
+		migrateStrikeRecordV1(&rec)
+	case strikeLogVersion:
+	default:
+		if rec.Version < 0 {
+			return rec, fmt.Errorf("invalid strike record version %d", rec.Version)
+		}
+		return rec, &UnsupportedRecordVersionError{Version: rec.Version}
+	}
+	return rec, nil
+}
+
+// migrateStrikeRecordV1 fills the fields v1 records lack with the values a v2
+// writer would have recorded for the same trade:
+//   - exit_reason: take_profit for a hit, stop_loss for a miss, empty otherwise
+//   - level_source: formula, the only level source v1 had
+//   - leverage, liquidity/momentum/performance factors: 1 (neutral) when zero
+//   - risk_reward: recomputed from the entry, target and stop
+//   - fees, slippage, duration: 0, since v1 never measured them
+func migrateStrikeRecordV1(rec *StrikeLogRecord) {
+	rec.Version = strikeLogVersion
+	s := rec.Strike
+	if s == nil {
+		return
+	}
+	if s.ExitReason == "" {
+		switch s.Status {
+		case Hit:
+			s.ExitReason = ExitTakeProfit
+		case Miss:
+			s.ExitReason = ExitStopLoss
+		}
+	}
+	if s.LevelSource == "" {
+		s.LevelSource = LevelSourceFormula
+	}
+	for _, f := range []*float64{&s.LiquidityFactor, &s.MomentumFactor, &s.PerformanceFactor} {
+		if *f == 0 {
+			*f = 1
+		}
+	}
+	if s.Leverage == 0 {
+		s.Leverage = 1
+	}
+	if s.RiskReward == 0 {
+		s.RiskReward = riskReward(s)
+	}
+}
+
+// ReadStrikeLog decodes every record in r, oldest first. Blank lines are
+// skipped; a line that doesn't decode stops the read with its line number.
+func ReadStrikeLog(r io.Reader) ([]StrikeLogRecord, error) {
+	var out []StrikeLogRecord
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		rec, err := DecodeStrikeRecord(sc.Bytes())
+		if err != nil {
+			return out, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+// ReadStrikeLogFile reads a strike log, plain or gzip-rotated
+func ReadStrikeLogFile(path string) ([]StrikeLogRecord, error) {
+	r, closeFn, err := openMaybeGzip(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+	recs, err := ReadStrikeLog(r)
+	if err != nil {
+		return recs, fmt.Errorf("%s: %w", path, err)
+	}
+	return recs, nil
+}
+
+// ImportStrikeLogs loads strike logs of any supported version into the
+// journal, returning how many strikes it recorded. Rows are keyed by run and
+// strike ID, so importing a log twice is harmless.
+func (te *TradingEngine) ImportStrikeLogs(paths []string) (int, error) {
+	if te.journal == nil {
+		return 0, fmt.Errorf("import-strike-log needs JOURNAL_DB or JOURNAL_POSTGRES_DSN")
+	}
+	imported := 0
+	for _, path := range paths {
+		recs, err := ReadStrikeLogFile(path)
+		if err != nil {
+			return imported, err
+		}
+		for _, rec := range recs {
+			if rec.Type == "strike" && rec.Strike != nil {
+				te.journal.RecordStrike(rec.RunID, rec.Strike)
+				imported++
+			}
+		}
+	}
+	te.journal.Flush()
+	return imported, nil
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStrikeLogV1FixtureMigrates(t *testing.T) {
+	recs, err := ReadStrikeLogFile(filepath.Join("testdata", "strike_log_v1.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadStrikeLogFile: %v", err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("read %d records, want 3", len(recs))
+	}
+	for _, rec := range recs {
+		if rec.Version != strikeLogVersion {
+			t.Errorf("record version = %d, want it upgraded to %d", rec.Version, strikeLogVersion)
+		}
+	}
+	if recs[1].Type != "skip" || recs[1].SkipReason != SkipLowConfidence || recs[1].Strike != nil {
+		t.Errorf("skip record = %+v", recs[1])
+	}
+
+	hit, miss := recs[0].Strike, recs[2].Strike
+	if hit.ExitReason != ExitTakeProfit || miss.ExitReason != ExitStopLoss {
+		t.Errorf("exit reasons = %q, %q; want %q, %q", hit.ExitReason, miss.ExitReason, ExitTakeProfit, ExitStopLoss)
+	}
+	if hit.LevelSource != LevelSourceFormula || hit.LiquidityFactor != 1 || hit.MomentumFactor != 1 || hit.PerformanceFactor != 1 {
+		t.Errorf("v1 defaults not applied: %+v", hit)
+	}
+	// A recorded leverage is kept; a missing one becomes 1x
+	if hit.Leverage != 5 || miss.Leverage != 1 {
+		t.Errorf("leverage = %d, %d; want 5, 1", hit.Leverage, miss.Leverage)
+	}
+	if hit.RiskReward != 1.5 {
+		t.Errorf("risk/reward = %v, want 1.5 recomputed from the levels", hit.RiskReward)
+	}
+	if hit.Fees != 0 || hit.Slippage != 0 || *hit.PnL != 45 {
+		t.Errorf("fees %v slippage %v pnl %v, want 0, 0, 45", hit.Fees, hit.Slippage, *hit.PnL)
+	}
+}
+
+func TestStrikeLogV2FixtureReadsUnchanged(t *testing.T) {
+	recs, err := ReadStrikeLogFile(filepath.Join("testdata", "strike_log_v2.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadStrikeLogFile: %v", err)
+	}
+	if len(recs) != 2 || recs[1].SkipReason != SkipVolatility {
+		t.Fatalf("records = %+v", recs)
+	}
+	s := recs[0].Strike
+	if s.ExitReason != ExitHoldExpired || s.LevelSource != LevelSourceAnalyst || s.PerformanceFactor != 0.75 {
+		t.Errorf("v2 fields overwritten: exit %q source %q perf %v", s.ExitReason, s.LevelSource, s.PerformanceFactor)
+	}
+	if s.Fees != 0.12 || s.DurationMs != 20150 || *s.EntryTxID != "OENTRY-1" || len(s.TradeIDs) != 2 || len(s.Transitions) != 3 {
+		t.Errorf("v2 strike = %+v", s)
+	}
+}
+
+func TestStrikeLogWritesCurrentVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strikes.jsonl")
+	sl, err := NewJSONLStrikeLogger(path, "run-1", RotationPolicy{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pnl := 1.5
+	sl.LogStrike(&MacroStrike{ID: 1, Symbol: "WETH/USDC", Status: Hit, PnL: &pnl, ExitReason: ExitHoldExpired}, 1001.5)
+	sl.LogSkip(newSkip(SkipRiskReward, "r:r 0.8"), 1001.5)
+	if err := sl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := ReadStrikeLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].Version != strikeLogVersion || recs[0].Strike.ExitReason != ExitHoldExpired {
+		t.Errorf("records = %+v", recs)
+	}
+}
+
+func TestStrikeRecordRejectsFutureVersions(t *testing.T) {
+	_, err := DecodeStrikeRecord([]byte(`{"v":3,"type":"strike","strike":{"id":1}}`))
+	var uv *UnsupportedRecordVersionError
+	if !errors.As(err, &uv) || uv.Version != 3 {
+		t.Errorf("err = %v, want an UnsupportedRecordVersionError for v3", err)
+	}
+	if _, err := DecodeStrikeRecord([]byte(`{"v":-1,"type":"skip"}`)); err == nil {
+		t.Error("a negative version should be rejected")
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// StateTransition is one step in a strike's lifecycle. The first entry has no
+// From: it records the strike being created in Targeting.
+type StateTransition struct {
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+	Price  float64   `json:"price"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// transition moves a strike to status and appends it to the strike's timeline
+func (te *TradingEngine) transition(strike *MacroStrike, to StrikeStatus, price float64, reason string) {
+	st := StateTransition{To: to.String(), At: te.Clock.Now().UTC(), Price: price, Reason: reason}
+	if len(strike.Transitions) > 0 {
+		st.From = strike.Status.String()
+	}
+	strike.Status = to
+	strike.Transitions = append(strike.Transitions, st)
+	te.trackOpenStrike(strike, st.At)
+}
+
+// DumpTransitions writes the strike's timeline, one transition per line
+func (s *MacroStrike) DumpTransitions(w io.Writer) error {
+	for _, t := range s.Transitions {
+		step := t.To
+		if t.From != "" {
+			step = t.From + " → " + t.To
+		}
+		line := fmt.Sprintf("%s  %-20s price=%.6f", t.At.Format(time.RFC3339Nano), step, t.Price)
+		if t.Reason != "" {
+			line += "  (" + t.Reason + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransitionLog returns DumpTransitions as a string
+func (s *MacroStrike) TransitionLog() string {
+	var sb strings.Builder
+	s.DumpTransitions(&sb)
+	return sb.String()
+}
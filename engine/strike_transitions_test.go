@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestStrikeRecordsStateTransitions(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	clock := clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Clock = clock
+	te.SimMinHoldMs = 1000
+
+	strike, err := te.GenerateStrike(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateStrike: %v", err)
+	}
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+
+	tr := strike.Transitions
+	if len(tr) != 3 {
+		t.Fatalf("got %d transitions, want 3: %+v", len(tr), tr)
+	}
+	if tr[0].From != "" || tr[0].To != "targeting" || tr[0].Price != strike.EntryPrice {
+		t.Errorf("first transition = %+v, want creation in targeting at entry", tr[0])
+	}
+	if tr[1].From != "targeting" || tr[1].To != "striking" {
+		t.Errorf("second transition = %+v, want targeting → striking", tr[1])
+	}
+	last := tr[2]
+	if last.From != "striking" || last.To != strike.Status.String() || last.Reason != strike.ExitReason || last.Price != *strike.ExitPrice {
+		t.Errorf("final transition = %+v, want striking → %s (%s) at exit", last, strike.Status, strike.ExitReason)
+	}
+	// The simulated hold elapses on the fake clock between striking and the outcome
+	if d := last.At.Sub(tr[1].At); d <= 0 {
+		t.Errorf("outcome recorded %v after striking, want a positive hold", d)
+	}
+
+	dump := strike.TransitionLog()
+	if lines := strings.Split(strings.TrimSpace(dump), "\n"); len(lines) != 3 || !strings.Contains(lines[1], "targeting → striking") {
+		t.Errorf("unexpected dump:\n%s", dump)
+	}
+}
@@ -0,0 +1,32 @@
+package engine
+
+// defaultTargetSlippageBps is the round-trip slippage TARGET_COST_HAIRCUT
+// assumes without TARGET_SLIPPAGE_BPS
+const defaultTargetSlippageBps = 10.0
+
+// targetCostPct is the round-trip cost a formulaic target has to clear:
+// exchange fees plus the configured slippage
+func (te *TradingEngine) targetCostPct() float64 {
+	return RoundTripFeePct + te.TargetSlippagePct
+}
+
+// netTargetReturn is the return the formulaic target is placed at. With
+// TARGET_COST_HAIRCUT the estimated round-trip costs come off expectedReturn
+// so the target is reachable net of them; covered is false when they take
+// the whole return.
+func (te *TradingEngine) netTargetReturn(expectedReturn float64) (net float64, covered bool) {
+	if !te.TargetCostHaircut {
+		return expectedReturn, true
+	}
+	net = expectedReturn - te.targetCostPct()
+	if net <= 0 {
+		return 0, false
+	}
+	return net, true
+}
+
+// costSkip turns away a setup whose expected return does not cover its costs
+func (te *TradingEngine) costSkip(symbol string, expectedReturn float64) error {
+	return newSkip(SkipCostHaircut, "%s expected return %.3f%% does not cover %.3f%% round-trip costs",
+		symbol, expectedReturn*100, te.targetCostPct()*100)
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"macro-strike-bot/config"
+)
+
+func TestTargetCostHaircutNetsFormulaTarget(t *testing.T) {
+	gen := func(cfg config.Config) *MacroStrike {
+		t.Helper()
+		te := NewTradingEngineFromConfig(cfg)
+		for {
+			strike, err := te.generateAnalyzedStrike(context.Background())
+			if strike != nil {
+				return strike
+			}
+			var skip *skipError
+			if !errors.As(err, &skip) {
+				t.Fatalf("generateAnalyzedStrike: %v", err)
+			}
+		}
+	}
+	base := config.Config{"SIM_MODE": "1", "RAND_SEED": "7"}
+	gross := gen(base)
+	net := gen(config.Config{"SIM_MODE": "1", "RAND_SEED": "7", "TARGET_COST_HAIRCUT": "1", "TARGET_SLIPPAGE_BPS": "20"})
+	if gross.ID != net.ID || gross.StrikeType != net.StrikeType {
+		t.Fatalf("seeded runs diverged: %d/%v vs %d/%v", gross.ID, gross.StrikeType, net.ID, net.StrikeType)
+	}
+	grossRet := gross.TargetPrice/gross.EntryPrice - 1
+	netRet := net.TargetPrice/net.EntryPrice - 1
+	if want := grossRet - (RoundTripFeePct + 0.002); math.Abs(netRet-want) > 1e-9 {
+		t.Errorf("net target return = %.5f, want %.5f (gross %.5f less fees and 20bps)", netRet, want, grossRet)
+	}
+	if net.ExpectedReturn != gross.ExpectedReturn {
+		t.Errorf("expected return changed to %.5f; only the target should be netted", net.ExpectedReturn)
+	}
+}
+
+func TestTargetCostHaircutSkipsUncoveredReturns(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"TARGET_COST_HAIRCUT": "1"})
+	if got, ok := te.netTargetReturn(0.005); !ok || math.Abs(got-(0.005-RoundTripFeePct-0.001)) > 1e-12 {
+		t.Errorf("net of 0.5%% = %.5f (%v)", got, ok)
+	}
+	if _, ok := te.netTargetReturn(0.002); ok {
+		t.Error("a 0.2% return does not cover 0.26% of costs")
+	}
+	var skip *skipError
+	if err := te.costSkip("WETH/USDC", 0.002); !errors.As(err, &skip) || skip.Reason != SkipCostHaircut {
+		t.Errorf("cost skip = %v", err)
+	}
+	if got, ok := NewTradingEngineFromConfig(config.Config{}).netTargetReturn(0.002); !ok || got != 0.002 {
+		t.Errorf("haircut applied without TARGET_COST_HAIRCUT: %.5f", got)
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+// Pipeline stages timed on each strike
+const (
+	StageAnalysis    = "analysis"
+	StageOrderSubmit = "order_submit"
+	StageEntryFill   = "entry_fill"
+	StageHold        = "hold"
+	StageExitSubmit  = "exit_submit"
+	StageExitFill    = "exit_fill"
+)
+
+// stageWindowSize is the number of recent samples each stage's percentiles cover
+const stageWindowSize = 1000
+
+// StrikeTimings is how long each pipeline stage took on one strike. Stages a
+// strike never reached, and every order stage of a simulated strike, stay 0;
+// take-profit rungs sold during the hold count toward the hold.
+type StrikeTimings struct {
+	AnalysisMs    int64 `json:"analysis_ms"`
+	OrderSubmitMs int64 `json:"order_submit_ms"`
+	EntryFillMs   int64 `json:"entry_fill_ms"`
+	HoldMs        int64 `json:"hold_ms"`
+	ExitSubmitMs  int64 `json:"exit_submit_ms"`
+	ExitFillMs    int64 `json:"exit_fill_ms"`
+}
+
+func (t *StrikeTimings) set(stage string, d time.Duration) {
+	ms := d.Milliseconds()
+	switch stage {
+	case StageAnalysis:
+		t.AnalysisMs = ms
+	case StageOrderSubmit:
+		t.OrderSubmitMs = ms
+	case StageEntryFill:
+		t.EntryFillMs = ms
+	case StageHold:
+		t.HoldMs = ms
+	case StageExitSubmit:
+		t.ExitSubmitMs = ms
+	case StageExitFill:
+		t.ExitFillMs = ms
+	}
+}
+
+// stageTimed records d as one stage of strike, on the strike itself, in the
+// campaign's stage percentiles and in the stage latency histogram. Callers
+// measure d on te.Clock so tests control it.
+func (te *TradingEngine) stageTimed(strike *MacroStrike, stage string, d time.Duration) {
+	if strike.Timings == nil {
+		strike.Timings = &StrikeTimings{}
+	}
+	strike.Timings.set(stage, d)
+	te.stageLatency.Observe(stage, d)
+	te.metrics.StageLatency(stage, d)
+}
+
+// stageWindow keeps a stage's most recent durations
+type stageWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *stageWindow) add(d time.Duration) {
+	if len(w.samples) < stageWindowSize {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % stageWindowSize
+}
+
+// StageLatencyTracker aggregates pipeline stage durations across a campaign
+type StageLatencyTracker struct {
+	mu      sync.Mutex
+	byStage map[string]*stageWindow
+}
+
+// NewStageLatencyTracker creates an empty stage tracker
+func NewStageLatencyTracker() *StageLatencyTracker {
+	return &StageLatencyTracker{byStage: make(map[string]*stageWindow)}
+}
+
+// Observe records one stage duration
+func (st *StageLatencyTracker) Observe(stage string, d time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	w, ok := st.byStage[stage]
+	if !ok {
+		w = &stageWindow{}
+		st.byStage[stage] = w
+	}
+	w.add(d)
+}
+
+// Stats returns nearest-rank percentiles for every stage observed so far
+func (st *StageLatencyTracker) Stats() map[string]report.StageLatency {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make(map[string]report.StageLatency, len(st.byStage))
+	for stage, w := range st.byStage {
+		ms := make([]float64, len(w.samples))
+		for i, d := range w.samples {
+			ms[i] = float64(d) / float64(time.Millisecond)
+		}
+		sort.Float64s(ms)
+		out[stage] = report.StageLatency{
+			Samples: len(ms),
+			P50Ms:   nearestRank(ms, 50),
+			P90Ms:   nearestRank(ms, 90),
+			P99Ms:   nearestRank(ms, 99),
+			MaxMs:   ms[len(ms)-1],
+		}
+	}
+	return out
+}
+
+// nearestRank returns the p-th percentile of sorted, non-empty values
+func nearestRank(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
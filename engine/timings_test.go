@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/report"
+)
+
+func TestLiveStrikeRecordsStageTimings(t *testing.T) {
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"open","vol_exec":"0"}}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"2500"}}`),
+		krakenReply("/0/private/AddOrder", `{"txid":["SELL1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"SELL1":{"status":"closed","vol_exec":"0.04","price":"2510"}}`),
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.OrderUSDSize = 100
+	te.FillPollIntervalMs = 250
+	// Poll jitter comes from the strike's seeded stream, so a pinned seed pins it
+	te.RandSeed = 42
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	strike.MaxExposureTimeMs = 20000
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	// The fake clock only moves on sleeps: one jittered 200ms entry poll and the 20s hold
+	want := StrikeTimings{EntryFillMs: 179, HoldMs: 20000}
+	if strike.Timings == nil || *strike.Timings != want {
+		t.Fatalf("timings = %+v, want %+v", strike.Timings, want)
+	}
+
+	stats := te.Stats().StageLatency
+	if hold := stats[StageHold]; hold.Samples != 1 || hold.P50Ms != 20000 || hold.P99Ms != 20000 {
+		t.Errorf("hold stats = %+v, want one 20000ms sample", hold)
+	}
+	if _, ok := stats[StageAnalysis]; ok {
+		t.Error("analysis stats recorded for a strike that was never analyzed")
+	}
+
+	var buf bytes.Buffer
+	te.WriteMetrics(&buf)
+	for _, line := range []string{
+		`macro_stage_latency_seconds_bucket{stage="hold",le="30"} 1`,
+		`macro_stage_latency_seconds_bucket{stage="entry_fill",le="0.25"} 1`,
+		`macro_stage_latency_seconds_count{stage="exit_fill"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics missing %q", line)
+		}
+	}
+}
+
+func TestStageLatencyPercentiles(t *testing.T) {
+	st := NewStageLatencyTracker()
+	for i := 1; i <= 100; i++ {
+		st.Observe(StageEntryFill, time.Duration(i)*time.Millisecond)
+	}
+	got := st.Stats()[StageEntryFill]
+	want := report.StageLatency{Samples: 100, P50Ms: 50, P90Ms: 90, P99Ms: 99, MaxMs: 100}
+	if got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// Only the most recent window counts
+	for i := 0; i < stageWindowSize; i++ {
+		st.Observe(StageEntryFill, time.Second)
+	}
+	if got := st.Stats()[StageEntryFill]; got.Samples != stageWindowSize || got.P50Ms != 1000 || got.MaxMs != 1000 {
+		t.Errorf("stats after a full window = %+v, want %d samples of 1000ms", got, stageWindowSize)
+	}
+}
@@ -0,0 +1,386 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+)
+
+// Span export tuning: ended spans are batched and posted every
+// traceFlushInterval, or sooner once traceBatchSize are waiting. Spans beyond
+// traceQueueLimit are dropped rather than held in memory.
+const (
+	traceFlushInterval = 5 * time.Second
+	traceBatchSize     = 256
+	traceQueueLimit    = 4096
+	traceDrainTimeout  = 5 * time.Second
+	traceScopeName     = "macro-strike-bot"
+)
+
+// OTLP status codes
+const (
+	spanStatusOK    = 1
+	spanStatusError = 2
+)
+
+// Tracer records spans and exports them to an OTLP/HTTP collector as JSON.
+// A nil *Tracer is a no-op, as are the nil spans it hands out.
+type Tracer struct {
+	Endpoint string
+	Service  string
+	headers  map[string]string
+	client   *http.Client
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	pending []*Span
+	dropped int
+	closed  bool
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewTracerFromConfig returns nil, leaving tracing off, unless
+// OTEL_EXPORTER_OTLP_ENDPOINT (the collector base URL, /v1/traces appended)
+// or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (the full URL) is set.
+// OTEL_EXPORTER_OTLP_HEADERS adds k=v,... request headers and
+// OTEL_SERVICE_NAME names the service.
+func NewTracerFromConfig(cfg config.Config, client *http.Client, clock clock.Clock) (*Tracer, error) {
+	endpoint := cfg.Get("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		if base := cfg.Get("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT: %q is not an http(s) URL", endpoint)
+	}
+	headers := make(map[string]string)
+	if v := cfg.Get("OTEL_EXPORTER_OTLP_HEADERS"); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %q is not key=value", pair)
+			}
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+	}
+	service := cfg.Get("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = traceScopeName
+	}
+	return NewTracer(endpoint, service, headers, client, clock), nil
+}
+
+// NewTracer starts the export worker; Close flushes and stops it
+func NewTracer(endpoint, service string, headers map[string]string, client *http.Client, clock clock.Clock) *Tracer {
+	t := &Tracer{
+		Endpoint: endpoint,
+		Service:  service,
+		headers:  headers,
+		client:   client,
+		clock:    clock,
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Span is one timed operation in a trace
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  map[string]interface{}
+	errMsg string
+	ended  bool
+}
+
+type spanKey struct{}
+
+// spanFromContext returns the span carried by ctx, if any
+func spanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// Start begins a span named name, a child of the span in ctx if there is
+// one, and returns a context carrying it. attrs are key, value pairs.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{tracer: t, name: name, start: t.clock.Now(), attrs: make(map[string]interface{})}
+	rand.Read(s.spanID[:])
+	if parent := spanFromContext(ctx); parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	s.SetAttrs(attrs...)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttrs records key, value pairs on the span
+func (s *Span) SetAttrs(kv ...interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+1 < len(kv); i += 2 {
+		if k, ok := kv[i].(string); ok {
+			s.attrs[k] = kv[i+1]
+		}
+	}
+}
+
+// SetError marks the span failed; a nil err leaves it unchanged
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export; later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = s.tracer.clock.Now()
+	s.mu.Unlock()
+	s.tracer.enqueue(s)
+}
+
+// Fail ends the span with err's outcome and returns err, for return sites.
+// A skip is recorded as its reason rather than as an error; a nil err just
+// ends the span.
+func (s *Span) Fail(err error) error {
+	var skip *skipError
+	if errors.As(err, &skip) {
+		s.SetAttrs("skip.reason", skip.Reason)
+	} else {
+		s.SetError(err)
+	}
+	s.End()
+	return err
+}
+
+// strikeContext returns the context carrying strike's root span, or a bare
+// one when the strike is untraced
+func strikeContext(strike *MacroStrike) context.Context {
+	if strike.traceCtx != nil {
+		return strike.traceCtx
+	}
+	return context.Background()
+}
+
+// strikeTrace returns strike's root span and ctx carrying it, starting the
+// span for a strike that arrived without one (scripted or replayed strikes).
+// Cancellation comes from ctx, not from the context the strike was built in.
+func (te *TradingEngine) strikeTrace(ctx context.Context, strike *MacroStrike) (context.Context, *Span) {
+	if strike.traceCtx == nil {
+		strike.traceCtx, _ = te.tracer.Start(ctx, "strike", "strike.id", strike.ID, "strike.symbol", strike.Symbol)
+		return strike.traceCtx, spanFromContext(strike.traceCtx)
+	}
+	root := spanFromContext(strike.traceCtx)
+	if root != nil {
+		ctx = context.WithValue(ctx, spanKey{}, root)
+	}
+	return ctx, root
+}
+
+func (t *Tracer) enqueue(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed || len(t.pending) >= traceQueueLimit {
+		t.dropped++
+		return
+	}
+	t.pending = append(t.pending, s)
+	if len(t.pending) >= traceBatchSize {
+		select {
+		case t.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close exports the spans still queued, waiting at most timeout
+func (t *Tracer) Close(timeout time.Duration) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.stop)
+	}
+	t.mu.Unlock()
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	tick := time.NewTicker(traceFlushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+		case <-t.kick:
+		case <-t.stop:
+			t.flush()
+			return
+		}
+		t.flush()
+	}
+}
+
+// flush posts every queued span; a failed export is logged and its spans lost
+func (t *Tracer) flush() {
+	t.mu.Lock()
+	batch, dropped := t.pending, t.dropped
+	t.pending, t.dropped = nil, 0
+	t.mu.Unlock()
+	if dropped > 0 {
+		log.Printf("⚠️ Tracing dropped %d spans: export queue full", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := t.export(batch); err != nil {
+		log.Printf("⚠️ Trace export of %d spans failed: %v", len(batch), err)
+	}
+}
+
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// otlpRequest builds an ExportTraceServiceRequest in the OTLP JSON encoding
+func (t *Tracer) otlpRequest(spans []*Span) map[string]interface{} {
+	out := make([]map[string]interface{}, len(spans))
+	for i, s := range spans {
+		s.mu.Lock()
+		span := map[string]interface{}{
+			"traceId":           hex.EncodeToString(s.traceID[:]),
+			"spanId":            hex.EncodeToString(s.spanID[:]),
+			"name":              s.name,
+			"kind":              1,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+			"attributes":        otlpAttributes(s.attrs),
+			"status":            map[string]interface{}{"code": spanStatusOK},
+		}
+		if s.parentID != ([8]byte{}) {
+			span["parentSpanId"] = hex.EncodeToString(s.parentID[:])
+		}
+		if s.errMsg != "" {
+			span["status"] = map[string]interface{}{"code": spanStatusError, "message": s.errMsg}
+		}
+		s.mu.Unlock()
+		out[i] = span
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": t.Service}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": traceScopeName},
+				"spans": out,
+			}},
+		}},
+	}
+}
+
+// otlpAttributes encodes attributes as OTLP KeyValues, sorted by key
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]map[string]interface{}, 0, len(keys))
+	for _, k := range keys {
+		var value map[string]interface{}
+		switch v := attrs[k].(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case uint64:
+			value = map[string]interface{}{"intValue": strconv.FormatUint(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, map[string]interface{}{"key": k, "value": value})
+	}
+	return out
+}
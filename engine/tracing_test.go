@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// otlpSpan is the part of an exported OTLP/JSON span the tests read
+type otlpSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Attributes   []struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func (s otlpSpan) attr(key string) interface{} {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			for _, v := range a.Value {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// otlpCollector is a fake collector that keeps every span posted to it
+func otlpCollector(t *testing.T) (*httptest.Server, func() map[string]otlpSpan) {
+	t.Helper()
+	var mu sync.Mutex
+	var spans []otlpSpan
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("export to %s as %q", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []otlpSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode export: %v", err)
+		}
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() map[string]otlpSpan {
+		mu.Lock()
+		defer mu.Unlock()
+		byName := make(map[string]otlpSpan)
+		for _, s := range spans {
+			byName[s.Name] = s
+		}
+		return byName
+	}
+}
+
+func TestSimStrikeExportsSpanTree(t *testing.T) {
+	srv, spans := otlpCollector(t)
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1", "RAND_SEED": "1", "OTEL_EXPORTER_OTLP_ENDPOINT": srv.URL + "/"})
+	if te.tracer == nil {
+		t.Fatal("OTEL_EXPORTER_OTLP_ENDPOINT did not enable tracing")
+	}
+	var strike *MacroStrike
+	for strike == nil {
+		strike, _ = te.generateAnalyzedStrike(context.Background())
+	}
+	pnl, err := te.ExecuteStrike(context.Background(), strike)
+	if err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if !te.tracer.Close(5 * time.Second) {
+		t.Fatal("tracer did not flush")
+	}
+
+	got := spans()
+	root, ok := got["strike"]
+	if !ok {
+		t.Fatalf("no root strike span in %v", got)
+	}
+	if root.attr("strike.id") != strconv.FormatUint(strike.ID, 10) {
+		t.Errorf("strike.id = %v, want %d", root.attr("strike.id"), strike.ID)
+	}
+	if root.attr("strike.symbol") != strike.Symbol || root.attr("strike.pnl") != pnl {
+		t.Errorf("root attrs symbol=%v pnl=%v, want %s %v", root.attr("strike.symbol"), root.attr("strike.pnl"), strike.Symbol, pnl)
+	}
+	for _, name := range []string{"sizing", "exit"} {
+		s, ok := got[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if s.TraceID != root.TraceID || s.ParentSpanID != root.SpanID {
+			t.Errorf("%s span is not a child of the strike span", name)
+		}
+	}
+}
+
+func TestFailedLiveExitSetsSpanStatus(t *testing.T) {
+	srv, spans := otlpCollector(t)
+	te := replayEngine(t,
+		krakenReply("/0/private/AddOrder", `{"txid":["BUY1"]}`),
+		krakenReply("/0/private/QueryOrders", `{"BUY1":{"status":"closed","vol_exec":"0.04","price":"2500"}}`),
+		// No reply for the exit: it fails once the replay runs out
+	)
+	te.pairInfoCache["ETHUSD"] = pairInfo{LotDecimals: 8, PairDecimals: 2}
+	te.tracer = NewTracer(srv.URL+"/v1/traces", "test", nil, srv.Client(), te.Clock)
+
+	strike := certainStrike(1, true)
+	strike.EntryPrice = 2500
+	if _, err := te.ExecuteStrike(context.Background(), strike); err == nil {
+		t.Fatal("exit rejection did not fail the strike")
+	}
+	te.tracer.Close(5 * time.Second)
+
+	got := spans()
+	if s := got["add_order"]; s.attr("txid") != "BUY1" || s.Status.Code != spanStatusOK {
+		t.Errorf("add_order span txid=%v status=%d, want BUY1 ok", s.attr("txid"), s.Status.Code)
+	}
+	for _, name := range []string{"exit", "strike"} {
+		if s := got[name]; s.Status.Code != spanStatusError || !strings.Contains(s.Status.Message, "exit failed") {
+			t.Errorf("%s span status = %d %q, want the exit error", name, s.Status.Code, s.Status.Message)
+		}
+	}
+	if got["strike"].attr("entry.txid") != "BUY1" {
+		t.Errorf("root entry.txid = %v", got["strike"].attr("entry.txid"))
+	}
+}
+
+func TestTracingOffWithoutEndpoint(t *testing.T) {
+	te := NewTradingEngineFromConfig(config.Config{"SIM_MODE": "1"})
+	if te.tracer != nil {
+		t.Fatal("tracing enabled without an endpoint")
+	}
+	strike := certainStrike(1, true)
+	if _, err := te.ExecuteStrike(context.Background(), strike); err != nil {
+		t.Fatalf("ExecuteStrike: %v", err)
+	}
+	if err := NewTradingEngineFromConfig(config.Config{"OTEL_EXPORTER_OTLP_ENDPOINT": "collector:4318"}).ValidateConfig(); err == nil {
+		t.Error("an endpoint without a scheme should be rejected")
+	}
+}
@@ -0,0 +1,421 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/exchange"
+)
+
+// importRunID is the journal run every imported round trip is stored under
+const importRunID = "kraken-import"
+
+// importCheckpointVersion is bumped whenever the checkpoint layout changes
+const importCheckpointVersion = 1
+
+// KrakenFill is one execution from /0/private/TradesHistory
+type KrakenFill struct {
+	TxID    string  `json:"txid"`
+	OrderTx string  `json:"ordertxid"`
+	Pair    string  `json:"pair"`
+	Time    float64 `json:"time"`
+	Side    string  `json:"type"`
+	Price   float64 `json:"price"`
+	Cost    float64 `json:"cost"`
+	Fee     float64 `json:"fee"`
+	Volume  float64 `json:"vol"`
+}
+
+// importCheckpoint is the paging progress of an interrupted import, so a
+// rerun over the same range continues from the saved offset
+type importCheckpoint struct {
+	Version int                   `json:"version"`
+	Start   time.Time             `json:"start"`
+	End     time.Time             `json:"end"`
+	Offset  int                   `json:"offset"`
+	Count   int                   `json:"count"`
+	Fills   map[string]KrakenFill `json:"fills"`
+}
+
+// ImportOptions selects the trade history to import
+type ImportOptions struct {
+	Start time.Time
+	End   time.Time
+	// Offset overrides the checkpoint's paging offset when non-negative
+	Offset int
+	// CheckpointPath persists paging progress; empty disables resuming
+	CheckpointPath string
+	// PageDelay spaces TradesHistory calls to stay inside Kraken's rate limit
+	PageDelay time.Duration
+}
+
+// ImportSummary reports what an import did
+type ImportSummary struct {
+	Fills           int
+	Unmapped        int
+	RoundTrips      int
+	Imported        int
+	AlreadyImported int
+	OpenPositions   int
+	UnpairedSells   int
+}
+
+// ImportTradeHistory pages through Kraken's TradesHistory for opts' range,
+// rebuilds round trips per engine symbol and writes the new ones to the
+// journal (run importRunID) and the performance store. Round-trip IDs derive
+// from their first fill, so re-running never duplicates anything.
+func (te *TradingEngine) ImportTradeHistory(ctx context.Context, opts ImportOptions) (ImportSummary, error) {
+	var sum ImportSummary
+	if te.journal == nil {
+		return sum, fmt.Errorf("importing trades needs JOURNAL_DB or JOURNAL_POSTGRES_DSN")
+	}
+	fills, err := te.fetchTradeHistory(ctx, opts)
+	if err != nil {
+		return sum, err
+	}
+	sum.Fills = len(fills)
+
+	pairs, err := te.importPairSymbols(ctx)
+	if err != nil {
+		return sum, fmt.Errorf("AssetPairs: %v", err)
+	}
+	bySymbol := make(map[string][]KrakenFill)
+	for _, f := range fills {
+		sym, ok := pairs[f.Pair]
+		if !ok {
+			sum.Unmapped++
+			continue
+		}
+		bySymbol[sym] = append(bySymbol[sym], f)
+	}
+
+	existing, err := te.journal.QueryStrikes(StrikeQuery{RunID: importRunID})
+	if err != nil {
+		return sum, fmt.Errorf("journal: %v", err)
+	}
+	seen := make(map[uint64]bool, len(existing))
+	for _, s := range existing {
+		seen[s.ID] = true
+	}
+
+	symbolsSorted := make([]string, 0, len(bySymbol))
+	for sym := range bySymbol {
+		symbolsSorted = append(symbolsSorted, sym)
+	}
+	sort.Strings(symbolsSorted)
+	for _, sym := range symbolsSorted {
+		trips, open, unpaired := buildRoundTrips(sym, bySymbol[sym])
+		sum.RoundTrips += len(trips)
+		sum.OpenPositions += open
+		sum.UnpairedSells += unpaired
+		for _, strike := range trips {
+			if seen[strike.ID] {
+				sum.AlreadyImported++
+				continue
+			}
+			te.journal.RecordStrike(importRunID, strike)
+			if te.perfStore != nil {
+				te.perfStore.Record(strike.Symbol, strike.StrikeType.String(), time.Unix(*strike.HitTime, 0), *strike.PnL, strike.Status == Hit)
+			}
+			sum.Imported++
+		}
+	}
+	te.journal.Flush()
+	if opts.CheckpointPath != "" {
+		if err := os.Remove(opts.CheckpointPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️ Import checkpoint cleanup: %v", err)
+		}
+	}
+	return sum, nil
+}
+
+// fetchTradeHistory collects every fill in the range, resuming from and
+// saving to the checkpoint after each page
+func (te *TradingEngine) fetchTradeHistory(ctx context.Context, opts ImportOptions) ([]KrakenFill, error) {
+	cp := importCheckpoint{Version: importCheckpointVersion, Start: opts.Start, End: opts.End, Count: -1, Fills: make(map[string]KrakenFill)}
+	if opts.CheckpointPath != "" {
+		if saved, ok, err := loadImportCheckpoint(opts.CheckpointPath); err != nil {
+			return nil, err
+		} else if ok && saved.Start.Equal(opts.Start) && (opts.End.IsZero() || saved.End.Equal(opts.End)) {
+			log.Printf("♻️ Resuming trade import at offset %d (%d fills fetched)", saved.Offset, len(saved.Fills))
+			cp = saved
+		}
+	}
+	if cp.End.IsZero() {
+		// A fixed end keeps offsets stable while new trades arrive
+		cp.End = te.Clock.Now()
+	}
+	if opts.Offset >= 0 {
+		cp.Offset = opts.Offset
+	}
+
+	for cp.Count < 0 || cp.Offset < cp.Count {
+		if cp.Count >= 0 && opts.PageDelay > 0 {
+			if err := clock.SleepContext(ctx, te.Clock, opts.PageDelay); err != nil {
+				return nil, err
+			}
+		}
+		vals := url.Values{}
+		if !cp.Start.IsZero() {
+			vals.Set("start", strconv.FormatInt(cp.Start.Unix(), 10))
+		}
+		vals.Set("end", strconv.FormatInt(cp.End.Unix(), 10))
+		vals.Set("ofs", strconv.Itoa(cp.Offset))
+		res, err := te.krakenPrivateWithRetry(ctx, "/0/private/TradesHistory", vals)
+		if err != nil {
+			return nil, fmt.Errorf("TradesHistory at offset %d: %v", cp.Offset, err)
+		}
+		page, entries, count, err := parseTradesHistory(res)
+		if err != nil {
+			return nil, err
+		}
+		cp.Count = count
+		for _, f := range page {
+			cp.Fills[f.TxID] = f
+		}
+		if entries == 0 {
+			break
+		}
+		cp.Offset += entries
+		if opts.CheckpointPath != "" {
+			if err := saveImportCheckpoint(opts.CheckpointPath, cp); err != nil {
+				return nil, fmt.Errorf("checkpoint: %v", err)
+			}
+		}
+		te.debugf("trade import: %d/%d fills", cp.Offset, cp.Count)
+	}
+
+	fills := make([]KrakenFill, 0, len(cp.Fills))
+	for _, f := range cp.Fills {
+		fills = append(fills, f)
+	}
+	sort.Slice(fills, func(i, j int) bool {
+		if fills[i].Time != fills[j].Time {
+			return fills[i].Time < fills[j].Time
+		}
+		return fills[i].TxID < fills[j].TxID
+	})
+	return fills, nil
+}
+
+// parseTradesHistory reads one TradesHistory page: its usable fills, the
+// number of entries it held (for the next offset) and the total fill count
+func parseTradesHistory(res map[string]interface{}) ([]KrakenFill, int, int, error) {
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("unexpected kraken TradesHistory response")
+	}
+	trades, _ := result["trades"].(map[string]interface{})
+	var fills []KrakenFill
+	for txid, v := range trades {
+		raw, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		f := KrakenFill{
+			TxID:   txid,
+			Pair:   fmt.Sprint(raw["pair"]),
+			Side:   fmt.Sprint(raw["type"]),
+			Time:   exchange.ParseNumericField(raw["time"]),
+			Price:  exchange.ParseNumericField(raw["price"]),
+			Cost:   exchange.ParseNumericField(raw["cost"]),
+			Fee:    exchange.ParseNumericField(raw["fee"]),
+			Volume: exchange.ParseNumericField(raw["vol"]),
+		}
+		f.OrderTx, _ = raw["ordertxid"].(string)
+		if f.Volume <= 0 || f.Price <= 0 {
+			continue
+		}
+		if f.Cost <= 0 {
+			f.Cost = f.Price * f.Volume
+		}
+		fills = append(fills, f)
+	}
+	return fills, len(trades), int(exchange.ParseNumericField(result["count"])), nil
+}
+
+// importPairSymbols maps Kraken pair names, canonical and alternate, to the
+// engine symbol trading them
+func (te *TradingEngine) importPairSymbols(ctx context.Context) (map[string]string, error) {
+	res, err := te.krakenPublic(ctx, "/0/public/AssetPairs", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected kraken AssetPairs response")
+	}
+	wanted := make(map[string]string, len(symbols))
+	for _, sym := range symbols {
+		if pair := te.krakenPair(sym); pair != "" {
+			wanted[pair] = sym
+		}
+	}
+	out := make(map[string]string)
+	for name, v := range result {
+		raw, _ := v.(map[string]interface{})
+		alt, _ := raw["altname"].(string)
+		sym, ok := wanted[name]
+		if !ok {
+			sym, ok = wanted[alt]
+		}
+		if !ok {
+			continue
+		}
+		out[name] = sym
+		if alt != "" {
+			out[alt] = sym
+		}
+	}
+	for pair, sym := range wanted {
+		out[pair] = sym
+	}
+	return out, nil
+}
+
+// buildRoundTrips pairs a symbol's fills (oldest first) into flat-to-flat
+// round trips. A sell larger than the open position only closes what is open;
+// sells with nothing open and a position still open at the end are counted
+// but not imported.
+func buildRoundTrips(symbol string, fills []KrakenFill) (trips []*MacroStrike, open, unpairedSells int) {
+	var (
+		boughtVol, boughtCost, soldVol, soldValue, fees float64
+		first, last                                     KrakenFill
+		entryTx, exitTx                                 string
+		tradeIDs                                        []string
+	)
+	reset := func() {
+		boughtVol, boughtCost, soldVol, soldValue, fees = 0, 0, 0, 0, 0
+		entryTx, exitTx, tradeIDs = "", "", nil
+	}
+	for _, f := range fills {
+		position := boughtVol - soldVol
+		switch f.Side {
+		case "buy":
+			if boughtVol == 0 {
+				first, entryTx = f, f.OrderTx
+			}
+			boughtVol += f.Volume
+			boughtCost += f.Cost
+			fees += f.Fee
+			tradeIDs = append(tradeIDs, f.TxID)
+		case "sell":
+			if position <= 0 {
+				unpairedSells++
+				continue
+			}
+			used := math.Min(f.Volume, position)
+			share := used / f.Volume
+			soldVol += used
+			soldValue += f.Cost * share
+			fees += f.Fee * share
+			last, exitTx = f, f.OrderTx
+			tradeIDs = append(tradeIDs, f.TxID)
+		default:
+			continue
+		}
+		if boughtVol > 0 && boughtVol-soldVol <= boughtVol*1e-9 {
+			trips = append(trips, importedStrike(symbol, first, last, boughtVol, boughtCost, soldVol, soldValue, fees, entryTx, exitTx, tradeIDs))
+			reset()
+		}
+	}
+	if boughtVol > 0 {
+		open = 1
+	}
+	return trips, open, unpairedSells
+}
+
+// importedStrike records one reconstructed round trip as a completed strike
+func importedStrike(symbol string, first, last KrakenFill, boughtVol, boughtCost, soldVol, soldValue, fees float64, entryTx, exitTx string, tradeIDs []string) *MacroStrike {
+	entry := boughtCost / boughtVol
+	exit := soldValue / soldVol
+	pnl := soldValue - entry*soldVol
+	opened, closed := fillTime(first), fillTime(last)
+	closedUnix := closed.Unix()
+	s := &MacroStrike{
+		ID:          importStrikeID(first.TxID),
+		Symbol:      symbol,
+		StrikeType:  ImportedTrade,
+		EntryPrice:  entry,
+		StrikeForce: boughtCost,
+		Timestamp:   opened.Unix(),
+		HitTime:     &closedUnix,
+		ExitPrice:   &exit,
+		PnL:         &pnl,
+		Leverage:    1,
+		Fees:        fees,
+		ExitReason:  ExitImported,
+		DurationMs:  closed.Sub(opened).Milliseconds(),
+		TradeIDs:    tradeIDs,
+		Status:      Miss,
+	}
+	if pnl > 0 {
+		s.Status = Hit
+	}
+	if entryTx != "" {
+		s.EntryTxID = &entryTx
+	}
+	if exitTx != "" {
+		s.ExitTxID = &exitTx
+	}
+	s.Transitions = []StateTransition{
+		{To: Striking.String(), At: opened, Price: entry, Reason: "imported"},
+		{From: Striking.String(), To: s.Status.String(), At: closed, Price: exit, Reason: ExitImported},
+	}
+	return s
+}
+
+// fillTime converts Kraken's fractional-second timestamp
+func fillTime(f KrakenFill) time.Time {
+	sec, frac := math.Modf(f.Time)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// importStrikeID derives a stable journal ID from a round trip's first fill
+func importStrikeID(txid string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(txid))
+	// Journals store IDs as signed 64-bit integers
+	return h.Sum64() &^ (1 << 63)
+}
+
+// loadImportCheckpoint reads saved paging progress; ok is false when none exists
+func loadImportCheckpoint(path string) (importCheckpoint, bool, error) {
+	var cp importCheckpoint
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, false, nil
+	}
+	if err != nil {
+		return cp, false, err
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, false, fmt.Errorf("import checkpoint %s: %v", path, err)
+	}
+	if cp.Version != importCheckpointVersion {
+		return cp, false, fmt.Errorf("import checkpoint %s: unsupported version %d", path, cp.Version)
+	}
+	if cp.Fills == nil {
+		cp.Fills = make(map[string]KrakenFill)
+	}
+	return cp, true, nil
+}
+
+// saveImportCheckpoint writes paging progress atomically
+func saveImportCheckpoint(path string, cp importCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Two ETH buys closed by one sell, a BTC sell with nothing open and an XRP
+// fill on a pair the engine doesn't trade, over two TradesHistory pages
+var (
+	tradesPage1 = krakenReply("/0/private/TradesHistory", `{"count":5,"trades":{
+		"TSELL-ETH":{"ordertxid":"OSELL","pair":"XETHZUSD","time":1736100300.5,"type":"sell","price":"2200","cost":"4400","fee":"8.8","vol":"2"},
+		"TSELL-BTC":{"ordertxid":"OBTC","pair":"XXBTZUSD","time":1736100150,"type":"sell","price":"90000","cost":"900","fee":"1.8","vol":"0.01"}}}`)
+	tradesPage2 = krakenReply("/0/private/TradesHistory", `{"count":5,"trades":{
+		"TBUY-ETH-2":{"ordertxid":"OBUY2","pair":"XETHZUSD","time":1736100200,"type":"buy","price":"2100","cost":"2100","fee":"4.2","vol":"1"},
+		"TBUY-ETH-1":{"ordertxid":"OBUY1","pair":"XETHZUSD","time":1736100100,"type":"buy","price":"2000","cost":"2000","fee":"4","vol":"1"},
+		"TXRP":{"ordertxid":"OXRP","pair":"XXRPZUSD","time":1736100000,"type":"buy","price":"2","cost":"20","fee":"0.04","vol":"10"}}}`)
+	importAssetPairs = krakenReply("/0/public/AssetPairs", `{
+		"XETHZUSD":{"altname":"ETHUSD"},"XXBTZUSD":{"altname":"XBTUSD"},"XXRPZUSD":{"altname":"XRPUSD"}}`)
+)
+
+// importEngine replays an import into a journal and performance store under dir
+func importEngine(t *testing.T, dir string, records ...krakenExchangeRecord) *TradingEngine {
+	t.Helper()
+	t.Setenv("JOURNAL_DB", filepath.Join(dir, "journal.db"))
+	t.Setenv("PERF_STORE_FILE", filepath.Join(dir, "perf.json"))
+	te := replayEngine(t, records...)
+	if err := te.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return te
+}
+
+func TestImportTradeHistoryBuildsRoundTripsOnce(t *testing.T) {
+	dir := t.TempDir()
+	opts := ImportOptions{Offset: -1, PageDelay: time.Second}
+	te := importEngine(t, dir, tradesPage1, tradesPage2, importAssetPairs)
+	sum, err := te.ImportTradeHistory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("ImportTradeHistory: %v", err)
+	}
+	want := ImportSummary{Fills: 5, Unmapped: 1, RoundTrips: 1, Imported: 1, UnpairedSells: 1}
+	if sum != want {
+		t.Errorf("summary = %+v, want %+v", sum, want)
+	}
+
+	rows, err := te.journal.QueryStrikes(StrikeQuery{RunID: importRunID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("journal has %d imported rows, want 1", len(rows))
+	}
+	s := rows[0]
+	if s.Symbol != "WETH/USDC" || s.StrikeType != ImportedTrade || s.Status != Hit || s.ExitReason != ExitImported {
+		t.Errorf("imported strike = %s %s %s %s", s.Symbol, s.StrikeType, s.Status, s.ExitReason)
+	}
+	// Bought 2 @ 2050 average, sold 2 @ 2200
+	if s.EntryPrice != 2050 || *s.ExitPrice != 2200 || *s.PnL != 300 || math.Abs(s.Fees-17) > 1e-9 {
+		t.Errorf("entry %.2f exit %.2f pnl %.2f fees %.2f, want 2050/2200/300/17", s.EntryPrice, *s.ExitPrice, *s.PnL, s.Fees)
+	}
+	if s.ID != importStrikeID("TBUY-ETH-1") || len(s.TradeIDs) != 3 || *s.EntryTxID != "OBUY1" || *s.ExitTxID != "OSELL" {
+		t.Errorf("ids: id=%d trades=%v entry=%v exit=%v", s.ID, s.TradeIDs, *s.EntryTxID, *s.ExitTxID)
+	}
+	if s.DurationMs != 200_500 {
+		t.Errorf("duration = %dms, want 200500", s.DurationMs)
+	}
+	te.Close()
+
+	// Re-running the same import changes nothing
+	te = importEngine(t, dir, tradesPage1, tradesPage2, importAssetPairs)
+	defer te.Close()
+	if sum, err = te.ImportTradeHistory(context.Background(), opts); err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if sum.Imported != 0 || sum.AlreadyImported != 1 {
+		t.Errorf("second import: %+v, want nothing new", sum)
+	}
+	if rows, _ := te.journal.QueryStrikes(StrikeQuery{RunID: importRunID}); len(rows) != 1 {
+		t.Errorf("journal has %d imported rows after a rerun, want 1", len(rows))
+	}
+	// One trade, decayed a little between its close and the engine clock
+	if trades := te.perfStore.Symbol("WETH/USDC", te.Clock.Now()).Trades; trades < 0.9 || trades > 1 {
+		t.Errorf("performance store counts %.3f WETH/USDC trades, want just under 1", trades)
+	}
+}
+
+func TestImportTradeHistoryResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	opts := ImportOptions{Offset: -1, CheckpointPath: filepath.Join(dir, "import.json")}
+
+	// The second page is unavailable: the first page's progress is kept
+	te := importEngine(t, dir, tradesPage1)
+	if _, err := te.ImportTradeHistory(context.Background(), opts); err == nil {
+		t.Fatal("import with a missing page should fail")
+	}
+	te.Close()
+	cp, ok, err := loadImportCheckpoint(opts.CheckpointPath)
+	if err != nil || !ok || cp.Offset != 2 || len(cp.Fills) != 2 {
+		t.Fatalf("checkpoint = %+v (ok=%v err=%v), want offset 2 with 2 fills", cp, ok, err)
+	}
+
+	te = importEngine(t, dir, tradesPage2, importAssetPairs)
+	defer te.Close()
+	sum, err := te.ImportTradeHistory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("resumed import: %v", err)
+	}
+	if sum.Fills != 5 || sum.Imported != 1 {
+		t.Errorf("resumed import = %+v, want all 5 fills and 1 round trip", sum)
+	}
+	if _, ok, _ := loadImportCheckpoint(opts.CheckpointPath); ok {
+		t.Error("checkpoint should be removed after a completed import")
+	}
+}
+
+func TestBuildRoundTripsClosesOnlyOpenVolume(t *testing.T) {
+	fills := []KrakenFill{
+		{TxID: "B1", Side: "buy", Time: 10, Price: 100, Cost: 100, Volume: 1},
+		// Sells 2 with only 1 open: half the proceeds and fee belong to the trip
+		{TxID: "S1", Side: "sell", Time: 20, Price: 110, Cost: 220, Fee: 2, Volume: 2},
+		{TxID: "B2", Side: "buy", Time: 30, Price: 100, Cost: 50, Volume: 0.5},
+	}
+	trips, open, unpaired := buildRoundTrips("WETH/USDC", fills)
+	if len(trips) != 1 || open != 1 || unpaired != 0 {
+		t.Fatalf("trips=%d open=%d unpaired=%d, want 1/1/0", len(trips), open, unpaired)
+	}
+	if s := trips[0]; *s.PnL != 10 || s.Fees != 1 || s.Status != Hit {
+		t.Errorf("pnl %.2f fees %.2f status %s, want 10, 1, hit", *s.PnL, s.Fees, s.Status)
+	}
+}
@@ -0,0 +1,3067 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/exchange"
+	"macro-strike-bot/exchange/kraken"
+	"macro-strike-bot/report"
+	"macro-strike-bot/sim"
+)
+
+// StrikeType represents different types of macro strikes
+type StrikeType int
+
+const (
+	MacroArbitrage StrikeType = iota
+	MacroMomentum
+	MacroVolatility
+	MacroLiquidity
+	MacroFunding
+	MacroFlash
+	numStrikeTypes = iota
+)
+
+// ImportedTrade tags round trips imported from Kraken trade history; it is
+// never generated
+const ImportedTrade StrikeType = numStrikeTypes
+
+// String returns the name of a strike type as used in config, logs and sinks
+func (t StrikeType) String() string {
+	switch t {
+	case MacroArbitrage:
+		return "MacroArbitrage"
+	case MacroMomentum:
+		return "MacroMomentum"
+	case MacroVolatility:
+		return "MacroVolatility"
+	case MacroLiquidity:
+		return "MacroLiquidity"
+	case MacroFunding:
+		return "MacroFunding"
+	case MacroFlash:
+		return "MacroFlash"
+	case ImportedTrade:
+		return "imported"
+	default:
+		return "unknown"
+	}
+}
+
+// StrikeStatus represents the status of a strike
+type StrikeStatus int
+
+const (
+	Targeting StrikeStatus = iota
+	Striking
+	Hit
+	Miss
+	Aborted
+)
+
+// String returns the lowercase name of a strike status
+func (s StrikeStatus) String() string {
+	switch s {
+	case Targeting:
+		return "targeting"
+	case Striking:
+		return "striking"
+	case Hit:
+		return "hit"
+	case Miss:
+		return "miss"
+	case Aborted:
+		return "aborted"
+	default:
+		return "unknown"
+	}
+}
+
+// MarketAnalysis represents comprehensive market analysis data
+type MarketAnalysis struct {
+	Symbol         string  `json:"symbol"`
+	StrikeType     string  `json:"strike_type"`
+	Price          float64 `json:"price"`
+	Confidence     float64 `json:"confidence"`
+	ExpectedReturn float64 `json:"expected_return"`
+	Volatility     float64 `json:"volatility"`
+	Momentum       float64 `json:"momentum"`
+	Liquidity      float64 `json:"liquidity"`
+	PrecisionScore float64 `json:"precision_score"`
+	Recommendation string  `json:"recommendation"`
+	Timestamp      int64   `json:"timestamp"`
+
+	// Optional levels from the model; used when sane, otherwise formulas apply
+	SuggestedStop   *float64 `json:"suggested_stop,omitempty"`
+	SuggestedTarget *float64 `json:"suggested_target,omitempty"`
+}
+
+// AnalysisSnapshot is the analyzer output a strike was built from, kept so
+// wins and losses can be traced back to their rationale
+type AnalysisSnapshot struct {
+	Confidence     float64 `json:"confidence"`
+	Volatility     float64 `json:"volatility"`
+	Momentum       float64 `json:"momentum"`
+	Liquidity      float64 `json:"liquidity"`
+	PrecisionScore float64 `json:"precision_score"`
+	Recommendation string  `json:"recommendation"`
+	Timestamp      int64   `json:"timestamp"`
+}
+
+// snapshot keeps the fields of an analysis that explain a strike
+func (a *MarketAnalysis) snapshot() *AnalysisSnapshot {
+	return &AnalysisSnapshot{
+		Confidence:     a.Confidence,
+		Volatility:     a.Volatility,
+		Momentum:       a.Momentum,
+		Liquidity:      a.Liquidity,
+		PrecisionScore: a.PrecisionScore,
+		Recommendation: a.Recommendation,
+		Timestamp:      a.Timestamp,
+	}
+}
+
+// Level sources recorded on each strike
+const (
+	LevelSourceFormula = "formula"
+	LevelSourceAnalyst = "analyst"
+)
+
+// MacroStrike represents a trading strike
+type MacroStrike struct {
+	ID                uint64      `json:"id"`
+	Symbol            string      `json:"symbol"`
+	StrikeType        StrikeType  `json:"strike_type"`
+	Direction         Direction   `json:"direction"`
+	EntryPrice        float64     `json:"entry_price"`
+	TargetPrice       float64     `json:"target_price"`
+	StopLoss          float64     `json:"stop_loss"`
+	Confidence        float64     `json:"confidence"`
+	ExpectedReturn    float64     `json:"expected_return"`
+	MaxExposureTimeMs uint64      `json:"max_exposure_time_ms"`
+	StrikeForce       float64     `json:"strike_force"`
+	Timestamp         int64       `json:"timestamp"`
+	Status            StrikeStatus `json:"status"`
+	HitTime           *int64      `json:"hit_time,omitempty"`
+	ExitPrice         *float64    `json:"exit_price,omitempty"`
+	PnL               *float64    `json:"pnl,omitempty"`
+	Leverage          uint32      `json:"leverage"`
+	ConfidenceThreshold float64   `json:"confidence_threshold"`
+	LevelSource       string      `json:"level_source"`
+	LiquidityFactor   float64     `json:"liquidity_factor"`
+	MomentumFactor    float64     `json:"momentum_factor"`
+	PerformanceFactor float64     `json:"performance_factor"`
+	RiskReward        float64     `json:"risk_reward"`
+	Fees              float64     `json:"fees"`
+	// Simulated funding on the levered notional over the hold, in USD
+	Funding           float64     `json:"funding"`
+	Slippage          float64     `json:"slippage"`
+	ExitReason        string      `json:"exit_reason,omitempty"`
+	DurationMs        int64       `json:"duration_ms"`
+
+	// Take-profit ladder and the partial exits it produced; empty for a single exit
+	TakeProfitLadder []TakeProfitRung `json:"take_profit_ladder,omitempty"`
+	Exits            []StrikeExit     `json:"exits,omitempty"`
+
+	// Analyzer output behind the strike; nil for simulated strikes
+	Analysis *AnalysisSnapshot `json:"analysis,omitempty"`
+
+	// How long each pipeline stage took; nil until the first stage is timed
+	Timings *StrikeTimings `json:"timings,omitempty"`
+
+	// traceCtx carries the strike's root trace span once one is started
+	traceCtx context.Context
+
+	// Exchange identifiers and redacted raw order traffic; live only, null in sim
+	EntryTxID     *string        `json:"entry_txid"`
+	ExitTxID      *string        `json:"exit_txid"`
+	TradeIDs      []string       `json:"trade_ids"`
+	OrderPayloads []OrderPayload `json:"order_payloads"`
+
+	// Lifecycle timeline: Targeting → Striking → Hit/Miss/Aborted
+	Transitions []StateTransition `json:"transitions"`
+}
+
+// Exit reasons recorded on completed strikes
+const (
+	ExitTakeProfit   = "take_profit"
+	ExitStopLoss     = "stop_loss"
+	ExitHoldExpired  = "hold_expired"
+	// A live position still open at MaxExposureTimeMs, exited at market
+	ExitTimeStop     = "time_stop"
+	ExitImported     = "imported"
+	ExitThinFill     = "thin_fill"
+)
+
+// TradingEngine handles the core trading logic
+type TradingEngine struct {
+	Capital            int64
+	TargetCapital      int64
+	PeakCapital        int64
+	drawdown           DrawdownState
+	drawdownMu         sync.Mutex
+	NextStrikeID       uint64
+	ConsecutiveMisses  int64
+	MaxConsecutiveMisses int64
+	TotalStrikes       int64
+	SuccessfulStrikes  int64
+	FailedStrikes      int64
+	TotalPnL           int64
+	// Fees paid in cents: exchange-reported where available, modeled otherwise
+	TotalFeesPaid      int64
+	TradesCompleted    int64
+	AbortedStrikes     int64
+	blownUp            int32
+	// Held for writing while the counters above change, so Snapshot reads
+	// them as one consistent set; single reads may still use atomics
+	countersMu         sync.RWMutex
+
+	// Live trading config
+	LiveTrading        bool
+	// Currency the account is funded in (ACCOUNT_QUOTE_CURRENCY); pairs, fills
+	// and the lot ledger are in it while capital and PnL stay in USD
+	QuoteCurrency      string
+	// Simulated strikes in place of the Julia analyzer (SIM_MODE)
+	SimMode            bool
+	// Missing-analyzer policy, abort or sim (JULIA_MISSING)
+	JuliaMissing       string
+	KrakenAPIKey       string
+	KrakenAPISecret    string
+	// API root, overridable (KRAKEN_API_URL) to point at a test double
+	KrakenBaseURL      string
+	OrderUSDSize       float64
+	PairOverrides      map[string]string
+	// Venue live orders are routed to (EXCHANGE); nil means Kraken
+	Exchange           exchange.Exchange
+
+	// Live entry order type; limit entries chase the book up to LimitMaxChases times
+	LiveEntryOrder     string
+	// What the process does once a campaign ends (POST_CAMPAIGN)
+	PostCampaign       PostCampaignAction
+	LimitMaxChases     int
+	LimitChaseWait     time.Duration
+	// Live entries filling under this fraction of the requested volume are flattened and aborted (0 accepts any fill)
+	MinFillRatio       float64
+
+	// Minimum simulated time in trade, scaled per strike type (0 resolves instantly)
+	SimMinHoldMs       int64
+	// Calibration from stated confidence to simulated hit rate (SIM_HIT_MODEL)
+	SimHitModel        sim.HitModel
+	// Seed for the per-strike random streams (RAND_SEED, else the start time)
+	RandSeed           int64
+	// Default take-profit ladder for strikes that don't carry one (TP_LADDER)
+	TakeProfitLadder   []TakeProfitRung
+
+	// Live order-status polling: how often to query and how long to wait for a fill
+	FillPollIntervalMs int64
+	FillTimeoutMs      int64
+
+	// Risk & campaign
+	OrderRiskPct       float64
+	CampaignStart      time.Time
+	CampaignDays       int
+	MaxDrawdownPct     float64
+	// Realized loss for one UTC day, percent of that day's opening capital, that
+	// pauses trading until the next day (or ends the campaign); 0 disables
+	MaxDailyLossPct       float64
+	DailyLossEndsCampaign bool
+	dailyLossPauseUntil   time.Time
+	// Ignore TotalTrades and run until a stop condition ends the campaign
+	InfiniteTrades     bool
+	// Absolute floor in cents; below it no new strikes are generated
+	MinTradingCapital  int64
+	// Minimum (target-entry)/(entry-stop); 0 disables the check
+	MinRiskReward      float64
+	// Net formulaic targets of round-trip fees plus TargetSlippagePct
+	TargetCostHaircut  bool
+	TargetSlippagePct  float64
+	// Tradeable band for the analysis volatility; 0 disables either bound
+	MinVolatility      float64
+	MaxVolatility      float64
+	// Cap on levered notional open across strikes, in USD; 0 disables
+	MaxNotionalUSD     float64
+	notionalMu         sync.Mutex
+	openNotional       map[uint64]float64
+	// API retries allowed per rolling hour; nil when RETRY_BUDGET_PER_HOUR=0
+	retryBudget        *RetryBudget
+	// Strikes allowed open at once on one symbol; 0 disables
+	MaxPositionsPerSymbol int
+	openSymbols        map[uint64]string
+	// Live positions allowed open at once; above 1 exits run on the
+	// position manager instead of blocking the campaign loop
+	MaxOpenPositions   int
+	positions          *PositionManager
+
+	// Confidence gate: global default with per-symbol overrides
+	ConfidenceThreshold        float64
+	SymbolConfidenceThresholds map[string]float64
+	config                     config.Config
+	configErrors               []error
+
+	// Relative sampling weights per strike type; empty means round-robin
+	StrikeTypeWeights map[StrikeType]float64
+	// Directions each strike type may trade (DIRECTION_BY_TYPE); unlisted
+	// types take the direction their levels imply
+	DirectionByType   map[StrikeType]DirectionPolicy
+
+	// Sizing adjustments from analysis liquidity and momentum scores (0-1, 0.5 neutral)
+	LiquidityWeight    float64
+	LiquidityFactorMin float64
+	LiquidityFactorMax float64
+	MomentumWeight     float64
+	MomentumFactorMin  float64
+	MomentumFactorMax  float64
+	// How much the analysis precision score discounts confidence (0-1)
+	PrecisionWeight    float64
+
+	// Stablecoin pairs trade on basis-point levels and skip directional strike types
+	StablecoinSymbols   map[string]bool
+	StablecoinTargetPct float64
+	StablecoinStopPct   float64
+	// Simulated hourly funding on levered notional, as fractions: a default
+	// and per-symbol overrides
+	FundingRatePerHour  float64
+	SymbolFundingRates  map[string]float64
+
+	// Bounds on analyst-supplied stop/target distance from entry (fractions)
+	MaxSuggestedStopPct   float64
+	MaxSuggestedTargetPct float64
+	// Skip or stop on analysis carrying NaN or Inf (NONFINITE_ANALYSIS)
+	NonFiniteAnalysis     string
+	levelStatsMu          sync.Mutex
+	levelStats            map[string]*LevelSourceStats
+
+	// Diagnostics
+	DebugLogging       bool
+	// Log every skipped setup and a per-reason tally at campaign end
+	SkipLog            bool
+	krakenLatency      *LatencyTracker
+	stageLatency       *StageLatencyTracker
+	// OTLP span exporter; nil leaves tracing off
+	tracer             *Tracer
+	// Masks credentials in logs, reports, alerts and status responses
+	redactor           *config.Redactor
+	// Logs strike results and the campaign summary off the event bus
+	eventLog           *EventLogger
+	// Recent strike results and stops served at /events?format=json; nil when EVENT_HISTORY_SIZE=0
+	recentEvents       *EventRing
+	// Shared pooled client for Kraken requests; HTTPWarmup pre-connects it before live trading
+	HTTPClient         *http.Client
+	HTTPWarmup         bool
+
+	// Analysis price sanity check against the live ticker
+	PriceDeviationTolerance float64
+	SimPriceCheck           bool
+	tickerMu                sync.Mutex
+	tickerCache             map[string]tickerQuote
+
+	// Volatility-scaled stops: StopLoss = entry - ATRStopMultiple×ATR (0 disables)
+	ATRStopMultiple    float64
+	ATRPeriod          int
+	ATRIntervalMin     int
+	candleMu           sync.Mutex
+	candleCache        map[string]candleCacheEntry
+	pairInfoMu         sync.Mutex
+	pairInfoCache      map[string]pairInfo
+
+	// Skipped setups by reason
+	skipMu             sync.Mutex
+	skipCounts         map[string]int64
+
+	// Symbols sit out SymbolLossCooldownMs after a miss (0 disables)
+	SymbolLossCooldownMs int64
+	symbolLossMu         sync.Mutex
+	symbolLastLoss       map[string]time.Time
+
+	// Raise live orders under the pair minimum to it instead of skipping
+	AutoBumpMin        bool
+
+	// Capture/replay of Kraken traffic (public and private) for offline debugging
+	RecordMode         bool
+	ReplayMode         bool
+	krakenRecorder     *krakenRecorder
+	krakenReplayer     *krakenReplayer
+	payloadMu          sync.Mutex
+	orderPayloads      map[string][]OrderPayload
+
+	// Run identity and optional SQLite trade journal; runs counts the
+	// campaigns this engine has started, for their IDs
+	RunID              string
+	runs               uint64
+	journal            Journal
+	StrikeLog          StrikeLogger
+	csvExport          *CSVExporter
+	parquetExport      *ParquetExporter
+	campaignStats      *report.CampaignStats
+	statsRestored      bool
+	pnlRollups         *report.PnLRollups
+	metrics            *EngineMetrics
+	// Strike lifecycle events streamed at /events
+	events             *EventBus
+	// Recent win rate, weighted by WinRateAlpha (WIN_RATE_EMA_ALPHA)
+	winRate            *winRateEMA
+	WinRateAlpha       float64
+	lotLedger          *LotLedger
+	RealizedGainsPath  string
+	ReportJSONPath     string
+	ReportHTMLPath     string
+
+	// Every executed strike (completed or aborted), dumped to StrikesJSONPath at
+	// campaign end; strikesMu also guards the in-flight strike served at /status
+	Strikes            []*MacroStrike
+	strikesMu          sync.Mutex
+	openStrike         *OpenStrikeStatus
+	statusServer       *http.Server
+	StrikesJSONPath    string
+
+	// Local artifact paths and the optional S3 uploader that ships them off-box
+	JournalPath        string
+	StrikeLogPath      string
+	CSVExportPath      string
+	ParquetExportPath  string
+	ParquetEquityPath  string
+	artifacts          *S3Uploader
+	// Webhook alerts (ALERT_WEBHOOK_URL); nil when disabled. AlertLossUSD and
+	// AlertDrawdownLevels set the per-strike alert thresholds.
+	alerts               *AlertNotifier
+	// Campaign-end summary by email (SMTP_HOST); nil when disabled
+	email                *EmailNotifier
+	AlertLossUSD         float64
+	AlertDrawdownLevels  []float64
+	alertDrawdownCrossed int64
+
+	// Periodic state snapshots for resuming an interrupted campaign. StartCapital
+	// (cents) is the capital the campaign first started with; it and the
+	// per-trade return tracker survive a resume so results keep one basis.
+	StateFile          string
+	StateSnapshotEvery int64
+	// Trades between progress log lines; 0 turns them off
+	ProgressLogEvery   int64
+	StartCapital       int64
+	tracker            *campaignTracker
+	resumedReturns     *trackerState
+
+	// Decayed per-symbol/strike-type history persisted across runs; weak
+	// performers get a size haircut or are excluded until history decays
+	perfStore          *PerformanceStore
+	PerfStoreFile      string
+	PerfMinTrades      float64
+	PerfWinRateFloor   float64
+	PerfHaircut        float64
+	PerfExcludeWinRate float64
+	Resumed            bool
+
+	// Time source for strike execution and campaign pacing
+	Clock              clock.Clock
+
+	// Closed by Stop to wind the campaign down; ShutdownGrace is how long a
+	// signalled shutdown waits for the in-flight strike before flattening
+	stopCh             chan struct{}
+	stopOnce           sync.Once
+	ShutdownGrace      time.Duration
+
+	// Remote kill switch: POST /kill, authorized by ControlToken, stops the
+	// campaign and waits up to KillTimeout for campaignDone (closed once the
+	// campaign has flattened and written its reports)
+	killed             int32
+	ControlToken       string
+	KillTimeout        time.Duration
+	campaignDone       chan struct{}
+	campaignDoneOnce   sync.Once
+
+	// Set by Pause/Resume; while paused no new strikes are generated. With
+	// PauseExtendsWindow (PAUSE_EXTENDS_WINDOW=1) paused time doesn't count
+	// against CampaignDays.
+	pauseMu            sync.Mutex
+	paused             bool
+	pausedSince        time.Time
+	pausedTotal        time.Duration
+	PauseExtendsWindow bool
+
+	// Probes: the loop's last heartbeat (unix nanos) backs /healthz, which
+	// fails after HealthStaleAfter; /readyz results are cached for ReadyCacheTTL
+	loopHeartbeat      int64
+	HealthStaleAfter   time.Duration
+	ReadyCacheTTL      time.Duration
+	readiness          readinessCache
+
+	// Stall watchdog: with WatchdogStall set, a loop and in-flight order that
+	// both go quiet that long get a stall report and alert; WatchdogAbort
+	// also abandons the strike
+	WatchdogStall      time.Duration
+	WatchdogAbort      bool
+	watchdog           watchdogState
+
+	// Source of strikes for the campaign loop
+	Generator          StrikeGenerator
+
+	// Live exposure not yet confirmed flat, keyed by strike ID
+	positionsMu        sync.Mutex
+	openPositions      map[uint64]*openPosition
+	orderWAL           *OrderWAL
+	walPending         []walEntry
+}
+
+// openPosition tracks a live fill whose exit has not been confirmed
+type openPosition struct {
+	StrikeID uint64
+	Pair     string
+	Volume   float64
+	EntryTx  string
+	ExitTx   string
+}
+
+// Constants
+const (
+	TotalTrades           = 2500
+	InitialCapital        = 10000000  // $100k in cents
+	TargetCapital         = 11850000  // $118.5k in cents (18.5% in window)
+	StrikeForce           = 0.15      // 15% of capital per strike
+	PrecisionThreshold    = 0.85      // 85% confidence required
+	DefaultConfidenceGate = 0.80      // Precision-adjusted confidence required to strike
+	MaxExposureTimeMs     = 30000     // 30 seconds max exposure
+	StrikeCooldownMs      = 1         // 1ms cooldown
+	MaxConsecutiveMisses  = 20        // Max consecutive misses before emergency stop
+)
+
+// Leverage policy (applies in simulation/PNL model). Live spot orders are unlevered but log intended leverage.
+const (
+    MinLeverage = 3
+    MaxLeverage = 5
+)
+
+// Simulation PnL parameters
+const (
+    RoundTripFeePct = 0.0016 // 0.16% total fees
+    SimTakeProfitPct = 0.003 // 0.30% TP
+    SimStopLossPct   = 0.0025 // 0.25% SL
+)
+
+var symbols = []string{
+	"WETH/USDC", "WBTC/USDC", "LINK/USDC", "UNI/USDC",
+	"AAVE/USDC", "CRV/USDC", "USDC/USDT", "DAI/USDC",
+}
+
+var basePrices = []float64{
+	3000.0, 45000.0, 15.50, 8.50, 120.0, 0.85, 1.00, 1.00,
+}
+
+// NewTradingEngine creates a new trading engine configured from the environment
+func NewTradingEngine() *TradingEngine {
+	return NewTradingEngineFromConfig(config.EnvConfig())
+}
+
+// NewTradingEngineFromConfig creates a new trading engine from explicit
+// settings, scheduled on the real clock
+func NewTradingEngineFromConfig(cfg config.Config) *TradingEngine {
+	return New(cfg, clock.Real{})
+}
+
+// New creates a trading engine from explicit settings and the clock it
+// schedules on; it never reads the environment itself
+func New(cfg config.Config, clk clock.Clock) *TradingEngine {
+	live := cfg.Get("LIVE_TRADING") == "1"
+	orderSize := 25.0
+	if v := cfg.Get("ORDER_USD_SIZE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			orderSize = f
+		}
+	}
+	orderRisk := 0.01
+	if v := cfg.Get("ORDER_RISK_PCT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			orderRisk = f / 100.0
+		}
+	}
+	campaignDays := 5
+	if v := cfg.Get("CAMPAIGN_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			campaignDays = n
+		}
+	}
+	maxDD := 10.0
+	if v := cfg.Get("MAX_DRAWDOWN_PCT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			maxDD = f
+		}
+	}
+	var configErrors []error
+	confGate := DefaultConfidenceGate
+	if v := cfg.Get("CONFIDENCE_THRESHOLD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			confGate = f
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("CONFIDENCE_THRESHOLD: %v", err))
+		}
+	}
+	symbolGates := make(map[string]float64)
+	if v := cfg.Get("SYMBOL_CONFIDENCE_THRESHOLDS"); v != "" {
+		pairs, err := config.ParseKeyValueList(v)
+		if err != nil {
+			configErrors = append(configErrors, fmt.Errorf("SYMBOL_CONFIDENCE_THRESHOLDS: %v", err))
+		}
+		for sym, raw := range pairs {
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				configErrors = append(configErrors, fmt.Errorf("SYMBOL_CONFIDENCE_THRESHOLDS: %s: %v", sym, err))
+				continue
+			}
+			symbolGates[sym] = f
+		}
+	}
+	fundingRates, err := parseFundingRates(cfg.Get("SYMBOL_FUNDING_RATES"))
+	if err != nil {
+		configErrors = append(configErrors, fmt.Errorf("SYMBOL_FUNDING_RATES: %v", err))
+	}
+	maxSuggestedStop := 0.10
+	if v := cfg.Get("MAX_SUGGESTED_STOP_PCT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			maxSuggestedStop = f / 100.0
+		}
+	}
+	maxSuggestedTarget := 0.20
+	if v := cfg.Get("MAX_SUGGESTED_TARGET_PCT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			maxSuggestedTarget = f / 100.0
+		}
+	}
+	typeWeights := make(map[StrikeType]float64)
+	if v := cfg.Get("STRIKE_TYPE_WEIGHTS"); v != "" {
+		w, err := parseStrikeTypeWeights(v)
+		if err != nil {
+			configErrors = append(configErrors, fmt.Errorf("STRIKE_TYPE_WEIGHTS: %v", err))
+		}
+		typeWeights = w
+	}
+	directionByType := make(map[StrikeType]DirectionPolicy)
+	if v := cfg.Get("DIRECTION_BY_TYPE"); v != "" {
+		d, err := parseDirectionByType(v)
+		if err != nil {
+			configErrors = append(configErrors, fmt.Errorf("DIRECTION_BY_TYPE: %v", err))
+		} else {
+			directionByType = d
+		}
+	}
+	atrPeriod := 14
+	if v := cfg.Get("ATR_PERIOD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			atrPeriod = n
+		}
+	}
+	atrInterval := 5
+	if v := cfg.Get("ATR_INTERVAL_MIN"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			atrInterval = n
+		}
+	}
+	var simMinHold int64
+	if v := cfg.Get("SIM_MIN_HOLD_MS"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			simMinHold = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("SIM_MIN_HOLD_MS: %q is not a non-negative integer", v))
+		}
+	}
+	randSeed := clk.Now().UnixNano()
+	if v := cfg.Get("RAND_SEED"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			randSeed = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("RAND_SEED: %q is not a non-negative integer", v))
+		}
+	}
+	var symbolLossCooldown int64
+	if v := cfg.Get("SYMBOL_LOSS_COOLDOWN_MS"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			symbolLossCooldown = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("SYMBOL_LOSS_COOLDOWN_MS: %q is not a non-negative integer", v))
+		}
+	}
+	fillPoll, fillTimeout := int64(2000), int64(30000)
+	for _, f := range []struct {
+		name string
+		dst  *int64
+	}{{"FILL_POLL_INTERVAL_MS", &fillPoll}, {"FILL_TIMEOUT_MS", &fillTimeout}} {
+		if v := cfg.Get(f.name); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+				*f.dst = n
+			} else {
+				configErrors = append(configErrors, fmt.Errorf("%s: %q is not a positive integer", f.name, v))
+			}
+		}
+	}
+	if fillPoll > fillTimeout {
+		configErrors = append(configErrors, fmt.Errorf("FILL_POLL_INTERVAL_MS (%d) exceeds FILL_TIMEOUT_MS (%d)", fillPoll, fillTimeout))
+	}
+	pairOverrides := make(map[string]string)
+	if v := cfg.Get("KRAKEN_PAIR_OVERRIDES"); v != "" {
+		overrides, err := config.ParseKeyValueList(v)
+		if err != nil {
+			configErrors = append(configErrors, fmt.Errorf("KRAKEN_PAIR_OVERRIDES: %v", err))
+		}
+		for sym, pair := range overrides {
+			if !isKnownSymbol(sym) {
+				configErrors = append(configErrors, fmt.Errorf("KRAKEN_PAIR_OVERRIDES: unknown symbol %q", sym))
+				continue
+			}
+			if pair == "" {
+				configErrors = append(configErrors, fmt.Errorf("KRAKEN_PAIR_OVERRIDES: empty pair for %s", sym))
+				continue
+			}
+			pairOverrides[sym] = pair
+		}
+	}
+	stablecoins := map[string]bool{"USDC/USDT": true, "DAI/USDC": true}
+	if v, ok := cfg.Lookup("STABLECOIN_SYMBOLS"); ok {
+		stablecoins = make(map[string]bool)
+		for _, sym := range strings.Split(v, ",") {
+			if sym = strings.TrimSpace(sym); sym != "" {
+				stablecoins[sym] = true
+			}
+		}
+	}
+	nonFinite := NonFiniteSkip
+	if v := cfg.Get("NONFINITE_ANALYSIS"); v != "" {
+		if v == NonFiniteSkip || v == NonFiniteStop {
+			nonFinite = v
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("NONFINITE_ANALYSIS: %q is not skip or stop", v))
+		}
+	}
+	entryOrder := EntryOrderMarket
+	if v := cfg.Get("LIVE_ENTRY_ORDER"); v != "" {
+		if v == EntryOrderMarket || v == EntryOrderLimit {
+			entryOrder = v
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("LIVE_ENTRY_ORDER: %q is not market or limit", v))
+		}
+	}
+	postCampaign, err := parsePostCampaignAction(cfg.Get("POST_CAMPAIGN"))
+	if err != nil {
+		configErrors = append(configErrors, err)
+	}
+	limitChases := 3
+	if v := cfg.Get("LIMIT_MAX_CHASES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limitChases = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("LIMIT_MAX_CHASES: %q is not a non-negative integer", v))
+		}
+	}
+	maxPerSymbol := 1
+	if v := cfg.Get("MAX_POSITIONS_PER_SYMBOL"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxPerSymbol = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("MAX_POSITIONS_PER_SYMBOL: %q is not a non-negative integer", v))
+		}
+	}
+	maxOpen := 1
+	if v := cfg.Get("MAX_OPEN_POSITIONS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			maxOpen = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("MAX_OPEN_POSITIONS: %q is not a positive integer", v))
+		}
+	}
+	quoteCurrency, err := parseQuoteCurrency(cfg.Get("ACCOUNT_QUOTE_CURRENCY"))
+	if err != nil {
+		configErrors = append(configErrors, err)
+	}
+	retryBudget := 0
+	if v := cfg.Get("RETRY_BUDGET_PER_HOUR"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			retryBudget = n
+		} else {
+			configErrors = append(configErrors, fmt.Errorf("RETRY_BUDGET_PER_HOUR: %q is not a non-negative integer", v))
+		}
+	}
+	te := &TradingEngine{
+		Capital:             InitialCapital,
+		TargetCapital:       TargetCapital,
+		PeakCapital:         InitialCapital,
+		NextStrikeID:        1,
+		ConsecutiveMisses:   0,
+		MaxConsecutiveMisses: MaxConsecutiveMisses,
+		LiveTrading:         live,
+		QuoteCurrency:       quoteCurrency,
+		SimMode:             cfg.Get("SIM_MODE") == "1",
+		JuliaMissing:        cfg.Get("JULIA_MISSING"),
+		KrakenAPIKey:        cfg.Get("KRAKEN_API_KEY"),
+		KrakenAPISecret:     cfg.Get("KRAKEN_API_SECRET"),
+		KrakenBaseURL:       cfg.Get("KRAKEN_API_URL"),
+		OrderUSDSize:        orderSize,
+		PairOverrides:       pairOverrides,
+		LiveEntryOrder:      entryOrder,
+		PostCampaign:        postCampaign,
+		LimitMaxChases:      limitChases,
+		LimitChaseWait:      time.Duration(cfg.Float("LIMIT_CHASE_WAIT_MS", 3000, &configErrors)) * time.Millisecond,
+		MinFillRatio:        cfg.Float("MIN_FILL_RATIO", 0, &configErrors),
+		SimMinHoldMs:        simMinHold,
+		RandSeed:            randSeed,
+		FillPollIntervalMs:  fillPoll,
+		FillTimeoutMs:       fillTimeout,
+		OrderRiskPct:        orderRisk,
+		CampaignStart:       clk.Now(),
+		CampaignDays:        campaignDays,
+		InfiniteTrades:      cfg.Get("INFINITE") == "1",
+		MaxDrawdownPct:      maxDD,
+		MaxDailyLossPct:     cfg.Float("MAX_DAILY_LOSS_PCT", 0, &configErrors),
+		DailyLossEndsCampaign: cfg.Get("DAILY_LOSS_ENDS_CAMPAIGN") == "1",
+		MinTradingCapital:   FromDollars(cfg.Float("MIN_TRADING_CAPITAL", 10, &configErrors)).Cents(),
+		MinRiskReward:       cfg.Float("MIN_RISK_REWARD", 0, &configErrors),
+		TargetCostHaircut:   cfg.Get("TARGET_COST_HAIRCUT") == "1",
+		TargetSlippagePct:   cfg.Float("TARGET_SLIPPAGE_BPS", defaultTargetSlippageBps, &configErrors) / 10000.0,
+		MinVolatility:       cfg.Float("MIN_VOLATILITY", 0, &configErrors),
+		MaxVolatility:       cfg.Float("MAX_VOLATILITY", 0, &configErrors),
+		MaxNotionalUSD:      cfg.Float("MAX_NOTIONAL_USD", 0, &configErrors),
+		openNotional:        make(map[uint64]float64),
+		MaxPositionsPerSymbol: maxPerSymbol,
+		MaxOpenPositions:    maxOpen,
+		positions:           NewPositionManager(maxOpen),
+		retryBudget:         NewRetryBudget(retryBudget),
+		openSymbols:         make(map[uint64]string),
+		openPositions:       make(map[uint64]*openPosition),
+		ConfidenceThreshold:        confGate,
+		SymbolConfidenceThresholds: symbolGates,
+		StrikeTypeWeights:          typeWeights,
+		DirectionByType:            directionByType,
+		LiquidityWeight:            cfg.Float("LIQUIDITY_WEIGHT", 0.5, &configErrors),
+		LiquidityFactorMin:         cfg.Float("LIQUIDITY_FACTOR_MIN", 0.25, &configErrors),
+		LiquidityFactorMax:         cfg.Float("LIQUIDITY_FACTOR_MAX", 1.0, &configErrors),
+		MomentumWeight:             cfg.Float("MOMENTUM_WEIGHT", 0.5, &configErrors),
+		PrecisionWeight:            cfg.Float("PRECISION_WEIGHT", 1.0, &configErrors),
+		MomentumFactorMin:          cfg.Float("MOMENTUM_FACTOR_MIN", 0.5, &configErrors),
+		MomentumFactorMax:          cfg.Float("MOMENTUM_FACTOR_MAX", 1.5, &configErrors),
+		StablecoinSymbols:          stablecoins,
+		StablecoinTargetPct:        cfg.Float("STABLECOIN_TARGET_BPS", 5, &configErrors) / 10000.0,
+		StablecoinStopPct:          cfg.Float("STABLECOIN_STOP_BPS", 10, &configErrors) / 10000.0,
+		FundingRatePerHour:         cfg.Float("FUNDING_RATE_PCT_PER_HOUR", 0, &configErrors) / 100.0,
+		SymbolFundingRates:         fundingRates,
+		MaxSuggestedStopPct:        maxSuggestedStop,
+		MaxSuggestedTargetPct:      maxSuggestedTarget,
+		NonFiniteAnalysis:          nonFinite,
+		levelStats:                 make(map[string]*LevelSourceStats),
+		PriceDeviationTolerance:    cfg.Float("PRICE_DEVIATION_TOLERANCE_PCT", 5.0, &configErrors) / 100.0,
+		SimPriceCheck:              cfg.Get("SIM_PRICE_CHECK") == "1",
+		tickerCache:                make(map[string]tickerQuote),
+		ATRStopMultiple:            cfg.Float("ATR_STOP_MULTIPLE", 0, &configErrors),
+		ATRPeriod:                  atrPeriod,
+		ATRIntervalMin:             atrInterval,
+		candleCache:                make(map[string]candleCacheEntry),
+		pairInfoCache:              make(map[string]pairInfo),
+		skipCounts:                 make(map[string]int64),
+		SymbolLossCooldownMs:       symbolLossCooldown,
+		symbolLastLoss:             make(map[string]time.Time),
+		orderPayloads:              make(map[string][]OrderPayload),
+		DebugLogging:               strings.EqualFold(cfg.Get("LOG_LEVEL"), "debug"),
+		SkipLog:                    cfg.Get("SKIP_LOG") == "1",
+		krakenLatency:              NewLatencyTracker(),
+		stageLatency:               NewStageLatencyTracker(),
+		RunID:                      newRunID(clk.Now(), randSeed, 0),
+		StrikeLog:                  nopStrikeLogger{},
+		Clock:                      clk,
+		campaignStats:              report.NewCampaignStats(clk.Now(), Money(InitialCapital).Dollars()),
+		pnlRollups:                 report.NewPnLRollups(),
+		metrics:                    NewEngineMetrics(),
+		events:                     NewEventBus(),
+		lotLedger:                  NewLotLedger(),
+		RealizedGainsPath:          cfg.Get("REALIZED_GAINS_CSV"),
+		ReportJSONPath:             cfg.Get("REPORT_JSON"),
+		ReportHTMLPath:             cfg.Get("REPORT_HTML"),
+		StrikesJSONPath:            cfg.Get("STRIKES_JSON"),
+	}
+	te.config = cfg
+	te.configErrors = configErrors
+	te.WinRateAlpha = cfg.Float("WIN_RATE_EMA_ALPHA", defaultWinRateAlpha, &te.configErrors)
+	te.winRate = newWinRateEMA(te.WinRateAlpha)
+	if model, err := sim.ParseHitModel(cfg.Get("SIM_HIT_MODEL")); err != nil {
+		te.configErrors = append(te.configErrors, fmt.Errorf("SIM_HIT_MODEL: %v", err))
+	} else {
+		te.SimHitModel = model
+	}
+	te.Generator = analyzedStrikeGenerator{te}
+	te.stopCh = make(chan struct{})
+	te.ShutdownGrace = defaultShutdownGrace
+	te.campaignDone = make(chan struct{})
+	te.ControlToken = cfg.Get("CONTROL_TOKEN")
+	te.KillTimeout = defaultKillTimeout
+	te.PauseExtendsWindow = cfg.Get("PAUSE_EXTENDS_WINDOW") == "1"
+	te.AutoBumpMin = cfg.Get("AUTO_BUMP_MIN") == "1"
+	if v := cfg.Get("TP_LADDER"); v != "" {
+		if ladder, err := parseTakeProfitLadder(v); err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("TP_LADDER: %v", err))
+		} else {
+			te.TakeProfitLadder = ladder
+		}
+	}
+	te.WatchdogAbort = cfg.Get("WATCHDOG_ABORT") == "1"
+	te.HealthStaleAfter, te.ReadyCacheTTL = defaultHealthStaleAfter, defaultReadyCacheTTL
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{{"HEALTH_STALE_AFTER", &te.HealthStaleAfter}, {"READY_CACHE_TTL", &te.ReadyCacheTTL}, {"KILL_TIMEOUT", &te.KillTimeout}, {"WATCHDOG_STALL", &te.WatchdogStall}} {
+		if v := cfg.Get(d.name); v != "" {
+			if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+				*d.dst = parsed
+			} else {
+				te.configErrors = append(te.configErrors, fmt.Errorf("%s: %q is not a positive duration", d.name, v))
+			}
+		}
+	}
+	if v := cfg.Get("SHUTDOWN_GRACE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			te.ShutdownGrace = d
+		} else {
+			te.configErrors = append(te.configErrors, fmt.Errorf("SHUTDOWN_GRACE: %q is not a positive duration", v))
+		}
+	}
+	httpCfg, httpErrs := httpClientConfigFromConfig(cfg)
+	te.configErrors = append(te.configErrors, httpErrs...)
+	te.HTTPClient = newHTTPClient(httpCfg)
+	if ex, err := te.newExchange(cfg.Get("EXCHANGE")); err != nil {
+		te.configErrors = append(te.configErrors, err)
+	} else {
+		te.Exchange = ex
+	}
+	te.HTTPWarmup = cfg.Get("HTTP_WARMUP") != "0"
+	te.StateFile = cfg.Get("STATE_FILE")
+	te.StateSnapshotEvery = 10
+	if v := cfg.Get("STATE_SNAPSHOT_EVERY"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			te.StateSnapshotEvery = n
+		}
+	}
+	te.ProgressLogEvery = defaultProgressLogEvery
+	if v := cfg.Get("PROGRESS_LOG_EVERY"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			te.ProgressLogEvery = n
+		} else {
+			te.configErrors = append(te.configErrors, fmt.Errorf("PROGRESS_LOG_EVERY: %q is not a non-negative trade count", v))
+		}
+	}
+	perfHalfLife := 7 * 24 * time.Hour
+	if v := cfg.Get("PERF_HALF_LIFE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			perfHalfLife = d
+		} else {
+			te.configErrors = append(te.configErrors, fmt.Errorf("PERF_HALF_LIFE: %q is not a positive duration", v))
+		}
+	}
+	if te.MinFillRatio < 0 || te.MinFillRatio > 1 {
+		te.configErrors = append(te.configErrors, fmt.Errorf("MIN_FILL_RATIO: %.2f must be in [0, 1]", te.MinFillRatio))
+	}
+	te.PerfStoreFile = cfg.Get("PERF_STORE_FILE")
+	te.PerfMinTrades = cfg.Float("PERF_MIN_TRADES", 20, &te.configErrors)
+	te.PerfWinRateFloor = cfg.Float("PERF_WIN_RATE_FLOOR", 0.5, &te.configErrors)
+	te.PerfHaircut = cfg.Float("PERF_HAIRCUT", 0.5, &te.configErrors)
+	te.PerfExcludeWinRate = cfg.Float("PERF_EXCLUDE_WIN_RATE", 0.3, &te.configErrors)
+	if te.PerfHaircut <= 0 || te.PerfHaircut > 1 {
+		te.configErrors = append(te.configErrors, fmt.Errorf("PERF_HAIRCUT: %.2f must be in (0, 1]; use PERF_EXCLUDE_WIN_RATE to exclude", te.PerfHaircut))
+	}
+	te.perfStore = NewPerformanceStore(perfHalfLife)
+	if te.PerfStoreFile != "" {
+		if cfg.Get("PERF_STORE_RESET") == "1" {
+			log.Printf("♻️ Performance store %s reset", te.PerfStoreFile)
+		} else if ps, err := LoadPerformanceStore(te.PerfStoreFile, perfHalfLife); err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("PERF_STORE_FILE: %v", err))
+		} else {
+			te.perfStore = ps
+		}
+	}
+	if cfg.Get("RESUME") == "1" {
+		if te.StateFile == "" {
+			te.configErrors = append(te.configErrors, fmt.Errorf("RESUME=1 requires STATE_FILE"))
+		} else if st, err := loadState(te.StateFile); err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("RESUME: %v", err))
+		} else {
+			te.restoreState(st)
+			te.Resumed = true
+			log.Printf("♻️ Resuming run %s: %d trades done, capital %v, started %s",
+				st.RunID, st.TradesCompleted, Money(st.Capital), st.CampaignStart.Format(time.RFC3339))
+		}
+	}
+	if path := cfg.Get("KRAKEN_REPLAY_FILE"); path != "" {
+		rp, err := newKrakenReplayer(path)
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("KRAKEN_REPLAY_FILE: %v", err))
+		} else {
+			te.ReplayMode = true
+			te.krakenReplayer = rp
+			log.Printf("Kraken REPLAY mode: serving API responses from %s", path)
+		}
+	} else if path := cfg.Get("KRAKEN_RECORD_FILE"); path != "" {
+		rec, err := newKrakenRecorder(path, te.sinkRotation("KRAKEN_RECORD", RotationPolicy{}))
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("KRAKEN_RECORD_FILE: %v", err))
+		} else {
+			rec.now = func() time.Time { return te.Clock.Now() }
+			te.RecordMode = true
+			te.krakenRecorder = rec
+			log.Printf("Kraken RECORD mode: capturing API traffic to %s", path)
+		}
+	}
+	if dsn := cfg.Get("JOURNAL_POSTGRES_DSN"); dsn != "" {
+		if cfg.Get("JOURNAL_DB") != "" {
+			te.configErrors = append(te.configErrors, fmt.Errorf("set only one of JOURNAL_DB and JOURNAL_POSTGRES_DSN"))
+		}
+		instanceID := cfg.Get("INSTANCE_ID")
+		if instanceID == "" {
+			host, _ := os.Hostname()
+			instanceID = fmt.Sprintf("%s-%d", host, os.Getpid())
+		}
+		bufferMax := 10000
+		if v := cfg.Get("JOURNAL_BUFFER_MAX"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				bufferMax = n
+			} else {
+				te.configErrors = append(te.configErrors, fmt.Errorf("JOURNAL_BUFFER_MAX: %q is not a positive integer", v))
+			}
+		}
+		j, err := OpenPostgresJournal(dsn, instanceID, bufferMax, func() time.Time { return te.Clock.Now() })
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("JOURNAL_POSTGRES_DSN: %v", err))
+		} else {
+			te.journal = j
+			log.Printf("Trade journal: postgres as instance %s (run %s)", instanceID, te.RunID)
+		}
+	} else if path := cfg.Get("JOURNAL_DB"); path != "" {
+		j, err := OpenSQLiteJournal(path)
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("JOURNAL_DB: %v", err))
+		} else {
+			te.journal = j
+			te.JournalPath = path
+			log.Printf("Trade journal: %s (run %s)", path, te.RunID)
+		}
+	}
+	if path := cfg.Get("ORDER_WAL"); path != "" {
+		w, pending, err := OpenOrderWAL(path, te.RunID)
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("ORDER_WAL: %v", err))
+		} else {
+			w.now = func() time.Time { return te.Clock.Now() }
+			te.orderWAL = w
+			te.walPending = pending
+		}
+	}
+	if path := cfg.Get("STRIKE_LOG"); path != "" {
+		audit := cfg.Get("STRIKE_LOG_AUDIT") == "1"
+		sl, err := newJSONLStrikeLogger(path, te.RunID, te.sinkRotation("STRIKE_LOG", defaultStrikeLogRotation), audit)
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("STRIKE_LOG: %v", err))
+		} else {
+			// Read through te so a clock swapped in later (sweeps, replays) stamps the records
+			sl.now = func() time.Time { return te.Clock.Now() }
+			te.StrikeLog = sl
+			te.StrikeLogPath = path
+		}
+	}
+	if path := cfg.Get("CSV_EXPORT_PATH"); path != "" {
+		stream := cfg.Get("CSV_EXPORT_MODE") != "end"
+		ce, err := NewCSVExporter(path, stream, te.sinkRotation("CSV_EXPORT", defaultCSVExportRotation))
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("CSV_EXPORT_PATH: %v", err))
+		} else {
+			te.csvExport = ce
+			te.CSVExportPath = path
+		}
+	}
+	if path := cfg.Get("PARQUET_EXPORT_PATH"); path != "" {
+		pe, err := NewParquetExporter(path)
+		if err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("PARQUET_EXPORT_PATH: %v", err))
+		} else {
+			te.parquetExport = pe
+			te.ParquetExportPath = path
+		}
+	}
+	te.ParquetEquityPath = cfg.Get("PARQUET_EQUITY_PATH")
+	if up, err := NewS3UploaderFromConfig(cfg); err != nil {
+		te.configErrors = append(te.configErrors, err)
+	} else if up != nil {
+		up.now = func() time.Time { return te.Clock.Now() }
+		te.artifacts = up
+		log.Printf("Artifacts will upload to s3://%s/%s", up.Bucket, up.Prefix)
+	}
+	if n, err := NewAlertNotifierFromConfig(cfg, te.HTTPClient, te.Clock, te.metrics); err != nil {
+		te.configErrors = append(te.configErrors, err)
+	} else {
+		te.alerts = n
+	}
+	if n, err := NewEmailNotifierFromConfig(cfg); err != nil {
+		te.configErrors = append(te.configErrors, err)
+	} else if n != nil {
+		n.now = func() time.Time { return te.Clock.Now() }
+		te.email = n
+	}
+	te.redactor = config.NewRedactor(cfg)
+	te.eventLog = StartEventLogger(te.events)
+	te.eventLog.after = func(d time.Duration) <-chan time.Time { return te.Clock.After(d) }
+	historySize := defaultEventHistory
+	if v := cfg.Get("EVENT_HISTORY_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			historySize = n
+		} else {
+			te.configErrors = append(te.configErrors, fmt.Errorf("EVENT_HISTORY_SIZE: %q is not a non-negative event count", v))
+		}
+	}
+	if historySize > 0 {
+		te.recentEvents = NewEventRing(historySize)
+		recordRecentEvents(te.events, te.recentEvents)
+	}
+	if t, err := NewTracerFromConfig(cfg, te.HTTPClient, te.Clock); err != nil {
+		te.configErrors = append(te.configErrors, err)
+	} else if t != nil {
+		te.tracer = t
+		log.Printf("Tracing strikes to %s", t.Endpoint)
+	}
+	te.AlertLossUSD = cfg.Float("ALERT_LOSS_USD", 0, &te.configErrors)
+	if v := cfg.Get("ALERT_DRAWDOWN_LEVELS"); v != "" {
+		if levels, err := parseAlertDrawdownLevels(v); err != nil {
+			te.configErrors = append(te.configErrors, fmt.Errorf("ALERT_DRAWDOWN_LEVELS: %v", err))
+		} else {
+			te.AlertDrawdownLevels = levels
+		}
+	}
+	// In simulation mode, raise target capital to avoid early stop
+	if te.SimMode {
+		te.TargetCapital = te.Capital * 100 // allow growth without early stop
+	}
+	return te
+}
+
+
+// configSnapshot captures the non-secret settings of this run
+func (te *TradingEngine) configSnapshot() map[string]interface{} {
+	directions := make(map[string]string, len(te.DirectionByType))
+	for t, p := range te.DirectionByType {
+		directions[t.String()] = p.String()
+	}
+	return map[string]interface{}{
+		"live_trading":                 te.LiveTrading,
+		"exchange":                     te.exchange().Name(),
+		"sim_mode":                     te.SimMode,
+		"order_usd_size":               te.OrderUSDSize,
+		"kraken_pair_overrides":        te.PairOverrides,
+		"order_risk_pct":               te.OrderRiskPct,
+		"sim_min_hold_ms":              te.SimMinHoldMs,
+		"symbol_loss_cooldown_ms":      te.SymbolLossCooldownMs,
+		"auto_bump_min":                te.AutoBumpMin,
+		"sim_hit_model":                te.SimHitModel.String(),
+		"rand_seed":                    te.RandSeed,
+		"tp_ladder":                    te.TakeProfitLadder,
+		"fill_poll_interval_ms":        te.FillPollIntervalMs,
+		"fill_timeout_ms":              te.FillTimeoutMs,
+		"http_timeout_ms":              te.httpClient().Timeout.Milliseconds(),
+		"live_entry_order":             te.LiveEntryOrder,
+		"post_campaign":                te.PostCampaign,
+		"limit_max_chases":             te.LimitMaxChases,
+		"min_fill_ratio":               te.MinFillRatio,
+		"campaign_days":                te.CampaignDays,
+		"pause_extends_window":         te.PauseExtendsWindow,
+		"max_drawdown_pct":             te.MaxDrawdownPct,
+		"max_daily_loss_pct":           te.MaxDailyLossPct,
+		"max_notional_usd":             te.MaxNotionalUSD,
+		"max_positions_per_symbol":     te.MaxPositionsPerSymbol,
+		"max_open_positions":           te.MaxOpenPositions,
+		"skip_log":                     te.SkipLog,
+		"account_quote_currency":       te.QuoteCurrency,
+		"retry_budget_per_hour":        te.retryBudget.Limit(),
+		"daily_loss_ends_campaign":     te.DailyLossEndsCampaign,
+		"min_trading_capital":          Money(te.MinTradingCapital).Dollars(),
+		"min_risk_reward":              te.MinRiskReward,
+		"target_cost_haircut":          te.TargetCostHaircut,
+		"target_slippage_bps":          te.TargetSlippagePct * 10000.0,
+		"alerts_enabled":               te.alerts != nil,
+		"email_enabled":                te.email != nil,
+		"alert_loss_usd":               te.AlertLossUSD,
+		"alert_drawdown_levels":        te.AlertDrawdownLevels,
+		"direction_by_type":            directions,
+		"infinite_trades":              te.InfiniteTrades,
+		"min_volatility":               te.MinVolatility,
+		"max_volatility":               te.MaxVolatility,
+		"win_rate_ema_alpha":           te.WinRateAlpha,
+		"perf_min_trades":              te.PerfMinTrades,
+		"perf_win_rate_floor":          te.PerfWinRateFloor,
+		"perf_haircut":                 te.PerfHaircut,
+		"perf_exclude_win_rate":        te.PerfExcludeWinRate,
+		"max_consecutive_misses":       te.MaxConsecutiveMisses,
+		"target_capital":               Money(te.TargetCapital).Dollars(),
+		"confidence_threshold":         te.ConfidenceThreshold,
+		"symbol_confidence_thresholds": te.SymbolConfidenceThresholds,
+		"funding_rate_pct_per_hour":    te.FundingRatePerHour * 100.0,
+		"symbol_funding_rates":         te.SymbolFundingRates,
+		"liquidity_weight":             te.LiquidityWeight,
+		"precision_weight":             te.PrecisionWeight,
+		"momentum_weight":              te.MomentumWeight,
+		"atr_stop_multiple":            te.ATRStopMultiple,
+		"price_deviation_tolerance":    te.PriceDeviationTolerance,
+		"nonfinite_analysis":           te.NonFiniteAnalysis,
+		"stablecoin_symbols":           te.StablecoinSymbols,
+		"stablecoin_target_pct":        te.StablecoinTargetPct,
+		"stablecoin_stop_pct":          te.StablecoinStopPct,
+	}
+}
+
+// journalStrike writes the strike's current state to the journal, if enabled
+func (te *TradingEngine) journalStrike(strike *MacroStrike) {
+	if te.journal != nil {
+		_, span := te.tracer.Start(strikeContext(strike), "journal", "strike.status", strike.Status.String())
+		te.journal.RecordStrike(te.RunID, strike)
+		span.End()
+	}
+}
+
+// Close flushes and releases the engine's persistent sinks, saves the
+// performance store and ships final artifacts
+func (te *TradingEngine) Close() {
+	if !te.eventLog.Close(eventLogDrainTimeout) {
+		log.Printf("⚠️ Event log still writing after %v; giving up", eventLogDrainTimeout)
+	}
+	te.stopStatusServer()
+	te.savePerformanceStore()
+	if !te.alerts.Close(alertDrainTimeout) {
+		log.Printf("⚠️ Alerts still sending after %v; giving up", alertDrainTimeout)
+	}
+	if !te.email.Close(emailDrainTimeout) {
+		log.Printf("⚠️ Email still sending after %v; giving up", emailDrainTimeout)
+	}
+	if !te.tracer.Close(traceDrainTimeout) {
+		log.Printf("⚠️ Trace export still running after %v; giving up", traceDrainTimeout)
+	}
+	te.closeSinks()
+	if te.artifacts != nil {
+		// Sinks are closed, so this captures their final contents
+		te.uploadArtifacts("shutdown", true)
+		if !te.artifacts.Wait(artifactDrainTimeout) {
+			log.Printf("⚠️ Artifact uploads still running after %v; giving up", artifactDrainTimeout)
+		}
+	}
+}
+
+// closeSinks flushes and closes every file and database sink the engine
+// opened. It is all that runs when startup validation fails: the performance
+// store is not saved over a file that may have failed to load.
+func (te *TradingEngine) closeSinks() {
+	if te.journal != nil {
+		if err := te.journal.Close(); err != nil {
+			log.Printf("⚠️ Journal close: %v", err)
+		}
+	}
+	if err := te.orderWAL.Close(); err != nil {
+		log.Printf("⚠️ Order WAL close: %v", err)
+	}
+	if err := te.StrikeLog.Close(); err != nil {
+		log.Printf("⚠️ Strike log close: %v", err)
+	}
+	if te.csvExport != nil {
+		if err := te.csvExport.Close(); err != nil {
+			log.Printf("⚠️ CSV export close: %v", err)
+		}
+	}
+	if te.parquetExport != nil {
+		if err := te.parquetExport.Close(); err != nil {
+			log.Printf("⚠️ Parquet export close: %v", err)
+		}
+	}
+	if te.krakenRecorder != nil {
+		if err := te.krakenRecorder.Close(); err != nil {
+			log.Printf("⚠️ Kraken capture close: %v", err)
+		}
+	}
+}
+
+// strikeCompleted hands a finished strike to every configured sink
+func (te *TradingEngine) strikeCompleted(strike *MacroStrike, capitalAfter int64) {
+	te.recordExecutedStrike(strike)
+	te.metrics.StrikeResolved(strike)
+	te.journalStrike(strike)
+	te.StrikeLog.LogStrike(strike, Money(capitalAfter).Dollars())
+	if te.csvExport != nil {
+		te.csvExport.Record(strike)
+	}
+	if te.parquetExport != nil {
+		te.parquetExport.Record(strike)
+	}
+	now := te.Clock.Now()
+	te.campaignStats.Record(strike.statsTrade(), now, Money(capitalAfter).Dollars())
+	var pnl float64
+	if strike.PnL != nil {
+		pnl = *strike.PnL
+	}
+	te.pnlRollups.Record(now, pnl, strike.Status == Hit)
+	te.countersMu.Lock()
+	atomic.AddInt64(&te.TotalFeesPaid, FromDollars(strike.Fees).Cents())
+	te.countersMu.Unlock()
+	te.winRate.Observe(strike.Status == Hit)
+	if strike.Status == Miss {
+		te.recordSymbolLoss(strike.Symbol, now)
+	}
+	te.perfStore.Record(strike.Symbol, strike.StrikeType.String(), now, pnl, strike.Status == Hit)
+	te.checkStrikeAlerts(strike)
+	te.publish(EventStrikeClosed, strike, map[string]interface{}{
+		"status":      strike.Status.String(),
+		"pnl":         pnl,
+		"exit_price":  strike.ExitPrice,
+		"exit_reason": strike.ExitReason,
+		"capital":     Money(capitalAfter).Dollars(),
+	})
+}
+
+// strikeSide returns the direction a strike traded, as exports record it
+func strikeSide(strike *MacroStrike) string {
+	return strike.Direction.String()
+}
+
+// debugf logs only when debug logging is enabled
+func (te *TradingEngine) debugf(format string, args ...interface{}) {
+	if te.DebugLogging {
+		log.Printf("DEBUG "+format, args...)
+	}
+}
+
+// isKnownSymbol reports whether symbol is one the engine trades
+func isKnownSymbol(symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// NewValidated builds an engine like New and fails on bad settings
+func NewValidated(cfg config.Config, clk clock.Clock) (*TradingEngine, error) {
+	te := New(cfg, clk)
+	if err := te.ValidateConfig(); err != nil {
+		// Sinks opened before validation still need flushing; nothing else ran
+		te.closeSinks()
+		return nil, err
+	}
+	return te, nil
+}
+
+// Preflight checks what a campaign needs before its first strike: the
+// analyzer and, for accounts not funded in USD, the quote pairs. On failure
+// the engine's sinks are flushed and it should not be used.
+func (te *TradingEngine) Preflight(ctx context.Context) error {
+	if err := te.checkAnalyzerInstalled(); err != nil {
+		te.closeSinks()
+		return err
+	}
+	if err := te.checkQuotePairs(ctx); err != nil {
+		te.closeSinks()
+		return err
+	}
+	return nil
+}
+
+// ValidateConfig checks settings that cannot be safely defaulted.
+// It is called once at startup so bad configuration fails fast.
+func (te *TradingEngine) ValidateConfig() error {
+	var problems []string
+	for _, err := range te.configErrors {
+		problems = append(problems, err.Error())
+	}
+	if len(te.StrikeTypeWeights) > 0 {
+		total := 0.0
+		for _, w := range te.StrikeTypeWeights {
+			total += w
+		}
+		if total <= 0 {
+			problems = append(problems, "strike type weights disable every strike type")
+		}
+	}
+	if te.LiquidityFactorMin <= 0 || te.LiquidityFactorMin > te.LiquidityFactorMax {
+		problems = append(problems, fmt.Sprintf("liquidity factor clamp [%.2f, %.2f] invalid", te.LiquidityFactorMin, te.LiquidityFactorMax))
+	}
+	if te.MomentumFactorMin <= 0 || te.MomentumFactorMin > te.MomentumFactorMax {
+		problems = append(problems, fmt.Sprintf("momentum factor clamp [%.2f, %.2f] invalid", te.MomentumFactorMin, te.MomentumFactorMax))
+	}
+	problems = append(problems, checkShortsDisabled(te.DirectionByType)...)
+	if te.PrecisionWeight > 1 {
+		problems = append(problems, fmt.Sprintf("precision weight %.2f outside [0,1]", te.PrecisionWeight))
+	}
+	if te.MinVolatility < 0 || te.MaxVolatility < 0 || (te.MaxVolatility > 0 && te.MinVolatility > te.MaxVolatility) {
+		problems = append(problems, fmt.Sprintf("volatility band [%.4f, %.4f] invalid", te.MinVolatility, te.MaxVolatility))
+	}
+	// Limit chasing and traffic capture speak Kraken's API directly
+	if name := te.exchange().Name(); name != exchange.Kraken {
+		if te.LiveEntryOrder == EntryOrderLimit {
+			problems = append(problems, fmt.Sprintf("limit entries are only supported on kraken, not %s", name))
+		}
+		if te.QuoteCurrency != defaultQuoteCurrency {
+			problems = append(problems, fmt.Sprintf("%s funding is only supported on kraken, not %s", te.QuoteCurrency, name))
+		}
+		if te.ReplayMode || te.RecordMode {
+			problems = append(problems, fmt.Sprintf("kraken record/replay cannot be used with %s", name))
+		}
+	}
+	if te.WinRateAlpha <= 0 || te.WinRateAlpha > 1 {
+		problems = append(problems, fmt.Sprintf("win rate EMA alpha %.4f outside (0,1]", te.WinRateAlpha))
+	}
+	if te.MaxDailyLossPct < 0 || te.MaxDailyLossPct >= 100 {
+		problems = append(problems, fmt.Sprintf("max daily loss %.2f%% outside [0,100)", te.MaxDailyLossPct))
+	}
+	if te.ConfidenceThreshold <= 0 || te.ConfidenceThreshold > 1 {
+		problems = append(problems, fmt.Sprintf("confidence threshold %.4f outside (0,1]", te.ConfidenceThreshold))
+	}
+	for sym, gate := range te.SymbolConfidenceThresholds {
+		if !isKnownSymbol(sym) {
+			problems = append(problems, fmt.Sprintf("confidence threshold for unknown symbol %q", sym))
+		}
+		if gate <= 0 || gate > 1 {
+			problems = append(problems, fmt.Sprintf("confidence threshold %.4f for %s outside (0,1]", gate, sym))
+		}
+	}
+	for sym := range te.StablecoinSymbols {
+		if !isKnownSymbol(sym) {
+			problems = append(problems, fmt.Sprintf("unknown stablecoin symbol %q", sym))
+		}
+	}
+	// Stablecoin levels are meant to be basis points; anything past 1% is a units mistake
+	if te.StablecoinTargetPct <= 0 || te.StablecoinTargetPct > 0.01 {
+		problems = append(problems, fmt.Sprintf("stablecoin target %.1fbps outside (0,100]", te.StablecoinTargetPct*10000.0))
+	}
+	if te.StablecoinStopPct <= 0 || te.StablecoinStopPct > 0.01 {
+		problems = append(problems, fmt.Sprintf("stablecoin stop %.1fbps outside (0,100]", te.StablecoinStopPct*10000.0))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// confidenceThreshold returns the effective confidence gate for a symbol
+func (te *TradingEngine) confidenceThreshold(symbol string) float64 {
+	if gate, ok := te.SymbolConfidenceThresholds[symbol]; ok {
+		return gate
+	}
+	return te.ConfidenceThreshold
+}
+
+// krakenPair maps our symbol to Kraken's pair code
+func (te *TradingEngine) krakenPair(symbol string) string {
+	if pair, ok := te.PairOverrides[symbol]; ok {
+		return pair
+	}
+	return te.quotePair(kraken.USDPair(symbol))
+}
+
+// krakenBaseURL returns the API root requests are sent to
+func (te *TradingEngine) krakenBaseURL() string {
+	if te.KrakenBaseURL != "" {
+		return strings.TrimRight(te.KrakenBaseURL, "/")
+	}
+	return kraken.APIURL
+}
+
+// krakenPrivate performs a signed private API request
+func (te *TradingEngine) krakenPrivate(ctx context.Context, path string, data url.Values) (res map[string]interface{}, err error) {
+	defer func() { te.metrics.KrakenError(err) }()
+	if te.ReplayMode {
+		body, err := te.krakenReplayer.next(path)
+		if err != nil {
+			return nil, err
+		}
+		te.captureOrderPayload(path, data, body)
+		return kraken.DecodeResponse(body)
+	}
+	if te.KrakenAPIKey == "" || te.KrakenAPISecret == "" {
+		return nil, fmt.Errorf("kraken credentials not set")
+	}
+
+	nonce := fmt.Sprintf("%d", time.Now().UnixNano()/int64(time.Millisecond))
+	req, err := kraken.NewPrivateRequest(ctx, te.krakenBaseURL(), path, data, nonce, te.KrakenAPIKey, te.KrakenAPISecret)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := te.httpClient().Do(req)
+	elapsed := time.Since(start)
+	te.krakenLatency.Observe(path, elapsed)
+	te.debugf("kraken %s round-trip %.1fms", path, float64(elapsed)/float64(time.Millisecond))
+	if err != nil {
+		if te.RecordMode {
+			te.krakenRecorder.record(path, data, nil, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if te.RecordMode {
+		te.krakenRecorder.record(path, data, body, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	te.captureOrderPayload(path, data, body)
+	return kraken.DecodeResponse(body)
+}
+
+// krakenPrivateWithRetry wraps krakenPrivate with simple retry/backoff
+func (te *TradingEngine) krakenPrivateWithRetry(ctx context.Context, path string, data url.Values) (map[string]interface{}, error) {
+    var lastErr error
+    for i := 0; i < 3; i++ {
+        if i > 0 {
+            te.spendRetry(path, lastErr)
+        }
+        res, err := te.krakenPrivate(ctx, path, data)
+        if err == nil {
+            return res, nil
+        }
+        lastErr = err
+        if isOrderMinimumError(err) {
+            // Kraken will refuse the same volume every time
+            break
+        }
+        if err := clock.SleepContext(ctx, te.Clock, time.Duration(500*(i+1)) * time.Millisecond); err != nil {
+            return nil, err
+        }
+    }
+    return nil, lastErr
+}
+
+// placeMarketOrder places a market buy order sized by USD
+func (te *TradingEngine) placeMarketOrder(ctx context.Context, pair string, side string, usdSize float64, price float64) (string, error) {
+	volumeStr, err := te.marketOrderVolume(ctx, pair, usdSize, price)
+	if err != nil {
+		return "", err
+	}
+	vals := url.Values{}
+	vals.Set("pair", pair)
+	vals.Set("type", side)
+	vals.Set("ordertype", "market")
+	vals.Set("volume", volumeStr)
+
+	res, err := te.krakenPrivateWithRetry(ctx, "/0/private/AddOrder", vals)
+	if err != nil {
+		return "", err
+	}
+	if result, ok := res["result"].(map[string]interface{}); ok {
+		if txids, ok := result["txid"].([]interface{}); ok && len(txids) > 0 {
+			return fmt.Sprintf("%v", txids[0]), nil
+		}
+	}
+	return "", fmt.Errorf("unexpected kraken response")
+}
+
+// marketOrderVolume converts a USD size at price into an order volume,
+// rounded down to the pair's lot increment when AssetPairs is available
+func (te *TradingEngine) marketOrderVolume(ctx context.Context, pair string, usdSize float64, price float64) (string, error) {
+	if usdSize <= 0 || price <= 0 {
+		return "", fmt.Errorf("invalid size/price")
+	}
+	volume := usdSize / price
+	volumeStr := fmt.Sprintf("%.8f", volume)
+	if info, err := te.pairInfo(ctx, pair); err == nil {
+		// Kraken rejects or adjusts volumes finer than the pair's lot increment
+		volume = roundVolumeDown(volume, info.LotDecimals)
+		if volume <= 0 {
+			return "", fmt.Errorf("order of $%.2f rounds to zero volume for %s", usdSize, pair)
+		}
+		volumeStr = strconv.FormatFloat(volume, 'f', info.LotDecimals, 64)
+		effectiveUSD := volume * price
+		if diff := (usdSize - effectiveUSD) / usdSize; diff > 0.01 {
+			log.Printf("⚠️ %s lot rounding: requested $%.2f, effective $%.2f (%.2f%% smaller)", pair, usdSize, effectiveUSD, diff*100.0)
+		}
+	} else {
+		te.debugf("%s: AssetPairs unavailable, sending unrounded volume: %v", pair, err)
+	}
+	return volumeStr, nil
+}
+
+// getOrder retrieves order info
+func (te *TradingEngine) getOrder(ctx context.Context, txid string) (map[string]interface{}, error) {
+    vals := url.Values{}
+    vals.Set("txid", txid)
+    return te.krakenPrivateWithRetry(ctx, "/0/private/QueryOrders", vals)
+}
+
+// placeMarketExit sells the filled quantity at market
+func (te *TradingEngine) placeMarketExit(ctx context.Context, pair string, volume float64) (string, error) {
+    vals := url.Values{}
+    vals.Set("pair", pair)
+    vals.Set("type", "sell")
+    vals.Set("ordertype", "market")
+    vals.Set("volume", fmt.Sprintf("%.8f", volume))
+    res, err := te.krakenPrivateWithRetry(ctx, "/0/private/AddOrder", vals)
+    if err != nil { return "", err }
+    if result, ok := res["result"].(map[string]interface{}); ok {
+        if txids, ok := result["txid"].([]interface{}); ok && len(txids) > 0 {
+            return fmt.Sprintf("%v", txids[0]), nil
+        }
+    }
+    return "", fmt.Errorf("unexpected kraken response")
+}
+
+// analyzerBinary runs market_analysis.jl
+const analyzerBinary = "julia"
+
+// checkAnalyzerInstalled looks for Julia once before the campaign instead of
+// letting every strike fail on a missing binary. JULIA_MISSING=sim falls back
+// to SIM_MODE (never while live trading); otherwise the run aborts.
+func (te *TradingEngine) checkAnalyzerInstalled() error {
+	if te.SimMode {
+		return nil
+	}
+	if _, err := exec.LookPath(analyzerBinary); err == nil {
+		return nil
+	}
+	switch mode := te.JuliaMissing; mode {
+	case "", "abort":
+		return fmt.Errorf("%s not found on PATH: install it, set SIM_MODE=1, or set JULIA_MISSING=sim to fall back", analyzerBinary)
+	case "sim":
+		if te.LiveTrading {
+			return fmt.Errorf("%s not found on PATH: JULIA_MISSING=sim cannot fall back to simulated strikes while live trading", analyzerBinary)
+		}
+		log.Printf("⚠️ %s not found on PATH; falling back to SIM_MODE (JULIA_MISSING=sim)", analyzerBinary)
+		te.SimMode = true
+		// As NewTradingEngine does for SIM_MODE runs
+		te.TargetCapital = te.Capital * 100
+		return nil
+	default:
+		return fmt.Errorf("JULIA_MISSING: %q is not abort or sim", mode)
+	}
+}
+
+// GetMarketAnalysis fetches market analysis using Julia script, killing it
+// once ctx is done
+func (te *TradingEngine) GetMarketAnalysis(ctx context.Context, symbol string, strikeType string) (*MarketAnalysis, error) {
+	cmd := exec.CommandContext(ctx, analyzerBinary, "market_analysis.jl", symbol, strikeType)
+	start := te.Clock.Now()
+	output, err := cmd.Output()
+	te.metrics.AnalyzerLatency(te.Clock.Since(start))
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %v", ErrAnalyzerMissing, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market analysis: %v", err)
+	}
+
+	return parseMarketAnalysis(output)
+}
+
+// checkVolatility skips setups whose volatility is too low to reach a target
+// or too high to manage the stop
+func (te *TradingEngine) checkVolatility(symbol string, vol float64) error {
+	if te.MinVolatility > 0 && vol < te.MinVolatility {
+		return newSkip(SkipVolatility, "%s volatility %.4f below %.4f", symbol, vol, te.MinVolatility)
+	}
+	if te.MaxVolatility > 0 && vol > te.MaxVolatility {
+		return newSkip(SkipVolatility, "%s volatility %.4f above %.4f", symbol, vol, te.MaxVolatility)
+	}
+	return nil
+}
+
+// GenerateStrike returns the next strike from the engine's StrikeGenerator
+func (te *TradingEngine) GenerateStrike(ctx context.Context) (*MacroStrike, error) {
+	strike, err := te.Generator.NextStrike(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(strike.Transitions) == 0 {
+		te.transition(strike, Targeting, strike.EntryPrice, "generated")
+	}
+	if err := te.checkDirection(strike); err != nil {
+		return nil, err
+	}
+	if err := te.checkSymbolCooldown(strike); err != nil {
+		return nil, err
+	}
+	if err := te.checkSymbolPositions(strike); err != nil {
+		return nil, err
+	}
+	strike.RiskReward = riskReward(strike)
+	if te.MinRiskReward > 0 && strike.RiskReward < te.MinRiskReward {
+		return nil, newSkip(SkipRiskReward, "%s R:R %.2f below %.2f (entry %.6f target %.6f stop %.6f)",
+			strike.Symbol, strike.RiskReward, te.MinRiskReward, strike.EntryPrice, strike.TargetPrice, strike.StopLoss)
+	}
+	factor, err := te.performanceFactor(strike)
+	if err != nil {
+		return nil, err
+	}
+	strike.PerformanceFactor = factor
+	te.publish(EventStrikeGenerated, strike, map[string]interface{}{
+		"strike_type": strike.StrikeType.String(),
+		"direction":   strike.Direction.String(),
+		"confidence":  strike.Confidence,
+		"entry_price": strike.EntryPrice,
+		"target":      strike.TargetPrice,
+		"stop_loss":   strike.StopLoss,
+	})
+	return strike, nil
+}
+
+// generateAnalyzedStrike creates a new trading strike from market analysis
+// (or the simulation model); it backs the default StrikeGenerator
+func (te *TradingEngine) generateAnalyzedStrike(ctx context.Context) (*MacroStrike, error) {
+	ctx, root := te.tracer.Start(ctx, "strike")
+	strike, err := te.generateStrike(ctx)
+	if err != nil {
+		root.Fail(err)
+		return nil, err
+	}
+	strike.traceCtx = ctx
+	return strike, nil
+}
+
+// generateStrike builds the next strike under the root span in ctx
+func (te *TradingEngine) generateStrike(ctx context.Context) (*MacroStrike, error) {
+	strikeID := atomic.AddUint64(&te.NextStrikeID, 1)
+	symbolID := int(strikeID) % len(symbols)
+	symbol := symbols[symbolID]
+	spanFromContext(ctx).SetAttrs("strike.id", strikeID, "strike.symbol", symbol)
+
+	// Generate strike type
+	rng := te.strikeRand(strikeID, streamGenerate)
+	strikeType := te.nextStrikeType(strikeID, rng)
+	strikeTypeName := strikeType.String()
+	threshold := te.confidenceThreshold(symbol)
+	stablecoin := te.StablecoinSymbols[symbol]
+	if stablecoin && !stablecoinStrikeType(strikeType) {
+		return nil, newSkip(SkipStablecoinType, "%s is a stablecoin pair; %s strikes excluded", symbol, strikeTypeName)
+	}
+
+	// Simulation mode: bypass Julia, generate high-confidence strikes
+	if te.SimMode {
+		basePrice := basePrices[symbolID]
+		if te.SimPriceCheck {
+			if err := te.checkAnalysisPrice(ctx, symbol, basePrice, false); err != nil {
+				return nil, err
+			}
+		}
+		expectedReturn := te.getExpectedReturn(strikeType)
+		targetReturn, covered := te.netTargetReturn(expectedReturn)
+		targetPrice, stopLoss := basePrice*(1.0+targetReturn), basePrice*0.98
+		if stablecoin {
+			expectedReturn = te.StablecoinTargetPct
+			targetPrice, stopLoss = te.stablecoinLevels(basePrice)
+		} else if !covered {
+			return nil, te.costSkip(symbol, expectedReturn)
+		}
+		conf := 0.80 + rng.Float64()*0.15 // 0.80 - 0.95
+		if conf < threshold {
+			return nil, newSkip(SkipLowConfidence, "%s sim conf=%.2f threshold=%.2f", symbol, conf, threshold)
+		}
+		return &MacroStrike{
+			ID:                strikeID,
+			Symbol:            symbol,
+			StrikeType:        strikeType,
+			EntryPrice:        basePrice,
+			TargetPrice:       targetPrice,
+			StopLoss:          stopLoss,
+			Direction:         levelDirection(basePrice, targetPrice),
+			Confidence:        conf,
+			ExpectedReturn:    expectedReturn,
+			MaxExposureTimeMs: MaxExposureTimeMs,
+			StrikeForce:       0.0,
+			Timestamp:         te.Clock.Now().Unix(),
+			Status:            Targeting,
+			Leverage:          1,
+			ConfidenceThreshold: threshold,
+			LevelSource:       LevelSourceFormula,
+			LiquidityFactor:   1.0,
+			MomentumFactor:    1.0,
+		}, nil
+	}
+
+	// Get market analysis from Julia
+	analysisStart := te.Clock.Now()
+	_, analysisSpan := te.tracer.Start(ctx, "analysis", "strike.type", strikeTypeName)
+	analysis, err := te.GetMarketAnalysis(ctx, symbol, strikeTypeName)
+	analysisSpan.Fail(err)
+	analysisTime := te.Clock.Since(analysisStart)
+	if errors.Is(err, ErrAnalyzerMissing) {
+		return nil, err
+	}
+	if errors.Is(err, ErrAnalysisNonFinite) {
+		return nil, te.nonFiniteAnalysis(symbol, err)
+	}
+	if err != nil {
+		// For accuracy: skip when analysis is unavailable
+		return nil, newSkip(SkipAnalysisUnavailable, "analysis unavailable")
+	}
+	if err := te.checkVolatility(symbol, analysis.Volatility); err != nil {
+		return nil, err
+	}
+
+	// Use Julia analysis for strike parameters
+	entryPrice := analysis.Price
+	confidence := analysis.Confidence
+	expectedReturn := analysis.ExpectedReturn
+
+	// Use Julia's precision score to adjust confidence, weighted by PrecisionWeight
+	precisionAdjustedConfidence := precisionConfidence(confidence, analysis.PrecisionScore, te.PrecisionWeight)
+
+	// Disable soft TA gate for accuracy-only mode
+	allowSoft := false
+
+	// Proceed if EXECUTE with high confidence or soft gate approves
+	if !(analysis.Recommendation == "EXECUTE" && precisionAdjustedConfidence >= threshold) && !allowSoft {
+		// Skip low-quality setups; caller will try next without counting a trade
+		return nil, newSkip(SkipLowConfidence, "%s %s conf=%.2f threshold=%.2f", symbol, analysis.Recommendation, precisionAdjustedConfidence, threshold)
+	}
+
+	// Never build levels around a price the market disagrees with; mandatory when live
+	if err := te.checkAnalysisPrice(ctx, symbol, entryPrice, te.LiveTrading); err != nil {
+		return nil, err
+	}
+
+	targetReturn, covered := te.netTargetReturn(expectedReturn)
+	targetPrice, stopLoss, levelSource := te.strikeLevels(analysis, entryPrice, targetReturn)
+	if stablecoin {
+		// Percent-scale levels around a ~$1 peg never trigger; analyst levels included
+		expectedReturn = te.StablecoinTargetPct
+		targetPrice, stopLoss = te.stablecoinLevels(entryPrice)
+		levelSource = LevelSourceFormula
+	} else if levelSource == LevelSourceFormula {
+		if !covered {
+			return nil, te.costSkip(symbol, expectedReturn)
+		}
+		if stop, ok := te.atrStop(ctx, symbol, entryPrice); ok {
+			stopLoss = stop
+		}
+	}
+
+	strike := &MacroStrike{
+		ID:                strikeID,
+		Symbol:            symbol,
+		StrikeType:        strikeType,
+		EntryPrice:        entryPrice,
+		TargetPrice:       targetPrice,
+		StopLoss:          stopLoss,
+		Direction:         levelDirection(entryPrice, targetPrice),
+		Confidence:        precisionAdjustedConfidence,
+		ExpectedReturn:    expectedReturn,
+		MaxExposureTimeMs: MaxExposureTimeMs,
+		StrikeForce:       0.0, // Will be calculated
+		Timestamp:         te.Clock.Now().Unix(),
+		Status:            Targeting,
+		Leverage:          1,
+		ConfidenceThreshold: threshold,
+		LevelSource:       levelSource,
+		LiquidityFactor:   liquidityFactor(analysis.Liquidity, te.LiquidityWeight, te.LiquidityFactorMin, te.LiquidityFactorMax),
+		MomentumFactor:    momentumFactor(strikeType, analysis.Momentum, te.MomentumWeight, te.MomentumFactorMin, te.MomentumFactorMax),
+		Analysis:          analysis.snapshot(),
+	}
+	te.stageTimed(strike, StageAnalysis, analysisTime)
+	return strike, nil
+}
+
+// riskReward returns (target-entry)/(entry-stop) for a long strike, or 0 when
+// the levels don't bracket the entry
+func riskReward(strike *MacroStrike) float64 {
+	reward := strike.TargetPrice - strike.EntryPrice
+	risk := strike.EntryPrice - strike.StopLoss
+	if reward <= 0 || risk <= 0 {
+		return 0
+	}
+	return reward / risk
+}
+
+// stablecoinStrikeType reports whether a strike type makes sense on a pegged
+// pair; momentum and volatility strikes need a price that actually moves
+func stablecoinStrikeType(strikeType StrikeType) bool {
+	return strikeType != MacroMomentum && strikeType != MacroVolatility
+}
+
+// stablecoinLevels returns basis-point scale target and stop prices
+func (te *TradingEngine) stablecoinLevels(entryPrice float64) (float64, float64) {
+	return entryPrice * (1.0 + te.StablecoinTargetPct), entryPrice * (1.0 - te.StablecoinStopPct)
+}
+
+// strikeLevels picks target and stop prices for a long entry. Analyst-suggested
+// levels are used when both are present and sane; otherwise the formulaic
+// target (entry × (1+expectedReturn)) and 2% stop apply.
+func (te *TradingEngine) strikeLevels(analysis *MarketAnalysis, entryPrice, expectedReturn float64) (float64, float64, string) {
+	target := entryPrice * (1.0 + expectedReturn)
+	stop := entryPrice * 0.98 // 2% stop loss
+	if analysis.SuggestedStop == nil || analysis.SuggestedTarget == nil || entryPrice <= 0 {
+		return target, stop, LevelSourceFormula
+	}
+	suggestedStop, suggestedTarget := *analysis.SuggestedStop, *analysis.SuggestedTarget
+	stopDist := (entryPrice - suggestedStop) / entryPrice
+	targetDist := (suggestedTarget - entryPrice) / entryPrice
+	if stopDist <= 0 || stopDist > te.MaxSuggestedStopPct || targetDist <= 0 || targetDist > te.MaxSuggestedTargetPct {
+		te.debugf("%s: ignoring suggested levels stop=%.6f target=%.6f around entry %.6f", analysis.Symbol, suggestedStop, suggestedTarget, entryPrice)
+		return target, stop, LevelSourceFormula
+	}
+	return suggestedTarget, suggestedStop, LevelSourceAnalyst
+}
+
+// ExecuteStrike executes a trading strike, closing its trace's root span
+// with the outcome
+func (te *TradingEngine) ExecuteStrike(ctx context.Context, strike *MacroStrike) (float64, error) {
+	ctx, root := te.strikeTrace(ctx, strike)
+	pnl, err := te.executeStrike(ctx, strike)
+	if !errors.Is(err, ErrPositionManaged) {
+		// A managed position closes its root span when it exits
+		endStrikeSpan(root, strike, pnl, err)
+	}
+	return pnl, err
+}
+
+// endStrikeSpan closes a strike's root span with its outcome
+func endStrikeSpan(root *Span, strike *MacroStrike, pnl float64, err error) {
+	root.SetAttrs("strike.status", strike.Status.String(), "strike.pnl", pnl)
+	if strike.EntryTxID != nil {
+		root.SetAttrs("entry.txid", *strike.EntryTxID)
+	}
+	if strike.ExitTxID != nil {
+		root.SetAttrs("exit.txid", *strike.ExitTxID)
+	}
+	root.Fail(err)
+}
+
+// executeStrike sizes, places and exits strike, recording a child span of
+// ctx for each step
+func (te *TradingEngine) executeStrike(ctx context.Context, strike *MacroStrike) (float64, error) {
+	if strike.Direction == Short {
+		// Neither the sim PnL model nor the spot exits can carry a short yet,
+		// so a type trading both directions is held to its long setups
+		return 0, newSkip(SkipDirection, "%s short strike %d cannot execute yet", strike.Symbol, strike.ID)
+	}
+	execStart := te.Clock.Now()
+	_, sizing := te.tracer.Start(ctx, "sizing")
+
+	// Calculate strike size
+	currentCapital := Money(atomic.LoadInt64(&te.Capital)).Dollars()
+	strikeSize := baseStrikeSize(currentCapital, strike)
+
+	// Enforce leverage policy 3x-5x in PnL model
+	intendedLeverage := float64(MinLeverage)
+	if strike.StrikeType == MacroMomentum || strike.StrikeType == MacroVolatility {
+		intendedLeverage = float64(MaxLeverage)
+	}
+	strike.Leverage = uint32(intendedLeverage)
+	strikeSize *= intendedLeverage
+
+	// In simulation, cap position by risk percent of equity
+	if te.SimMode && te.OrderRiskPct > 0 {
+		// risk per trade in USD
+		riskUSD := currentCapital * te.OrderRiskPct
+		// size so that loss at stop equals riskUSD
+		stopPct := SimStopLossPct
+		if te.StablecoinSymbols[strike.Symbol] {
+			stopPct = te.StablecoinStopPct
+		}
+		maxSizeByRisk := riskUSD / (stopPct * intendedLeverage)
+		if maxSizeByRisk < strikeSize {
+			strikeSize = maxSizeByRisk
+		}
+	}
+
+	if !te.LiveTrading {
+		// Live orders book their own, unlevered size below
+		reserved, err := te.reserveNotional(strike, strikeSize)
+		if err != nil {
+			return 0, sizing.Fail(err)
+		}
+		defer te.releaseNotional(strike.ID)
+		strikeSize = reserved
+	}
+
+	strike.StrikeForce = strikeSize
+	sizing.SetAttrs("strike.size_usd", strikeSize, "strike.leverage", int64(strike.Leverage))
+	sizing.End()
+	te.transition(strike, Striking, strike.EntryPrice, "")
+
+	if te.LiveTrading {
+		// LIVE: open with a market order of OrderUSDSize (scaled by the strike's sizing factors) on the exchange for the pair at current entry price
+		ex := te.exchange()
+		pair := ex.Pair(strike.Symbol)
+		if pair == "" {
+			return 0, fmt.Errorf("no %s pair for %s", ex.Name(), strike.Symbol)
+		}
+		// Fills come back in the funding currency; PnL is booked in USD
+		quoteRate, err := te.quotePerUSD(ctx)
+		if err != nil {
+			return 0, err
+		}
+		var txid string
+		var filledVolume float64
+		buyPrice := strike.EntryPrice
+		// Every order placed for this strike; captured payloads are released on any exit path
+		var orderTxs []string
+		defer func() { te.takeOrderPayloads(orderTxs...) }()
+		side := strike.Direction.entrySide()
+		_, addOrder := te.tracer.Start(ctx, "add_order", "pair", pair, "order.side", side)
+		orderUSD, err := te.checkOrderMinimum(ctx, pair, te.liveOrderUSD(strike), strike.EntryPrice)
+		if err != nil {
+			return 0, addOrder.Fail(err)
+		}
+		if orderUSD, err = te.reserveNotional(strike, orderUSD); err != nil {
+			return 0, addOrder.Fail(err)
+		}
+		defer te.releaseNotionalUnlessOpen(strike.ID)
+		defer te.orderDone(strike.ID)
+		te.orderProgress(strike.ID, "placing entry", "")
+		entryStart := te.Clock.Now()
+		te.orderWAL.Intent(strike.ID, pair, side, orderUSD)
+		if te.LiveEntryOrder == EntryOrderLimit {
+			var err error
+			txid, filledVolume, buyPrice, err = te.chaseLimit(ctx, pair, side, orderUSD, te.LimitMaxChases, func(tx string) {
+				orderTxs = append(orderTxs, tx)
+				te.orderWAL.Placed(strike.ID, side, tx)
+				te.orderPlaced(strike.ID, pair, side, tx)
+			})
+			if err != nil {
+				return 0, addOrder.Fail(err)
+			}
+			log.Printf("LIVE LIMIT ORDER: %s %s $%.2f filled %.8f @ ~%.2f (txid=%s)", pair, side, orderUSD, filledVolume, buyPrice, txid)
+		} else {
+			// Use entry price as indicative; the market order fills against the book
+			var err error
+			txid, err = ex.PlaceMarketOrder(ctx, pair, side, orderUSD, strike.EntryPrice)
+			if isOrderMinimumError(err) {
+				// Nothing was placed, so the intent is settled
+				te.orderWAL.Resolved(strike.ID)
+				log.Printf("⏭️ %s order $%.2f rejected below the pair minimum; skipping", pair, orderUSD)
+				return 0, addOrder.Fail(newSkip(SkipOrderMinimum, "%s order $%.2f rejected: %v", pair, orderUSD, err))
+			}
+			if err != nil {
+				return 0, addOrder.Fail(err)
+			}
+			orderTxs = append(orderTxs, txid)
+			te.orderWAL.Placed(strike.ID, side, txid)
+			te.orderPlaced(strike.ID, pair, side, txid)
+			log.Printf("LIVE ORDER: %s %s $%.2f @ ~%.2f (txid=%s)", pair, side, orderUSD, strike.EntryPrice, txid)
+		}
+		te.stageTimed(strike, StageOrderSubmit, te.Clock.Since(entryStart))
+		addOrder.SetAttrs("txid", txid, "order.usd", orderUSD)
+		addOrder.End()
+
+		// Poll fills briefly (up to FillTimeoutMs); a chased limit entry has already filled
+		fillTimeout := time.Duration(te.FillTimeoutMs) * time.Millisecond
+		start := te.Clock.Now()
+		_, fillPoll := te.tracer.Start(ctx, "fill_poll", "txid", txid)
+		var entryFee float64
+		if filledVolume == 0 {
+			// MIN_FILL_RATIO judges the settled order, not its first partial fill
+			filled := func(o exchange.OrderInfo) bool { return o.VolExec > 0 }
+			if te.MinFillRatio > 0 {
+				filled = func(o exchange.OrderInfo) bool { return o.Status == exchange.OrderClosed }
+			}
+			ord, err := te.pollOrder(ctx, strike.ID, txid, "polling entry fill", filled)
+			if ord.Price > 0 {
+				buyPrice = ord.Price
+			}
+			if ord.VolExec > 0 {
+				filledVolume = ord.VolExec
+				entryFee = ord.Fee
+			}
+			var terminal *orderTerminalError
+			switch {
+			case errors.Is(err, errPollAborted):
+				return 0, fillPoll.Fail(fmt.Errorf("aborted by watchdog while polling entry %s", txid))
+			case errors.As(err, &terminal) && ord.VolExec == 0:
+				return 0, fillPoll.Fail(fmt.Errorf("entry %w without filling", err))
+			case err != nil && !errors.Is(err, errPollTimeout):
+				return 0, fillPoll.Fail(fmt.Errorf("stopped polling entry %s: %w", txid, err))
+			}
+		}
+		te.stageTimed(strike, StageEntryFill, te.Clock.Since(start))
+		if filledVolume == 0 {
+			return 0, fillPoll.Fail(fmt.Errorf("no fill for %s in %v", txid, fillTimeout))
+		}
+		if requested := orderUSD / strike.EntryPrice; te.thinFill(filledVolume, requested) {
+			return 0, fillPoll.Fail(te.flattenThinFill(context.WithoutCancel(ctx), strike, pair, txid, filledVolume, requested, entryFee, buyPrice, quoteRate, &orderTxs))
+		}
+		te.metrics.FillLatency(te.Clock.Since(entryStart))
+		fillPoll.SetAttrs("fill.volume", filledVolume, "fill.price", buyPrice)
+		fillPoll.End()
+		te.publish(EventOrderFilled, strike, map[string]interface{}{"txid": txid, "side": side, "price": buyPrice, "volume": filledVolume})
+		pos := te.trackPosition(strike.ID, pair, filledVolume, txid)
+		// Each leg's fee is the exchange-reported one, modeled when not reported
+		entryCost := buyPrice * filledVolume
+		if entryFee <= 0 {
+			entryFee = entryCost * RoundTripFeePct / 2.0
+		}
+		te.lotLedger.Acquire(te.pairAsset(pair), strike.ID, filledVolume, entryCost, entryFee, te.Clock.Now())
+		strike.EntryTxID = &txid
+		if strike.EntryPrice > 0 {
+			strike.Slippage = (buyPrice - strike.EntryPrice) / strike.EntryPrice
+		}
+		te.journalStrike(strike)
+
+		fill := liveFill{strike: strike, pos: pos, pair: pair, txid: txid, buyPrice: buyPrice, filledVolume: filledVolume,
+			entryFee: entryFee, quoteRate: quoteRate, orderTxs: orderTxs, execStart: execStart}
+		if te.positions != nil {
+			// The position manager owns the position, and its order payloads, from here
+			orderTxs = nil
+			te.positions.Go(strike, func() (float64, error) {
+				pnl, err := te.finishLiveStrike(ctx, fill)
+				endStrikeSpan(spanFromContext(ctx), strike, pnl, err)
+				return pnl, err
+			})
+			return 0, ErrPositionManaged
+		}
+		return te.finishLiveStrike(ctx, fill)
+	}
+
+	// Simulated backtest mode retained for offline runs
+	if te.SimMinHoldMs > 0 {
+		holdStart := te.Clock.Now()
+		_, holdSpan := te.tracer.Start(ctx, "hold")
+		clock.SleepContext(ctx, te.Clock, te.simHold(strike))
+		holdSpan.End()
+		te.stageTimed(strike, StageHold, te.Clock.Since(holdStart))
+	}
+	_, exitSpan := te.tracer.Start(ctx, "exit")
+	rng := te.strikeRand(strike.ID, streamExecute)
+	te.debugf("strike %d: replay with ReproduceStrike(%d, %d) at capital $%.2f", strike.ID, te.RandSeed, strike.ID, currentCapital)
+	priceMovement := (rng.Float64() - 0.5) * 0.04 // ±2% movement (noise only)
+	stablecoin := te.StablecoinSymbols[strike.Symbol]
+	if stablecoin {
+		priceMovement *= 0.05 // pegged pairs wander ±0.1%
+	}
+
+	// Determine hit/miss from confidence and where the target and stop sit
+	hitProbability := te.SimHitModel.HitProbability(strike.EntryPrice, strike.TargetPrice, strike.StopLoss, strike.Confidence)
+	hitDraw := rng.Float64()
+	isHit := hitDraw < hitProbability
+	finalPrice := sim.ExitPrice(strike.EntryPrice, strike.TargetPrice, strike.StopLoss, isHit, priceMovement)
+
+	// Calculate PnL with TP/SL and fees
+	var pnl float64
+	fees := strikeSize * RoundTripFeePct
+	if isHit {
+		// Use realistic TP in SIM_MODE, else strategy expectedReturn
+		tp := strike.ExpectedReturn
+		if te.SimMode { tp = SimTakeProfitPct }
+		if stablecoin { tp = te.StablecoinTargetPct }
+		gross := strikeSize * tp * float64(strike.Leverage)
+		pnl = gross - fees
+		if priceMovement > 0 {
+			pnl += strikeSize * 0.0002 * float64(strike.Leverage) // tiny bonus
+		}
+	} else {
+		// Use realistic SL in SIM_MODE
+		sl := SimStopLossPct
+		if stablecoin { sl = te.StablecoinStopPct }
+		grossLoss := strikeSize * sl * float64(strike.Leverage)
+		pnl = -grossLoss - fees
+	}
+	exitReason := ExitStopLoss
+	if isHit {
+		exitReason = ExitTakeProfit
+	}
+	if ladder := te.strikeLadder(strike); len(ladder) > 0 {
+		// PnL aggregates the rungs reached and the remainder's exit
+		var gross float64
+		gross, finalPrice, exitReason = te.simLadderExit(strike, ladder, strikeSize, hitDraw, isHit, pnl+fees, finalPrice)
+		pnl = gross - fees
+		isHit = pnl > 0
+	}
+	// The levered notional pays funding for as long as it is modeled as held
+	funding := te.fundingCost(strike.Symbol, strikeSize, te.simHold(strike))
+	pnl -= funding
+
+	// Update counters, capital and peak; a loss larger than remaining capital blows up the account
+	currentCapitalInt := te.settleStrike(FromDollars(pnl).Cents(), isHit)
+	if isHit {
+		te.transition(strike, Hit, finalPrice, exitReason)
+	} else {
+		te.transition(strike, Miss, finalPrice, exitReason)
+	}
+
+	// Set exit price and PnL
+	strike.ExitPrice = &finalPrice
+	strike.PnL = &pnl
+	now := te.Clock.Now().Unix()
+	strike.HitTime = &now
+	strike.Fees = fees
+	strike.Funding = funding
+	strike.ExitReason = exitReason
+	exitSpan.SetAttrs("exit.reason", exitReason, "exit.price", finalPrice, "strike.pnl", pnl)
+	exitSpan.End()
+	strike.DurationMs = te.Clock.Since(execStart).Milliseconds()
+	te.strikeCompleted(strike, currentCapitalInt)
+
+	return pnl, nil
+}
+
+// liveFill is a filled live entry handed to finishLiveStrike
+type liveFill struct {
+	strike       *MacroStrike
+	pos          *openPosition
+	pair, txid   string
+	buyPrice     float64
+	filledVolume float64
+	entryFee     float64
+	quoteRate    float64
+	orderTxs     []string
+	execStart    time.Time
+}
+
+// finishLiveStrike is the post-fill half of a live strike: the hold, the
+// exit and its polling, and booking the PnL. It runs inline, or on the
+// PositionManager when MAX_OPEN_POSITIONS lets positions overlap.
+func (te *TradingEngine) finishLiveStrike(ctx context.Context, f liveFill) (float64, error) {
+	strike, pos, pair, txid, execStart := f.strike, f.pos, f.pair, f.txid, f.execStart
+	buyPrice, filledVolume, entryFee, quoteRate := f.buyPrice, f.filledVolume, f.entryFee, f.quoteRate
+	orderTxs := f.orderTxs
+	defer func() { te.takeOrderPayloads(orderTxs...) }()
+	defer te.releaseNotionalUnlessOpen(strike.ID)
+	defer te.orderDone(strike.ID)
+	ex := te.exchange()
+	var exitFee, finalFee float64
+	var err error
+
+	// Hold until the target or stop is reached, or for at most the strike's
+	// MaxExposureTimeMs, then exit at market, scaling out along any
+	// take-profit ladder on the way. A shutdown or a cancelled ctx cuts the
+	// hold short so the position is exited, not abandoned: the exit itself
+	// runs on exitCtx, which ctx's cancellation does not reach
+	exitCtx := context.WithoutCancel(ctx)
+	remaining, pnl := filledVolume, 0.0
+	var exitTx string
+	holdStart := te.Clock.Now()
+	holdDeadline := holdStart.Add(exposureLimit(strike))
+	holdCtx, hold := te.tracer.Start(ctx, "hold")
+	exitReason := ExitHoldExpired
+	if len(te.strikeLadder(strike)) > 0 {
+		var rungFees float64
+		remaining, pnl, rungFees, exitTx, exitReason = te.liveLadderHold(holdCtx, strike, pos, pair, buyPrice, filledVolume, holdDeadline, &orderTxs)
+		exitFee += rungFees
+	} else {
+		exitReason = te.liveMonitorHold(holdCtx, strike, pair, buyPrice, holdDeadline)
+	}
+	te.stageTimed(strike, StageHold, te.Clock.Since(holdStart))
+	hold.SetAttrs("exit.remaining_volume", remaining, "exit.reason", exitReason)
+	hold.End()
+	if exitReason == ExitTimeStop {
+		log.Printf("⏱️ Strike %d open %v: time stop, exiting %s at market", strike.ID, exposureLimit(strike), pair)
+	}
+	sellPrice := buyPrice
+	if remaining <= lotEpsilon {
+		// Every rung filled; nothing is left for a final exit
+		te.releasePosition(strike.ID)
+		exitReason = ExitTakeProfit
+	} else {
+		// PlaceMarketExit only sells; executeStrike turns shorts away before entry
+		exitSide := strike.Direction.exitSide()
+		_, exitSpan := te.tracer.Start(exitCtx, "exit", "pair", pair, "order.side", exitSide, "exit.volume", remaining)
+		te.orderWAL.Intent(strike.ID, pair, exitSide, remaining)
+		te.orderProgress(strike.ID, "placing exit", "")
+		exitStart := te.Clock.Now()
+		exitTx, err = ex.PlaceMarketExit(exitCtx, pair, remaining)
+		te.stageTimed(strike, StageExitSubmit, te.Clock.Since(exitStart))
+		if err != nil {
+			te.alert(AlertExitFailed, "exit of %s %.8f for strike %d failed: %v", pair, remaining, strike.ID, err)
+			return 0, exitSpan.Fail(fmt.Errorf("exit failed: %v", err))
+		}
+		orderTxs = append(orderTxs, exitTx)
+		te.orderWAL.Placed(strike.ID, exitSide, exitTx)
+		te.orderPlaced(strike.ID, pair, exitSide, exitTx)
+		te.positionsMu.Lock()
+		pos.ExitTx = exitTx
+		te.positionsMu.Unlock()
+
+		// Poll exit to get price; the position is only released once the exchange reports it closed
+		start := te.Clock.Now()
+		ord, err := te.pollOrder(exitCtx, strike.ID, exitTx, "polling exit fill", func(o exchange.OrderInfo) bool { return o.Status == exchange.OrderClosed })
+		if errors.Is(err, errPollAborted) {
+			// The position stays tracked for the campaign-end flatten
+			return 0, exitSpan.Fail(fmt.Errorf("aborted by watchdog while polling exit %s", exitTx))
+		}
+		if ord.Price > 0 {
+			sellPrice = ord.Price
+		}
+		finalFee = ord.Fee
+		if err == nil {
+			te.releasePosition(strike.ID)
+		} else {
+			log.Printf("⚠️ Exit %s for strike %d not confirmed closed (%v); leaving it for the flatten", exitTx, strike.ID, err)
+		}
+		te.stageTimed(strike, StageExitFill, te.Clock.Since(start))
+
+		proceeds := sellPrice * remaining
+		if finalFee <= 0 {
+			finalFee = proceeds * RoundTripFeePct / 2.0
+		}
+		exitFee += finalFee
+		te.lotLedger.Dispose(te.pairAsset(pair), remaining, proceeds, finalFee, te.Clock.Now())
+		pnl += (sellPrice - buyPrice) * remaining
+		exitSpan.SetAttrs("txid", exitTx, "exit.price", sellPrice)
+		exitSpan.End()
+		if len(strike.Exits) > 0 {
+			strike.Exits = append(strike.Exits, StrikeExit{Portion: remaining / filledVolume, Price: sellPrice, Reason: exitReason, TxID: exitTx})
+		}
+	}
+	if len(strike.Exits) > 0 {
+		// Report the portion-weighted exit across rungs and the remainder
+		sellPrice = buyPrice + pnl/filledVolume
+	}
+	strike.ExitTxID = &exitTx
+
+	// PnL in USD aggregates every exit
+	pnl /= quoteRate
+	currentCapitalInt := te.settleStrike(FromDollars(pnl).Cents(), pnl >= 0)
+	if pnl >= 0 {
+		te.transition(strike, Hit, sellPrice, exitReason)
+	} else {
+		te.transition(strike, Miss, sellPrice, exitReason)
+	}
+	strike.PnL = &pnl
+	strike.ExitPrice = &sellPrice
+	exitTime := te.Clock.Now().Unix()
+	strike.HitTime = &exitTime
+	strike.Fees = (entryFee + exitFee) / quoteRate
+	strike.ExitReason = exitReason
+	strike.DurationMs = te.Clock.Since(execStart).Milliseconds()
+	te.attachOrderDetails(exitCtx, strike, orderTxs)
+	te.strikeCompleted(strike, currentCapitalInt)
+	log.Printf("LIVE EXIT: %s filled=%.8f buy=%.2f sell=%.2f PnL=$%.2f (buyTx=%s, sellTx=%s)", pair, filledVolume, buyPrice, sellPrice, pnl, txid, exitTx)
+	return pnl, nil
+}
+
+// applyPnL books a PnL delta (cents) against capital, tracks the peak, and
+// floors capital at zero. Hitting zero marks the engine as blown up, a
+// terminal state the campaign loop stops on. Returns capital after the delta.
+func (te *TradingEngine) applyPnL(pnlCents int64) int64 {
+	te.countersMu.Lock()
+	defer te.countersMu.Unlock()
+	return te.bookPnL(pnlCents)
+}
+
+// settleStrike books a resolved strike's outcome and PnL together, so no
+// Snapshot sees one without the other, and returns capital after it
+func (te *TradingEngine) settleStrike(pnlCents int64, hit bool) int64 {
+	te.countersMu.Lock()
+	defer te.countersMu.Unlock()
+	atomic.AddInt64(&te.TotalStrikes, 1)
+	if hit {
+		atomic.AddInt64(&te.SuccessfulStrikes, 1)
+		atomic.StoreInt64(&te.ConsecutiveMisses, 0)
+	} else {
+		atomic.AddInt64(&te.FailedStrikes, 1)
+		atomic.AddInt64(&te.ConsecutiveMisses, 1)
+	}
+	return te.bookPnL(pnlCents)
+}
+
+// bookPnL moves capital, peak and drawdown; countersMu must be held. Capital
+// and peak still move by compare-and-swap, so neither can be regressed by a
+// writer that doesn't take the lock.
+func (te *TradingEngine) bookPnL(pnlCents int64) int64 {
+	// Only the capital that actually existed can be lost
+	capital, booked := addFloored(&te.Capital, pnlCents)
+	if capital <= 0 {
+		if atomic.CompareAndSwapInt32(&te.blownUp, 0, 1) {
+			log.Printf("💥 BLOWN UP: capital exhausted")
+		}
+	}
+	atomic.AddInt64(&te.TotalPnL, booked)
+
+	peakCapital := storeMax(&te.PeakCapital, capital)
+	te.updateDrawdown(capital, peakCapital)
+	return capital
+}
+
+// addFloored adds delta to *addr, flooring the result at zero in the same
+// swap, and returns the new value and the part of delta applied
+func addFloored(addr *int64, delta int64) (int64, int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		next := cur + delta
+		if next < 0 {
+			next = 0
+		}
+		if atomic.CompareAndSwapInt64(addr, cur, next) {
+			return next, next - cur
+		}
+	}
+}
+
+// storeMax raises *addr to v unless it already holds more, and returns
+// what it holds afterwards
+func storeMax(addr *int64, v int64) int64 {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if v <= cur {
+			return cur
+		}
+		if atomic.CompareAndSwapInt64(addr, cur, v) {
+			return v
+		}
+	}
+}
+
+// BlownUp reports whether capital has been exhausted
+func (te *TradingEngine) BlownUp() bool {
+	return atomic.LoadInt32(&te.blownUp) == 1
+}
+
+// CheckEmergencyStops checks if emergency stops should be triggered
+func (te *TradingEngine) CheckEmergencyStops() bool {
+	snap := te.Snapshot()
+	currentCapital, peakCapital, consecutiveMisses := snap.Capital, snap.PeakCapital, snap.ConsecutiveMisses
+
+	// Check emergency stop (15% drawdown from peak)
+	if currentCapital < peakCapital*85/100 {
+		return te.emergencyStop("Capital dropped 15%% from peak")
+	}
+	// Configurable max drawdown
+	if te.MaxDrawdownPct > 0 {
+		threshold := int64(float64(peakCapital) * (1.0 - te.MaxDrawdownPct/100.0))
+		if currentCapital < threshold {
+			return te.emergencyStop("Configured drawdown hit: %.2f%%", te.MaxDrawdownPct)
+		}
+	}
+
+	// Check consecutive misses
+	if consecutiveMisses >= te.MaxConsecutiveMisses {
+		return te.emergencyStop("Too many consecutive misses: %d", consecutiveMisses)
+	}
+
+	// Daily loss limit: pause until the next UTC day unless configured to stop
+	if te.MaxDailyLossPct > 0 {
+		now := te.Clock.Now().UTC()
+		if loss := te.dailyLossPct(now, currentCapital); loss >= te.MaxDailyLossPct {
+			if te.DailyLossEndsCampaign {
+				return te.emergencyStop("Daily loss %.2f%% hit the %.2f%% limit", loss, te.MaxDailyLossPct)
+			}
+			te.dailyLossPauseUntil = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			log.Printf("⏸️ Daily loss %.2f%% hit the %.2f%% limit; pausing until %s",
+				loss, te.MaxDailyLossPct, te.dailyLossPauseUntil.Format(time.RFC3339))
+		}
+	}
+
+	return false
+}
+
+// emergencyStop logs and alerts an emergency stop; it always returns true so
+// CheckEmergencyStops can return it directly
+func (te *TradingEngine) emergencyStop(format string, args ...interface{}) bool {
+	msg := fmt.Sprintf(format, args...)
+	te.publish(EventEmergencyStop, nil, map[string]interface{}{"reason": msg})
+	te.alert(AlertEmergencyStop, "%s", msg)
+	return true
+}
+
+// dailyLossPct is the realized loss for now's UTC day as a percent of the
+// capital the day opened with; 0 when the day is flat or up
+func (te *TradingEngine) dailyLossPct(now time.Time, capital int64) float64 {
+	dayPnL := te.pnlRollups.DayPnL(now)
+	if dayPnL >= 0 {
+		return 0
+	}
+	opening := Money(capital).Dollars() - dayPnL
+	if opening <= 0 {
+		return 0
+	}
+	return -dayPnL / opening * 100.0
+}
+
+// tradeLimitLabel is the trade-count denominator for progress logs
+func (te *TradingEngine) tradeLimitLabel() string {
+	if te.InfiniteTrades {
+		return "∞"
+	}
+	return strconv.Itoa(TotalTrades)
+}
+
+// ExecuteCampaign runs the full trading campaign and reports how it ended.
+// Cancelling ctx abandons pending analysis, orders and waits, but a live
+// position is still exited and flattened before it returns.
+func (te *TradingEngine) ExecuteCampaign(ctx context.Context) *report.CampaignResult {
+	if te.InfiniteTrades {
+		log.Printf("🎯 MACRO STRIKE CAMPAIGN INITIATED - UNBOUNDED")
+	} else {
+		log.Printf("🎯 MACRO STRIKE CAMPAIGN INITIATED - %d TRADES", TotalTrades)
+	}
+	log.Printf("Target: %v in 5 days", Money(te.TargetCapital))
+	log.Printf("Total Trades: %s", te.tradeLimitLabel())
+	log.Printf("Strike Force: %.1f%% per strike", StrikeForce*100.0)
+	log.Printf("🎲 RAND_SEED=%d (set it to replay this run)", te.RandSeed)
+
+	startTime := te.Clock.Now()
+	isSim := te.SimMode
+	if te.journal != nil {
+		te.journal.StartCampaign(te.RunID, te.CampaignStart, te.configSnapshot())
+	}
+	if te.LiveTrading && te.HTTPWarmup {
+		te.warmupHTTP(ctx)
+	}
+	if te.WatchdogStall > 0 {
+		stop := te.startWatchdog()
+		defer stop()
+	}
+	te.reconcileOrderWAL(ctx)
+	if te.Resumed {
+		// Positions in flight when the previous process died must not be forgotten
+		te.flattenOpenPositions(ctx, "Resume reconciliation")
+	}
+	if !te.Resumed || te.StartCapital <= 0 {
+		// State saved before StartCapital was persisted resumes from its current capital
+		te.StartCapital = atomic.LoadInt64(&te.Capital)
+	}
+	startCapital := Money(te.StartCapital).Dollars()
+	tracker := newCampaignTracker(startCapital)
+	if te.Resumed {
+		// Drawdowns are measured from the pre-restart peak, not the resume capital
+		var returns trackerState
+		if te.resumedReturns != nil {
+			returns = *te.resumedReturns
+		}
+		tracker.resumeFrom(Money(atomic.LoadInt64(&te.PeakCapital)).Dollars(), te.Drawdown().MaxDrawdownPct/100.0, returns)
+	}
+	te.tracker = tracker
+	if !te.statsRestored {
+		te.campaignStats = report.NewCampaignStats(startTime, Money(atomic.LoadInt64(&te.Capital)).Dollars())
+	}
+	stopReason := report.StopTradesCompleted
+
+	// A resumed campaign may already be past its limits before the first trade
+	halted := te.Resumed && te.CheckEmergencyStops()
+	if halted {
+		stopReason = report.StopEmergency
+	}
+	// settle books an executed strike's outcome and returns a stop reason
+	// when it ends the campaign
+	settle := func(strike *MacroStrike, pnl float64, err error) string {
+		if err != nil {
+			te.transition(strike, Aborted, strike.EntryPrice, err.Error())
+			te.metrics.StrikeResolved(strike)
+			te.recordExecutedStrike(strike)
+			// A live entry may already be journaled as striking; close its row out
+			te.journalStrike(strike)
+			te.countersMu.Lock()
+			atomic.AddInt64(&te.AbortedStrikes, 1)
+			te.countersMu.Unlock()
+			te.publish(EventStrikeClosed, strike, map[string]interface{}{
+				"status":  strike.Status.String(),
+				"error":   err.Error(),
+				"capital": Money(atomic.LoadInt64(&te.Capital)).Dollars(),
+			})
+			te.debugf("strike %d timeline:\n%s", strike.ID, strike.TransitionLog())
+			return ""
+		}
+
+		te.countersMu.Lock()
+		tradesDone := atomic.AddInt64(&te.TradesCompleted, 1)
+		te.countersMu.Unlock()
+		te.recordLevelOutcome(strike)
+		if tradesDone%te.StateSnapshotEvery == 0 {
+			if te.StateFile != "" {
+				if err := te.SaveState(); err != nil {
+					log.Printf("⚠️ State snapshot failed: %v", err)
+				}
+			}
+			te.savePerformanceStore()
+		}
+
+		// The result itself was published when the strike closed
+		currentCapital := Money(atomic.LoadInt64(&te.Capital)).Dollars()
+		tracker.observe(pnl, currentCapital)
+
+		// Check emergency stops
+		if te.BlownUp() {
+			log.Printf("💥 Campaign stopped: account blown up")
+			return report.StopBankrupt
+		}
+		if te.CheckEmergencyStops() {
+			return report.StopEmergency
+		}
+
+		// Progress logging every ProgressLogEvery trades
+		if snap := te.Snapshot(); te.ProgressLogEvery > 0 && snap.TradesCompleted%te.ProgressLogEvery == 0 {
+			capital := Money(snap.Capital)
+			progress := (capital.Dollars() - startCapital) / startCapital
+			elapsed := te.Clock.Since(startTime).Seconds()
+			tradesPerSecond := float64(snap.TradesCompleted) / elapsed
+
+			log.Printf("Progress: %d/%s trades | Capital: %v | Progress: %.1f%% | Rate: %.1f trades/sec | Win rate: %.1f%%",
+				snap.TradesCompleted, te.tradeLimitLabel(), capital, progress*100.0, tradesPerSecond, snap.WinRate*100.0)
+			log.Printf("Pace: %s", te.Project().PaceSummary())
+		}
+		return ""
+	}
+
+	// Positions still open count toward the trade limit
+	for !halted && (te.InfiniteTrades || atomic.LoadInt64(&te.TradesCompleted)+int64(te.positions.Pending()) < TotalTrades) {
+		te.beat()
+		// Collect managed exits, waiting for one when every position slot is taken
+		if reason := te.collectPositions(settle); reason != "" {
+			stopReason = reason
+			break
+		}
+		// Campaign stop: shutdown requested (signal or Stop)
+		if te.stopRequested() {
+			log.Printf("🛑 Campaign stopped: shutdown requested")
+			stopReason = te.shutdownStopReason()
+			break
+		}
+		// Campaign stop: the caller cancelled ctx
+		if ctx.Err() != nil {
+			log.Printf("🛑 Campaign stopped: %v", context.Cause(ctx))
+			stopReason = report.StopCanceled
+			break
+		}
+		// Campaign stop: bankruptcy is terminal
+		if te.BlownUp() {
+			log.Printf("💥 Campaign stopped: account blown up")
+			stopReason = report.StopBankrupt
+			break
+		}
+		// Campaign stop: too little capital left for meaningful order sizes
+		if capital := atomic.LoadInt64(&te.Capital); capital < te.MinTradingCapital {
+			log.Printf("🪫 Capital floor reached: %v < %v", Money(capital), Money(te.MinTradingCapital))
+			stopReason = report.StopCapitalFloor
+			break
+		}
+		// Campaign stop: time window (skip in simulation)
+		if !isSim && te.campaignElapsed() > time.Duration(te.CampaignDays)*24*time.Hour {
+			log.Printf("⏱️ Campaign window ended: %d days", te.CampaignDays)
+			stopReason = report.StopCampaignWindow
+			break
+		}
+		// Campaign stop: target capital reached (skip in simulation)
+		if !isSim && atomic.LoadInt64(&te.Capital) >= te.TargetCapital {
+			log.Printf("🎉 Target capital reached: %v", Money(te.TargetCapital))
+			stopReason = report.StopTargetReached
+			break
+		}
+
+		// Daily loss pause: wait out the rest of the UTC day, then re-check the stops
+		if !te.dailyLossPauseUntil.IsZero() {
+			if wait := te.dailyLossPauseUntil.Sub(te.Clock.Now()); wait > 0 {
+				te.sleepUnlessStopped(ctx, wait)
+			}
+			te.dailyLossPauseUntil = time.Time{}
+			log.Printf("▶️ New trading day; daily loss limit reset")
+			continue
+		}
+
+		// Paused: hold off on new strikes until Resume
+		if paused, _ := te.Paused(); paused {
+			te.sleepUnlessStopped(ctx, pausePollInterval)
+			continue
+		}
+
+		// Generate and execute strike (skip low-quality setups quietly)
+		strike, err := te.GenerateStrike(ctx)
+		if err != nil {
+			if errors.Is(err, ErrNoMoreStrikes) {
+				log.Printf("Strike generator exhausted")
+				stopReason = report.StopGeneratorExhausted
+				break
+			}
+			if errors.Is(err, ErrAnalyzerMissing) {
+				log.Printf("🛑 Campaign stopped: %v", err)
+				stopReason = report.StopAnalyzerMissing
+				break
+			}
+			if errors.Is(err, ErrAnalysisNonFinite) {
+				log.Printf("🛑 Campaign stopped: %v", err)
+				stopReason = report.StopNonFiniteAnalysis
+				break
+			}
+			if strings.HasPrefix(err.Error(), "skip:") {
+				te.recordSkip(err)
+				te.StrikeLog.LogSkip(err, Money(atomic.LoadInt64(&te.Capital)).Dollars())
+				// Try next setup without logging noise
+				clock.SleepContext(ctx, te.Clock, time.Duration(StrikeCooldownMs) * time.Millisecond)
+				continue
+			}
+			log.Printf("Error generating strike: %v", err)
+			continue
+		}
+		// A graceful stop still runs the setup it interrupted; a kill opens nothing more
+		if te.killRequested() {
+			log.Printf("🛑 Campaign stopped: kill switch")
+			stopReason = report.StopKilled
+			break
+		}
+
+		pnl, err := te.ExecuteStrike(ctx, strike)
+		var skip *skipError
+		if errors.As(err, &skip) {
+			// Turned away before any order filled: a skip, not a failed trade
+			te.recordSkip(err)
+			te.StrikeLog.LogSkip(err, Money(atomic.LoadInt64(&te.Capital)).Dollars())
+			clock.SleepContext(ctx, te.Clock, time.Duration(StrikeCooldownMs) * time.Millisecond)
+			continue
+		}
+		if errors.Is(err, ErrPositionManaged) {
+			// Filled; the position manager reports the exit once it lands
+			clock.SleepContext(ctx, te.Clock, time.Duration(StrikeCooldownMs) * time.Millisecond)
+			continue
+		}
+		if reason := settle(strike, pnl, err); reason != "" {
+			stopReason = reason
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		// Minimal cooldown
+		clock.SleepContext(ctx, te.Clock, time.Duration(StrikeCooldownMs) * time.Millisecond)
+	}
+
+	// Shutdown waits for managed positions to exit before anything is flattened
+	if n := te.positions.Pending(); n > 0 {
+		log.Printf("⏳ Waiting for %d open position(s) to exit", n)
+	}
+	for te.positions.Pending() > 0 {
+		r := te.positions.Next()
+		if reason := settle(r.Strike, r.PnL, r.Err); reason != "" && stopReason == report.StopTradesCompleted {
+			stopReason = reason
+		}
+	}
+
+	// Make sure no live exposure outlives the campaign, cancelled or not
+	settleCtx := context.WithoutCancel(ctx)
+	te.flattenOpenPositions(settleCtx, "Campaign-end flatten")
+	if err := te.SaveState(); err != nil {
+		log.Printf("⚠️ State snapshot failed: %v", err)
+	}
+
+	// Campaign complete
+	finalCapital := Money(atomic.LoadInt64(&te.Capital)).Dollars()
+	finalReturn := (finalCapital - startCapital) / startCapital
+	totalTime := te.Clock.Since(startTime)
+	tradesCompleted := atomic.LoadInt64(&te.TradesCompleted)
+
+	totalFees := Money(atomic.LoadInt64(&te.TotalFeesPaid)).Dollars()
+	if te.journal != nil {
+		te.journal.FinishCampaign(te.RunID, te.Clock.Now(), CampaignSummary{
+			FinalCapital:      finalCapital,
+			TotalPnL:          Money(atomic.LoadInt64(&te.TotalPnL)).Dollars(),
+			TradesCompleted:   tradesCompleted,
+			SuccessfulStrikes: atomic.LoadInt64(&te.SuccessfulStrikes),
+			FailedStrikes:     atomic.LoadInt64(&te.FailedStrikes),
+		})
+	}
+	result := &report.CampaignResult{
+		RunID:           te.RunID,
+		StartCapital:    startCapital,
+		FinalCapital:    finalCapital,
+		ReturnPct:       finalReturn * 100.0,
+		TradesCompleted: tradesCompleted,
+		Wins:            atomic.LoadInt64(&te.SuccessfulStrikes),
+		Losses:          atomic.LoadInt64(&te.FailedStrikes),
+		Aborted:         atomic.LoadInt64(&te.AbortedStrikes),
+		MaxDrawdownPct:  tracker.maxDD * 100.0,
+		Sharpe:          tracker.sharpe(),
+		TotalFees:       totalFees,
+		Elapsed:         totalTime,
+		StopReason:      stopReason,
+		StageLatency:    te.stageLatency.Stats(),
+	}
+	te.publish(EventCampaignFinished, nil, map[string]interface{}{
+		"result":      result,
+		"trade_limit": te.tradeLimitLabel(),
+		"levels":      te.LevelStats(),
+	})
+	te.alert(AlertCampaignComplete, "campaign ended (%s): $%.2f -> $%.2f (%.2f%%), %d trades, max drawdown %.2f%%",
+		result.StopReason, result.StartCapital, result.FinalCapital, result.ReturnPct, result.TradesCompleted, result.MaxDrawdownPct)
+	te.logSkipSummary()
+	te.writeReports(result)
+	te.emailCampaignReport(result)
+	te.writeRealizedGains(settleCtx)
+	te.writeEquityParquet()
+	if te.StrikesJSONPath != "" {
+		if err := te.WriteStrikesJSON(te.StrikesJSONPath); err != nil {
+			log.Printf("⚠️ Strikes JSON export failed: %v", err)
+		} else {
+			log.Printf("📄 Strikes JSON written to %s", te.StrikesJSONPath)
+		}
+	}
+	te.uploadArtifacts("campaign end", false)
+	te.campaignDoneOnce.Do(func() { close(te.campaignDone) })
+	return result
+}
+
+// LevelSourceStats compares outcomes of analyst-supplied vs formulaic levels
+type LevelSourceStats struct {
+	Strikes int64   `json:"strikes"`
+	Hits    int64   `json:"hits"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// recordLevelOutcome tallies a completed strike under its level source
+func (te *TradingEngine) recordLevelOutcome(strike *MacroStrike) {
+	source := strike.LevelSource
+	if source == "" {
+		source = LevelSourceFormula
+	}
+	te.levelStatsMu.Lock()
+	defer te.levelStatsMu.Unlock()
+	st, ok := te.levelStats[source]
+	if !ok {
+		st = &LevelSourceStats{}
+		te.levelStats[source] = st
+	}
+	st.Strikes++
+	if strike.Status == Hit {
+		st.Hits++
+	}
+}
+
+// LevelStats returns per-source hit rates for completed strikes
+func (te *TradingEngine) LevelStats() map[string]LevelSourceStats {
+	te.levelStatsMu.Lock()
+	defer te.levelStatsMu.Unlock()
+	out := make(map[string]LevelSourceStats, len(te.levelStats))
+	for source, st := range te.levelStats {
+		c := *st
+		if c.Strikes > 0 {
+			c.HitRate = float64(c.Hits) / float64(c.Strikes)
+		}
+		out[source] = c
+	}
+	return out
+}
+
+// trackPosition records a filled live entry as open exposure
+func (te *TradingEngine) trackPosition(strikeID uint64, pair string, volume float64, entryTx string) *openPosition {
+	pos := &openPosition{StrikeID: strikeID, Pair: pair, Volume: volume, EntryTx: entryTx}
+	te.positionsMu.Lock()
+	te.openPositions[strikeID] = pos
+	te.positionsMu.Unlock()
+	return pos
+}
+
+// releasePosition marks a strike's live exposure as flat
+func (te *TradingEngine) releasePosition(strikeID uint64) {
+	te.positionsMu.Lock()
+	delete(te.openPositions, strikeID)
+	te.positionsMu.Unlock()
+	te.releaseNotional(strikeID)
+	te.orderWAL.Resolved(strikeID)
+}
+
+// orderStatus returns the exchange status and executed volume for an order
+func (te *TradingEngine) orderStatus(ctx context.Context, txid string) (string, float64, error) {
+	ord, err := te.exchange().GetOrder(ctx, txid)
+	if err != nil {
+		return "", 0, err
+	}
+	return ord.Status, ord.VolExec, nil
+}
+
+// flattenOpenPositions reconciles lingering live exposure (at campaign end or
+// after a resume), selling whatever part of each position has not been
+// confirmed exited. A resting exit is cancelled before anything is sold, and
+// a position whose exit cannot be queried stays tracked rather than being
+// sold blind. when labels the log lines.
+func (te *TradingEngine) flattenOpenPositions(ctx context.Context, when string) {
+	if !te.LiveTrading {
+		return
+	}
+	te.positionsMu.Lock()
+	positions := make([]*openPosition, 0, len(te.openPositions))
+	for _, pos := range te.openPositions {
+		positions = append(positions, pos)
+	}
+	te.positionsMu.Unlock()
+
+	if len(positions) == 0 {
+		log.Printf("✅ %s: no lingering positions", when)
+		return
+	}
+
+	flattened := 0
+	for _, pos := range positions {
+		remaining := pos.Volume
+		if pos.ExitTx != "" {
+			status, volExec, err := te.orderStatus(ctx, pos.ExitTx)
+			if err == nil && (status == "open" || status == "pending") {
+				// A resting exit could still fill alongside a flatten sale; cancel it and re-read what it executed
+				if cerr := te.exchange().CancelOrder(ctx, pos.ExitTx); cerr != nil {
+					log.Printf("⚠️ Cancel of exit %s for strike %d failed: %v", pos.ExitTx, pos.StrikeID, cerr)
+				}
+				status, volExec, err = te.orderStatus(ctx, pos.ExitTx)
+			}
+			if err != nil {
+				// The exit may already have filled; selling blind could sell the position twice
+				log.Printf("🚨 FLATTEN SKIPPED: could not query exit %s for strike %d: %v; %s %.8f left tracked, check it manually",
+					pos.ExitTx, pos.StrikeID, err, pos.Pair, pos.Volume)
+				continue
+			}
+			switch status {
+			case "closed":
+				log.Printf("Exit %s for strike %d confirmed closed", pos.ExitTx, pos.StrikeID)
+				te.releasePosition(pos.StrikeID)
+				continue
+			case "open", "pending":
+				log.Printf("🚨 FLATTEN SKIPPED: exit %s for strike %d is still resting after cancel; %s %.8f left tracked, check it manually",
+					pos.ExitTx, pos.StrikeID, pos.Pair, pos.Volume)
+				continue
+			}
+			remaining -= volExec
+		}
+		if remaining <= 0 {
+			te.releasePosition(pos.StrikeID)
+			continue
+		}
+		te.orderWAL.Intent(pos.StrikeID, pos.Pair, "sell", remaining)
+		txid, err := te.exchange().PlaceMarketExit(ctx, pos.Pair, remaining)
+		if err != nil {
+			log.Printf("🚨 FLATTEN FAILED: %s %.8f for strike %d: %v", pos.Pair, remaining, pos.StrikeID, err)
+			te.alert(AlertExitFailed, "flatten of %s %.8f for strike %d failed: %v", pos.Pair, remaining, pos.StrikeID, err)
+			continue
+		}
+		te.orderPlaced(pos.StrikeID, pos.Pair, "sell", txid)
+		log.Printf("FLATTEN: %s sold %.8f for strike %d (txid=%s)", pos.Pair, remaining, pos.StrikeID, txid)
+		te.disposeFlattened(ctx, pos.Pair, remaining, txid)
+		te.releasePosition(pos.StrikeID)
+		flattened++
+	}
+	log.Printf("%s: %d lingering position(s), %d flattened", when, len(positions), flattened)
+}
+
+// disposeFlattened books a flatten sale against the lot ledger, pricing it from
+// the order or, if it has not reported yet, the last trade. It returns the
+// sale price, 0 when none was found, and the fee.
+func (te *TradingEngine) disposeFlattened(ctx context.Context, pair string, volume float64, txid string) (float64, float64) {
+	ex := te.exchange()
+	ord, err := ex.GetOrder(ctx, txid)
+	price := ord.Price
+	if err != nil || price <= 0 {
+		if last, terr := ex.GetTicker(ctx, pair); terr == nil {
+			price = last
+		}
+	}
+	if price <= 0 {
+		log.Printf("⚠️ No price for flatten %s; realized gain recorded with zero proceeds", txid)
+	}
+	proceeds := price * volume
+	fee := ord.Fee
+	if fee <= 0 {
+		fee = proceeds * RoundTripFeePct / 2.0
+	}
+	te.lotLedger.Dispose(te.pairAsset(pair), volume, proceeds, fee, te.Clock.Now())
+	return price, fee
+}
+
+// parseStrikeTypeWeights parses "MacroFlash=0,MacroMomentum=2" into a full
+// weight table. Types not mentioned keep a weight of 1; 0 disables a type.
+func parseStrikeTypeWeights(raw string) (map[StrikeType]float64, error) {
+	entries, err := config.ParseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[StrikeType]float64, numStrikeTypes)
+	for t := StrikeType(0); t < numStrikeTypes; t++ {
+		weights[t] = 1.0
+	}
+	for name, rawWeight := range entries {
+		t, ok := strikeTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown strike type %q", name)
+		}
+		w, err := strconv.ParseFloat(rawWeight, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", rawWeight, name)
+		}
+		weights[t] = w
+	}
+	return weights, nil
+}
+
+// strikeTypeByName maps a strike type name back to its StrikeType
+func strikeTypeByName(name string) (StrikeType, bool) {
+	for t := StrikeType(0); t < numStrikeTypes; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// nextStrikeType samples a strike type from the configured weights,
+// falling back to round-robin by strike ID when none are set
+func (te *TradingEngine) nextStrikeType(strikeID uint64, rng *rand.Rand) StrikeType {
+	if len(te.StrikeTypeWeights) == 0 {
+		return StrikeType(int(strikeID) % numStrikeTypes)
+	}
+	total := 0.0
+	for t := StrikeType(0); t < numStrikeTypes; t++ {
+		total += te.StrikeTypeWeights[t]
+	}
+	if total <= 0 {
+		return StrikeType(int(strikeID) % numStrikeTypes)
+	}
+	r := rng.Float64() * total
+	for t := StrikeType(0); t < numStrikeTypes; t++ {
+		w := te.StrikeTypeWeights[t]
+		if w <= 0 {
+			continue
+		}
+		if r < w {
+			return t
+		}
+		r -= w
+	}
+	// Floating-point remainder: return the last enabled type
+	for t := StrikeType(numStrikeTypes - 1); t >= 0; t-- {
+		if te.StrikeTypeWeights[t] > 0 {
+			return t
+		}
+	}
+	return MacroArbitrage
+}
+
+// getExpectedReturn returns the expected return for a strike type
+func (te *TradingEngine) getExpectedReturn(strikeType StrikeType) float64 {
+	switch strikeType {
+	case MacroArbitrage:
+		return 0.005
+	case MacroMomentum:
+		return 0.022
+	case MacroVolatility:
+		return 0.032
+	case MacroLiquidity:
+		return 0.035
+	case MacroFunding:
+		return 0.042
+	case MacroFlash:
+		return 0.059
+	default:
+		return 0.01
+	}
+}
+
+// simHoldScale stretches or shortens SimMinHoldMs by how long each strike
+// type typically stays in the market
+func simHoldScale(strikeType StrikeType) float64 {
+	switch strikeType {
+	case MacroFlash:
+		return 0.25
+	case MacroArbitrage:
+		return 0.5
+	case MacroMomentum, MacroVolatility:
+		return 1.0
+	case MacroLiquidity:
+		return 1.5
+	case MacroFunding:
+		return 3.0
+	default:
+		return 1.0
+	}
+}
@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dashboard layout and refresh
+const (
+	tuiRefresh     = 500 * time.Millisecond
+	tuiTradeRows   = 10
+	tuiSparkPoints = 60
+	tuiLogRows     = 6
+	tuiLogKeep     = 40
+)
+
+// ANSI sequences the dashboard draws with
+const (
+	ansiHome       = "\x1b[H\x1b[2J"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiGreen      = "\x1b[32m"
+	ansiRed        = "\x1b[31m"
+	ansiBold       = "\x1b[1m"
+	ansiDim        = "\x1b[2m"
+	ansiReset      = "\x1b[0m"
+)
+
+// Dashboard redraws a live campaign view from the engine's event bus. While
+// it runs, the standard logger is captured so log lines don't scroll over it;
+// the strike log, journal and export sinks write to their own files and are
+// unaffected.
+type Dashboard struct {
+	te  *TradingEngine
+	out io.Writer
+
+	mu      sync.Mutex
+	open    *dashStrike
+	trades  []dashTrade
+	capital []float64
+
+	logs    *logTail
+	prevLog io.Writer
+	sub     *eventSub
+	cancel  func()
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type dashStrike struct {
+	ID     uint64
+	Symbol string
+	Type   string
+	Since  time.Time
+}
+
+type dashTrade struct {
+	ID     uint64
+	Symbol string
+	Status string
+	PnL    float64
+}
+
+// StartDashboard takes over out and the standard logger until Stop
+func StartDashboard(te *TradingEngine, out io.Writer) *Dashboard {
+	d := newDashboard(te, out)
+	d.prevLog = log.Writer()
+	log.SetOutput(te.redactor.Writer(d.logs))
+	fmt.Fprint(out, ansiHideCursor)
+	d.sub, d.cancel = te.events.Subscribe("tui", eventStreamBuffer)
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+func newDashboard(te *TradingEngine, out io.Writer) *Dashboard {
+	return &Dashboard{
+		te:      te,
+		out:     out,
+		capital: []float64{Money(atomic.LoadInt64(&te.Capital)).Dollars()},
+		logs:    &logTail{keep: tuiLogKeep},
+		stop:    make(chan struct{}),
+	}
+}
+
+func (d *Dashboard) run() {
+	defer d.wg.Done()
+	tick := time.NewTicker(tuiRefresh)
+	defer tick.Stop()
+	for {
+		select {
+		case e, ok := <-d.sub.ch:
+			if !ok {
+				return
+			}
+			d.handle(e)
+		case <-tick.C:
+			d.render()
+		case <-d.stop:
+			// Events published before Stop still belong on the final frame
+			for {
+				select {
+				case e, ok := <-d.sub.ch:
+					if !ok {
+						return
+					}
+					d.handle(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stop draws a final frame, hands the logger back and replays the log lines
+// captured at the end of the run, so the campaign summary stays visible
+func (d *Dashboard) Stop() {
+	// Lines still queued for the event log belong on the dashboard's tail
+	d.te.eventLog.Sync(eventLogDrainTimeout)
+	close(d.stop)
+	d.wg.Wait()
+	d.cancel()
+	d.render()
+	fmt.Fprint(d.out, ansiShowCursor)
+	log.SetOutput(d.prevLog)
+	for _, line := range d.logs.Lines(tuiLogKeep) {
+		fmt.Fprintln(d.prevLog, line)
+	}
+}
+
+// handle folds one event into the dashboard state
+func (d *Dashboard) handle(e Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch e.Type {
+	case EventStrikeGenerated:
+		typ, _ := e.Data["strike_type"].(string)
+		d.open = &dashStrike{ID: e.StrikeID, Symbol: e.Symbol, Type: typ, Since: e.Time}
+	case EventStrikeClosed:
+		if d.open != nil && d.open.ID == e.StrikeID {
+			d.open = nil
+		}
+		status, _ := e.Data["status"].(string)
+		pnl, _ := e.Data["pnl"].(float64)
+		d.trades = append(d.trades, dashTrade{ID: e.StrikeID, Symbol: e.Symbol, Status: status, PnL: pnl})
+		if len(d.trades) > tuiTradeRows {
+			d.trades = d.trades[len(d.trades)-tuiTradeRows:]
+		}
+		if capital, ok := e.Data["capital"].(float64); ok {
+			d.capital = append(d.capital, capital)
+			if len(d.capital) > tuiSparkPoints {
+				d.capital = d.capital[len(d.capital)-tuiSparkPoints:]
+			}
+		}
+	}
+}
+
+// render redraws the whole screen
+func (d *Dashboard) render() {
+	var b bytes.Buffer
+	d.frame(&b)
+	d.out.Write(b.Bytes())
+}
+
+func (d *Dashboard) frame(w io.Writer) {
+	te := d.te
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	capital, peak := atomic.LoadInt64(&te.Capital), atomic.LoadInt64(&te.PeakCapital)
+	current := 0.0
+	if peak > 0 && capital < peak {
+		current = float64(peak-capital) / float64(peak) * 100.0
+	}
+	fmt.Fprint(w, ansiHome)
+	fmt.Fprintf(w, "%sMacro Strike Bot%s  run %s  trades %d/%s", ansiBold, ansiReset, te.RunID,
+		atomic.LoadInt64(&te.TradesCompleted), te.tradeLimitLabel())
+	if paused, _ := te.Paused(); paused {
+		fmt.Fprintf(w, "  %sPAUSED%s", ansiRed, ansiReset)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Capital  %v  peak %v  drawdown %.2f%% (max %.2f%%)\n",
+		Money(capital), Money(peak), current, te.Drawdown().MaxDrawdownPct)
+	fmt.Fprintf(w, "Equity   %s\n\n", sparkline(d.capital))
+
+	if d.open != nil {
+		fmt.Fprintf(w, "Open     #%d %s %s  exposed %s\n\n", d.open.ID, d.open.Symbol, d.open.Type,
+			te.Clock.Since(d.open.Since).Round(time.Second))
+	} else {
+		fmt.Fprintf(w, "Open     %s-%s\n\n", ansiDim, ansiReset)
+	}
+
+	fmt.Fprintf(w, "%sLast trades%s\n", ansiBold, ansiReset)
+	if len(d.trades) == 0 {
+		fmt.Fprintf(w, "  %snone yet%s\n", ansiDim, ansiReset)
+	}
+	for i := len(d.trades) - 1; i >= 0; i-- {
+		t := d.trades[i]
+		color := ansiGreen
+		if t.PnL < 0 || t.Status == Miss.String() {
+			color = ansiRed
+		}
+		fmt.Fprintf(w, "  #%-6d %-10s %-5s %s%+10.2f%s\n", t.ID, t.Symbol, t.Status, color, t.PnL, ansiReset)
+	}
+
+	fmt.Fprintf(w, "\n%sSkips%s     %s\n", ansiBold, ansiReset, formatCounts(te.SkipCounts()))
+	fmt.Fprintf(w, "%sErrors%s    %s\n", ansiBold, ansiReset, formatCounts(te.metrics.KrakenErrorCounts()))
+	if n := te.events.Dropped(); n > 0 {
+		fmt.Fprintf(w, "%sdashboard missed %d events%s\n", ansiDim, n, ansiReset)
+	}
+
+	fmt.Fprintf(w, "\n%sLog%s\n", ansiBold, ansiReset)
+	for _, line := range d.logs.Lines(tuiLogRows) {
+		fmt.Fprintf(w, "  %s%s%s\n", ansiDim, line, ansiReset)
+	}
+}
+
+// sparkBlocks draw a sparkline from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline scales values between their min and max
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// formatCounts renders counters as "a=1 b=2", sorted by name
+func formatCounts(counts map[string]int64) string {
+	if len(counts) == 0 {
+		return ansiDim + "none" + ansiReset
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// logTail keeps the last lines written to it
+type logTail struct {
+	mu    sync.Mutex
+	keep  int
+	lines []string
+	part  []byte
+}
+
+func (t *logTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.part = append(t.part, p...)
+	for {
+		i := bytes.IndexByte(t.part, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, string(t.part[:i]))
+		t.part = t.part[i+1:]
+	}
+	if len(t.lines) > t.keep {
+		t.lines = append([]string(nil), t.lines[len(t.lines)-t.keep:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns up to the last n complete lines
+func (t *logTail) Lines(n int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.lines) > n {
+		return append([]string(nil), t.lines[len(t.lines)-n:]...)
+	}
+	return append([]string(nil), t.lines...)
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+)
+
+func TestDashboardShowsTradesSkipsAndRestoresLogs(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	te := NewTradingEngine()
+	te.Clock = clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Err: newSkip(SkipLowConfidence, "scripted skip")},
+		{Strike: certainStrike(2, false)},
+	}}
+
+	var logs, screen bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(prev)
+
+	dash := StartDashboard(te, &screen)
+	te.ExecuteCampaign(context.Background())
+	if logs.Len() != 0 {
+		t.Errorf("log lines escaped the dashboard: %q", logs.String())
+	}
+	dash.Stop()
+
+	frame := screen.String()
+	if i := strings.LastIndex(frame, ansiHome); i >= 0 {
+		frame = frame[i:]
+	}
+	for _, want := range []string{"#1", "#2", "WETH/USDC", ansiGreen, ansiRed, "low_confidence=1", "Errors" + ansiReset + "    " + ansiDim + "none"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("final frame missing %q:\n%s", want, frame)
+		}
+	}
+	if log.Writer() != &logs {
+		t.Error("Stop did not restore the logger")
+	}
+	if !strings.Contains(logs.String(), "CAMPAIGN COMPLETE") {
+		t.Errorf("campaign summary not replayed to the log after Stop: %q", logs.String())
+	}
+}
+
+func TestSparklineScalesToRange(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3}); got != "▁▄█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]float64{5, 5}); got != "▁▁" {
+		t.Errorf("flat sparkline = %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// watchdogMinInterval keeps short WATCHDOG_STALL settings from busy-checking
+const watchdogMinInterval = time.Second
+
+// watchdogStackLimit caps the goroutine dump attached to a stall report
+const watchdogStackLimit = 64 << 10
+
+// orderOp is the order operation a live strike is blocked on
+type orderOp struct {
+	StrikeID     uint64
+	What         string
+	TxID         string
+	Started      time.Time
+	LastProgress time.Time
+}
+
+// watchdogState tracks in-flight order work for the watchdog. A stall is
+// reported once; progress on the loop or the order re-arms the report.
+type watchdogState struct {
+	mu       sync.Mutex
+	op       *orderOp
+	reported bool
+	aborted  uint64
+}
+
+// orderProgress records that a live strike's order work moved on: what it is
+// doing now and the txid involved, if any
+func (te *TradingEngine) orderProgress(strikeID uint64, what, txid string) {
+	now := te.Clock.Now()
+	w := &te.watchdog
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.op == nil || w.op.StrikeID != strikeID || w.op.What != what || w.op.TxID != txid {
+		w.op = &orderOp{StrikeID: strikeID, What: what, TxID: txid, Started: now}
+	}
+	w.op.LastProgress = now
+	w.reported = false
+}
+
+// orderDone clears a strike's order work once it returns
+func (te *TradingEngine) orderDone(strikeID uint64) {
+	w := &te.watchdog
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.op != nil && w.op.StrikeID == strikeID {
+		w.op = nil
+	}
+}
+
+// stallAborted reports whether the watchdog has given up on a strike
+func (te *TradingEngine) stallAborted(strikeID uint64) bool {
+	return atomic.LoadUint64(&te.watchdog.aborted) == strikeID
+}
+
+// watchdogCheck reports a stall when neither the campaign loop nor the
+// in-flight order has progressed for WatchdogStall. It returns whether a
+// new stall was reported.
+func (te *TradingEngine) watchdogCheck() bool {
+	if te.WatchdogStall <= 0 {
+		return false
+	}
+	now := te.Clock.Now()
+	last := time.Time{}
+	if beat := atomic.LoadInt64(&te.loopHeartbeat); beat != 0 {
+		last = time.Unix(0, beat)
+	}
+	w := &te.watchdog
+	w.mu.Lock()
+	var op *orderOp
+	if w.op != nil {
+		c := *w.op
+		op = &c
+		if c.LastProgress.After(last) {
+			last = c.LastProgress
+		}
+	}
+	if last.IsZero() || now.Sub(last) < te.WatchdogStall || w.reported {
+		w.mu.Unlock()
+		return false
+	}
+	w.reported = true
+	w.mu.Unlock()
+
+	stalled := now.Sub(last).Round(time.Second)
+	log.Printf("🐕 WATCHDOG: no progress for %v\n%s", stalled, te.stallReport(now, op))
+	summary := fmt.Sprintf("no progress for %v", stalled)
+	if op != nil {
+		summary += fmt.Sprintf(" on strike %d: %s", op.StrikeID, op.What)
+		if op.TxID != "" {
+			summary += " " + op.TxID
+		}
+	}
+	te.alert(AlertStall, "%s", summary)
+	if te.WatchdogAbort && op != nil {
+		log.Printf("🐕 WATCHDOG: aborting strike %d once its %s call returns (WATCHDOG_ABORT=1)", op.StrikeID, op.What)
+		atomic.StoreUint64(&w.aborted, op.StrikeID)
+	}
+	return true
+}
+
+// stallReport describes what the engine was doing when it stalled, followed
+// by every goroutine's stack
+func (te *TradingEngine) stallReport(now time.Time, op *orderOp) string {
+	var b strings.Builder
+	if op != nil {
+		fmt.Fprintf(&b, "  order: strike %d %s", op.StrikeID, op.What)
+		if op.TxID != "" {
+			fmt.Fprintf(&b, " txid=%s", op.TxID)
+		}
+		fmt.Fprintf(&b, " for %v (last progress %v ago)\n", now.Sub(op.Started).Round(time.Second), now.Sub(op.LastProgress).Round(time.Second))
+	} else {
+		fmt.Fprintf(&b, "  order: none in flight\n")
+	}
+	if beat := atomic.LoadInt64(&te.loopHeartbeat); beat != 0 {
+		fmt.Fprintf(&b, "  loop: last heartbeat %v ago\n", now.Sub(time.Unix(0, beat)).Round(time.Second))
+	}
+	st := te.Status()
+	if st.OpenStrike != nil {
+		fmt.Fprintf(&b, "  strike: #%d %s %s %s since %s\n", st.OpenStrike.ID, st.OpenStrike.Symbol, st.OpenStrike.StrikeType,
+			st.OpenStrike.Status, st.OpenStrike.Since.Format(time.RFC3339))
+	}
+	te.positionsMu.Lock()
+	fmt.Fprintf(&b, "  capital: %v, %d trades, %d open positions\n",
+		Money(atomic.LoadInt64(&te.Capital)), atomic.LoadInt64(&te.TradesCompleted), len(te.openPositions))
+	te.positionsMu.Unlock()
+	buf := make([]byte, watchdogStackLimit)
+	buf = buf[:runtime.Stack(buf, true)]
+	fmt.Fprintf(&b, "  goroutines:\n%s", buf)
+	return b.String()
+}
+
+// startWatchdog checks for stalls in the background until the returned
+// stop func is called
+func (te *TradingEngine) startWatchdog() func() {
+	interval := te.WatchdogStall / 4
+	if interval < watchdogMinInterval {
+		interval = watchdogMinInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		tick := te.Clock.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C():
+				te.watchdogCheck()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
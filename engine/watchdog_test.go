@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/exchange"
+)
+
+// frozenExchange fills nothing: its first GetOrder hangs until released and
+// then fails, as a wedged connection would
+type frozenExchange struct {
+	entered chan struct{}
+	release chan struct{}
+	calls   int
+}
+
+func (e *frozenExchange) Name() string              { return "frozen" }
+func (e *frozenExchange) Pair(symbol string) string { return "ETHUSD" }
+func (e *frozenExchange) PlaceMarketOrder(ctx context.Context, pair, side string, usdSize, price float64) (string, error) {
+	return "BUY1", nil
+}
+func (e *frozenExchange) PlaceMarketExit(ctx context.Context, pair string, volume float64) (string, error) {
+	return "", errors.New("not expected")
+}
+func (e *frozenExchange) GetOrder(ctx context.Context, txid string) (exchange.OrderInfo, error) {
+	if e.calls++; e.calls == 1 {
+		close(e.entered)
+		<-e.release
+	}
+	return exchange.OrderInfo{}, errors.New("connection reset")
+}
+func (e *frozenExchange) CancelOrder(ctx context.Context, txid string) error          { return nil }
+func (e *frozenExchange) GetBalance(ctx context.Context) (map[string]float64, error)  { return nil, nil }
+func (e *frozenExchange) GetTicker(ctx context.Context, pair string) (float64, error) { return 0, nil }
+
+func TestWatchdogReportsAndAbortsFrozenPoll(t *testing.T) {
+	alerts := make(chan Alert, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var a Alert
+		json.Unmarshal(raw, &a)
+		alerts <- a
+	}))
+	defer srv.Close()
+
+	te := replayEngine(t)
+	ex := &frozenExchange{entered: make(chan struct{}), release: make(chan struct{})}
+	te.Exchange = ex
+	te.WatchdogStall = 30 * time.Second
+	te.WatchdogAbort = true
+	te.alerts = NewAlertNotifier(srv.URL, AlertFormatJSON, "", 0, srv.Client(), te.Clock, te.metrics)
+	defer te.alerts.Close(time.Second)
+	clock := te.Clock.(*clock.Fake)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := te.ExecuteStrike(context.Background(), certainStrike(1, true))
+		errs <- err
+	}()
+	<-ex.entered
+
+	clock.Advance(10 * time.Second)
+	if te.watchdogCheck() {
+		t.Error("stall reported after 10s of a 30s limit")
+	}
+	clock.Advance(25 * time.Second)
+	if !te.watchdogCheck() {
+		t.Fatal("no stall reported after 35s frozen")
+	}
+	if te.watchdogCheck() {
+		t.Error("the same stall was reported twice")
+	}
+	select {
+	case a := <-alerts:
+		if a.Kind != AlertStall || !strings.Contains(a.Message, "polling entry fill BUY1") {
+			t.Errorf("alert = %+v, want a stall naming the polled txid", a)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stall alert delivered")
+	}
+
+	close(ex.release)
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "aborted by watchdog") {
+		t.Errorf("ExecuteStrike = %v, want the strike aborted by the watchdog", err)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import "sync"
+
+// defaultWinRateAlpha weights the latest strike in the recent win rate; about
+// the last 1/alpha strikes dominate the average
+const defaultWinRateAlpha = 0.1
+
+// winRateEMA is an exponential moving average of the hit indicator, so
+// recent regime changes show up where the cumulative win rate hides them.
+// The first strike seeds it.
+type winRateEMA struct {
+	mu     sync.Mutex
+	alpha  float64
+	value  float64
+	seeded bool
+}
+
+func newWinRateEMA(alpha float64) *winRateEMA {
+	return &winRateEMA{alpha: alpha}
+}
+
+// Observe folds one completed strike into the average
+func (e *winRateEMA) Observe(hit bool) {
+	if e == nil {
+		return
+	}
+	x := 0.0
+	if hit {
+		x = 1.0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.seeded {
+		e.value, e.seeded = x, true
+		return
+	}
+	e.value += e.alpha * (x - e.value)
+}
+
+// Value returns the average and whether any strike has been observed
+func (e *winRateEMA) Value() (float64, bool) {
+	if e == nil {
+		return 0, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.value, e.seeded
+}
+
+// restore resumes the average from a saved value
+func (e *winRateEMA) restore(v float64) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.value, e.seeded = v, true
+	e.mu.Unlock()
+}
+
+// RecentWinRate is the exponentially weighted win rate over recent strikes,
+// 0 before the first completed strike
+func (te *TradingEngine) RecentWinRate() float64 {
+	v, _ := te.winRate.Value()
+	return v
+}
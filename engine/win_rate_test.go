@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestRecentWinRateWeightsLatestStrikes(t *testing.T) {
+	t.Setenv("SIM_MODE", "1")
+	t.Setenv("WIN_RATE_EMA_ALPHA", "0.5")
+	te := NewTradingEngine()
+	te.Generator = &scriptedStrikeGenerator{steps: []scriptedStrike{
+		{Strike: certainStrike(1, true)},
+		{Strike: certainStrike(2, true)},
+		{Strike: certainStrike(3, false)},
+		{Strike: certainStrike(4, false)},
+	}}
+	te.ExecuteCampaign(context.Background())
+
+	// Seeded at 1 by the first hit, then halved by each miss
+	if got := te.RecentWinRate(); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("recent win rate = %v, want 0.25", got)
+	}
+	if got := te.Stats().RecentWinRate; math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("/stats recent_win_rate = %v, want 0.25", got)
+	}
+
+	resumed := NewTradingEngine()
+	resumed.restoreState(te.captureState())
+	if got := resumed.RecentWinRate(); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("restored recent win rate = %v, want 0.25", got)
+	}
+}
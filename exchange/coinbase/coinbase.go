@@ -0,0 +1,365 @@
+// Package coinbase routes orders through the Coinbase Advanced Trade API. Its
+// Exchange satisfies exchange.Exchange, so the engine can trade on Coinbase
+// wherever it would otherwise use Kraken.
+package coinbase
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/exchange"
+)
+
+// APIURL is Coinbase's Advanced Trade API root
+const APIURL = "https://api.coinbase.com"
+
+// jwtLifetime is how long a signed request token stays valid
+const jwtLifetime = 2 * time.Minute
+
+// builtinProducts maps our symbols to Coinbase product IDs. The stablecoin
+// pairs have no Coinbase equivalent (USDC settles as USD there) and are only
+// tradable through COINBASE_PRODUCT_OVERRIDES.
+var builtinProducts = map[string]string{
+	"WETH/USDC": "ETH-USD",
+	"WBTC/USDC": "BTC-USD",
+	"LINK/USDC": "LINK-USD",
+	"UNI/USDC":  "UNI-USD",
+	"AAVE/USDC": "AAVE-USD",
+	"CRV/USDC":  "CRV-USD",
+}
+
+// Exchange trades through the Coinbase Advanced Trade API, signing
+// each request with a short-lived ES256 JWT from a CDP API key
+type Exchange struct {
+	// CDP key name ("organizations/{org}/apiKeys/{key}") and its EC private key
+	KeyName string
+	key     *ecdsa.PrivateKey
+	BaseURL string
+	// Symbol -> product ID entries taking precedence over the built-in products
+	Products map[string]string
+	client   *http.Client
+	clock    clock.Clock
+}
+
+// New builds a client for keyName, whose secret is the
+// PEM-encoded EC private key CDP issues. An empty baseURL uses production.
+func New(keyName, secretPEM, baseURL string, products map[string]string, client *http.Client, clock clock.Clock) (*Exchange, error) {
+	if keyName == "" || secretPEM == "" {
+		return nil, fmt.Errorf("coinbase credentials not set")
+	}
+	key, err := parseKey(secretPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coinbase secret: %v", err)
+	}
+	if baseURL == "" {
+		baseURL = APIURL
+	}
+	return &Exchange{
+		KeyName:  keyName,
+		key:      key,
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Products: products,
+		client:   client,
+		clock:    clock,
+	}, nil
+}
+
+// FromConfig reads COINBASE_API_KEY, COINBASE_API_SECRET,
+// COINBASE_API_URL and COINBASE_PRODUCT_OVERRIDES
+func FromConfig(cfg config.Config, client *http.Client, clock clock.Clock) (*Exchange, error) {
+	products, err := config.ParseKeyValueList(cfg.Get("COINBASE_PRODUCT_OVERRIDES"))
+	if err != nil {
+		return nil, fmt.Errorf("COINBASE_PRODUCT_OVERRIDES: %v", err)
+	}
+	return New(cfg.Get("COINBASE_API_KEY"), cfg.Get("COINBASE_API_SECRET"),
+		cfg.Get("COINBASE_API_URL"), products, client, clock)
+}
+
+// parseKey decodes a SEC1 or PKCS#8 EC private key. Keys pasted into
+// an env var often carry literal "\n" sequences instead of newlines.
+func parseKey(secretPEM string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(secretPEM, `\n`, "\n")))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an EC private key")
+	}
+	return key, nil
+}
+
+func (c *Exchange) Name() string { return exchange.Coinbase }
+
+func (c *Exchange) Pair(symbol string) string {
+	if product, ok := c.Products[symbol]; ok {
+		return product
+	}
+	return builtinProducts[symbol]
+}
+
+// jwt signs a token authorizing one request, bound to its method, host and path
+func (c *Exchange) jwt(method, path string) (string, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	now := c.clock.Now().Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": c.KeyName, "nonce": hex.EncodeToString(nonce)})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"sub": c.KeyName,
+		"iss": "cdp",
+		"nbf": now,
+		"exp": now + int64(jwtLifetime/time.Second),
+		"uri": method + " " + u.Host + path,
+	})
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signing))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// ES256 signatures are the fixed-width r||s pair, not ASN.1
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// do sends one request, signed unless public, and decodes the JSON reply into out
+func (c *Exchange) do(ctx context.Context, method, path string, query url.Values, body interface{}, public bool, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(raw)
+	}
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if !public {
+		token, err := c.jwt(method, path)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(raw, &apiErr)
+		return fmt.Errorf("coinbase %s %s: %s %s %s", method, path, resp.Status, apiErr.Error, apiErr.Message)
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// doWithRetry mirrors the engine's Kraken retry: three attempts with backoff
+func (c *Exchange) doWithRetry(ctx context.Context, method, path string, query url.Values, body interface{}, public bool, out interface{}) error {
+	var lastErr error
+	for i := 0; i < 3; i++ {
+		if lastErr = c.do(ctx, method, path, query, body, public, out); lastErr == nil {
+			return nil
+		}
+		if err := clock.SleepContext(ctx, c.clock, time.Duration(500*(i+1))*time.Millisecond); err != nil {
+			return err
+		}
+	}
+	return lastErr
+}
+
+// createOrder places a market IOC order. The client order ID is fixed across
+// retries, so Coinbase returns the original order instead of placing a second.
+func (c *Exchange) createOrder(ctx context.Context, product, side string, config map[string]string) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	body := map[string]interface{}{
+		"client_order_id":     hex.EncodeToString(id),
+		"product_id":          product,
+		"side":                strings.ToUpper(side),
+		"order_configuration": map[string]interface{}{"market_market_ioc": config},
+	}
+	var res struct {
+		Success         bool `json:"success"`
+		SuccessResponse struct {
+			OrderID string `json:"order_id"`
+		} `json:"success_response"`
+		ErrorResponse struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		} `json:"error_response"`
+	}
+	if err := c.doWithRetry(ctx, "POST", "/api/v3/brokerage/orders", nil, body, false, &res); err != nil {
+		return "", err
+	}
+	if !res.Success || res.SuccessResponse.OrderID == "" {
+		return "", fmt.Errorf("coinbase order rejected: %s %s", res.ErrorResponse.Error, res.ErrorResponse.Message)
+	}
+	return res.SuccessResponse.OrderID, nil
+}
+
+// PlaceMarketOrder spends usdSize of quote currency on a buy; a sell is sized
+// in base currency at the indicative price
+func (c *Exchange) PlaceMarketOrder(ctx context.Context, pair, side string, usdSize, price float64) (string, error) {
+	if usdSize <= 0 || price <= 0 {
+		return "", fmt.Errorf("invalid size/price")
+	}
+	if side == "buy" {
+		return c.createOrder(ctx, pair, side, map[string]string{"quote_size": strconv.FormatFloat(usdSize, 'f', 2, 64)})
+	}
+	return c.PlaceMarketExit(ctx, pair, usdSize/price)
+}
+
+func (c *Exchange) PlaceMarketExit(ctx context.Context, pair string, volume float64) (string, error) {
+	return c.createOrder(ctx, pair, "sell", map[string]string{"base_size": strconv.FormatFloat(volume, 'f', 8, 64)})
+}
+
+// orderStatuses maps Coinbase order statuses onto Kraken's
+var orderStatuses = map[string]string{
+	"PENDING":       exchange.OrderPending,
+	"QUEUED":        exchange.OrderPending,
+	"OPEN":          exchange.OrderOpen,
+	"CANCEL_QUEUED": exchange.OrderOpen,
+	"FILLED":        exchange.OrderClosed,
+	"CANCELLED":     exchange.OrderCanceled,
+	"FAILED":        exchange.OrderCanceled,
+	"EXPIRED":       exchange.OrderExpired,
+}
+
+func (c *Exchange) GetOrder(ctx context.Context, txid string) (exchange.OrderInfo, error) {
+	var res struct {
+		Order struct {
+			Status             string `json:"status"`
+			FilledSize         string `json:"filled_size"`
+			AverageFilledPrice string `json:"average_filled_price"`
+			TotalFees          string `json:"total_fees"`
+		} `json:"order"`
+	}
+	if err := c.doWithRetry(ctx, "GET", "/api/v3/brokerage/orders/historical/"+url.PathEscape(txid), nil, nil, false, &res); err != nil {
+		return exchange.OrderInfo{}, err
+	}
+	status, ok := orderStatuses[res.Order.Status]
+	if !ok {
+		return exchange.OrderInfo{}, fmt.Errorf("order %s has unknown coinbase status %q", txid, res.Order.Status)
+	}
+	return exchange.OrderInfo{
+		Status:  status,
+		VolExec: exchange.ParseNumericField(res.Order.FilledSize),
+		Price:   exchange.ParseNumericField(res.Order.AverageFilledPrice),
+		Fee:     exchange.ParseNumericField(res.Order.TotalFees),
+	}, nil
+}
+
+func (c *Exchange) CancelOrder(ctx context.Context, txid string) error {
+	var res struct {
+		Results []struct {
+			Success       bool   `json:"success"`
+			FailureReason string `json:"failure_reason"`
+		} `json:"results"`
+	}
+	if err := c.doWithRetry(ctx, "POST", "/api/v3/brokerage/orders/batch_cancel", nil, map[string][]string{"order_ids": {txid}}, false, &res); err != nil {
+		return err
+	}
+	if len(res.Results) == 0 || !res.Results[0].Success {
+		reason := "no result"
+		if len(res.Results) > 0 {
+			reason = res.Results[0].FailureReason
+		}
+		return fmt.Errorf("coinbase cancel of %s failed: %s", txid, reason)
+	}
+	return nil
+}
+
+// GetBalance sums available and held funds per currency across every page of accounts
+func (c *Exchange) GetBalance(ctx context.Context) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	query := url.Values{"limit": {"250"}}
+	for {
+		var res struct {
+			Accounts []struct {
+				Currency  string `json:"currency"`
+				Available struct {
+					Value string `json:"value"`
+				} `json:"available_balance"`
+				Hold struct {
+					Value string `json:"value"`
+				} `json:"hold"`
+			} `json:"accounts"`
+			HasNext bool   `json:"has_next"`
+			Cursor  string `json:"cursor"`
+		}
+		if err := c.doWithRetry(ctx, "GET", "/api/v3/brokerage/accounts", query, nil, false, &res); err != nil {
+			return nil, err
+		}
+		for _, a := range res.Accounts {
+			balances[a.Currency] += exchange.ParseNumericField(a.Available.Value) + exchange.ParseNumericField(a.Hold.Value)
+		}
+		if !res.HasNext || res.Cursor == "" {
+			return balances, nil
+		}
+		query.Set("cursor", res.Cursor)
+	}
+}
+
+// GetTicker reads the product's last price from the public market endpoint
+func (c *Exchange) GetTicker(ctx context.Context, pair string) (float64, error) {
+	var res struct {
+		Price string `json:"price"`
+	}
+	if err := c.doWithRetry(ctx, "GET", "/api/v3/brokerage/market/products/"+url.PathEscape(pair), nil, nil, true, &res); err != nil {
+		return 0, err
+	}
+	price := exchange.ParseNumericField(res.Price)
+	if price <= 0 {
+		return 0, fmt.Errorf("no ticker price for %s", pair)
+	}
+	return price, nil
+}
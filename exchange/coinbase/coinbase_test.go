@@ -0,0 +1,154 @@
+package coinbase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-strike-bot/clock"
+	"macro-strike-bot/config"
+	"macro-strike-bot/exchange"
+)
+
+// testKey returns a fresh CDP-style EC key and its PEM encoding
+func testKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+// verifyJWT checks a request's bearer token against the key and
+// returns its claims
+func verifyJWT(t *testing.T, key *ecdsa.PrivateKey, r *http.Request) map[string]interface{} {
+	t.Helper()
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("%s %s: malformed token %q", r.Method, r.URL.Path, token)
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if len(sig) != 64 || !ecdsa.Verify(&key.PublicKey, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Fatalf("%s %s: token signature does not verify", r.Method, r.URL.Path)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+// stub serves one strike's round trip: a buy filling 0.1 @ 2500 and a
+// sell filling @ 2550, with orders recorded in placed
+func stub(t *testing.T, key *ecdsa.PrivateKey, placed *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v3/brokerage/market/") {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("public endpoint %s was sent credentials", r.URL.Path)
+			}
+			io.WriteString(w, `{"price":"2510.5"}`)
+			return
+		}
+		claims := verifyJWT(t, key, r)
+		if want := r.Method + " " + r.Host + r.URL.Path; claims["uri"] != want || claims["sub"] != "organizations/o/apiKeys/k" {
+			t.Errorf("claims %v, want uri %q", claims, want)
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v3/brokerage/orders":
+			var order map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&order)
+			*placed = append(*placed, order)
+			id := "BUY-1"
+			if order["side"] == "SELL" {
+				id = "SELL-1"
+			}
+			io.WriteString(w, `{"success":true,"success_response":{"order_id":"`+id+`"}}`)
+		case r.URL.Path == "/api/v3/brokerage/orders/historical/BUY-1":
+			io.WriteString(w, `{"order":{"status":"FILLED","filled_size":"0.1","average_filled_price":"2500"}}`)
+		case r.URL.Path == "/api/v3/brokerage/orders/historical/SELL-1":
+			io.WriteString(w, `{"order":{"status":"FILLED","filled_size":"0.1","average_filled_price":"2550"}}`)
+		case r.URL.Path == "/api/v3/brokerage/accounts" && r.URL.Query().Get("cursor") == "":
+			io.WriteString(w, `{"accounts":[{"currency":"USD","available_balance":{"value":"90"},"hold":{"value":"10"}}],"has_next":true,"cursor":"p2"}`)
+		case r.URL.Path == "/api/v3/brokerage/accounts":
+			io.WriteString(w, `{"accounts":[{"currency":"ETH","available_balance":{"value":"0.5"},"hold":{"value":"0"}}],"has_next":false}`)
+		default:
+			http.Error(w, `{"error":"NOT_FOUND"}`, http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExchangeSignsOrdersAndPagesBalances(t *testing.T) {
+	key, secret := testKey(t)
+	var placed []map[string]interface{}
+	srv := stub(t, key, &placed)
+	defer srv.Close()
+
+	cb, err := New("organizations/o/apiKeys/k", secret, srv.URL, nil, srv.Client(), clock.NewFake(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if pair := cb.Pair("WETH/USDC"); pair != "ETH-USD" {
+		t.Fatalf("Pair = %q, want ETH-USD", pair)
+	}
+	txid, err := cb.PlaceMarketOrder(ctx, "ETH-USD", "buy", 250, 2500)
+	if err != nil || txid != "BUY-1" {
+		t.Fatalf("PlaceMarketOrder = %q, %v", txid, err)
+	}
+	conf := placed[0]["order_configuration"].(map[string]interface{})["market_market_ioc"].(map[string]interface{})
+	if placed[0]["product_id"] != "ETH-USD" || placed[0]["side"] != "BUY" || conf["quote_size"] != "250.00" {
+		t.Errorf("order = %v", placed[0])
+	}
+	info, err := cb.GetOrder(ctx, "BUY-1")
+	if err != nil || info != (exchange.OrderInfo{Status: exchange.OrderClosed, VolExec: 0.1, Price: 2500}) {
+		t.Errorf("GetOrder = %+v (err %v)", info, err)
+	}
+	balances, err := cb.GetBalance(ctx)
+	if err != nil || balances["USD"] != 100 || balances["ETH"] != 0.5 {
+		t.Errorf("balances = %v (err %v), want USD 100 and ETH 0.5 across both pages", balances, err)
+	}
+	if price, err := cb.GetTicker(ctx, "ETH-USD"); err != nil || price != 2510.5 {
+		t.Errorf("ticker = %v (err %v), want 2510.5", price, err)
+	}
+}
+
+func TestFromConfigReadsSettings(t *testing.T) {
+	_, secret := testKey(t)
+	cfg := config.Config{
+		"COINBASE_API_KEY":           "organizations/o/apiKeys/k",
+		"COINBASE_API_SECRET":        strings.ReplaceAll(secret, "\n", `\n`),
+		"COINBASE_PRODUCT_OVERRIDES": "USDC/USDT=USDT-USD",
+	}
+	cb, err := FromConfig(cfg, http.DefaultClient, clock.Real{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cb.BaseURL != APIURL || cb.Pair("USDC/USDT") != "USDT-USD" {
+		t.Errorf("base %s pair %q, want production and the override", cb.BaseURL, cb.Pair("USDC/USDT"))
+	}
+	delete(cfg, "COINBASE_API_SECRET")
+	if _, err := FromConfig(cfg, http.DefaultClient, clock.Real{}); err == nil || !strings.Contains(err.Error(), "credentials not set") {
+		t.Errorf("err = %v, want missing credentials", err)
+	}
+}
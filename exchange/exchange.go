@@ -0,0 +1,64 @@
+// Package exchange defines the venue interface live strikes are routed
+// through and the order vocabulary every venue maps onto. The venues
+// themselves live in the kraken and coinbase subpackages.
+package exchange
+
+import (
+	"context"
+	"strconv"
+)
+
+// Exchange is the venue live strikes are routed to. Pairs are the exchange's
+// own codes, resolved from our symbols with Pair.
+type Exchange interface {
+	Name() string
+	// Pair maps our symbol to the exchange's pair code, "" when it isn't listed
+	Pair(symbol string) string
+	// PlaceMarketOrder places a market order worth usdSize at the indicative price
+	PlaceMarketOrder(ctx context.Context, pair, side string, usdSize, price float64) (string, error)
+	// PlaceMarketExit sells volume of the pair's base asset at market
+	PlaceMarketExit(ctx context.Context, pair string, volume float64) (string, error)
+	GetOrder(ctx context.Context, txid string) (OrderInfo, error)
+	CancelOrder(ctx context.Context, txid string) error
+	// GetBalance returns the total holding of every asset, keyed by asset code
+	GetBalance(ctx context.Context) (map[string]float64, error)
+	// GetTicker returns the pair's last trade price
+	GetTicker(ctx context.Context, pair string) (float64, error)
+}
+
+// Order statuses, in Kraken's vocabulary; other exchanges map onto these
+const (
+	OrderPending  = "pending"
+	OrderOpen     = "open"
+	OrderClosed   = "closed"
+	OrderCanceled = "canceled"
+	OrderExpired  = "expired"
+)
+
+// OrderInfo is an order's state as reported by the exchange
+type OrderInfo struct {
+	Status  string
+	VolExec float64
+	// Average execution price, 0 until something fills
+	Price float64
+	// Fee charged so far in quote currency, 0 when the venue doesn't report it
+	Fee float64
+}
+
+// Supported EXCHANGE values
+const (
+	Kraken   = "kraken"
+	Coinbase = "coinbase"
+)
+
+// ParseNumericField reads an API number that may be encoded as a string
+func ParseNumericField(v interface{}) float64 {
+	switch n := v.(type) {
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,20 @@
+package exchange
+
+import "testing"
+
+func TestParseNumericField(t *testing.T) {
+	for _, tc := range []struct {
+		in   interface{}
+		want float64
+	}{
+		{"0.25", 0.25},
+		{2500.5, 2500.5},
+		{"", 0},
+		{nil, 0},
+		{true, 0},
+	} {
+		if got := ParseNumericField(tc.in); got != tc.want {
+			t.Errorf("ParseNumericField(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package kraken holds the parts of the Kraken REST client that don't depend
+// on the trading engine: pair names, request building and signing, and
+// response decoding. Tools that talk to Kraken directly can import it
+// without pulling in the bot.
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIURL is Kraken's production API root
+const APIURL = "https://api.kraken.com"
+
+// Sign returns the API-Sign header for a private request: an HMAC-SHA512,
+// keyed by the base64 secret, over path followed by SHA256(nonce+postData).
+// postData must already carry the nonce field.
+func Sign(path, nonce, postData, secret string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid kraken secret: %v", err)
+	}
+	sha := sha256.Sum256([]byte(nonce + postData))
+	mac := hmac.New(sha512.New, key)
+	mac.Write([]byte(path))
+	mac.Write(sha[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// DecodeResponse parses a Kraken JSON envelope, surfacing API errors
+func DecodeResponse(body []byte) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if errs, ok := out["error"].([]interface{}); ok && len(errs) > 0 {
+		return nil, fmt.Errorf("kraken error: %v", errs)
+	}
+	return out, nil
+}
+
+// USDPair is the built-in Kraken USD pair for a symbol, or "" when Kraken
+// doesn't list one
+func USDPair(symbol string) string {
+	switch symbol {
+	case "WETH/USDC":
+		return "ETHUSD"
+	case "WBTC/USDC":
+		return "XBTUSD"
+	case "LINK/USDC":
+		return "LINKUSD"
+	case "UNI/USDC":
+		return "UNIUSD"
+	case "AAVE/USDC":
+		return "AAVEUSD"
+	case "CRV/USDC":
+		return "CRVUSD"
+	case "USDC/USDT":
+		return "USDCUSD"
+	case "DAI/USDC":
+		return "DAIUSD"
+	default:
+		return ""
+	}
+}
+
+// NewPublicRequest builds a GET for a public endpoint under baseURL
+func NewPublicRequest(ctx context.Context, baseURL, path string, params url.Values) (*http.Request, error) {
+	u := baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+}
+
+// NewPrivateRequest builds a signed POST for a private endpoint. It sets the
+// nonce field on data before signing, so callers that log data afterwards see
+// exactly what was sent.
+func NewPrivateRequest(ctx context.Context, baseURL, path string, data url.Values, nonce, key, secret string) (*http.Request, error) {
+	data.Set("nonce", nonce)
+	postData := data.Encode()
+	signature, err := Sign(path, nonce, postData, secret)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+path, strings.NewReader(postData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("API-Key", key)
+	req.Header.Set("API-Sign", signature)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	return req, nil
+}
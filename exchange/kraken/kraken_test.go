@@ -0,0 +1,88 @@
+package kraken
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestSignMatchesKrakenExample checks Sign against the worked example in
+// Kraken's REST authentication docs
+func TestSignMatchesKrakenExample(t *testing.T) {
+	secret := "kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=="
+	postData := "nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25"
+	got, err := Sign("/0/private/AddOrder", "1616492376594", postData, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ=="
+	if got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestSignRejectsBadSecret(t *testing.T) {
+	if _, err := Sign("/0/private/Balance", "1", "nonce=1", "not base64!"); err == nil || !strings.Contains(err.Error(), "invalid kraken secret") {
+		t.Fatalf("err = %v, want invalid kraken secret", err)
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	out, err := DecodeResponse([]byte(`{"error":[],"result":{"status":"online"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["result"].(map[string]interface{})["status"] != "online" {
+		t.Fatalf("result = %v", out["result"])
+	}
+	if _, err := DecodeResponse([]byte(`{"error":["EGeneral:Invalid arguments"]}`)); err == nil || !strings.Contains(err.Error(), "EGeneral:Invalid arguments") {
+		t.Fatalf("err = %v, want the kraken error", err)
+	}
+	if _, err := DecodeResponse([]byte(`<html>`)); err == nil {
+		t.Fatal("want a parse error for a non-JSON body")
+	}
+}
+
+func TestNewPrivateRequestSignsWithNonce(t *testing.T) {
+	secret := "kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=="
+	data := url.Values{"pair": {"XBTUSD"}}
+	req, err := NewPrivateRequest(context.Background(), "https://api.example", "/0/private/Balance", data, "42", "key", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://api.example/0/private/Balance" {
+		t.Fatalf("request = %s %s", req.Method, req.URL)
+	}
+	if data.Get("nonce") != "42" {
+		t.Fatalf("nonce = %q, want it set on data", data.Get("nonce"))
+	}
+	body, _ := io.ReadAll(req.Body)
+	want, _ := Sign("/0/private/Balance", "42", string(body), secret)
+	if req.Header.Get("API-Key") != "key" || req.Header.Get("API-Sign") != want {
+		t.Fatalf("headers = %v", req.Header)
+	}
+	if _, err := NewPrivateRequest(context.Background(), "https://api.example", "/0/private/Balance", url.Values{}, "1", "key", "not base64!"); err == nil {
+		t.Fatal("want an error for a bad secret")
+	}
+}
+
+func TestNewPublicRequestEncodesParams(t *testing.T) {
+	req, err := NewPublicRequest(context.Background(), "https://api.example", "/0/public/Ticker", url.Values{"pair": {"XBTUSD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.URL.String() != "https://api.example/0/public/Ticker?pair=XBTUSD" {
+		t.Fatalf("request = %s %s", req.Method, req.URL)
+	}
+}
+
+func TestUSDPair(t *testing.T) {
+	if got := USDPair("WBTC/USDC"); got != "XBTUSD" {
+		t.Fatalf("USDPair(WBTC/USDC) = %q", got)
+	}
+	if got := USDPair("DOGE/USDC"); got != "" {
+		t.Fatalf("USDPair(DOGE/USDC) = %q, want none", got)
+	}
+}
@@ -100,7 +100,7 @@ echo ""
 echo -e "${YELLOW}🔨 Building Ferrari Go Engine...${NC}"
 echo -e "${WHITE}━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━${NC}"
 
-go build -o macro_strike_bot trading_engine.go
+go build -o macro_strike_bot ./cmd/msb
 
 if [ $? -eq 0 ]; then
     echo -e "${GREEN}✓ Ferrari engine built successfully!${NC}"
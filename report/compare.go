@@ -0,0 +1,315 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+// CompareThresholds decide which deltas are flagged as significant: a change
+// must exceed both the absolute and the relative threshold
+type CompareThresholds struct {
+	MinAbs float64
+	MinPct float64
+}
+
+// MetricDelta is one compared numeric field
+type MetricDelta struct {
+	Field       string   `json:"field"`
+	A           float64  `json:"a"`
+	B           float64  `json:"b"`
+	Delta       float64  `json:"delta"`
+	DeltaPct    *float64 `json:"delta_pct,omitempty"`
+	Significant bool     `json:"significant"`
+}
+
+// GroupComparison compares one per-symbol or per-strike-type row
+type GroupComparison struct {
+	Key     string        `json:"key"`
+	OnlyIn  string        `json:"only_in,omitempty"` // "a" or "b" when the group is missing from the other report
+	Flipped string        `json:"flipped,omitempty"` // "to_loss" or "to_profit" when PnL changed sign
+	Metrics []MetricDelta `json:"metrics,omitempty"`
+}
+
+// ConfigChange is a config key whose value differs between the runs
+type ConfigChange struct {
+	Key string      `json:"key"`
+	A   interface{} `json:"a"`
+	B   interface{} `json:"b"`
+}
+
+// ReportComparison is the diff of two campaign reports
+type ReportComparison struct {
+	SchemaA      int               `json:"schema_a"`
+	SchemaB      int               `json:"schema_b"`
+	Headline     []MetricDelta     `json:"headline"`
+	Drawdown     []MetricDelta     `json:"drawdown"`
+	BySymbol     []GroupComparison `json:"by_symbol"`
+	ByStrikeType []GroupComparison `json:"by_strike_type"`
+	Config       []ConfigChange    `json:"config"`
+}
+
+// LoadReportDocument reads a campaign report as a generic document so
+// reports from other schema versions can still be compared field by field
+func LoadReportDocument(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// CompareReports diffs two report documents, comparing only fields present
+// in both
+func CompareReports(a, b map[string]interface{}, th CompareThresholds) *ReportComparison {
+	cmp := &ReportComparison{
+		SchemaA: int(numberField(a, "schema_version")),
+		SchemaB: int(numberField(b, "schema_version")),
+	}
+	cmp.Headline = compareNumericFields(objectField(a, "result"), objectField(b, "result"), th)
+	cmp.Drawdown = compareDrawdownProfiles(a, b, th)
+	cmp.BySymbol = compareGroups(objectField(a, "by_symbol"), objectField(b, "by_symbol"), th)
+	cmp.ByStrikeType = compareGroups(objectField(a, "by_strike_type"), objectField(b, "by_strike_type"), th)
+
+	ca, cb := objectField(a, "config"), objectField(b, "config")
+	for _, k := range sortedKeys(ca) {
+		if vb, ok := cb[k]; ok && !reflect.DeepEqual(ca[k], vb) {
+			cmp.Config = append(cmp.Config, ConfigChange{Key: k, A: ca[k], B: vb})
+		}
+	}
+	return cmp
+}
+
+// compareNumericFields diffs every numeric field the two objects share
+func compareNumericFields(a, b map[string]interface{}, th CompareThresholds) []MetricDelta {
+	var out []MetricDelta
+	for _, k := range sortedKeys(a) {
+		va, okA := a[k].(float64)
+		vb, okB := b[k].(float64)
+		if okA && okB {
+			out = append(out, newMetricDelta(k, va, vb, th))
+		}
+	}
+	return out
+}
+
+func newMetricDelta(field string, a, b float64, th CompareThresholds) MetricDelta {
+	d := MetricDelta{Field: field, A: a, B: b, Delta: b - a}
+	significant := math.Abs(d.Delta) > th.MinAbs
+	if a != 0 {
+		pct := d.Delta / math.Abs(a) * 100
+		d.DeltaPct = &pct
+		significant = significant && math.Abs(pct) > th.MinPct
+	}
+	d.Significant = significant
+	return d
+}
+
+// compareGroups diffs per-group stats, noting groups present on one side only
+// and groups whose PnL changed sign
+func compareGroups(a, b map[string]interface{}, th CompareThresholds) []GroupComparison {
+	keys := sortedKeys(a)
+	for _, k := range sortedKeys(b) {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var out []GroupComparison
+	for _, k := range keys {
+		ga, okA := a[k].(map[string]interface{})
+		gb, okB := b[k].(map[string]interface{})
+		gc := GroupComparison{Key: k}
+		switch {
+		case okA && !okB:
+			gc.OnlyIn = "a"
+		case okB && !okA:
+			gc.OnlyIn = "b"
+		default:
+			gc.Metrics = compareNumericFields(ga, gb, th)
+			pa, hasA := ga["pnl"].(float64)
+			pb, hasB := gb["pnl"].(float64)
+			if hasA && hasB {
+				if pa > 0 && pb <= 0 {
+					gc.Flipped = "to_loss"
+				} else if pa <= 0 && pb > 0 {
+					gc.Flipped = "to_profit"
+				}
+			}
+		}
+		out = append(out, gc)
+	}
+	return out
+}
+
+// drawdownProfile summarises an equity curve's drawdowns
+type drawdownProfile struct {
+	MaxPct         float64
+	AvgPct         float64
+	LongestTrades  float64
+	Episodes       float64
+	TimeUnderwater float64 // fraction of points below the running peak
+}
+
+// equityDrawdownProfile walks capital values in trade order
+func equityDrawdownProfile(capital []float64) drawdownProfile {
+	var p drawdownProfile
+	if len(capital) == 0 {
+		return p
+	}
+	peak := capital[0]
+	var sum float64
+	var underwater, run int
+	for _, c := range capital {
+		if c >= peak {
+			peak = c
+			run = 0
+			continue
+		}
+		dd := 0.0
+		if peak > 0 {
+			dd = (peak - c) / peak * 100
+		}
+		if run == 0 {
+			p.Episodes++
+		}
+		run++
+		underwater++
+		sum += dd
+		p.MaxPct = math.Max(p.MaxPct, dd)
+		p.LongestTrades = math.Max(p.LongestTrades, float64(run))
+	}
+	if underwater > 0 {
+		p.AvgPct = sum / float64(underwater)
+	}
+	p.TimeUnderwater = float64(underwater) / float64(len(capital))
+	return p
+}
+
+// compareDrawdownProfiles derives drawdown profiles from both equity curves
+func compareDrawdownProfiles(a, b map[string]interface{}, th CompareThresholds) []MetricDelta {
+	ca, okA := equityCapital(a)
+	cb, okB := equityCapital(b)
+	if !okA || !okB {
+		return nil
+	}
+	pa, pb := equityDrawdownProfile(ca), equityDrawdownProfile(cb)
+	return []MetricDelta{
+		newMetricDelta("max_drawdown_pct", pa.MaxPct, pb.MaxPct, th),
+		newMetricDelta("avg_drawdown_pct", pa.AvgPct, pb.AvgPct, th),
+		newMetricDelta("drawdown_episodes", pa.Episodes, pb.Episodes, th),
+		newMetricDelta("longest_drawdown_trades", pa.LongestTrades, pb.LongestTrades, th),
+		newMetricDelta("time_underwater", pa.TimeUnderwater, pb.TimeUnderwater, th),
+	}
+}
+
+// equityCapital extracts the capital series from a report's equity curve
+func equityCapital(doc map[string]interface{}) ([]float64, bool) {
+	points, ok := doc["equity_curve"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	var out []float64
+	for _, p := range points {
+		if m, ok := p.(map[string]interface{}); ok {
+			if c, ok := m["capital"].(float64); ok {
+				out = append(out, c)
+			}
+		}
+	}
+	return out, true
+}
+
+func objectField(doc map[string]interface{}, key string) map[string]interface{} {
+	m, _ := doc[key].(map[string]interface{})
+	return m
+}
+
+func numberField(doc map[string]interface{}, key string) float64 {
+	f, _ := doc[key].(float64)
+	return f
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteText renders the comparison as side-by-side tables. Significant
+// deltas are marked with "*".
+func (c *ReportComparison) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if c.SchemaA != c.SchemaB {
+		fmt.Fprintf(tw, "note: schema versions differ (%d vs %d); only common fields compared\n\n", c.SchemaA, c.SchemaB)
+	}
+	writeMetricTable(tw, "HEADLINE", c.Headline)
+	writeMetricTable(tw, "DRAWDOWN PROFILE", c.Drawdown)
+	for _, section := range []struct {
+		title  string
+		groups []GroupComparison
+	}{{"BY SYMBOL", c.BySymbol}, {"BY STRIKE TYPE", c.ByStrikeType}} {
+		if len(section.groups) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\n", section.title)
+		for _, g := range section.groups {
+			switch {
+			case g.OnlyIn != "":
+				fmt.Fprintf(tw, "  %s: only in %s\n", g.Key, g.OnlyIn)
+				continue
+			case g.Flipped == "to_loss":
+				fmt.Fprintf(tw, "  %s: ⚠️ flipped from profitable to losing\n", g.Key)
+			case g.Flipped == "to_profit":
+				fmt.Fprintf(tw, "  %s: flipped from losing to profitable\n", g.Key)
+			default:
+				fmt.Fprintf(tw, "  %s\n", g.Key)
+			}
+			writeMetricRows(tw, "    ", g.Metrics)
+		}
+		fmt.Fprintln(tw)
+	}
+	if len(c.Config) > 0 {
+		fmt.Fprintln(tw, "CONFIG CHANGES")
+		for _, ch := range c.Config {
+			fmt.Fprintf(tw, "  %s\t%v\t→ %v\t\n", ch.Key, ch.A, ch.B)
+		}
+	}
+	return tw.Flush()
+}
+
+func writeMetricTable(w io.Writer, title string, rows []MetricDelta) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", title)
+	fmt.Fprintf(w, "  field\tA\tB\tdelta\tdelta %%\t\t\n")
+	writeMetricRows(w, "  ", rows)
+	fmt.Fprintln(w)
+}
+
+func writeMetricRows(w io.Writer, indent string, rows []MetricDelta) {
+	for _, r := range rows {
+		pct := "n/a"
+		if r.DeltaPct != nil {
+			pct = fmt.Sprintf("%+.2f%%", *r.DeltaPct)
+		}
+		mark := ""
+		if r.Significant {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s%s\t%.4g\t%.4g\t%+.4g\t%s\t%s\t\n", indent, r.Field, r.A, r.B, r.Delta, pct, mark)
+	}
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareReportsFlagsDeltasAndFlips(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	a := &CampaignReport{
+		SchemaVersion: SchemaVersion,
+		Result:        &CampaignResult{RunID: "a", FinalCapital: 1100, ReturnPct: 10, Sharpe: 1.5, MaxDrawdownPct: 2},
+		Config:        map[string]interface{}{"order_risk_pct": 0.01, "campaign_days": 5},
+		BySymbol: map[string]GroupStats{
+			"WETH/USDC": {Strikes: 10, Wins: 7, PnL: 80},
+			"WBTC/USDC": {Strikes: 10, Wins: 6, PnL: 20},
+			"LINK/USDC": {Strikes: 4, Wins: 1, PnL: -5},
+		},
+		EquityCurve: []EquityPoint{{0, start, 1000}, {1, start, 1050}, {2, start, 1030}, {3, start, 1100}},
+	}
+	b := &CampaignReport{
+		SchemaVersion: SchemaVersion,
+		Result:        &CampaignResult{RunID: "b", FinalCapital: 1101, ReturnPct: 10.1, Sharpe: 0.9, MaxDrawdownPct: 6},
+		Config:        map[string]interface{}{"order_risk_pct": 0.02, "campaign_days": 5},
+		BySymbol: map[string]GroupStats{
+			"WETH/USDC": {Strikes: 10, Wins: 7, PnL: 81},
+			"WBTC/USDC": {Strikes: 10, Wins: 3, PnL: -15},
+			"UNI/USDC":  {Strikes: 2, Wins: 2, PnL: 4},
+		},
+		EquityCurve: []EquityPoint{{0, start, 1000}, {1, start, 1050}, {2, start, 990}, {3, start, 1000}, {4, start, 1101}},
+	}
+	cmp := CompareReports(reportDoc(t, a), reportDoc(t, b), CompareThresholds{MinPct: 5})
+
+	headline := map[string]MetricDelta{}
+	for _, m := range cmp.Headline {
+		headline[m.Field] = m
+	}
+	if m := headline["sharpe"]; !m.Significant || m.Delta > -0.59 || m.Delta < -0.61 {
+		t.Errorf("sharpe delta = %+v, want a significant -0.6", m)
+	}
+	if headline["final_capital"].Significant {
+		t.Error("a 0.1% change in final capital should not be significant at 5%")
+	}
+
+	groups := map[string]GroupComparison{}
+	for _, g := range cmp.BySymbol {
+		groups[g.Key] = g
+	}
+	if groups["WBTC/USDC"].Flipped != "to_loss" {
+		t.Errorf("WBTC should flip to a loss: %+v", groups["WBTC/USDC"])
+	}
+	if groups["LINK/USDC"].OnlyIn != "a" || groups["UNI/USDC"].OnlyIn != "b" {
+		t.Errorf("one-sided groups not reported: %+v %+v", groups["LINK/USDC"], groups["UNI/USDC"])
+	}
+
+	dd := map[string]MetricDelta{}
+	for _, m := range cmp.Drawdown {
+		dd[m.Field] = m
+	}
+	if m := dd["longest_drawdown_trades"]; m.A != 1 || m.B != 2 {
+		t.Errorf("longest drawdown = %v → %v, want 1 → 2", m.A, m.B)
+	}
+	if len(cmp.Config) != 1 || cmp.Config[0].Key != "order_risk_pct" {
+		t.Errorf("config changes = %+v, want only order_risk_pct", cmp.Config)
+	}
+
+	var out bytes.Buffer
+	if err := cmp.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"HEADLINE", "DRAWDOWN PROFILE", "WBTC/USDC: ⚠️ flipped from profitable to losing", "UNI/USDC: only in b", "order_risk_pct"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCompareReportsToleratesSchemaDrift(t *testing.T) {
+	dir := t.TempDir()
+	// An older report without sharpe, equity curve or strike-type stats, plus a field the new one lacks
+	old := `{"schema_version": 0, "result": {"final_capital": 1000, "return_pct": 0, "legacy_score": 3},
+		"by_symbol": {"WETH/USDC": {"pnl": 5, "strikes": 2}}}`
+	oldPath := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(oldPath, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadReportDocument(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := reportDoc(t, &CampaignReport{
+		SchemaVersion: SchemaVersion,
+		Result:        &CampaignResult{FinalCapital: 1200, ReturnPct: 20, Sharpe: 2},
+		BySymbol:      map[string]GroupStats{"WETH/USDC": {Strikes: 3, Wins: 2, PnL: 9, WinRate: 0.67}},
+		EquityCurve:   []EquityPoint{{Trade: 0, Capital: 1000}},
+	})
+	cmp := CompareReports(a, b, CompareThresholds{MinPct: 5})
+
+	var fields []string
+	for _, m := range cmp.Headline {
+		fields = append(fields, m.Field)
+	}
+	if strings.Join(fields, ",") != "final_capital,return_pct" {
+		t.Errorf("headline fields = %v, want only the common final_capital,return_pct", fields)
+	}
+	if cmp.Drawdown != nil || cmp.ByStrikeType != nil {
+		t.Error("sections missing from one report should be omitted")
+	}
+	if len(cmp.BySymbol) != 1 || len(cmp.BySymbol[0].Metrics) != 2 {
+		t.Errorf("by_symbol = %+v, want pnl and strikes only", cmp.BySymbol)
+	}
+	var out bytes.Buffer
+	cmp.WriteText(&out)
+	if !strings.Contains(out.String(), "schema versions differ") {
+		t.Errorf("schema drift not noted:\n%s", out.String())
+	}
+}
+
+// reportDoc round-trips a report through JSON into a generic document
+func reportDoc(t *testing.T, r *CampaignReport) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// pnlDayLayout keys daily buckets by UTC calendar date
+const pnlDayLayout = "2006-01-02"
+
+// PnLBucket is realized PnL and trade counts for one day or hour-of-day
+type PnLBucket struct {
+	Day     string  `json:"day,omitempty"`
+	Hour    *int    `json:"hour,omitempty"`
+	Trades  int64   `json:"trades"`
+	Wins    int64   `json:"wins"`
+	PnL     float64 `json:"pnl"`
+	WinRate float64 `json:"win_rate"`
+}
+
+// PnLRollupSnapshot is the copyable view of the rollups, also persisted in
+// the state file. ByHour always has 24 entries, hour 0 first.
+type PnLRollupSnapshot struct {
+	ByDay  []PnLBucket `json:"by_day"`
+	ByHour []PnLBucket `json:"by_hour"`
+}
+
+// PnLRollups buckets realized PnL by UTC day and UTC hour-of-day
+type PnLRollups struct {
+	mu     sync.Mutex
+	byDay  map[string]*PnLBucket
+	byHour [24]PnLBucket
+}
+
+// NewPnLRollups returns empty rollups
+func NewPnLRollups() *PnLRollups {
+	return &PnLRollups{byDay: make(map[string]*PnLBucket)}
+}
+
+// Record adds one completed trade closed at the given time
+func (r *PnLRollups) Record(at time.Time, pnl float64, win bool) {
+	at = at.UTC()
+	day := at.Format(pnlDayLayout)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.byDay[day]
+	if !ok {
+		d = &PnLBucket{Day: day}
+		r.byDay[day] = d
+	}
+	for _, b := range []*PnLBucket{d, &r.byHour[at.Hour()]} {
+		b.Trades++
+		if win {
+			b.Wins++
+		}
+		b.PnL += pnl
+	}
+}
+
+// Snapshot copies the rollups, days in date order, with win rates filled in
+func (r *PnLRollups) Snapshot() PnLRollupSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snap := PnLRollupSnapshot{
+		ByDay:  make([]PnLBucket, 0, len(r.byDay)),
+		ByHour: make([]PnLBucket, 24),
+	}
+	for _, d := range r.byDay {
+		snap.ByDay = append(snap.ByDay, withWinRate(*d))
+	}
+	sort.Slice(snap.ByDay, func(i, j int) bool { return snap.ByDay[i].Day < snap.ByDay[j].Day })
+	for h := range r.byHour {
+		b := withWinRate(r.byHour[h])
+		hour := h
+		b.Hour = &hour
+		snap.ByHour[h] = b
+	}
+	return snap
+}
+
+// Restore replaces the rollups with a persisted snapshot
+func (r *PnLRollups) Restore(snap PnLRollupSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byDay = make(map[string]*PnLBucket, len(snap.ByDay))
+	for _, d := range snap.ByDay {
+		b := d
+		b.Hour = nil
+		r.byDay[b.Day] = &b
+	}
+	r.byHour = [24]PnLBucket{}
+	for _, h := range snap.ByHour {
+		if h.Hour == nil || *h.Hour < 0 || *h.Hour > 23 {
+			continue
+		}
+		b := h
+		b.Hour = nil
+		r.byHour[*h.Hour] = b
+	}
+}
+
+// DayPnL returns the realized PnL booked on at's UTC day
+func (r *PnLRollups) DayPnL(at time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.byDay[at.UTC().Format(pnlDayLayout)]; ok {
+		return d.PnL
+	}
+	return 0
+}
+
+func withWinRate(b PnLBucket) PnLBucket {
+	if b.Trades > 0 {
+		b.WinRate = float64(b.Wins) / float64(b.Trades)
+	}
+	return b
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPnLRollupsSplitAtUTCMidnight(t *testing.T) {
+	r := NewPnLRollups()
+	r.Record(time.Date(2025, 3, 9, 23, 59, 59, 0, time.UTC), 10, true)
+	r.Record(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), -4, false)
+	r.Record(time.Date(2025, 3, 10, 0, 30, 0, 0, time.UTC), 6, true)
+
+	snap := r.Snapshot()
+	if len(snap.ByDay) != 2 {
+		t.Fatalf("want 2 day buckets, got %+v", snap.ByDay)
+	}
+	if d := snap.ByDay[0]; d.Day != "2025-03-09" || d.Trades != 1 || d.PnL != 10 {
+		t.Errorf("first day = %+v", d)
+	}
+	if d := snap.ByDay[1]; d.Day != "2025-03-10" || d.Trades != 2 || d.Wins != 1 || d.PnL != 2 || d.WinRate != 0.5 {
+		t.Errorf("second day = %+v", d)
+	}
+	if h := snap.ByHour[23]; h.Trades != 1 || h.PnL != 10 {
+		t.Errorf("hour 23 = %+v", h)
+	}
+	if h := snap.ByHour[0]; h.Trades != 2 || h.PnL != 2 {
+		t.Errorf("hour 0 = %+v", h)
+	}
+	if len(snap.ByHour) != 24 || *snap.ByHour[17].Hour != 17 {
+		t.Errorf("hour buckets not indexed by hour: %+v", snap.ByHour)
+	}
+}
+
+func TestPnLRollupsBucketLocalTimesInUTC(t *testing.T) {
+	// 2025-03-09 is the US spring-forward day; a fixed offset keeps the test
+	// independent of the host's tz database
+	est := time.FixedZone("EST", -5*3600)
+	edt := time.FixedZone("EDT", -4*3600)
+	r := NewPnLRollups()
+	r.Record(time.Date(2025, 3, 9, 1, 30, 0, 0, est), 1, true)  // 06:30 UTC
+	r.Record(time.Date(2025, 3, 9, 3, 30, 0, 0, edt), 1, true)  // 07:30 UTC
+	r.Record(time.Date(2025, 3, 9, 20, 0, 0, 0, edt), 1, false) // 00:00 UTC next day
+
+	snap := r.Snapshot()
+	if snap.ByHour[6].Trades != 1 || snap.ByHour[7].Trades != 1 || snap.ByHour[0].Trades != 1 {
+		t.Errorf("hours not bucketed in UTC: 6=%d 7=%d 0=%d",
+			snap.ByHour[6].Trades, snap.ByHour[7].Trades, snap.ByHour[0].Trades)
+	}
+	if len(snap.ByDay) != 2 || snap.ByDay[1].Day != "2025-03-10" {
+		t.Errorf("days not bucketed in UTC: %+v", snap.ByDay)
+	}
+}
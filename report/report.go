@@ -0,0 +1,504 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"macro-strike-bot/config"
+)
+
+// SchemaVersion identifies the layout of campaign_report.json
+const SchemaVersion = 1
+
+// GroupStats aggregates completed strikes for one symbol or strike type
+type GroupStats struct {
+	Strikes int64   `json:"strikes"`
+	Wins    int64   `json:"wins"`
+	Losses  int64   `json:"losses"`
+	PnL     float64 `json:"pnl"`
+	Fees    float64 `json:"fees"`
+	Funding float64 `json:"funding"`
+	WinRate float64 `json:"win_rate"`
+}
+
+// EquityPoint is capital after a completed trade
+type EquityPoint struct {
+	Trade   int64     `json:"trade"`
+	Time    time.Time `json:"time"`
+	Capital float64   `json:"capital"`
+}
+
+// CampaignStats accumulates per-symbol, per-type and equity-curve data as
+// strikes complete
+type CampaignStats struct {
+	mu          sync.Mutex
+	bySymbol    map[string]*GroupStats
+	byType      map[string]*GroupStats
+	equityCurve []EquityPoint
+	trades      TradeDistribution
+}
+
+// TradeDistribution is the running per-trade PnL distribution (dollars)
+type TradeDistribution struct {
+	N       int64   `json:"n"`
+	Mean    float64 `json:"mean"`
+	M2      float64 `json:"m2"`
+	Wins    int64   `json:"wins"`
+	WinSum  float64 `json:"win_sum"`
+	Losses  int64   `json:"losses"`
+	LossSum float64 `json:"loss_sum"`
+}
+
+// CampaignStatsSnapshot is the persisted form of CampaignStats, carried in
+// engine state so a resumed campaign reports on every trade it has made
+type CampaignStatsSnapshot struct {
+	BySymbol    map[string]GroupStats `json:"by_symbol"`
+	ByType      map[string]GroupStats `json:"by_type"`
+	EquityCurve []EquityPoint         `json:"equity_curve"`
+	Trades      TradeDistribution     `json:"trades"`
+}
+
+// NewCampaignStats starts an equity curve at the given capital (dollars)
+func NewCampaignStats(start time.Time, startCapital float64) *CampaignStats {
+	return &CampaignStats{
+		bySymbol:    make(map[string]*GroupStats),
+		byType:      make(map[string]*GroupStats),
+		equityCurve: []EquityPoint{{Trade: 0, Time: start, Capital: startCapital}},
+	}
+}
+
+// Trade is the part of a completed strike the stats aggregate
+type Trade struct {
+	Symbol     string
+	StrikeType string
+	// Win and Loss are both false for a strike that neither hit nor missed
+	Win     bool
+	Loss    bool
+	PnL     float64
+	Fees    float64
+	Funding float64
+}
+
+// Record adds a completed trade and the capital after it
+func (cs *CampaignStats) Record(t Trade, at time.Time, capitalAfter float64) {
+	pnl := t.PnL
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, g := range []*GroupStats{groupFor(cs.bySymbol, t.Symbol), groupFor(cs.byType, t.StrikeType)} {
+		g.Strikes++
+		if t.Win {
+			g.Wins++
+		} else if t.Loss {
+			g.Losses++
+		}
+		g.PnL += pnl
+		g.Fees += t.Fees
+		g.Funding += t.Funding
+	}
+	d := &cs.trades
+	d.N++
+	delta := pnl - d.Mean
+	d.Mean += delta / float64(d.N)
+	d.M2 += delta * (pnl - d.Mean)
+	if pnl >= 0 {
+		d.Wins++
+		d.WinSum += pnl
+	} else {
+		d.Losses++
+		d.LossSum += pnl
+	}
+	last := cs.equityCurve[len(cs.equityCurve)-1]
+	cs.equityCurve = append(cs.equityCurve, EquityPoint{Trade: last.Trade + 1, Time: at, Capital: capitalAfter})
+}
+
+func groupFor(m map[string]*GroupStats, key string) *GroupStats {
+	g, ok := m[key]
+	if !ok {
+		g = &GroupStats{}
+		m[key] = g
+	}
+	return g
+}
+
+// snapshot copies the accumulated stats, filling in derived win rates
+func (cs *CampaignStats) snapshot() (map[string]GroupStats, map[string]GroupStats, []EquityPoint) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	copyGroups := func(src map[string]*GroupStats) map[string]GroupStats {
+		out := make(map[string]GroupStats, len(src))
+		for k, g := range src {
+			c := *g
+			if c.Strikes > 0 {
+				c.WinRate = float64(c.Wins) / float64(c.Strikes)
+			}
+			out[k] = c
+		}
+		return out
+	}
+	curve := make([]EquityPoint, len(cs.equityCurve))
+	copy(curve, cs.equityCurve)
+	return copyGroups(cs.bySymbol), copyGroups(cs.byType), curve
+}
+
+// CampaignReport is the end-of-campaign document written as JSON and HTML
+type CampaignReport struct {
+	SchemaVersion int                    `json:"schema_version"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+	Result        *CampaignResult        `json:"result"`
+	Config        map[string]interface{} `json:"config"`
+	BySymbol      map[string]GroupStats  `json:"by_symbol"`
+	ByStrikeType  map[string]GroupStats  `json:"by_strike_type"`
+	SkipReasons   map[string]int64       `json:"skip_reasons"`
+	PnLByDay      []PnLBucket            `json:"pnl_by_day"`
+	PnLByHour     []PnLBucket            `json:"pnl_by_hour"`
+	EquityCurve   []EquityPoint          `json:"equity_curve"`
+
+	// Redactor masks secrets in everything the report writes
+	Redactor *config.Redactor `json:"-"`
+}
+
+// Distribution returns the running per-trade PnL distribution and the time
+// the equity curve starts from
+func (cs *CampaignStats) Distribution() (TradeDistribution, time.Time) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.trades, cs.equityCurve[0].Time
+}
+
+// Snapshot returns a copy of the stats suitable for persisting
+func (cs *CampaignStats) Snapshot() CampaignStatsSnapshot {
+	bySymbol, byType, curve := cs.snapshot()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return CampaignStatsSnapshot{BySymbol: bySymbol, ByType: byType, EquityCurve: curve, Trades: cs.trades}
+}
+
+// RestoreCampaignStats rebuilds stats saved by Snapshot; a snapshot without an
+// equity curve is rejected since every curve starts with the campaign's capital
+func RestoreCampaignStats(snap CampaignStatsSnapshot) (*CampaignStats, error) {
+	if len(snap.EquityCurve) == 0 {
+		return nil, fmt.Errorf("campaign stats snapshot has no equity curve")
+	}
+	cs := &CampaignStats{
+		bySymbol:    make(map[string]*GroupStats, len(snap.BySymbol)),
+		byType:      make(map[string]*GroupStats, len(snap.ByType)),
+		equityCurve: append([]EquityPoint(nil), snap.EquityCurve...),
+		trades:      snap.Trades,
+	}
+	for k, g := range snap.BySymbol {
+		g := g
+		cs.bySymbol[k] = &g
+	}
+	for k, g := range snap.ByType {
+		g := g
+		cs.byType[k] = &g
+	}
+	return cs, nil
+}
+
+// WriteJSON writes the report to path
+func (r *CampaignReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(r.Redactor.Redact(string(data))), 0644)
+}
+
+// LoadCampaignReport reads a report written by WriteJSON
+func LoadCampaignReport(path string) (*CampaignReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r CampaignReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if r.Result == nil {
+		return nil, fmt.Errorf("%s: not a campaign report", path)
+	}
+	return &r, nil
+}
+
+// WriteSummary writes the headline result and the per-symbol table as text
+func (r *CampaignReport) WriteSummary(w io.Writer) error {
+	w = r.Redactor.Writer(w)
+	res := r.Result
+	fmt.Fprintf(w, "Run %s (%s, stopped: %s)\n", res.RunID, res.Elapsed.Round(time.Second), res.StopReason)
+	fmt.Fprintf(w, "Capital $%.2f -> $%.2f (%+.2f%%), max drawdown %.2f%%, sharpe %.2f\n",
+		res.StartCapital, res.FinalCapital, res.ReturnPct, res.MaxDrawdownPct, res.Sharpe)
+	fmt.Fprintf(w, "Trades %d: %d wins, %d losses, %d aborted, $%.2f in fees\n\n", res.TradesCompleted, res.Wins, res.Losses, res.Aborted, res.TotalFees)
+	symbols := make([]string, 0, len(r.BySymbol))
+	for sym := range r.BySymbol {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "symbol\tstrikes\twin rate\tpnl\tfees\tfunding\t")
+	for _, sym := range symbols {
+		g := r.BySymbol[sym]
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%.2f\t%.2f\t%.2f\t\n", sym, g.Strikes, g.WinRate*100, g.PnL, g.Fees, g.Funding)
+	}
+	return tw.Flush()
+}
+
+// Chart geometry for the inline SVGs
+const (
+	chartWidth   = 800.0
+	chartHeight  = 220.0
+	chartPadding = 10.0
+	chartMaxPts  = 1000
+)
+
+// svgChart is a pre-computed polyline for the HTML template
+type svgChart struct {
+	Points string
+	Min    float64
+	Max    float64
+	Empty  bool
+}
+
+// buildSVGChart scales values into the chart box, downsampling long series
+func buildSVGChart(values []float64) svgChart {
+	if len(values) < 2 {
+		return svgChart{Empty: true}
+	}
+	step := 1
+	if len(values) > chartMaxPts {
+		step = (len(values) + chartMaxPts - 1) / chartMaxPts
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	span := max - min
+	if span == 0 {
+		span = 1
+	}
+	var sb strings.Builder
+	n := len(values) - 1
+	for i := 0; i <= n; i += step {
+		writeSVGPoint(&sb, i, n, values[i], min, span)
+	}
+	if n%step != 0 {
+		writeSVGPoint(&sb, n, n, values[n], min, span)
+	}
+	return svgChart{Points: strings.TrimSpace(sb.String()), Min: min, Max: max}
+}
+
+func writeSVGPoint(sb *strings.Builder, i, n int, v, min, span float64) {
+	x := chartPadding + float64(i)/float64(n)*(chartWidth-2*chartPadding)
+	y := chartHeight - chartPadding - (v-min)/span*(chartHeight-2*chartPadding)
+	fmt.Fprintf(sb, "%.1f,%.1f ", x, y)
+}
+
+// reportGroupRow is one row of a per-symbol or per-type table
+type reportGroupRow struct {
+	Name string
+	GroupStats
+}
+
+func sortedGroupRows(m map[string]GroupStats) []reportGroupRow {
+	rows := make([]reportGroupRow, 0, len(m))
+	for name, g := range m {
+		rows = append(rows, reportGroupRow{Name: name, GroupStats: g})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+// reportView is the data handed to the HTML template
+type reportView struct {
+	Report   *CampaignReport
+	Result   CampaignResult
+	Equity   svgChart
+	Drawdown svgChart
+	Symbols  []reportGroupRow
+	Types    []reportGroupRow
+	Config   []reportConfigRow
+	Skips    []reportConfigRow
+	Hours    []PnLBucket
+	Width    float64
+	Height   float64
+}
+
+type reportConfigRow struct {
+	Key   string
+	Value string
+}
+
+// drawdownSeries returns the percentage below the running peak at each point
+// of the curve, with the peak seeded from the starting capital
+func drawdownSeries(curve []EquityPoint) []float64 {
+	drawdown := make([]float64, len(curve))
+	if len(curve) == 0 {
+		return drawdown
+	}
+	peak := curve[0].Capital
+	for i, p := range curve {
+		if p.Capital > peak {
+			peak = p.Capital
+		}
+		if peak > 0 {
+			drawdown[i] = -(peak - p.Capital) / peak * 100.0
+		}
+	}
+	return drawdown
+}
+
+// WriteHTML renders a self-contained HTML report (inline CSS and SVG only)
+func (r *CampaignReport) WriteHTML(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.RenderHTML(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RenderHTML writes the HTML report to w
+func (r *CampaignReport) RenderHTML(w io.Writer) error {
+	w = r.Redactor.Writer(w)
+	view := reportView{Report: r, Width: chartWidth, Height: chartHeight}
+	if r.Result != nil {
+		view.Result = *r.Result
+	}
+
+	equity := make([]float64, len(r.EquityCurve))
+	for i, p := range r.EquityCurve {
+		equity[i] = p.Capital
+	}
+	view.Equity = buildSVGChart(equity)
+	view.Drawdown = buildSVGChart(drawdownSeries(r.EquityCurve))
+	view.Symbols = sortedGroupRows(r.BySymbol)
+	view.Types = sortedGroupRows(r.ByStrikeType)
+	for _, h := range r.PnLByHour {
+		if h.Trades > 0 {
+			view.Hours = append(view.Hours, h)
+		}
+	}
+	for k, v := range r.Config {
+		view.Config = append(view.Config, reportConfigRow{Key: k, Value: fmt.Sprintf("%v", v)})
+	}
+	sort.Slice(view.Config, func(i, j int) bool { return view.Config[i].Key < view.Config[j].Key })
+	for k, v := range r.SkipReasons {
+		view.Skips = append(view.Skips, reportConfigRow{Key: k, Value: fmt.Sprintf("%d", v)})
+	}
+	sort.Slice(view.Skips, func(i, j int) bool { return view.Skips[i].Key < view.Skips[j].Key })
+	return reportTemplate.Execute(w, view)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": func(v float64) string { return fmt.Sprintf("%.2f%%", v*100.0) },
+	"usd": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"f2":  func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"f3":  func(v float64) string { return fmt.Sprintf("%.3f", v) },
+	"dur": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"ts":  func(t time.Time) string { return t.Format(time.RFC3339) },
+	"deref": func(p *int) int {
+		if p == nil {
+			return 0
+		}
+		return *p
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Macro Strike Campaign {{.Result.RunID}}</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; } h2 { font-size: 1.1em; margin-top: 1.6em; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.headline td { font-weight: bold; }
+.pos { color: #1a7f37; } .neg { color: #cf222e; }
+svg { background: #fafafa; border: 1px solid #ddd; }
+.empty { color: #888; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>Macro Strike Campaign Report</h1>
+<p>Run <code>{{.Result.RunID}}</code> &middot; generated {{ts .Report.GeneratedAt}}</p>
+
+<h2>Headline</h2>
+<table class="headline">
+<tr><th>Start capital</th><td>{{usd .Result.StartCapital}}</td></tr>
+<tr><th>Final capital</th><td>{{usd .Result.FinalCapital}}</td></tr>
+<tr><th>Return</th><td class="{{if lt .Result.ReturnPct 0.0}}neg{{else}}pos{{end}}">{{f2 .Result.ReturnPct}}%</td></tr>
+<tr><th>Trades</th><td>{{.Result.TradesCompleted}}</td></tr>
+<tr><th>Wins / Losses / Aborted</th><td>{{.Result.Wins}} / {{.Result.Losses}} / {{.Result.Aborted}}</td></tr>
+<tr><th>Max drawdown</th><td>{{f2 .Result.MaxDrawdownPct}}%</td></tr>
+<tr><th>Sharpe (per trade)</th><td>{{f3 .Result.Sharpe}}</td></tr>
+<tr><th>Elapsed</th><td>{{dur .Result.Elapsed}}</td></tr>
+<tr><th>Stop reason</th><td>{{.Result.StopReason}}</td></tr>
+</table>
+
+<h2>Equity curve</h2>
+{{if .Equity.Empty}}<p class="empty">No completed trades.</p>{{else}}
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+<polyline fill="none" stroke="#0969da" stroke-width="1.5" points="{{.Equity.Points}}"/>
+</svg>
+<p>Range {{usd .Equity.Min}} &ndash; {{usd .Equity.Max}}</p>{{end}}
+
+<h2>Drawdown</h2>
+{{if .Drawdown.Empty}}<p class="empty">No completed trades.</p>{{else}}
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+<polyline fill="none" stroke="#cf222e" stroke-width="1.5" points="{{.Drawdown.Points}}"/>
+</svg>
+<p>Deepest {{f2 .Drawdown.Min}}%</p>{{end}}
+
+<h2>By symbol</h2>
+{{if .Symbols}}<table>
+<tr><th>Symbol</th><th>Strikes</th><th>Wins</th><th>Losses</th><th>Win rate</th><th>PnL</th><th>Fees</th><th>Funding</th></tr>
+{{range .Symbols}}<tr><td>{{.Name}}</td><td>{{.Strikes}}</td><td>{{.Wins}}</td><td>{{.Losses}}</td><td>{{pct .WinRate}}</td><td class="{{if lt .PnL 0.0}}neg{{else}}pos{{end}}">{{usd .PnL}}</td><td>{{usd .Fees}}</td><td>{{usd .Funding}}</td></tr>
+{{end}}</table>{{else}}<p class="empty">No completed trades.</p>{{end}}
+
+<h2>By strike type</h2>
+{{if .Types}}<table>
+<tr><th>Strike type</th><th>Strikes</th><th>Wins</th><th>Losses</th><th>Win rate</th><th>PnL</th><th>Fees</th><th>Funding</th></tr>
+{{range .Types}}<tr><td>{{.Name}}</td><td>{{.Strikes}}</td><td>{{.Wins}}</td><td>{{.Losses}}</td><td>{{pct .WinRate}}</td><td class="{{if lt .PnL 0.0}}neg{{else}}pos{{end}}">{{usd .PnL}}</td><td>{{usd .Fees}}</td><td>{{usd .Funding}}</td></tr>
+{{end}}</table>{{else}}<p class="empty">No completed trades.</p>{{end}}
+
+<h2>PnL by day (UTC)</h2>
+{{if .Report.PnLByDay}}<table>
+<tr><th>Day</th><th>Trades</th><th>Wins</th><th>Win rate</th><th>PnL</th></tr>
+{{range .Report.PnLByDay}}<tr><td>{{.Day}}</td><td>{{.Trades}}</td><td>{{.Wins}}</td><td>{{pct .WinRate}}</td><td class="{{if lt .PnL 0.0}}neg{{else}}pos{{end}}">{{usd .PnL}}</td></tr>
+{{end}}</table>{{else}}<p class="empty">No completed trades.</p>{{end}}
+
+<h2>PnL by hour of day (UTC)</h2>
+{{if .Hours}}<table>
+<tr><th>Hour</th><th>Trades</th><th>Wins</th><th>Win rate</th><th>PnL</th></tr>
+{{range .Hours}}<tr><td>{{printf "%02d:00" (deref .Hour)}}</td><td>{{.Trades}}</td><td>{{.Wins}}</td><td>{{pct .WinRate}}</td><td class="{{if lt .PnL 0.0}}neg{{else}}pos{{end}}">{{usd .PnL}}</td></tr>
+{{end}}</table>{{else}}<p class="empty">No completed trades.</p>{{end}}
+
+{{if .Skips}}<h2>Skipped setups</h2>
+<table>
+{{range .Skips}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+
+<h2>Configuration</h2>
+<table>
+{{range .Config}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
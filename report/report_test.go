@@ -0,0 +1,18 @@
+package report
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDrawdownSeriesSeedsPeakFromStartingCapital(t *testing.T) {
+	// An opening loss is already a drawdown from the starting capital
+	curve := []EquityPoint{{Capital: 100}, {Capital: 90}, {Capital: 120}, {Capital: 108}}
+	want := []float64{0, -10, 0, -10}
+	got := drawdownSeries(curve)
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("drawdown[%d] = %.4f, want %.4f", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package report holds what a finished campaign produces: its result, the
+// per-symbol and per-type statistics behind it, and the JSON, HTML and text
+// reports built from them.
+package report
+
+import (
+	"time"
+)
+
+// Campaign stop reasons reported in CampaignResult
+const (
+	StopTradesCompleted    = "trades_completed"
+	StopTargetReached      = "target_reached"
+	StopCampaignWindow     = "campaign_window"
+	StopEmergency          = "emergency_stop"
+	StopBankrupt           = "blown_up"
+	StopCapitalFloor       = "capital_floor"
+	StopGeneratorExhausted = "generator_exhausted"
+	StopShutdown           = "shutdown"
+	StopKilled             = "killed"
+	StopCanceled           = "canceled"
+	StopAnalyzerMissing    = "analyzer_missing"
+	StopNonFiniteAnalysis  = "nonfinite_analysis"
+)
+
+// CampaignResult summarises a finished campaign for programmatic callers
+type CampaignResult struct {
+	RunID string `json:"run_id"`
+	// StartCapital is the capital the campaign first started with, even
+	// after a resume; ReturnPct and the report tables cover every trade since
+	StartCapital    float64       `json:"start_capital"`
+	FinalCapital    float64       `json:"final_capital"`
+	ReturnPct       float64       `json:"return_pct"`
+	TradesCompleted int64         `json:"trades_completed"`
+	Wins            int64         `json:"wins"`
+	Losses          int64         `json:"losses"`
+	Aborted         int64         `json:"aborted"`
+	MaxDrawdownPct  float64       `json:"max_drawdown_pct"`
+	Sharpe          float64       `json:"sharpe"`
+	TotalFees       float64       `json:"total_fees"`
+	Elapsed         time.Duration `json:"elapsed_ns"`
+	StopReason      string        `json:"stop_reason"`
+	// StageLatency holds per-stage pipeline percentiles over recent strikes
+	StageLatency map[string]StageLatency `json:"stage_latency,omitempty"`
+}
+
+// StageLatency summarizes one stage's recent durations
+type StageLatency struct {
+	Samples int     `json:"samples"`
+	P50Ms   float64 `json:"p50_ms"`
+	P90Ms   float64 `json:"p90_ms"`
+	P99Ms   float64 `json:"p99_ms"`
+	MaxMs   float64 `json:"max_ms"`
+}
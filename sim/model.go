@@ -0,0 +1,131 @@
+// Package sim models how simulated strikes play out: the hit model that
+// calibrates a strike's stated confidence, the chance its levels are reached,
+// and the price it exits at.
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"macro-strike-bot/config"
+)
+
+// HitModel calibrates stated confidence to the hit rate the simulation
+// actually realizes, so a run can model a generator whose confidence
+// overstates (or understates) its edge. The zero value is the identity.
+type HitModel struct {
+	// Realized = confidence^Exponent when set (>1 models overconfidence)
+	Exponent float64
+	// Piecewise-linear curve through (confidence, realized) points, anchored
+	// at (0,0) and (1,1); used when Exponent is 0
+	Points [][2]float64
+}
+
+// ParseHitModel reads SIM_HIT_MODEL: "identity", "power:1.3", or
+// "curve:0.6=0.55,0.8=0.65" listing confidence=realized points
+func ParseHitModel(raw string) (HitModel, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(raw), ":")
+	switch kind {
+	case "", "identity":
+		return HitModel{}, nil
+	case "power":
+		k, err := strconv.ParseFloat(arg, 64)
+		if err != nil || k <= 0 {
+			return HitModel{}, fmt.Errorf("power exponent %q is not a positive number", arg)
+		}
+		return HitModel{Exponent: k}, nil
+	case "curve":
+		pairs, err := config.ParseKeyValueList(arg)
+		if err != nil {
+			return HitModel{}, err
+		}
+		var m HitModel
+		for c, r := range pairs {
+			conf, err1 := strconv.ParseFloat(c, 64)
+			realized, err2 := strconv.ParseFloat(r, 64)
+			if err1 != nil || err2 != nil || conf <= 0 || conf >= 1 || realized < 0 || realized > 1 {
+				return HitModel{}, fmt.Errorf("curve point %s=%s must map (0,1) to [0,1]", c, r)
+			}
+			m.Points = append(m.Points, [2]float64{conf, realized})
+		}
+		if len(m.Points) == 0 {
+			return HitModel{}, fmt.Errorf("curve has no points")
+		}
+		sort.Slice(m.Points, func(i, j int) bool { return m.Points[i][0] < m.Points[j][0] })
+		return m, nil
+	default:
+		return HitModel{}, fmt.Errorf("%q is not identity, power:<k> or curve:<c=r,...>", raw)
+	}
+}
+
+// Realized maps a stated confidence to its simulated hit rate
+func (m HitModel) Realized(conf float64) float64 {
+	if conf <= 0 || conf >= 1 {
+		return conf
+	}
+	if m.Exponent > 0 {
+		return math.Pow(conf, m.Exponent)
+	}
+	if len(m.Points) == 0 {
+		return conf
+	}
+	prev := [2]float64{0, 0}
+	for _, p := range m.Points {
+		if conf <= p[0] {
+			return prev[1] + (conf-prev[0])/(p[0]-prev[0])*(p[1]-prev[1])
+		}
+		prev = p
+	}
+	return prev[1] + (conf-prev[0])/(1-prev[0])*(1-prev[1])
+}
+
+// String renders the model the way SIM_HIT_MODEL spells it
+func (m HitModel) String() string {
+	if m.Exponent > 0 {
+		return "power:" + strconv.FormatFloat(m.Exponent, 'g', -1, 64)
+	}
+	if len(m.Points) == 0 {
+		return "identity"
+	}
+	pts := make([]string, len(m.Points))
+	for i, p := range m.Points {
+		pts[i] = strconv.FormatFloat(p[0], 'g', -1, 64) + "=" + strconv.FormatFloat(p[1], 'g', -1, 64)
+	}
+	return "curve:" + strings.Join(pts, ",")
+}
+
+// HitProbability is the chance a simulated strike reaches its target before
+// its stop. Confidence, calibrated through the model, is read as the hit rate
+// of a symmetric bracket; the strike's actual levels reweight it the way a
+// drifted random walk would (gambler's ruin), so a far target over a tight
+// stop hits less often than a near target over a wide one. Levels that don't
+// bracket the entry fall back to the calibrated confidence.
+func (m HitModel) HitProbability(entry, target, stop, confidence float64) float64 {
+	conf := m.Realized(confidence)
+	up := target - entry
+	down := entry - stop
+	if conf <= 0 || conf >= 1 || up <= 0 || down <= 0 {
+		return conf
+	}
+	// Drift per unit of price that makes a ±down bracket hit with probability conf
+	theta := math.Log(conf/(1-conf)) / down
+	if theta == 0 {
+		return down / (up + down)
+	}
+	return math.Expm1(theta*down) / (math.Exp(theta*down) - math.Exp(-theta*up))
+}
+
+// ExitPrice is where a simulated strike leaves the market: the level it
+// touched, or entry moved by noise when it has no valid bracket
+func ExitPrice(entry, target, stop float64, hit bool, noise float64) float64 {
+	if target > entry && stop < entry && stop > 0 {
+		if hit {
+			return target
+		}
+		return stop
+	}
+	return entry * (1.0 + noise)
+}
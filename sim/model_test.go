@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHitProbabilityFollowsLevels(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		target, stop float64
+		conf, want   float64
+	}{
+		{"symmetric bracket keeps confidence", 102, 98, 0.7, 0.7},
+		{"coin flip scales with distance", 103, 99, 0.5, 0.25},
+		{"near target over wide stop", 101, 97, 0.6, 0.798},
+		{"far target over tight stop", 103, 99, 0.6, 0.4154},
+		{"no bracket falls back to confidence", 0, 0, 0.8, 0.8},
+		{"certain hit", 103, 99, 1, 1},
+		{"certain miss", 101, 97, 0, 0},
+	} {
+		if got := (HitModel{}).HitProbability(100, tc.target, tc.stop, tc.conf); math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("%s: p = %.4f, want %.3f", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHitModelCalibratesConfidence(t *testing.T) {
+	for _, tc := range []struct {
+		raw        string
+		conf, want float64
+	}{
+		{"", 0.7, 0.7},
+		{"identity", 0.7, 0.7},
+		{"power:1.3", 0.7, math.Pow(0.7, 1.3)},
+		{"curve:0.6=0.5,0.8=0.6", 0.3, 0.25},
+		{"curve:0.6=0.5,0.8=0.6", 0.7, 0.55},
+		{"curve:0.6=0.5,0.8=0.6", 0.9, 0.8},
+		// Certain outcomes stay certain under any calibration
+		{"power:1.3", 1, 1},
+	} {
+		m, err := ParseHitModel(tc.raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.raw, err)
+		}
+		if got := m.Realized(tc.conf); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: realized(%.2f) = %.4f, want %.4f", m, tc.conf, got, tc.want)
+		}
+	}
+	for _, raw := range []string{"power:0", "power:x", "curve:", "curve:1.2=0.5", "logistic:2"} {
+		if _, err := ParseHitModel(raw); err == nil {
+			t.Errorf("parse %q should fail", raw)
+		}
+	}
+
+	// An overconfident model shrinks the symmetric-bracket hit rate
+	if got := (HitModel{Exponent: 1.3}).HitProbability(100, 102, 98, 0.7); math.Abs(got-math.Pow(0.7, 1.3)) > 1e-9 {
+		t.Errorf("calibrated p = %.4f, want %.4f", got, math.Pow(0.7, 1.3))
+	}
+}
+
+func TestExitPriceUsesTouchedLevel(t *testing.T) {
+	if got := ExitPrice(100, 102, 98, true, 0.5); got != 102 {
+		t.Errorf("hit exit = %v, want the target", got)
+	}
+	if got := ExitPrice(100, 102, 98, false, 0.5); got != 98 {
+		t.Errorf("miss exit = %v, want the stop", got)
+	}
+	if got := ExitPrice(100, 0, 0, true, 0.01); math.Abs(got-101) > 1e-9 {
+		t.Errorf("unbracketed exit = %v, want entry moved by noise", got)
+	}
+}